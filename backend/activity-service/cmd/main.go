@@ -26,6 +26,8 @@ func main() {
 	}
 
 	db.Connect()
+	// Reject tokens revoked by user-service
+	auth.SessionValidator = db.ValidateSession
 
 	r := gin.Default()
 
@@ -43,13 +45,31 @@ func main() {
 
 	protected.POST("", handler.PostActivityHandler)
 	protected.GET("", handler.GetActivitiesHandler)
+	protected.POST("/import", handler.ImportWorkoutHandler)
 	protected.PUT("/:id", handler.UpdateActivityHandler)
 	protected.DELETE("/:id", handler.DeleteActivityHandler)
+	protected.PUT("/:id/image", handler.SetActivityImageHandler)
+	protected.DELETE("/:id/image", handler.DeleteActivityImageHandler)
+	protected.GET("/:id/track", handler.GetActivityTrackHandler)
+	protected.PUT("/:id/track", handler.PutActivityTrackHandler)
+	protected.GET("/:id/sets", handler.GetStrengthSetsHandler)
+	protected.PUT("/:id/sets", handler.PutStrengthSetsHandler)
+	protected.GET("/exercises", handler.GetExercisesHandler)
+	protected.GET("/exercises/:exercise_id/history", handler.GetExerciseHistoryHandler)
+	protected.GET("/data/export", handler.ExportActivityDataHandler)
+	protected.POST("/data/import", handler.ImportActivityDataHandler)
+	protected.GET("/records", handler.GetPersonalRecordsHandler)
 	protected.GET("/stats", handler.GetCurrentUserActivityStatsHandler)
 	protected.POST("/steps", handler.CreateStepEntryHandler)
 	protected.GET("/steps", handler.GetStepEntriesHandler)
 	protected.GET("/analytics/:user_id", handler.GetActivityAnalyticsHandler)
 
+	internal := r.Group("/internal")
+	internal.Use(auth.InternalMiddleware())
+	internal.DELETE("/users/:user_id", handler.PurgeUserDataHandler)
+	internal.GET("/users/:user_id/activity-summary", handler.GetActivitySummaryHandler)
+	internal.GET("/users/:user_id/activity-period", handler.GetActivityPeriodHandler)
+
 	cert_file := os.Getenv("TLS_CERT_PATH")
 	key_file := os.Getenv("TLS_KEY_PATH")
 
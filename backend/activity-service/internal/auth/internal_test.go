@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestInternalMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		configured     string
+		header         string
+		expectedStatus int
+	}{
+		{name: "Valid token", configured: "internal-secret", header: "internal-secret", expectedStatus: http.StatusOK},
+		{name: "Wrong token", configured: "internal-secret", header: "other", expectedStatus: http.StatusUnauthorized},
+		{name: "Missing header", configured: "internal-secret", header: "", expectedStatus: http.StatusUnauthorized},
+		{name: "Not configured", configured: "", header: "", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("INTERNAL_API_TOKEN", tt.configured)
+
+			router := gin.New()
+			router.Use(InternalMiddleware())
+			router.GET("/internal", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/internal", nil)
+			if tt.header != "" {
+				req.Header.Set(InternalTokenHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -8,9 +9,12 @@ import (
 	"time"
 
 	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/ffabious/healthy-summer/activity-service/internal/pagination"
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var DB *gorm.DB
@@ -62,9 +66,12 @@ func Connect() {
 		log.Fatalf("Failed to create enum type intensity_enum: %v", err)
 	}
 	log.Println("Database connection established successfully")
-	if err := DB.AutoMigrate(&model.Activity{}, &model.StepEntry{}); err != nil {
+	if err := DB.AutoMigrate(&model.Activity{}, &model.StepEntry{}, &model.TrackPoint{}, &model.WorkoutImport{}, &model.Exercise{}, &model.StrengthSet{}, &model.PersonalRecord{}); err != nil {
 		log.Fatalf("Failed to auto-migrate models: %v", err)
 	}
+	if err := SeedExercises(); err != nil {
+		log.Fatalf("Failed to seed exercise catalog: %v", err)
+	}
 	log.Println("Database models migrated successfully")
 	log.Println("Database connection and migration completed successfully")
 	log.Println("Database connection string:", dsn)
@@ -97,6 +104,34 @@ func GetActivitiesByUserID(userID string) (*[]model.Activity, error) {
 	return &activities, nil
 }
 
+// ListActivities returns one page of a user's activities ordered by
+// timestamp, optionally restricted to the given activity types.
+func ListActivities(userID string, types []string, params pagination.Params) (*model.ActivityPage, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	query := DB.Model(&model.Activity{}).Where("user_id = ?", userID)
+	if len(types) > 0 {
+		query = query.Where("type IN ?", types)
+	}
+	query = params.Filter(query, "timestamp")
+
+	page := model.ActivityPage{Activities: []model.Activity{}}
+	if err := query.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count activities for user %s: %w", userID, err)
+	}
+	if err := params.Page(query, "timestamp").Find(&page.Activities).Error; err != nil {
+		return nil, fmt.Errorf("failed to list activities for user %s: %w", userID, err)
+	}
+	page.Activities, page.NextCursor = pagination.Trim(page.Activities, params.Limit, func(a model.Activity) pagination.Cursor {
+		return pagination.Cursor{Timestamp: a.Timestamp, ID: a.ID}
+	})
+	return &page, nil
+}
+
 func GetActivityStatsByUserID(userID string) (*model.ActivityStats, error) {
 	if DB == nil {
 		return nil, fmt.Errorf("database connection is nil")
@@ -198,6 +233,57 @@ func GetActivityStatsByUserID(userID string) (*model.ActivityStats, error) {
 	return &stats, nil
 }
 
+// GetActivitySummaryByUserID returns the user's activity over the last week
+// and month and their most recent activity, if any.
+func GetActivitySummaryByUserID(userID string) (*model.ActivitySummary, error) {
+	stats, err := GetActivityStatsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity stats: %w", err)
+	}
+	summary := model.ActivitySummary{Week: stats.Week, Month: stats.Month}
+
+	var latest []model.Activity
+	if err := DB.Where("user_id = ?", userID).
+		Order("timestamp DESC").
+		Limit(1).
+		Find(&latest).Error; err != nil {
+		return nil, fmt.Errorf("failed to get latest activity: %w", err)
+	}
+	if len(latest) == 1 {
+		summary.LastActivityType = latest[0].Type
+		summary.LastActivityAt = &latest[0].Timestamp
+	}
+	return &summary, nil
+}
+
+// GetActivityPeriodByUserID totals the activities and steps a user logged
+// between from (inclusive) and to (exclusive).
+func GetActivityPeriodByUserID(userID string, from, to time.Time) (*model.ActivityPeriod, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	var period model.ActivityPeriod
+	if err := DB.Model(&model.Activity{}).
+		Select("COUNT(*) AS activity_count, COALESCE(SUM(duration_min),0) AS duration_min, COALESCE(SUM(calories),0) AS calories").
+		Where("user_id = ? AND timestamp >= ? AND timestamp < ?", userID, from, to).
+		Scan(&period).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum activities: %w", err)
+	}
+
+	var stepSum struct{ Steps int }
+	if err := DB.Model(&model.StepEntry{}).
+		Select("COALESCE(SUM(steps),0) AS steps").
+		Where("user_id = ? AND date >= ? AND date < ?", userID, from, to).
+		Scan(&stepSum).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum steps: %w", err)
+	}
+	period.Steps = stepSum.Steps
+	return &period, nil
+}
+
 func CreateStepEntry(stepEntry *model.StepEntry) error {
 	if DB == nil {
 		return fmt.Errorf("database connection is nil")
@@ -313,8 +399,294 @@ func DeleteActivity(activityID string) error {
 	if activityID == "" {
 		return fmt.Errorf("activityID cannot be empty")
 	}
-	if err := DB.Where("id = ?", activityID).Delete(&model.Activity{}).Error; err != nil {
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("activity_id = ?", activityID).Delete(&model.TrackPoint{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("activity_id = ?", activityID).Delete(&model.StrengthSet{}).Error; err != nil {
+			return err
+		}
+		// Forget the import so the same file can be uploaded again.
+		if err := tx.Where("activity_id = ?", activityID).Delete(&model.WorkoutImport{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", activityID).Delete(&model.Activity{}).Error
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete activity %s: %w", activityID, err)
 	}
 	return nil
 }
+
+// GetWorkoutImport returns the import record for a file previously uploaded by
+// the user, or nil if the file has not been imported.
+func GetWorkoutImport(userID, fileHash string) (*model.WorkoutImport, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if userID == "" || fileHash == "" {
+		return nil, fmt.Errorf("userID and fileHash cannot be empty")
+	}
+	var imp model.WorkoutImport
+	err := DB.Where("user_id = ? AND file_hash = ?", userID, fileHash).First(&imp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workout import: %w", err)
+	}
+	return &imp, nil
+}
+
+// CreateImportedActivity stores an activity together with its track points
+// and import record in a single transaction.
+func CreateImportedActivity(activity *model.Activity, points []model.TrackPoint, imp *model.WorkoutImport) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	if activity == nil || imp == nil {
+		return fmt.Errorf("activity and import cannot be nil")
+	}
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(activity).Error; err != nil {
+			return err
+		}
+		for i := range points {
+			points[i].ID = uuid.New()
+			points[i].ActivityID = activity.ID
+		}
+		if len(points) > 0 {
+			if err := tx.CreateInBatches(points, 500).Error; err != nil {
+				return err
+			}
+		}
+		imp.ActivityID = activity.ID
+		return tx.Create(imp).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import activity: %w", err)
+	}
+	return nil
+}
+
+// GetTrackPoints returns the stored track of an activity in recording order.
+func GetTrackPoints(activityID string) ([]model.TrackPoint, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if activityID == "" {
+		return nil, fmt.Errorf("activityID cannot be empty")
+	}
+	var points []model.TrackPoint
+	if err := DB.Where("activity_id = ?", activityID).Order("seq ASC").Find(&points).Error; err != nil {
+		return nil, fmt.Errorf("failed to get track for activity %s: %w", activityID, err)
+	}
+	return points, nil
+}
+
+// GetTrackPointsByActivityIDs returns the stored tracks of several activities
+// keyed by activity ID, each in recording order. Activities without a track
+// are left out.
+func GetTrackPointsByActivityIDs(activityIDs []uuid.UUID) (map[uuid.UUID][]model.TrackPoint, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	tracks := make(map[uuid.UUID][]model.TrackPoint)
+	if len(activityIDs) == 0 {
+		return tracks, nil
+	}
+	var points []model.TrackPoint
+	if err := DB.Where("activity_id IN ?", activityIDs).Order("activity_id ASC, seq ASC").Find(&points).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tracks: %w", err)
+	}
+	for _, p := range points {
+		tracks[p.ActivityID] = append(tracks[p.ActivityID], p)
+	}
+	return tracks, nil
+}
+
+// ReplaceTrackPoints replaces the stored track of an activity and updates the
+// activity distance to the length of the new track.
+func ReplaceTrackPoints(activityID string, points []model.TrackPoint, distanceM float64) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	if activityID == "" {
+		return fmt.Errorf("activityID cannot be empty")
+	}
+	id, err := uuid.Parse(activityID)
+	if err != nil {
+		return fmt.Errorf("invalid activityID: %w", err)
+	}
+	err = DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("activity_id = ?", activityID).Delete(&model.TrackPoint{}).Error; err != nil {
+			return err
+		}
+		for i := range points {
+			points[i].ID = uuid.New()
+			points[i].ActivityID = id
+			points[i].Seq = i
+		}
+		if len(points) > 0 {
+			if err := tx.CreateInBatches(points, 500).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&model.Activity{}).Where("id = ?", activityID).Update("distance_m", distanceM).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replace track for activity %s: %w", activityID, err)
+	}
+	return nil
+}
+
+// GetDailyStepTotals returns the user's step count summed per day.
+func GetDailyStepTotals(userID string) ([]model.DailySteps, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	var days []model.DailySteps
+	if err := DB.Model(&model.StepEntry{}).
+		Select("DATE(date) AS date, COALESCE(SUM(steps),0) AS steps").
+		Where("user_id = ?", userID).
+		Group("DATE(date)").
+		Order("date ASC").
+		Scan(&days).Error; err != nil {
+		return nil, fmt.Errorf("failed to get daily steps for user %s: %w", userID, err)
+	}
+	return days, nil
+}
+
+// GetPersonalRecords returns the stored personal records of a user.
+func GetPersonalRecords(userID string) ([]model.PersonalRecord, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	var records []model.PersonalRecord
+	if err := DB.Where("user_id = ?", userID).
+		Order("category ASC, activity_type ASC").
+		Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to get personal records for user %s: %w", userID, err)
+	}
+	return records, nil
+}
+
+// ReplacePersonalRecords overwrites the stored personal records of a user.
+func ReplacePersonalRecords(userID string, records []model.PersonalRecord) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	if userID == "" {
+		return fmt.Errorf("userID cannot be empty")
+	}
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.PersonalRecord{}).Error; err != nil {
+			return err
+		}
+		for i := range records {
+			records[i].ID = uuid.New()
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		return tx.Create(&records).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store personal records for user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// GetAllStepEntriesByUserID returns every step entry of a user ordered by date.
+func GetAllStepEntriesByUserID(userID string) ([]model.StepEntry, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	var stepEntries []model.StepEntry
+	if err := DB.Where("user_id = ?", userID).Order("date ASC").Find(&stepEntries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get step entries for user %s: %w", userID, err)
+	}
+	return stepEntries, nil
+}
+
+// InsertActivities bulk-inserts activities, skipping rows whose ID already
+// exists. It returns the number of rows inserted.
+func InsertActivities(activities []model.Activity) (int64, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database connection is nil")
+	}
+	if len(activities) == 0 {
+		return 0, nil
+	}
+	result := DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&activities, 500)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to insert activities: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// InsertStepEntries bulk-inserts step entries, skipping rows whose ID already
+// exists. It returns the number of rows inserted.
+func InsertStepEntries(stepEntries []model.StepEntry) (int64, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database connection is nil")
+	}
+	if len(stepEntries) == 0 {
+		return 0, nil
+	}
+	result := DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&stepEntries, 500)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to insert step entries: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// PurgeUserData deletes every row owned by a user: track points and strength
+// sets of their activities, workout imports, personal records, step entries
+// and the activities themselves. It returns the number of rows removed per
+// table and is safe to call again once the data is gone.
+func PurgeUserData(userID string) (map[string]int64, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	deleted := make(map[string]int64)
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		activityIDs := tx.Model(&model.Activity{}).Select("id").Where("user_id = ?", userID)
+		steps := []struct {
+			table string
+			query *gorm.DB
+			value any
+		}{
+			{"track_points", tx.Where("activity_id IN (?)", activityIDs), &model.TrackPoint{}},
+			{"strength_sets", tx.Where("user_id = ?", userID), &model.StrengthSet{}},
+			{"workout_imports", tx.Where("user_id = ?", userID), &model.WorkoutImport{}},
+			{"personal_records", tx.Where("user_id = ?", userID), &model.PersonalRecord{}},
+			{"step_entries", tx.Where("user_id = ?", userID), &model.StepEntry{}},
+			{"activities", tx.Where("user_id = ?", userID), &model.Activity{}},
+		}
+		for _, step := range steps {
+			result := step.query.Delete(step.value)
+			if result.Error != nil {
+				return fmt.Errorf("%s: %w", step.table, result.Error)
+			}
+			deleted[step.table] = result.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge data for user %s: %w", userID, err)
+	}
+	return deleted, nil
+}
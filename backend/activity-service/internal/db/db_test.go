@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/ffabious/healthy-summer/activity-service/internal/pagination"
 	"github.com/google/uuid"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -531,3 +532,399 @@ func TestConnectEnvironmentVariables(t *testing.T) {
 		})
 	}
 }
+
+func TestGetWorkoutImportWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+
+	result, err := GetWorkoutImport(uuid.New().String(), "abc")
+	if err == nil {
+		t.Error("Expected error with nil database, got none")
+	}
+	if result != nil {
+		t.Error("Expected nil result with nil database")
+	}
+}
+
+func TestCreateImportedActivityWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+
+	err := CreateImportedActivity(&model.Activity{}, nil, &model.WorkoutImport{})
+	if err == nil {
+		t.Error("Expected error with nil database, got none")
+	}
+}
+
+func TestCreateImportedActivityWithNilArguments(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	var err error
+	DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to setup test database: %v", err)
+	}
+
+	if err := CreateImportedActivity(nil, nil, &model.WorkoutImport{}); err == nil {
+		t.Error("Expected error for nil activity, got none")
+	}
+	if err := CreateImportedActivity(&model.Activity{}, nil, nil); err == nil {
+		t.Error("Expected error for nil import, got none")
+	}
+}
+
+func TestGetTrackPointsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+
+	points, err := GetTrackPoints(uuid.New().String())
+	if err == nil {
+		t.Error("Expected error with nil database, got none")
+	}
+	if points != nil {
+		t.Error("Expected nil result with nil database")
+	}
+
+	tracks, err := GetTrackPointsByActivityIDs([]uuid.UUID{uuid.New()})
+	if err == nil {
+		t.Error("Expected error from GetTrackPointsByActivityIDs with nil database, got none")
+	}
+	if tracks != nil {
+		t.Error("Expected nil tracks with nil database")
+	}
+}
+
+func TestReplaceTrackPointsWithInvalidID(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	var err error
+	DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to setup test database: %v", err)
+	}
+
+	if err := ReplaceTrackPoints("", nil, 0); err == nil {
+		t.Error("Expected error for empty activity ID, got none")
+	}
+	if err := ReplaceTrackPoints("not-a-uuid", nil, 0); err == nil {
+		t.Error("Expected error for invalid activity ID, got none")
+	}
+}
+
+func TestStrengthFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+
+	if err := SeedExercises(); err == nil {
+		t.Error("Expected error from SeedExercises with nil database, got none")
+	}
+	if _, err := SearchExercises("squat", ""); err == nil {
+		t.Error("Expected error from SearchExercises with nil database, got none")
+	}
+	if _, err := GetExercisesByIDs([]uuid.UUID{uuid.New()}); err == nil {
+		t.Error("Expected error from GetExercisesByIDs with nil database, got none")
+	}
+	if _, err := GetStrengthSets(uuid.New().String()); err == nil {
+		t.Error("Expected error from GetStrengthSets with nil database, got none")
+	}
+	if err := ReplaceStrengthSets(uuid.New().String(), nil); err == nil {
+		t.Error("Expected error from ReplaceStrengthSets with nil database, got none")
+	}
+	if _, err := GetExerciseHistory(uuid.New().String(), uuid.New().String()); err == nil {
+		t.Error("Expected error from GetExerciseHistory with nil database, got none")
+	}
+}
+
+func TestPersonalRecordFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+
+	userID := uuid.New().String()
+	if _, err := GetDailyStepTotals(userID); err == nil {
+		t.Error("Expected error from GetDailyStepTotals with nil database, got none")
+	}
+	if _, err := GetPersonalRecords(userID); err == nil {
+		t.Error("Expected error from GetPersonalRecords with nil database, got none")
+	}
+	if err := ReplacePersonalRecords(userID, nil); err == nil {
+		t.Error("Expected error from ReplacePersonalRecords with nil database, got none")
+	}
+}
+
+// createActivitiesTable creates the activities table in the SQLite test
+// database by hand, since AutoMigrate relies on Postgres-only defaults.
+func createActivitiesTable(t *testing.T) {
+	t.Helper()
+	if err := DB.Exec(`CREATE TABLE activities (
+		id TEXT PRIMARY KEY, user_id TEXT, type TEXT, duration_min INTEGER, intensity TEXT,
+		calories INTEGER, location TEXT, timestamp DATETIME, distance_m REAL,
+		elevation_gain_m REAL, avg_heart_rate INTEGER, max_heart_rate INTEGER,
+		image_id TEXT, image_url TEXT, thumbnail_url TEXT)`).Error; err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+}
+
+func TestListActivities(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	if _, err := ListActivities(uuid.New().String(), nil, pagination.Params{Limit: 10}); err == nil {
+		t.Error("Expected error with nil database, got none")
+	}
+
+	var err error
+	DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to setup test database: %v", err)
+	}
+	createActivitiesTable(t)
+
+	userID := uuid.New()
+	start := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		activityType := "running"
+		if i%2 == 1 {
+			activityType = "cycling"
+		}
+		activity := model.Activity{
+			ID: uuid.New(), UserID: userID, Type: activityType, DurationMin: 30,
+			Intensity: model.IntensityMedium, Timestamp: start.AddDate(0, 0, i),
+		}
+		if err := DB.Create(&activity).Error; err != nil {
+			t.Fatalf("Failed to insert activity: %v", err)
+		}
+	}
+
+	if _, err := ListActivities("", nil, pagination.Params{Limit: 10}); err == nil {
+		t.Error("Expected error for empty user ID, got none")
+	}
+
+	// Walk all pages newest first.
+	var seen []time.Time
+	params := pagination.Params{Limit: 2}
+	for {
+		page, err := ListActivities(userID.String(), nil, params)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if page.Total != 5 {
+			t.Errorf("Expected total 5, got %d", page.Total)
+		}
+		for _, a := range page.Activities {
+			seen = append(seen, a.Timestamp)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		params.After, err = pagination.DecodeCursor(page.NextCursor)
+		if err != nil {
+			t.Fatalf("Failed to decode cursor: %v", err)
+		}
+	}
+	if len(seen) != 5 {
+		t.Fatalf("Expected 5 activities across pages, got %d", len(seen))
+	}
+	for i := 1; i < len(seen); i++ {
+		if !seen[i].Before(seen[i-1]) {
+			t.Errorf("Expected descending order, got %v after %v", seen[i], seen[i-1])
+		}
+	}
+
+	from := start.AddDate(0, 0, 1)
+	page, err := ListActivities(userID.String(), []string{"running"}, pagination.Params{Limit: 10, From: &from, Ascending: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if page.Total != 2 || len(page.Activities) != 2 || page.NextCursor != "" {
+		t.Fatalf("Expected 2 running activities on one page, got %+v", page)
+	}
+	if !page.Activities[0].Timestamp.Before(page.Activities[1].Timestamp) {
+		t.Error("Expected ascending order")
+	}
+}
+
+func TestBulkImportFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+
+	if _, err := GetAllStepEntriesByUserID(uuid.New().String()); err == nil {
+		t.Error("Expected error from GetAllStepEntriesByUserID with nil database, got none")
+	}
+	if _, err := InsertActivities([]model.Activity{{}}); err == nil {
+		t.Error("Expected error from InsertActivities with nil database, got none")
+	}
+	if _, err := InsertStepEntries([]model.StepEntry{{}}); err == nil {
+		t.Error("Expected error from InsertStepEntries with nil database, got none")
+	}
+}
+
+func TestInsertActivitiesIsIdempotent(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	var err error
+	DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to setup test database: %v", err)
+	}
+	createActivitiesTable(t)
+
+	userID := uuid.New()
+	activities := []model.Activity{
+		{ID: uuid.New(), UserID: userID, Type: "running", DurationMin: 30, Intensity: model.IntensityHigh, Timestamp: time.Now()},
+		{ID: uuid.New(), UserID: userID, Type: "yoga", DurationMin: 45, Intensity: model.IntensityLow, Timestamp: time.Now()},
+	}
+
+	inserted, err := InsertActivities(activities)
+	if err != nil || inserted != 2 {
+		t.Fatalf("Expected 2 rows inserted, got %d (%v)", inserted, err)
+	}
+	inserted, err = InsertActivities(activities)
+	if err != nil || inserted != 0 {
+		t.Errorf("Expected re-import to insert nothing, got %d (%v)", inserted, err)
+	}
+}
+
+func TestPurgeUserData(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	if _, err := PurgeUserData(uuid.New().String()); err == nil {
+		t.Error("Expected error with nil database, got none")
+	}
+
+	var err error
+	DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to setup test database: %v", err)
+	}
+	createActivitiesTable(t)
+	for _, ddl := range []string{
+		`CREATE TABLE track_points (id TEXT PRIMARY KEY, activity_id TEXT)`,
+		`CREATE TABLE strength_sets (id TEXT PRIMARY KEY, activity_id TEXT, user_id TEXT)`,
+		`CREATE TABLE workout_imports (id TEXT PRIMARY KEY, activity_id TEXT, user_id TEXT)`,
+		`CREATE TABLE personal_records (id TEXT PRIMARY KEY, user_id TEXT)`,
+		`CREATE TABLE step_entries (id TEXT PRIMARY KEY, user_id TEXT)`,
+	} {
+		if err := DB.Exec(ddl).Error; err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+
+	userID, otherID := uuid.New().String(), uuid.New().String()
+	seed := func(owner string) {
+		activityID := uuid.New().String()
+		inserts := []struct {
+			query string
+			args  []any
+		}{
+			{`INSERT INTO activities (id, user_id) VALUES (?, ?)`, []any{activityID, owner}},
+			{`INSERT INTO track_points (id, activity_id) VALUES (?, ?)`, []any{uuid.New().String(), activityID}},
+			{`INSERT INTO track_points (id, activity_id) VALUES (?, ?)`, []any{uuid.New().String(), activityID}},
+			{`INSERT INTO strength_sets (id, activity_id, user_id) VALUES (?, ?, ?)`, []any{uuid.New().String(), activityID, owner}},
+			{`INSERT INTO workout_imports (id, activity_id, user_id) VALUES (?, ?, ?)`, []any{uuid.New().String(), activityID, owner}},
+			{`INSERT INTO personal_records (id, user_id) VALUES (?, ?)`, []any{uuid.New().String(), owner}},
+			{`INSERT INTO step_entries (id, user_id) VALUES (?, ?)`, []any{uuid.New().String(), owner}},
+		}
+		for _, insert := range inserts {
+			if err := DB.Exec(insert.query, insert.args...).Error; err != nil {
+				t.Fatalf("Failed to seed data: %v", err)
+			}
+		}
+	}
+	seed(userID)
+	seed(otherID)
+
+	deleted, err := PurgeUserData(userID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]int64{
+		"track_points": 2, "strength_sets": 1, "workout_imports": 1,
+		"personal_records": 1, "step_entries": 1, "activities": 1,
+	}
+	for table, count := range expected {
+		if deleted[table] != count {
+			t.Errorf("Expected %d rows deleted from %s, got %d", count, table, deleted[table])
+		}
+	}
+
+	var remaining int64
+	DB.Table("track_points").Count(&remaining)
+	if remaining != 2 {
+		t.Errorf("Expected other user's track points to remain, got %d rows", remaining)
+	}
+	DB.Table("activities").Count(&remaining)
+	if remaining != 1 {
+		t.Errorf("Expected other user's activity to remain, got %d rows", remaining)
+	}
+
+	deleted, err = PurgeUserData(userID)
+	if err != nil || deleted["activities"] != 0 {
+		t.Errorf("Expected repeated purge to delete nothing, got %v (%v)", deleted, err)
+	}
+}
+
+func TestGetActivityPeriodByUserID(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	if _, err := GetActivityPeriodByUserID(uuid.New().String(), time.Now(), time.Now()); err == nil {
+		t.Error("Expected error with nil database, got none")
+	}
+
+	var err error
+	DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to setup test database: %v", err)
+	}
+	createActivitiesTable(t)
+	if err := DB.Exec(`CREATE TABLE step_entries (id TEXT PRIMARY KEY, user_id TEXT, date DATETIME, steps INTEGER)`).Error; err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	userID := uuid.New()
+	from := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	for _, ts := range []time.Time{from.Add(-time.Minute), from, from.AddDate(0, 0, 3), to} {
+		activity := model.Activity{
+			ID: uuid.New(), UserID: userID, Type: "running", DurationMin: 30, Calories: 300,
+			Intensity: model.IntensityMedium, Timestamp: ts,
+		}
+		if err := DB.Create(&activity).Error; err != nil {
+			t.Fatalf("Failed to insert activity: %v", err)
+		}
+	}
+	for _, day := range []time.Time{from.AddDate(0, 0, -1), from, from.AddDate(0, 0, 6)} {
+		entry := model.StepEntry{ID: uuid.New(), UserID: userID, Date: day, Steps: 5000}
+		if err := DB.Create(&entry).Error; err != nil {
+			t.Fatalf("Failed to insert step entry: %v", err)
+		}
+	}
+
+	period, err := GetActivityPeriodByUserID(userID.String(), from, to)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := model.ActivityPeriod{ActivityCount: 2, DurationMin: 60, Calories: 600, Steps: 10000}
+	if *period != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *period)
+	}
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// ErrImageNotFound is returned when an image does not exist or belongs to
+// another user.
+var ErrImageNotFound = errors.New("image not found")
+
+// uploadURLs holds the columns of user-service's uploads table that are
+// copied onto an activity.
+type uploadURLs struct {
+	URL          string
+	ThumbnailURL string
+}
+
+// SetActivityImage attaches one of the owner's uploads to an activity, or
+// removes the image when imageID is nil. Ownership of the activity is
+// checked by the caller.
+func SetActivityImage(activity *model.Activity, imageID *uuid.UUID) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	if activity == nil {
+		return fmt.Errorf("activity cannot be nil")
+	}
+
+	var urls uploadURLs
+	if imageID != nil {
+		var rows []uploadURLs
+		if err := DB.Table("uploads").
+			Select("url, thumbnail_url").
+			Where("id = ? AND user_id = ?", *imageID, activity.UserID).
+			Limit(1).
+			Scan(&rows).Error; err != nil {
+			return fmt.Errorf("failed to get image: %w", err)
+		}
+		if len(rows) == 0 {
+			return ErrImageNotFound
+		}
+		urls = rows[0]
+	}
+
+	activity.ImageID = imageID
+	activity.ImageURL = urls.URL
+	activity.ThumbnailURL = urls.ThumbnailURL
+	if err := DB.Model(activity).Select("image_id", "image_url", "thumbnail_url").Updates(activity).Error; err != nil {
+		return fmt.Errorf("failed to update activity image: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSetActivityImage(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	var err error
+	DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to setup test database: %v", err)
+	}
+	createActivitiesTable(t)
+	if err := DB.Exec(`CREATE TABLE uploads (id TEXT PRIMARY KEY, user_id TEXT, url TEXT, thumbnail_url TEXT)`).Error; err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	owner := uuid.New()
+	imageID := uuid.New()
+	otherImageID := uuid.New()
+	if err := DB.Exec(`INSERT INTO uploads (id, user_id, url, thumbnail_url) VALUES (?, ?, ?, ?), (?, ?, ?, ?)`,
+		imageID, owner, "/img.jpg", "/img_thumb.jpg",
+		otherImageID, uuid.New(), "/other.jpg", "/other_thumb.jpg").Error; err != nil {
+		t.Fatalf("Failed to seed uploads: %v", err)
+	}
+	activity := &model.Activity{ID: uuid.New(), UserID: owner, Type: "running", DurationMin: 30, Intensity: "medium"}
+	if err := DB.Create(activity).Error; err != nil {
+		t.Fatalf("Failed to seed activity: %v", err)
+	}
+
+	if err := SetActivityImage(activity, &otherImageID); !errors.Is(err, ErrImageNotFound) {
+		t.Errorf("Expected ErrImageNotFound for another user's image, got %v", err)
+	}
+
+	if err := SetActivityImage(activity, &imageID); err != nil {
+		t.Fatalf("SetActivityImage failed: %v", err)
+	}
+	var stored model.Activity
+	DB.First(&stored, "id = ?", activity.ID)
+	if stored.ImageID == nil || *stored.ImageID != imageID || stored.ImageURL != "/img.jpg" || stored.ThumbnailURL != "/img_thumb.jpg" {
+		t.Errorf("Unexpected image fields after attach: %+v", stored)
+	}
+
+	if err := SetActivityImage(activity, nil); err != nil {
+		t.Fatalf("SetActivityImage(nil) failed: %v", err)
+	}
+	stored = model.Activity{}
+	DB.First(&stored, "id = ?", activity.ID)
+	if stored.ImageID != nil || stored.ImageURL != "" || stored.ThumbnailURL != "" {
+		t.Errorf("Expected image to be removed, got %+v", stored)
+	}
+}
+
+func TestSetActivityImageWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+	DB = nil
+
+	if err := SetActivityImage(&model.Activity{}, nil); err == nil {
+		t.Error("Expected error with nil database, got none")
+	}
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"fmt"
+)
+
+// activityPrivacy holds the columns of user-service's privacy_settings
+// table that govern activity data.
+type activityPrivacy struct {
+	ProfileVisibility string
+	ShareActivities   bool
+}
+
+// CanViewActivities reports whether viewerID may see ownerID's activity data.
+// Owners always can. Others need the owner to share activities and either
+// have a public profile or be friends with the viewer. Users without privacy
+// settings get user-service's defaults: friends-only, activities shared.
+func CanViewActivities(ownerID, viewerID string) (bool, error) {
+	if ownerID == "" || viewerID == "" {
+		return false, fmt.Errorf("ownerID and viewerID cannot be empty")
+	}
+	if ownerID == viewerID {
+		return true, nil
+	}
+	if DB == nil {
+		return false, fmt.Errorf("database connection is nil")
+	}
+
+	settings := activityPrivacy{ProfileVisibility: "friends", ShareActivities: true}
+	var rows []activityPrivacy
+	if err := DB.Table("privacy_settings").
+		Select("profile_visibility, share_activities").
+		Where("user_id = ?", ownerID).
+		Limit(1).
+		Scan(&rows).Error; err != nil {
+		return false, fmt.Errorf("failed to get privacy settings: %w", err)
+	}
+	if len(rows) == 1 {
+		settings = rows[0]
+	}
+
+	switch {
+	case !settings.ShareActivities || settings.ProfileVisibility == "private":
+		return false, nil
+	case settings.ProfileVisibility == "public":
+		return true, nil
+	}
+
+	var friends int64
+	if err := DB.Table("friends").
+		Where("user_id = ? AND friend_id = ?", ownerID, viewerID).
+		Count(&friends).Error; err != nil {
+		return false, fmt.Errorf("failed to check friendship: %w", err)
+	}
+	return friends > 0, nil
+}
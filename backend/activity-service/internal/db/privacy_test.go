@@ -0,0 +1,81 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCanViewActivities(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	var err error
+	DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to setup test database: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE privacy_settings (user_id TEXT PRIMARY KEY, profile_visibility TEXT, share_activities BOOLEAN)`,
+		`CREATE TABLE friends (id TEXT PRIMARY KEY, user_id TEXT, friend_id TEXT)`,
+	} {
+		if err := DB.Exec(stmt).Error; err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+
+	viewer := uuid.New().String()
+	defaults := uuid.New().String()
+	defaultsFriend := uuid.New().String()
+	public := uuid.New().String()
+	private := uuid.New().String()
+	notShared := uuid.New().String()
+
+	settings := []struct {
+		userID     string
+		visibility string
+		share      bool
+	}{
+		{public, "public", true},
+		{private, "private", true},
+		{notShared, "public", false},
+	}
+	for _, s := range settings {
+		if err := DB.Exec(`INSERT INTO privacy_settings (user_id, profile_visibility, share_activities) VALUES (?, ?, ?)`,
+			s.userID, s.visibility, s.share).Error; err != nil {
+			t.Fatalf("Failed to seed privacy settings: %v", err)
+		}
+	}
+	for _, owner := range []string{defaultsFriend, private} {
+		if err := DB.Exec(`INSERT INTO friends (id, user_id, friend_id) VALUES (?, ?, ?)`,
+			uuid.New().String(), owner, viewer).Error; err != nil {
+			t.Fatalf("Failed to seed friends: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		ownerID  string
+		expected bool
+	}{
+		{"Own activities", viewer, true},
+		{"Default settings, not friends", defaults, false},
+		{"Default settings, friends", defaultsFriend, true},
+		{"Public profile", public, true},
+		{"Private profile of a friend", private, false},
+		{"Activities not shared", notShared, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := CanViewActivities(tt.ownerID, viewer)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if allowed != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, allowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestValidateSession(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	if err := ValidateSession(uuid.New().String(), 0); err == nil {
+		t.Error("Expected error with nil database, got none")
+	}
+
+	var err error
+	DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to setup test database: %v", err)
+	}
+	if err := DB.Exec(`CREATE TABLE users (id TEXT PRIMARY KEY, token_version INTEGER, suspended_at DATETIME)`).Error; err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	active := uuid.New().String()
+	suspended := uuid.New().String()
+	if err := DB.Exec(`INSERT INTO users (id, token_version, suspended_at) VALUES (?, 3, NULL), (?, 3, ?)`,
+		active, suspended, time.Now()).Error; err != nil {
+		t.Fatalf("Failed to seed users: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		userID  string
+		version int
+		revoked bool
+	}{
+		{"Current version", active, 3, false},
+		{"Revoked version", active, 2, true},
+		{"Suspended user", suspended, 3, true},
+		{"Deleted user", uuid.New().String(), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSession(tt.userID, tt.version)
+			if tt.revoked && !errors.Is(err, ErrSessionRevoked) {
+				t.Errorf("Expected ErrSessionRevoked, got %v", err)
+			}
+			if !tt.revoked && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultExercises is the built-in exercise catalog inserted on startup.
+var defaultExercises = []model.Exercise{
+	{Name: "Back Squat", Category: "legs", Equipment: "barbell"},
+	{Name: "Front Squat", Category: "legs", Equipment: "barbell"},
+	{Name: "Deadlift", Category: "back", Equipment: "barbell"},
+	{Name: "Romanian Deadlift", Category: "legs", Equipment: "barbell"},
+	{Name: "Bench Press", Category: "chest", Equipment: "barbell"},
+	{Name: "Incline Bench Press", Category: "chest", Equipment: "barbell"},
+	{Name: "Overhead Press", Category: "shoulders", Equipment: "barbell"},
+	{Name: "Barbell Row", Category: "back", Equipment: "barbell"},
+	{Name: "Pull-Up", Category: "back", Equipment: "bodyweight"},
+	{Name: "Chin-Up", Category: "back", Equipment: "bodyweight"},
+	{Name: "Push-Up", Category: "chest", Equipment: "bodyweight"},
+	{Name: "Dip", Category: "chest", Equipment: "bodyweight"},
+	{Name: "Lunge", Category: "legs", Equipment: "dumbbell"},
+	{Name: "Dumbbell Bench Press", Category: "chest", Equipment: "dumbbell"},
+	{Name: "Dumbbell Row", Category: "back", Equipment: "dumbbell"},
+	{Name: "Dumbbell Shoulder Press", Category: "shoulders", Equipment: "dumbbell"},
+	{Name: "Bicep Curl", Category: "arms", Equipment: "dumbbell"},
+	{Name: "Tricep Extension", Category: "arms", Equipment: "dumbbell"},
+	{Name: "Lat Pulldown", Category: "back", Equipment: "machine"},
+	{Name: "Leg Press", Category: "legs", Equipment: "machine"},
+	{Name: "Leg Curl", Category: "legs", Equipment: "machine"},
+	{Name: "Leg Extension", Category: "legs", Equipment: "machine"},
+	{Name: "Calf Raise", Category: "legs", Equipment: "machine"},
+	{Name: "Plank", Category: "core", Equipment: "bodyweight"},
+	{Name: "Hip Thrust", Category: "legs", Equipment: "barbell"},
+	{Name: "Kettlebell Swing", Category: "full_body", Equipment: "kettlebell"},
+}
+
+// SeedExercises inserts the built-in exercises that are not yet in the catalog.
+func SeedExercises() error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	exercises := make([]model.Exercise, len(defaultExercises))
+	for i, e := range defaultExercises {
+		e.ID = uuid.NewSHA1(uuid.NameSpaceOID, []byte("exercise:"+e.Name))
+		exercises[i] = e
+	}
+	if err := DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&exercises).Error; err != nil {
+		return fmt.Errorf("failed to seed exercises: %w", err)
+	}
+	return nil
+}
+
+// SearchExercises looks up exercises by name and optional category.
+func SearchExercises(query, category string) ([]model.Exercise, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var exercises []model.Exercise
+	q := DB.Model(&model.Exercise{})
+	if query != "" {
+		q = q.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(query)+"%")
+	}
+	if category != "" {
+		q = q.Where("category = ?", category)
+	}
+	if err := q.Order("name ASC").Find(&exercises).Error; err != nil {
+		return nil, fmt.Errorf("failed to search exercises: %w", err)
+	}
+	return exercises, nil
+}
+
+// GetExercisesByIDs returns the catalog entries for the given IDs keyed by ID.
+func GetExercisesByIDs(ids []uuid.UUID) (map[uuid.UUID]model.Exercise, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	result := make(map[uuid.UUID]model.Exercise, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+	var exercises []model.Exercise
+	if err := DB.Where("id IN ?", ids).Find(&exercises).Error; err != nil {
+		return nil, fmt.Errorf("failed to get exercises: %w", err)
+	}
+	for _, e := range exercises {
+		result[e.ID] = e
+	}
+	return result, nil
+}
+
+// GetStrengthSets returns the sets recorded for an activity in the order they
+// were performed.
+func GetStrengthSets(activityID string) ([]model.StrengthSet, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if activityID == "" {
+		return nil, fmt.Errorf("activityID cannot be empty")
+	}
+	var sets []model.StrengthSet
+	if err := DB.Where("activity_id = ?", activityID).Order("position ASC").Find(&sets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sets for activity %s: %w", activityID, err)
+	}
+	return sets, nil
+}
+
+// ReplaceStrengthSets replaces all sets recorded for an activity.
+func ReplaceStrengthSets(activityID string, sets []model.StrengthSet) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	if activityID == "" {
+		return fmt.Errorf("activityID cannot be empty")
+	}
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("activity_id = ?", activityID).Delete(&model.StrengthSet{}).Error; err != nil {
+			return err
+		}
+		if len(sets) == 0 {
+			return nil
+		}
+		return tx.Create(&sets).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replace sets for activity %s: %w", activityID, err)
+	}
+	return nil
+}
+
+// GetExerciseHistory returns every set of an exercise performed by a user,
+// newest session first.
+func GetExerciseHistory(userID, exerciseID string) ([]model.StrengthSet, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if userID == "" || exerciseID == "" {
+		return nil, fmt.Errorf("userID and exerciseID cannot be empty")
+	}
+	var sets []model.StrengthSet
+	if err := DB.Where("user_id = ? AND exercise_id = ?", userID, exerciseID).
+		Order("timestamp DESC, activity_id ASC, position ASC").
+		Find(&sets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get exercise history: %w", err)
+	}
+	return sets, nil
+}
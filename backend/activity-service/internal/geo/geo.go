@@ -0,0 +1,295 @@
+package geo
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EarthRadiusM is the mean Earth radius in metres used for distance calculations.
+const EarthRadiusM = 6371008.8
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// Distance returns the great-circle distance in metres between two points
+// given in decimal degrees, using the haversine formula.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusM * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// ValidCoordinate reports whether lat/lon are within WGS84 bounds.
+func ValidCoordinate(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180 &&
+		!math.IsNaN(lat) && !math.IsNaN(lon)
+}
+
+// Point is a timestamped position on a track.
+type Point struct {
+	Lat       float64
+	Lon       float64
+	Elevation *float64
+	Time      time.Time
+}
+
+// BoundingBox is the smallest lat/lon rectangle containing a track.
+type BoundingBox struct {
+	MinLat float64 `json:"min_lat"`
+	MinLon float64 `json:"min_lon"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLon float64 `json:"max_lon"`
+}
+
+// Bounds returns the bounding box of points. The zero box is returned for an
+// empty track.
+func Bounds(points []Point) BoundingBox {
+	if len(points) == 0 {
+		return BoundingBox{}
+	}
+	box := BoundingBox{
+		MinLat: points[0].Lat, MaxLat: points[0].Lat,
+		MinLon: points[0].Lon, MaxLon: points[0].Lon,
+	}
+	for _, p := range points[1:] {
+		box.MinLat = math.Min(box.MinLat, p.Lat)
+		box.MaxLat = math.Max(box.MaxLat, p.Lat)
+		box.MinLon = math.Min(box.MinLon, p.Lon)
+		box.MaxLon = math.Max(box.MaxLon, p.Lon)
+	}
+	return box
+}
+
+// TrackDistance returns the total length of the track in metres.
+func TrackDistance(points []Point) float64 {
+	var total float64
+	for i := 1; i < len(points); i++ {
+		total += Distance(points[i-1].Lat, points[i-1].Lon, points[i].Lat, points[i].Lon)
+	}
+	return total
+}
+
+// Simplify reduces the number of points with the Douglas-Peucker algorithm.
+// toleranceM is the maximum distance in metres a removed point may lie from
+// the simplified line. The first and last points are always kept.
+func Simplify(points []Point, toleranceM float64) []Point {
+	if len(points) < 3 || toleranceM <= 0 {
+		return points
+	}
+
+	// Project onto a local equirectangular plane in metres so the
+	// perpendicular distance test works in the same unit as the tolerance.
+	refLat := toRadians(points[0].Lat)
+	xy := make([][2]float64, len(points))
+	for i, p := range points {
+		xy[i] = [2]float64{
+			toRadians(p.Lon) * math.Cos(refLat) * EarthRadiusM,
+			toRadians(p.Lat) * EarthRadiusM,
+		}
+	}
+
+	keep := make([]bool, len(points))
+	keep[0], keep[len(points)-1] = true, true
+
+	// Iterative to avoid deep recursion on long tracks.
+	stack := [][2]int{{0, len(points) - 1}}
+	for len(stack) > 0 {
+		seg := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		first, last := seg[0], seg[1]
+
+		maxDist, index := 0.0, -1
+		for i := first + 1; i < last; i++ {
+			d := segmentDistance(xy[i], xy[first], xy[last])
+			if d > maxDist {
+				maxDist, index = d, i
+			}
+		}
+		if index != -1 && maxDist > toleranceM {
+			keep[index] = true
+			stack = append(stack, [2]int{first, index}, [2]int{index, last})
+		}
+	}
+
+	simplified := make([]Point, 0, len(points))
+	for i, p := range points {
+		if keep[i] {
+			simplified = append(simplified, p)
+		}
+	}
+	return simplified
+}
+
+func segmentDistance(p, a, b [2]float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p[0]-a[0], p[1]-a[1])
+	}
+	t := ((p[0]-a[0])*dx + (p[1]-a[1])*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p[0]-(a[0]+t*dx), p[1]-(a[1]+t*dy))
+}
+
+// EncodePolyline encodes points using the Google encoded polyline algorithm
+// with 5 decimal places of precision.
+func EncodePolyline(points []Point) string {
+	var sb strings.Builder
+	var prevLat, prevLon int64
+	for _, p := range points {
+		lat := int64(math.Round(p.Lat * 1e5))
+		lon := int64(math.Round(p.Lon * 1e5))
+		encodePolylineValue(&sb, lat-prevLat)
+		encodePolylineValue(&sb, lon-prevLon)
+		prevLat, prevLon = lat, lon
+	}
+	return sb.String()
+}
+
+func encodePolylineValue(sb *strings.Builder, v int64) {
+	u := v << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		sb.WriteByte(byte((0x20 | (u & 0x1f)) + 63))
+		u >>= 5
+	}
+	sb.WriteByte(byte(u + 63))
+}
+
+// Split summarises one kilometre (or the final partial kilometre) of a track.
+type Split struct {
+	Index          int     `json:"index"`
+	DistanceM      float64 `json:"distance_m"`
+	DurationSec    float64 `json:"duration_sec"`
+	PaceSecPerKm   float64 `json:"pace_sec_per_km"`
+	ElevationGainM float64 `json:"elevation_gain_m"`
+}
+
+// Splits divides the track into consecutive segments of splitM metres,
+// interpolating the time at each boundary. The last split may be shorter.
+// Durations are zero when the track has no timestamps.
+func Splits(points []Point, splitM float64) []Split {
+	if len(points) < 2 || splitM <= 0 {
+		return nil
+	}
+
+	timed := true
+	for _, p := range points {
+		if p.Time.IsZero() {
+			timed = false
+			break
+		}
+	}
+
+	var splits []Split
+	current := Split{Index: 1}
+	splitStart := points[0].Time
+	prevEle := points[0].Elevation
+
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		segLen := Distance(a.Lat, a.Lon, b.Lat, b.Lon)
+		segDur := b.Time.Sub(a.Time)
+		if b.Elevation != nil {
+			if prevEle != nil && *b.Elevation > *prevEle {
+				current.ElevationGainM += *b.Elevation - *prevEle
+			}
+			prevEle = b.Elevation
+		}
+
+		consumed := 0.0
+		for segLen-consumed > 0 && current.DistanceM+(segLen-consumed) >= splitM {
+			consumed += splitM - current.DistanceM
+			boundary := a.Time.Add(time.Duration(float64(segDur) * consumed / segLen))
+			current.DistanceM = splitM
+			if timed {
+				current.DurationSec = boundary.Sub(splitStart).Seconds()
+			}
+			splits = append(splits, finishSplit(current))
+			current = Split{Index: current.Index + 1}
+			splitStart = boundary
+		}
+		current.DistanceM += segLen - consumed
+	}
+
+	if current.DistanceM >= 1 {
+		if timed {
+			current.DurationSec = points[len(points)-1].Time.Sub(splitStart).Seconds()
+		}
+		splits = append(splits, finishSplit(current))
+	}
+	return splits
+}
+
+// FastestSegment returns the shortest time taken to cover distanceM metres
+// anywhere on the track, interpolating between points. It returns 0 when the
+// track is shorter than distanceM or has no timestamps.
+func FastestSegment(points []Point, distanceM float64) time.Duration {
+	if len(points) < 2 || distanceM <= 0 {
+		return 0
+	}
+	cumulative := make([]float64, len(points))
+	for i, p := range points {
+		if p.Time.IsZero() {
+			return 0
+		}
+		if i > 0 {
+			prev := points[i-1]
+			cumulative[i] = cumulative[i-1] + Distance(prev.Lat, prev.Lon, p.Lat, p.Lon)
+		}
+	}
+	total := cumulative[len(cumulative)-1]
+	if total < distanceM {
+		return 0
+	}
+
+	// timeAt interpolates the time at which the track reached dist metres.
+	timeAt := func(dist float64) time.Time {
+		i := sort.SearchFloat64s(cumulative, dist)
+		if i == 0 {
+			return points[0].Time
+		}
+		if i == len(points) {
+			return points[len(points)-1].Time
+		}
+		a, b := points[i-1], points[i]
+		segLen := cumulative[i] - cumulative[i-1]
+		if segLen <= 0 {
+			return b.Time
+		}
+		return a.Time.Add(time.Duration(float64(b.Time.Sub(a.Time)) * (dist - cumulative[i-1]) / segLen))
+	}
+
+	// The fastest window starts or ends at a recorded point, so checking the
+	// windows anchored at each point is enough.
+	var best time.Duration
+	consider := func(start float64) {
+		if start < 0 || start+distanceM > total {
+			return
+		}
+		if d := timeAt(start + distanceM).Sub(timeAt(start)); d > 0 && (best == 0 || d < best) {
+			best = d
+		}
+	}
+	for _, dist := range cumulative {
+		consider(dist)
+		consider(dist - distanceM)
+	}
+	return best
+}
+
+func finishSplit(s Split) Split {
+	s.DistanceM = math.Round(s.DistanceM*10) / 10
+	s.DurationSec = math.Round(s.DurationSec*10) / 10
+	s.ElevationGainM = math.Round(s.ElevationGainM*10) / 10
+	if s.DistanceM > 0 && s.DurationSec > 0 {
+		s.PaceSecPerKm = math.Round(s.DurationSec/(s.DistanceM/1000)*10) / 10
+	}
+	return s
+}
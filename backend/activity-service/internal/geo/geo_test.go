@@ -0,0 +1,237 @@
+package geo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat1     float64
+		lon1     float64
+		lat2     float64
+		lon2     float64
+		expected float64
+		delta    float64
+	}{
+		{
+			name:     "Same point",
+			lat1:     55.75,
+			lon1:     37.62,
+			lat2:     55.75,
+			lon2:     37.62,
+			expected: 0,
+			delta:    0.001,
+		},
+		{
+			name:     "One degree of latitude",
+			lat1:     0,
+			lon1:     0,
+			lat2:     1,
+			lon2:     0,
+			expected: 111195,
+			delta:    5,
+		},
+		{
+			name:     "Moscow to Saint Petersburg",
+			lat1:     55.7558,
+			lon1:     37.6173,
+			lat2:     59.9343,
+			lon2:     30.3351,
+			expected: 634000,
+			delta:    2000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Distance(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			if math.Abs(got-tt.expected) > tt.delta {
+				t.Errorf("Expected distance %.1f±%.1f, got %.1f", tt.expected, tt.delta, got)
+			}
+		})
+	}
+}
+
+func TestValidCoordinate(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat      float64
+		lon      float64
+		expected bool
+	}{
+		{name: "Origin", lat: 0, lon: 0, expected: true},
+		{name: "Bounds", lat: -90, lon: 180, expected: true},
+		{name: "Latitude too large", lat: 91, lon: 0, expected: false},
+		{name: "Longitude too small", lat: 0, lon: -181, expected: false},
+		{name: "NaN", lat: math.NaN(), lon: 0, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidCoordinate(tt.lat, tt.lon); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBounds(t *testing.T) {
+	if box := Bounds(nil); box != (BoundingBox{}) {
+		t.Errorf("Expected zero box for empty track, got %+v", box)
+	}
+
+	box := Bounds([]Point{
+		{Lat: 10, Lon: 20},
+		{Lat: -5, Lon: 25},
+		{Lat: 3, Lon: -1},
+	})
+	expected := BoundingBox{MinLat: -5, MinLon: -1, MaxLat: 10, MaxLon: 25}
+	if box != expected {
+		t.Errorf("Expected %+v, got %+v", expected, box)
+	}
+}
+
+func TestEncodePolyline(t *testing.T) {
+	// Reference example from the encoded polyline algorithm documentation.
+	points := []Point{
+		{Lat: 38.5, Lon: -120.2},
+		{Lat: 40.7, Lon: -120.95},
+		{Lat: 43.252, Lon: -126.453},
+	}
+	expected := "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+	if got := EncodePolyline(points); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	if got := EncodePolyline(nil); got != "" {
+		t.Errorf("Expected empty polyline, got %q", got)
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	// A straight line north with a small wobble and one large detour.
+	points := []Point{
+		{Lat: 0, Lon: 0},
+		{Lat: 0.001, Lon: 0.00001},
+		{Lat: 0.002, Lon: 0},
+		{Lat: 0.003, Lon: 0.01},
+		{Lat: 0.004, Lon: 0},
+		{Lat: 0.005, Lon: 0},
+	}
+
+	simplified := Simplify(points, 5)
+	if len(simplified) != 5 {
+		t.Fatalf("Expected 5 points after simplification, got %d: %+v", len(simplified), simplified)
+	}
+	if simplified[0] != points[0] || simplified[len(simplified)-1] != points[len(points)-1] {
+		t.Error("Expected endpoints to be preserved")
+	}
+	for _, p := range simplified {
+		if p == points[1] {
+			t.Error("Expected the ~1m wobble to be removed")
+		}
+	}
+
+	if got := Simplify(points, 0); len(got) != len(points) {
+		t.Errorf("Expected zero tolerance to keep all points, got %d", len(got))
+	}
+	if got := Simplify(points, 1e7); len(got) != 2 {
+		t.Errorf("Expected huge tolerance to keep only endpoints, got %d", len(got))
+	}
+}
+
+func TestSplits(t *testing.T) {
+	start := time.Date(2025, 6, 1, 7, 0, 0, 0, time.UTC)
+	ele := func(v float64) *float64 { return &v }
+
+	// Points every ~500 m due north, 150 s apart (5:00/km pace).
+	step := 500 / 111195.0
+	var points []Point
+	for i := 0; i <= 5; i++ {
+		points = append(points, Point{
+			Lat:       float64(i) * step,
+			Lon:       0,
+			Elevation: ele(float64(100 + i)),
+			Time:      start.Add(time.Duration(i) * 150 * time.Second),
+		})
+	}
+
+	splits := Splits(points, 1000)
+	if len(splits) != 3 {
+		t.Fatalf("Expected 3 splits, got %d: %+v", len(splits), splits)
+	}
+	for i, s := range splits[:2] {
+		if s.Index != i+1 {
+			t.Errorf("Expected index %d, got %d", i+1, s.Index)
+		}
+		if s.DistanceM != 1000 {
+			t.Errorf("Expected full split of 1000m, got %.1f", s.DistanceM)
+		}
+		if math.Abs(s.DurationSec-300) > 1 {
+			t.Errorf("Expected ~300s split, got %.1f", s.DurationSec)
+		}
+		if math.Abs(s.PaceSecPerKm-300) > 1 {
+			t.Errorf("Expected ~300s/km pace, got %.1f", s.PaceSecPerKm)
+		}
+	}
+	last := splits[2]
+	if math.Abs(last.DistanceM-500) > 1 || math.Abs(last.DurationSec-150) > 1 {
+		t.Errorf("Unexpected final partial split %+v", last)
+	}
+	if splits[0].ElevationGainM != 2 {
+		t.Errorf("Expected 2m gain in first split, got %.1f", splits[0].ElevationGainM)
+	}
+
+	if Splits(points[:1], 1000) != nil {
+		t.Error("Expected no splits for a single point")
+	}
+}
+
+func TestSplitsWithoutTimestamps(t *testing.T) {
+	points := []Point{{Lat: 0, Lon: 0}, {Lat: 0.02, Lon: 0}}
+	splits := Splits(points, 1000)
+	if len(splits) != 3 {
+		t.Fatalf("Expected 3 splits, got %d", len(splits))
+	}
+	for _, s := range splits {
+		if s.DurationSec != 0 || s.PaceSecPerKm != 0 {
+			t.Errorf("Expected zero duration without timestamps, got %+v", s)
+		}
+	}
+}
+
+func TestFastestSegment(t *testing.T) {
+	start := time.Date(2025, 6, 1, 7, 0, 0, 0, time.UTC)
+	// Four 1 km legs due north: the middle two are run twice as fast.
+	legDeg := 1000 / (EarthRadiusM * math.Pi / 180)
+	durations := []time.Duration{6 * time.Minute, 3 * time.Minute, 3 * time.Minute, 6 * time.Minute}
+	points := []Point{{Lat: 0, Lon: 0, Time: start}}
+	for i, d := range durations {
+		points = append(points, Point{Lat: float64(i+1) * legDeg, Lon: 0, Time: points[i].Time.Add(d)})
+	}
+
+	tests := []struct {
+		name      string
+		points    []Point
+		distanceM float64
+		expected  time.Duration
+	}{
+		{name: "Fastest 2 km", points: points, distanceM: 2000, expected: 6 * time.Minute},
+		{name: "Fastest 3 km", points: points, distanceM: 3000, expected: 12 * time.Minute},
+		{name: "Interpolated 1.5 km", points: points, distanceM: 1500, expected: 4*time.Minute + 30*time.Second},
+		{name: "Whole track", points: points, distanceM: 3999.9, expected: 18 * time.Minute},
+		{name: "Longer than the track", points: points, distanceM: 5000, expected: 0},
+		{name: "Single point", points: points[:1], distanceM: 1000, expected: 0},
+		{name: "No timestamps", points: []Point{{Lat: 0, Lon: 0}, {Lat: 0.02, Lon: 0}}, distanceM: 1000, expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FastestSegment(tt.points, tt.distanceM)
+			if diff := got - tt.expected; diff < -time.Second || diff > time.Second {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/db"
+	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/ffabious/healthy-summer/activity-service/internal/pagination"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Purge User Data
+// @Description Internal endpoint called by user-service once an account deletion falls due. Removes every activity-service row owned by the user; calling it again is a no-op.
+// @Tags internal
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param X-Internal-Token header string true "Internal API token"
+// @Success 200 {object} model.PurgeUserDataResponse
+// @Router /internal/users/{user_id} [delete]
+func PurgeUserDataHandler(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID", "details": err.Error()})
+		return
+	}
+
+	deleted, err := db.PurgeUserData(userID.String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge user data", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.PurgeUserDataResponse{
+		Service: "activity-service",
+		UserID:  userID,
+		Deleted: deleted,
+	})
+}
+
+// @Summary Get Activity Summary
+// @Description Internal endpoint used by user-service to show a user's recent activity on their profile. Privacy settings are checked by the caller.
+// @Tags internal
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param X-Internal-Token header string true "Internal API token"
+// @Success 200 {object} model.ActivitySummary
+// @Router /internal/users/{user_id}/activity-summary [get]
+func GetActivitySummaryHandler(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID", "details": err.Error()})
+		return
+	}
+
+	summary, err := db.GetActivitySummaryByUserID(userID.String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get activity summary", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// @Summary Get Activity Period
+// @Description Internal endpoint used by user-service to build weekly digests. Totals the user's activities and steps between from (inclusive) and to (exclusive).
+// @Tags internal
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param from query string true "Start of the period (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string true "End of the period (RFC 3339 or YYYY-MM-DD)"
+// @Param X-Internal-Token header string true "Internal API token"
+// @Success 200 {object} model.ActivityPeriod
+// @Router /internal/users/{user_id}/activity-period [get]
+func GetActivityPeriodHandler(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID", "details": err.Error()})
+		return
+	}
+	params, err := pagination.FromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+	if params.From == nil || params.To == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": "from and to are required"})
+		return
+	}
+
+	period, err := db.GetActivityPeriodByUserID(userID.String(), *params.From, *params.To)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get activity period", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, period)
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestPurgeUserDataHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		userID         string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Invalid user ID",
+			userID:         "not-a-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid user ID",
+		},
+		{
+			name:           "Database unavailable",
+			userID:         uuid.New().String(),
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to purge user data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.DELETE("/internal/users/:user_id", PurgeUserDataHandler)
+
+			req := httptest.NewRequest("DELETE", "/internal/users/"+tt.userID, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedError) {
+				t.Errorf("Expected error message to contain '%s', got: %s", tt.expectedError, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetActivitySummaryHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		userID         string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Invalid user ID",
+			userID:         "not-a-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid user ID",
+		},
+		{
+			name:           "Database unavailable",
+			userID:         uuid.New().String(),
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to get activity summary",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/internal/users/:user_id/activity-summary", GetActivitySummaryHandler)
+
+			req := httptest.NewRequest("GET", "/internal/users/"+tt.userID+"/activity-summary", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedError) {
+				t.Errorf("Expected error message to contain '%s', got: %s", tt.expectedError, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetActivityPeriodHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Invalid user ID",
+			path:           "/internal/users/not-a-uuid/activity-period?from=2025-06-02&to=2025-06-08",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid user ID",
+		},
+		{
+			name:           "Missing period",
+			path:           "/internal/users/" + userID + "/activity-period?from=2025-06-02",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "from and to are required",
+		},
+		{
+			name:           "Period ending before it starts",
+			path:           "/internal/users/" + userID + "/activity-period?from=2025-06-09&to=2025-06-02",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "from must be before to",
+		},
+		{
+			name:           "Database unavailable",
+			path:           "/internal/users/" + userID + "/activity-period?from=2025-06-02T00:00:00Z&to=2025-06-09T00:00:00Z",
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to get activity period",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/internal/users/:user_id/activity-period", GetActivityPeriodHandler)
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedError) {
+				t.Errorf("Expected error message to contain '%s', got: %s", tt.expectedError, w.Body.String())
+			}
+		})
+	}
+}
@@ -1,13 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ffabious/healthy-summer/activity-service/internal/auth"
 	"github.com/ffabious/healthy-summer/activity-service/internal/db"
 	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/ffabious/healthy-summer/activity-service/internal/pagination"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -18,7 +21,7 @@ import (
 // @Accept json
 // @Produce json
 // @Param activity body model.PostActivityRequest true "Activity data"
-// @Success 201 {object} model.Activity
+// @Success 201 {object} model.CreateActivityResponse
 // @Router /api/activities [post]
 // @Security BearerAuth
 func PostActivityHandler(c *gin.Context) {
@@ -47,14 +50,23 @@ func PostActivityHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create activity", "details": err.Error()})
 		return
 	}
-	c.JSON(http.StatusCreated, activity)
+	c.JSON(http.StatusCreated, model.CreateActivityResponse{
+		Activity:           activity,
+		NewPersonalRecords: refreshPersonalRecords(user_id),
+	})
 }
 
 // @Summary Get Activities
-// @Description Get all activities for a user
+// @Description Get a page of the authenticated user's activities
 // @Tags activities
 // @Produce json
-// @Success 200 {array} model.Activity
+// @Param limit query int false "Page size (default: 50, max: 200)"
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Param from query string false "Start of the range, RFC 3339 timestamp or YYYY-MM-DD (inclusive)"
+// @Param to query string false "End of the range, RFC 3339 timestamp or YYYY-MM-DD (inclusive day)"
+// @Param type query string false "Comma-separated activity types"
+// @Param sort query string false "Sort by timestamp: desc (default) or asc"
+// @Success 200 {object} model.ActivityPage
 // @Router /api/activities [get]
 // @Security BearerAuth
 func GetActivitiesHandler(c *gin.Context) {
@@ -64,13 +76,25 @@ func GetActivitiesHandler(c *gin.Context) {
 		return
 	}
 
-	activities, err := db.GetActivitiesByUserID(user_id)
+	params, err := pagination.FromQuery(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Activities not found", "details": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+	var types []string
+	for _, t := range strings.Split(c.Query("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+
+	page, err := db.ListActivities(user_id, types, params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve activities", "details": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, activities)
+	c.JSON(http.StatusOK, page)
 }
 
 // @Summary Get Current User Activity Stats
@@ -102,7 +126,7 @@ func GetCurrentUserActivityStatsHandler(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param step_entry body model.PostStepEntryRequest true "Step entry data"
-// @Success 201 {object} model.StepEntry
+// @Success 201 {object} model.CreateStepEntryResponse
 // @Router /api/activities/steps [post]
 // @Security BearerAuth
 func PostStepEntryHandler(c *gin.Context) {
@@ -128,7 +152,10 @@ func PostStepEntryHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, stepEntry)
+	c.JSON(http.StatusCreated, model.CreateStepEntryResponse{
+		StepEntry:          stepEntry,
+		NewPersonalRecords: refreshPersonalRecords(user_id),
+	})
 }
 
 // @Summary Get Step Entries
@@ -163,15 +190,36 @@ func GetStepEntriesHandler(c *gin.Context) {
 }
 
 // @Summary Get Activity Analytics
-// @Description Get activity analytics for a user
+// @Description Get activity analytics for a user. Other users' analytics are only returned if their privacy settings share activities with the caller.
 // @Tags activities
 // @Produce json
 // @Param user_id path string true "User ID"
 // @Success 200 {object} model.GetActivityAnalyticsResponse
 // @Router /api/activities/analytics/{user_id} [get]
+// @Security BearerAuth
 // GetActivityAnalyticsHandler retrieves activity analytics for a user
 func GetActivityAnalyticsHandler(c *gin.Context) {
+	viewerID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
 	user_id := c.Param("user_id")
+	if _, err := uuid.Parse(user_id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	allowed, err := db.CanViewActivities(user_id, viewerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check privacy settings", "details": err.Error()})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This user does not share activities with you"})
+		return
+	}
+
 	analytics, err := db.GetActivityAnalyticsByUserID(user_id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Activity analytics not found"})
@@ -222,22 +270,20 @@ func UpdateActivityHandler(c *gin.Context) {
 		return
 	}
 
-	// Update the activity
-	activity := model.Activity{
-		ID:          existingActivity.ID,
-		UserID:      existingActivity.UserID,
-		Type:        req.Type,
-		DurationMin: req.DurationMin,
-		Intensity:   req.Intensity,
-		Calories:    req.Calories,
-		Location:    req.Location,
-		Timestamp:   existingActivity.Timestamp, // Keep original timestamp
-	}
+	// Update the editable fields only; the timestamp, the summary of an
+	// imported track and an attached image are kept.
+	activity := *existingActivity
+	activity.Type = req.Type
+	activity.DurationMin = req.DurationMin
+	activity.Intensity = req.Intensity
+	activity.Calories = req.Calories
+	activity.Location = req.Location
 
 	if err := db.UpdateActivity(&activity); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update activity", "details": err.Error()})
 		return
 	}
+	refreshPersonalRecords(user_id)
 
 	c.JSON(http.StatusOK, activity)
 }
@@ -278,17 +324,78 @@ func DeleteActivityHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete activity", "details": err.Error()})
 		return
 	}
+	refreshPersonalRecords(user_id)
 
 	c.JSON(http.StatusNoContent, nil)
 }
 
+// @Summary Attach Activity Image
+// @Description Attach an image uploaded through POST /api/uploads to an activity, replacing any previous image
+// @Tags activities
+// @Accept json
+// @Produce json
+// @Param id path string true "Activity ID"
+// @Param image body model.AttachImageRequest true "Uploaded image"
+// @Success 200 {object} model.Activity
+// @Router /api/activities/{id}/image [put]
+// @Security BearerAuth
+func SetActivityImageHandler(c *gin.Context) {
+	var req model.AttachImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+	setActivityImage(c, &req.ImageID)
+}
+
+// @Summary Remove Activity Image
+// @Description Detach the image from an activity. The upload itself is kept.
+// @Tags activities
+// @Produce json
+// @Param id path string true "Activity ID"
+// @Success 200 {object} model.Activity
+// @Router /api/activities/{id}/image [delete]
+// @Security BearerAuth
+func DeleteActivityImageHandler(c *gin.Context) {
+	setActivityImage(c, nil)
+}
+
+func setActivityImage(c *gin.Context, imageID *uuid.UUID) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	activity, err := db.GetActivityByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found", "details": err.Error()})
+		return
+	}
+	if activity.UserID.String() != user_id {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only change your own activities"})
+		return
+	}
+
+	err = db.SetActivityImage(activity, imageID)
+	if errors.Is(err, db.ErrImageNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update activity image", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, activity)
+}
+
 // @Summary Create Step Entry
 // @Description Create a new step entry for the authenticated user
 // @Tags activities
 // @Accept json
 // @Produce json
 // @Param stepEntry body model.StepEntry true "Step entry data"
-// @Success 201 {object} model.StepEntry
+// @Success 201 {object} model.CreateStepEntryResponse
 // @Failure 400 {object} gin.H
 // @Failure 401 {object} gin.H
 // @Failure 500 {object} gin.H
@@ -324,5 +431,8 @@ func CreateStepEntryHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, stepEntry)
+	c.JSON(http.StatusCreated, model.CreateStepEntryResponse{
+		StepEntry:          stepEntry,
+		NewPersonalRecords: refreshPersonalRecords(user_id),
+	})
 }
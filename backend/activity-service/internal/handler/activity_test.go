@@ -9,9 +9,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ffabious/healthy-summer/activity-service/internal/db"
 	"github.com/ffabious/healthy-summer/activity-service/internal/model"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
 func TestPostActivityHandlerWithoutAuth(t *testing.T) {
@@ -318,26 +321,47 @@ func TestGetStepEntriesHandlerWithoutAuth(t *testing.T) {
 func TestGetActivityAnalyticsHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
+	viewerID := uuid.New().String()
+	token := "Bearer " + generateTestToken(t, viewerID)
+
 	tests := []struct {
 		name           string
 		userID         string
+		authHeader     string
 		expectedStatus int
 		expectedError  string
 	}{
 		{
-			name:           "Valid user ID",
+			name:           "Missing authorization header",
 			userID:         uuid.New().String(),
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Unauthorized",
+		},
+		{
+			name:           "Own analytics",
+			userID:         viewerID,
+			authHeader:     token,
 			expectedStatus: http.StatusNotFound, // Expected since no database setup
 		},
+		{
+			name:           "Other user's analytics",
+			userID:         uuid.New().String(),
+			authHeader:     token,
+			expectedStatus: http.StatusInternalServerError, // Privacy check needs the database
+			expectedError:  "Failed to check privacy settings",
+		},
 		{
 			name:           "Empty user ID",
 			userID:         "",
+			authHeader:     token,
 			expectedStatus: http.StatusNotFound,
 		},
 		{
 			name:           "Invalid user ID format",
 			userID:         "invalid-uuid",
-			expectedStatus: http.StatusNotFound,
+			authHeader:     token,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid user ID",
 		},
 	}
 
@@ -348,6 +372,9 @@ func TestGetActivityAnalyticsHandler(t *testing.T) {
 
 			url := "/api/activities/analytics/" + tt.userID
 			req := httptest.NewRequest("GET", url, nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
 
 			w := httptest.NewRecorder()
 			router.ServeHTTP(w, req)
@@ -443,6 +470,79 @@ func TestUpdateActivityHandlerWithoutAuth(t *testing.T) {
 	}
 }
 
+// setupActivityTestDB points the db package at an in-memory SQLite database
+// with an activities table, restoring the original connection afterwards.
+func setupActivityTestDB(t *testing.T) {
+	t.Helper()
+	originalDB := db.DB
+	t.Cleanup(func() { db.DB = originalDB })
+
+	var err error
+	db.DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to setup test database: %v", err)
+	}
+	if err := db.DB.Exec(`CREATE TABLE activities (
+		id TEXT PRIMARY KEY, user_id TEXT, type TEXT, duration_min INTEGER, intensity TEXT,
+		calories INTEGER, location TEXT, timestamp DATETIME, distance_m REAL,
+		elevation_gain_m REAL, avg_heart_rate INTEGER, max_heart_rate INTEGER,
+		image_id TEXT, image_url TEXT, thumbnail_url TEXT)`).Error; err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+}
+
+// updateActivity sends an update for activity as its owner and returns the
+// stored row.
+func updateActivity(t *testing.T, activity model.Activity, req model.UpdateActivityRequest) model.Activity {
+	t.Helper()
+	if err := db.DB.Create(&activity).Error; err != nil {
+		t.Fatalf("Failed to seed activity: %v", err)
+	}
+
+	router := gin.New()
+	router.PUT("/api/activities/:id", UpdateActivityHandler)
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest("PUT", "/api/activities/"+activity.ID.String(), bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+generateTestToken(t, activity.UserID.String()))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httpReq)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var stored model.Activity
+	if err := db.DB.First(&stored, "id = ?", activity.ID).Error; err != nil {
+		t.Fatalf("Failed to load activity: %v", err)
+	}
+	return stored
+}
+
+func TestUpdateActivityHandlerKeepsTrackSummary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupActivityTestDB(t)
+
+	timestamp := time.Date(2025, 6, 1, 7, 30, 0, 0, time.UTC)
+	stored := updateActivity(t, model.Activity{
+		ID: uuid.New(), UserID: uuid.New(), Type: "running", DurationMin: 42,
+		Intensity: model.IntensityMedium, Calories: 450, Timestamp: timestamp,
+		DistanceM: 8012.5, ElevationGainM: 74.3, AvgHeartRate: 148, MaxHeartRate: 171,
+	}, model.UpdateActivityRequest{
+		Type: "trail running", DurationMin: 45, Intensity: model.IntensityHigh, Calories: 500, Location: "Forest",
+	})
+
+	if stored.Type != "trail running" || stored.DurationMin != 45 || stored.Intensity != model.IntensityHigh ||
+		stored.Calories != 500 || stored.Location != "Forest" {
+		t.Errorf("Editable fields were not updated: %+v", stored)
+	}
+	if !stored.Timestamp.Equal(timestamp) {
+		t.Errorf("Expected timestamp %v to be kept, got %v", timestamp, stored.Timestamp)
+	}
+	if stored.DistanceM != 8012.5 || stored.ElevationGainM != 74.3 || stored.AvgHeartRate != 148 || stored.MaxHeartRate != 171 {
+		t.Errorf("Track summary was not kept: %+v", stored)
+	}
+}
+
 func TestDeleteActivityHandlerWithoutAuth(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -680,3 +780,24 @@ func TestActivityRequestValidation(t *testing.T) {
 	}
 }
 
+
+func TestGetActivitiesHandlerInvalidQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	token := "Bearer " + generateTestToken(t, uuid.New().String())
+
+	for _, query := range []string{"limit=-1", "cursor=not-a-cursor", "from=2025-13-01", "sort=random"} {
+		t.Run(query, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/api/activities", GetActivitiesHandler)
+
+			req := httptest.NewRequest("GET", "/api/activities?"+query, nil)
+			req.Header.Set("Authorization", token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/auth"
+	"github.com/ffabious/healthy-summer/activity-service/internal/db"
+	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/ffabious/healthy-summer/activity-service/internal/transfer"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MaxImportFileSize limits the size of a bulk import upload.
+const MaxImportFileSize = 20 << 20
+
+var activityColumns = []string{
+	"id", "type", "duration_min", "intensity", "calories", "location", "timestamp",
+	"distance_m", "elevation_gain_m", "avg_heart_rate", "max_heart_rate",
+}
+
+var stepColumns = []string{"id", "date", "steps"}
+
+// importID returns the ID an imported row is stored under. Rows exported by
+// us keep their ID; other rows get an ID derived from the user and the row
+// content, so uploading the same file twice does not create duplicates.
+func importID(userID uuid.UUID, dataset string, r transfer.Record) uuid.UUID {
+	if id, err := uuid.Parse(r.Fields["id"]); err == nil {
+		return id
+	}
+	keys := make([]string, 0, len(r.Fields))
+	for k := range r.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteString(dataset)
+	for _, k := range keys {
+		sb.WriteString("\x00" + k + "=" + r.Fields[k])
+	}
+	return uuid.NewSHA1(userID, []byte(sb.String()))
+}
+
+func activityFromRecord(userID uuid.UUID, r transfer.Record) (model.Activity, error) {
+	activity := model.Activity{ID: importID(userID, "activities", r), UserID: userID}
+	var err error
+	if activity.Type, err = r.Required("type"); err != nil {
+		return activity, err
+	}
+	if activity.DurationMin, err = r.Int("duration_min", true); err != nil {
+		return activity, err
+	}
+	if activity.DurationMin <= 0 {
+		return activity, fmt.Errorf("duration_min must be greater than 0")
+	}
+	activity.Intensity = model.Intensity(strings.ToLower(r.Fields["intensity"]))
+	if activity.Intensity == "" {
+		activity.Intensity = model.IntensityMedium
+	}
+	if !activity.Intensity.IsValid() {
+		return activity, fmt.Errorf("intensity must be low, medium or high")
+	}
+	if activity.Calories, err = r.Int("calories", false); err != nil {
+		return activity, err
+	}
+	if activity.Timestamp, err = r.Time("timestamp"); err != nil {
+		return activity, err
+	}
+	if activity.DistanceM, err = r.Float("distance_m", false); err != nil {
+		return activity, err
+	}
+	if activity.ElevationGainM, err = r.Float("elevation_gain_m", false); err != nil {
+		return activity, err
+	}
+	if activity.AvgHeartRate, err = r.Int("avg_heart_rate", false); err != nil {
+		return activity, err
+	}
+	if activity.MaxHeartRate, err = r.Int("max_heart_rate", false); err != nil {
+		return activity, err
+	}
+	if activity.Calories < 0 || activity.DistanceM < 0 || activity.ElevationGainM < 0 ||
+		activity.AvgHeartRate < 0 || activity.MaxHeartRate < 0 {
+		return activity, fmt.Errorf("numeric values cannot be negative")
+	}
+	activity.Location = r.Fields["location"]
+	if len(activity.Type) > 50 || len(activity.Location) > 100 {
+		return activity, fmt.Errorf("type or location is too long")
+	}
+	return activity, nil
+}
+
+func stepEntryFromRecord(userID uuid.UUID, r transfer.Record) (model.StepEntry, error) {
+	entry := model.StepEntry{ID: importID(userID, "steps", r), UserID: userID}
+	var err error
+	if entry.Date, err = r.Time("date"); err != nil {
+		return entry, err
+	}
+	if entry.Steps, err = r.Int("steps", true); err != nil {
+		return entry, err
+	}
+	if entry.Steps < 0 {
+		return entry, fmt.Errorf("steps cannot be negative")
+	}
+	return entry, nil
+}
+
+// parseRecords converts the records of a file, collecting per-row errors and
+// dropping rows that repeat an ID already seen in the upload.
+func parseRecords[T any](file transfer.File, report *transfer.Report, seen map[uuid.UUID]bool,
+	parse func(transfer.Record) (T, error), id func(T) uuid.UUID) ([]T, transfer.DatasetReport) {
+	summary := transfer.DatasetReport{Dataset: file.Name, Rows: len(file.Records)}
+	var items []T
+	for _, r := range file.Records {
+		item, err := parse(r)
+		if err != nil {
+			summary.Failed++
+			report.Errors = append(report.Errors, transfer.RowError{File: file.Path, Row: r.Row, Error: err.Error()})
+			continue
+		}
+		if seen[id(item)] {
+			summary.Skipped++
+			continue
+		}
+		seen[id(item)] = true
+		items = append(items, item)
+	}
+	return items, summary
+}
+
+// @Summary Export Activity Data
+// @Description Download the authenticated user's activities and step entries as a zip of CSV or JSON files
+// @Tags data
+// @Produce application/zip
+// @Param format query string false "csv (default) or json"
+// @Success 200 {file} file
+// @Router /api/activities/data/export [get]
+// @Security BearerAuth
+func ExportActivityDataHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+	format, err := transfer.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	activities, err := db.GetActivitiesByUserID(user_id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve activities", "details": err.Error()})
+		return
+	}
+	steps, err := db.GetAllStepEntriesByUserID(user_id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve step entries", "details": err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := transfer.WriteZip(&buf, format, activityDatasets(*activities, steps)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build export", "details": err.Error()})
+		return
+	}
+
+	filename := "activities-" + time.Now().UTC().Format("20060102") + ".zip"
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+func activityDatasets(activities []model.Activity, steps []model.StepEntry) []transfer.Dataset {
+	sort.SliceStable(activities, func(i, j int) bool {
+		return activities[i].Timestamp.Before(activities[j].Timestamp)
+	})
+	if activities == nil {
+		activities = []model.Activity{}
+	}
+	if steps == nil {
+		steps = []model.StepEntry{}
+	}
+
+	activityRows := make([][]string, len(activities))
+	for i, a := range activities {
+		activityRows[i] = []string{
+			a.ID.String(), a.Type, strconv.Itoa(a.DurationMin), string(a.Intensity),
+			strconv.Itoa(a.Calories), a.Location, a.Timestamp.UTC().Format(time.RFC3339),
+			transfer.FormatFloat(a.DistanceM), transfer.FormatFloat(a.ElevationGainM),
+			strconv.Itoa(a.AvgHeartRate), strconv.Itoa(a.MaxHeartRate),
+		}
+	}
+	stepRows := make([][]string, len(steps))
+	for i, s := range steps {
+		stepRows[i] = []string{s.ID.String(), s.Date.Format(time.DateOnly), strconv.Itoa(s.Steps)}
+	}
+
+	return []transfer.Dataset{
+		{Name: "activities", Columns: activityColumns, Rows: activityRows, Items: activities},
+		{Name: "steps", Columns: stepColumns, Rows: stepRows, Items: steps},
+	}
+}
+
+// @Summary Import Activity Data
+// @Description Import activities and step entries from a zip produced by the export endpoint or from a single activities/steps .csv or .json file. Invalid rows are reported and skipped; rows that were already imported are not duplicated.
+// @Tags data
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Zip, CSV or JSON file"
+// @Success 200 {object} transfer.Report
+// @Router /api/activities/data/import [post]
+// @Security BearerAuth
+func ImportActivityDataHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+	userID := uuid.MustParse(user_id)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Import file is required", "details": err.Error()})
+		return
+	}
+	if fileHeader.Size > MaxImportFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Import file is too large"})
+		return
+	}
+	f, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read import file", "details": err.Error()})
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(f, MaxImportFileSize+1))
+	f.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read import file", "details": err.Error()})
+		return
+	}
+
+	files, err := transfer.ReadFiles(fileHeader.Filename, data)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid import file", "details": err.Error()})
+		return
+	}
+
+	report := transfer.Report{Datasets: []transfer.DatasetReport{}, Errors: []transfer.RowError{}}
+	seen := make(map[uuid.UUID]bool)
+	for _, file := range files {
+		var summary transfer.DatasetReport
+		var inserted int64
+		switch file.Name {
+		case "activities":
+			var activities []model.Activity
+			activities, summary = parseRecords(file, &report, seen,
+				func(r transfer.Record) (model.Activity, error) { return activityFromRecord(userID, r) },
+				func(a model.Activity) uuid.UUID { return a.ID })
+			inserted, err = db.InsertActivities(activities)
+			summary.Skipped += len(activities) - int(inserted)
+		case "steps":
+			var steps []model.StepEntry
+			steps, summary = parseRecords(file, &report, seen,
+				func(r transfer.Record) (model.StepEntry, error) { return stepEntryFromRecord(userID, r) },
+				func(s model.StepEntry) uuid.UUID { return s.ID })
+			inserted, err = db.InsertStepEntries(steps)
+			summary.Skipped += len(steps) - int(inserted)
+		default:
+			report.Errors = append(report.Errors, transfer.RowError{File: file.Path, Error: "unknown dataset, expected activities or steps"})
+			continue
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import data", "details": err.Error(), "report": report})
+			return
+		}
+		summary.Imported = int(inserted)
+		report.Datasets = append(report.Datasets, summary)
+	}
+
+	refreshPersonalRecords(user_id)
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/ffabious/healthy-summer/activity-service/internal/transfer"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestActivityFromRecord(t *testing.T) {
+	userID := uuid.New()
+	valid := map[string]string{
+		"type": "running", "duration_min": "30", "intensity": "High", "calories": "300",
+		"timestamp": "2025-06-01T07:00:00Z", "distance_m": "5000",
+	}
+
+	activity, err := activityFromRecord(userID, transfer.Record{Row: 1, Fields: valid})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if activity.UserID != userID || activity.Intensity != model.IntensityHigh || activity.DistanceM != 5000 {
+		t.Errorf("Unexpected activity %+v", activity)
+	}
+
+	tests := []struct {
+		name     string
+		override map[string]string
+		errPart  string
+	}{
+		{name: "Missing type", override: map[string]string{"type": ""}, errPart: "type is required"},
+		{name: "Zero duration", override: map[string]string{"duration_min": "0"}, errPart: "duration_min"},
+		{name: "Bad intensity", override: map[string]string{"intensity": "extreme"}, errPart: "intensity"},
+		{name: "Negative calories", override: map[string]string{"calories": "-5"}, errPart: "negative"},
+		{name: "Bad timestamp", override: map[string]string{"timestamp": "yesterday"}, errPart: "timestamp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := make(map[string]string)
+			for k, v := range valid {
+				fields[k] = v
+			}
+			for k, v := range tt.override {
+				fields[k] = v
+			}
+			_, err := activityFromRecord(userID, transfer.Record{Fields: fields})
+			if err == nil || !strings.Contains(err.Error(), tt.errPart) {
+				t.Errorf("Expected error containing %q, got %v", tt.errPart, err)
+			}
+		})
+	}
+}
+
+func TestImportIDIsStable(t *testing.T) {
+	userID := uuid.New()
+	fields := map[string]string{"date": "2025-06-01", "steps": "12000"}
+
+	first := importID(userID, "steps", transfer.Record{Row: 1, Fields: fields})
+	second := importID(userID, "steps", transfer.Record{Row: 7, Fields: fields})
+	if first != second {
+		t.Error("Expected the same row to map to the same ID regardless of position")
+	}
+	if other := importID(uuid.New(), "steps", transfer.Record{Fields: fields}); other == first {
+		t.Error("Expected different users to get different IDs")
+	}
+
+	exported := uuid.New()
+	if got := importID(userID, "steps", transfer.Record{Fields: map[string]string{"id": exported.String()}}); got != exported {
+		t.Error("Expected exported rows to keep their ID")
+	}
+}
+
+func TestActivityDatasets(t *testing.T) {
+	datasets := activityDatasets(nil, nil)
+	if len(datasets) != 2 || datasets[0].Name != "activities" || datasets[1].Name != "steps" {
+		t.Fatalf("Unexpected datasets %+v", datasets)
+	}
+	if b, _ := json.Marshal(datasets[0].Items); string(b) != "[]" {
+		t.Errorf("Expected empty JSON array, got %s", b)
+	}
+}
+
+func TestImportActivityDataHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validToken := "Bearer " + generateTestToken(t, uuid.New().String())
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		filename       string
+		content        string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Invalid JWT token",
+			authHeader:     "Bearer invalid.token.here",
+			filename:       "activities.csv",
+			content:        "type\nrunning\n",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Missing file",
+			authHeader:     validToken,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Import file is required",
+		},
+		{
+			name:           "Unsupported file",
+			authHeader:     validToken,
+			filename:       "activities.xlsx",
+			content:        "binary",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "Invalid import file",
+		},
+		{
+			name:           "Unknown dataset",
+			authHeader:     validToken,
+			filename:       "meals.csv",
+			content:        "name\nsalad\n",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "unknown dataset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/api/activities/data/import", ImportActivityDataHandler)
+
+			body, contentType := multipartBody(t, tt.filename, []byte(tt.content), nil)
+			req := httptest.NewRequest("POST", "/api/activities/data/import", body)
+			req.Header.Set("Content-Type", contentType)
+			req.Header.Set("Authorization", tt.authHeader)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain '%s', got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestExportActivityDataHandlerInvalidFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/activities/data/export", ExportActivityDataHandler)
+
+	req := httptest.NewRequest("GET", "/api/activities/data/export?format=xml", nil)
+	req.Header.Set("Authorization", "Bearer "+generateTestToken(t, uuid.New().String()))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/auth"
+	"github.com/ffabious/healthy-summer/activity-service/internal/db"
+	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/ffabious/healthy-summer/activity-service/internal/trackfile"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MaxWorkoutFileSize limits the size of uploaded GPX/TCX/FIT files.
+const MaxWorkoutFileSize = 20 << 20
+
+// intensityFromHeartRate gives a rough intensity for an imported workout when
+// the client does not provide one.
+func intensityFromHeartRate(avgHR int) model.Intensity {
+	switch {
+	case avgHR == 0:
+		return model.IntensityMedium
+	case avgHR < 120:
+		return model.IntensityLow
+	case avgHR < 150:
+		return model.IntensityMedium
+	default:
+		return model.IntensityHigh
+	}
+}
+
+// @Summary Import Workout File
+// @Description Create an activity from a GPX, TCX or FIT file. Re-uploading the same file returns the activity created the first time.
+// @Tags activities
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Workout file (.gpx, .tcx or .fit)"
+// @Param type formData string false "Activity type (defaults to the sport recorded in the file)"
+// @Param intensity formData string false "Intensity: low, medium or high (defaults to a heart-rate estimate)"
+// @Param calories formData int false "Calories (defaults to the value recorded in the file)"
+// @Param location formData string false "Location"
+// @Success 201 {object} model.ImportWorkoutResponse
+// @Success 200 {object} model.ImportWorkoutResponse "File was already imported"
+// @Router /api/activities/import [post]
+// @Security BearerAuth
+func ImportWorkoutHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	var form model.ImportWorkoutForm
+	if err := c.ShouldBind(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Workout file is required", "details": err.Error()})
+		return
+	}
+	if fileHeader.Size > MaxWorkoutFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Workout file is too large"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read workout file", "details": err.Error()})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, MaxWorkoutFileSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read workout file", "details": err.Error()})
+		return
+	}
+	if len(data) > MaxWorkoutFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Workout file is too large"})
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	fileHash := hex.EncodeToString(sum[:])
+
+	if resp, ok := existingImport(user_id, fileHash); ok {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	workout, err := trackfile.Parse(fileHeader.Filename, data)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid workout file", "details": err.Error()})
+		return
+	}
+
+	activityType := form.Type
+	if activityType == "" {
+		activityType = trackfile.ActivityType(workout.Sport)
+	}
+	intensity := form.Intensity
+	if intensity == "" {
+		intensity = intensityFromHeartRate(workout.AvgHeartRate)
+	}
+	if !intensity.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": "intensity must be low, medium or high"})
+		return
+	}
+	calories := workout.Calories
+	if form.Calories != nil {
+		calories = *form.Calories
+	}
+	durationMin := int(math.Ceil(workout.Duration.Minutes()))
+	if durationMin < 1 {
+		durationMin = 1
+	}
+
+	activity := model.Activity{
+		ID:             uuid.New(),
+		UserID:         uuid.MustParse(user_id),
+		Type:           activityType,
+		DurationMin:    durationMin,
+		Intensity:      intensity,
+		Calories:       calories,
+		Location:       form.Location,
+		Timestamp:      workout.StartTime,
+		DistanceM:      workout.DistanceM,
+		ElevationGainM: workout.ElevationGainM,
+		AvgHeartRate:   workout.AvgHeartRate,
+		MaxHeartRate:   workout.MaxHeartRate,
+	}
+	imp := model.WorkoutImport{
+		ID:        uuid.New(),
+		UserID:    activity.UserID,
+		FileHash:  fileHash,
+		FileName:  fileHeader.Filename,
+		Format:    string(workout.Format),
+		CreatedAt: time.Now(),
+	}
+
+	if err := db.CreateImportedActivity(&activity, workout.Points, &imp); err != nil {
+		// A concurrent upload of the same file may have won the unique index.
+		if resp, ok := existingImport(user_id, fileHash); ok {
+			c.JSON(http.StatusOK, resp)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import activity", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.ImportWorkoutResponse{
+		Activity:           activity,
+		Format:             string(workout.Format),
+		PointCount:         len(workout.Points),
+		NewPersonalRecords: refreshPersonalRecords(user_id),
+	})
+}
+
+func existingImport(userID, fileHash string) (*model.ImportWorkoutResponse, bool) {
+	imp, err := db.GetWorkoutImport(userID, fileHash)
+	if err != nil || imp == nil {
+		return nil, false
+	}
+	activity, err := db.GetActivityByID(imp.ActivityID.String())
+	if err != nil {
+		return nil, false
+	}
+	return &model.ImportWorkoutResponse{
+		Activity:  *activity,
+		Format:    imp.Format,
+		Duplicate: true,
+	}, true
+}
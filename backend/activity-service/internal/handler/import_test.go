@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func generateTestToken(t *testing.T, userID string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": userID})
+	signed, err := token.SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func multipartBody(t *testing.T, filename string, content []byte, fields map[string]string) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for k, v := range fields {
+		if err := writer.WriteField(k, v); err != nil {
+			t.Fatalf("Failed to write field: %v", err)
+		}
+	}
+	if filename != "" {
+		part, err := writer.CreateFormFile("file", filename)
+		if err != nil {
+			t.Fatalf("Failed to create form file: %v", err)
+		}
+		part.Write(content)
+	}
+	writer.Close()
+	return &body, writer.FormDataContentType()
+}
+
+func TestImportWorkoutHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validToken := "Bearer " + generateTestToken(t, uuid.New().String())
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		filename       string
+		content        []byte
+		fields         map[string]string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Missing authorization header",
+			filename:       "run.gpx",
+			content:        []byte("<gpx></gpx>"),
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Unauthorized",
+		},
+		{
+			name:           "Invalid JWT token",
+			authHeader:     "Bearer invalid.token.here",
+			filename:       "run.gpx",
+			content:        []byte("<gpx></gpx>"),
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Unauthorized",
+		},
+		{
+			name:           "Missing file",
+			authHeader:     validToken,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Workout file is required",
+		},
+		{
+			name:           "Unsupported format",
+			authHeader:     validToken,
+			filename:       "notes.txt",
+			content:        []byte("just some text"),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "Invalid workout file",
+		},
+		{
+			name:           "GPX without points",
+			authHeader:     validToken,
+			filename:       "run.gpx",
+			content:        []byte("<gpx><trk><trkseg></trkseg></trk></gpx>"),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "no track data",
+		},
+		{
+			name:       "Invalid intensity",
+			authHeader: validToken,
+			filename:   "run.gpx",
+			content: []byte(`<gpx><trk><trkseg>
+				<trkpt lat="1" lon="1"><time>2025-06-01T07:00:00Z</time></trkpt>
+				<trkpt lat="1.01" lon="1"><time>2025-06-01T07:10:00Z</time></trkpt>
+			</trkseg></trk></gpx>`),
+			fields:         map[string]string{"intensity": "extreme"},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "intensity",
+		},
+		{
+			name:           "Type too long",
+			authHeader:     validToken,
+			filename:       "run.gpx",
+			content:        []byte("<gpx></gpx>"),
+			fields:         map[string]string{"type": strings.Repeat("a", 51)},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request data",
+		},
+		{
+			name:           "Location too long",
+			authHeader:     validToken,
+			filename:       "run.gpx",
+			content:        []byte("<gpx></gpx>"),
+			fields:         map[string]string{"location": strings.Repeat("a", 101)},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request data",
+		},
+		{
+			name:           "Negative calories",
+			authHeader:     validToken,
+			filename:       "run.gpx",
+			content:        []byte("<gpx></gpx>"),
+			fields:         map[string]string{"calories": "-5"},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request data",
+		},
+		{
+			name:           "Calories not a number",
+			authHeader:     validToken,
+			filename:       "run.gpx",
+			content:        []byte("<gpx></gpx>"),
+			fields:         map[string]string{"calories": "lots"},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/api/activities/import", ImportWorkoutHandler)
+
+			body, contentType := multipartBody(t, tt.filename, tt.content, tt.fields)
+			req := httptest.NewRequest("POST", "/api/activities/import", body)
+			req.Header.Set("Content-Type", contentType)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedError != "" && !strings.Contains(w.Body.String(), tt.expectedError) {
+				t.Errorf("Expected error message to contain '%s', got: %s", tt.expectedError, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestIntensityFromHeartRate(t *testing.T) {
+	tests := []struct {
+		avgHR    int
+		expected model.Intensity
+	}{
+		{avgHR: 0, expected: model.IntensityMedium},
+		{avgHR: 100, expected: model.IntensityLow},
+		{avgHR: 135, expected: model.IntensityMedium},
+		{avgHR: 165, expected: model.IntensityHigh},
+	}
+	for _, tt := range tests {
+		if got := intensityFromHeartRate(tt.avgHR); got != tt.expected {
+			t.Errorf("intensityFromHeartRate(%d) = %s, expected %s", tt.avgHR, got, tt.expected)
+		}
+	}
+}
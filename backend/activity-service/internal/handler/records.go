@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/auth"
+	"github.com/ffabious/healthy-summer/activity-service/internal/db"
+	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/ffabious/healthy-summer/activity-service/internal/records"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// recomputePersonalRecords rebuilds the user's records from their activities
+// and step entries and returns the records that improved.
+func recomputePersonalRecords(userID string) ([]model.PersonalRecord, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+	before, err := db.GetPersonalRecords(userID)
+	if err != nil {
+		return nil, err
+	}
+	activities, err := db.GetActivitiesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	// Only activities long enough for a 5k need their track.
+	var trackIDs []uuid.UUID
+	for _, a := range *activities {
+		if a.DistanceM >= records.FiveKM {
+			trackIDs = append(trackIDs, a.ID)
+		}
+	}
+	tracks, err := db.GetTrackPointsByActivityIDs(trackIDs)
+	if err != nil {
+		return nil, err
+	}
+	days, err := db.GetDailyStepTotals(userID)
+	if err != nil {
+		return nil, err
+	}
+	after := records.Compute(uid, *activities, tracks, days)
+	if err := db.ReplacePersonalRecords(userID, after); err != nil {
+		return nil, err
+	}
+	return records.Improved(before, after), nil
+}
+
+// refreshPersonalRecords recomputes records after a write. The write has
+// already succeeded, so a failure is logged rather than returned to the client.
+func refreshPersonalRecords(userID string) []model.PersonalRecord {
+	improved, err := recomputePersonalRecords(userID)
+	if err != nil {
+		log.Printf("Failed to recompute personal records for user %s: %v", userID, err)
+		return []model.PersonalRecord{}
+	}
+	return improved
+}
+
+// @Summary Get Personal Records
+// @Description Get the authenticated user's personal records: longest duration per activity type, most calories in one session, most steps in a day and fastest 5k per activity type
+// @Tags activities
+// @Produce json
+// @Success 200 {array} model.PersonalRecord
+// @Router /api/activities/records [get]
+// @Security BearerAuth
+func GetPersonalRecordsHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	personalRecords, err := db.GetPersonalRecords(user_id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve personal records", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, personalRecords)
+}
@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/auth"
+	"github.com/ffabious/healthy-summer/activity-service/internal/db"
+	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MaxStrengthSets limits the number of sets that can be attached to one activity.
+const MaxStrengthSets = 200
+
+// buildStrengthWorkout groups an activity's sets by exercise, keeping the
+// order in which exercises were first performed.
+func buildStrengthWorkout(activityID uuid.UUID, sets []model.StrengthSet, exercises map[uuid.UUID]model.Exercise) model.StrengthWorkoutResponse {
+	resp := model.StrengthWorkoutResponse{
+		ActivityID: activityID,
+		Exercises:  []model.ExerciseSets{},
+	}
+	index := make(map[uuid.UUID]int)
+	for _, s := range sets {
+		i, ok := index[s.ExerciseID]
+		if !ok {
+			i = len(resp.Exercises)
+			index[s.ExerciseID] = i
+			resp.Exercises = append(resp.Exercises, model.ExerciseSets{Exercise: exercises[s.ExerciseID]})
+		}
+		group := &resp.Exercises[i]
+		group.Sets = append(group.Sets, s)
+		group.VolumeKg += s.Volume()
+		group.EstimatedOneRepMax = math.Max(group.EstimatedOneRepMax, s.EstimatedOneRepMax())
+		resp.TotalSets++
+		resp.TotalVolumeKg += s.Volume()
+	}
+	return resp
+}
+
+// buildExerciseHistory groups sets (newest first) into per-activity sessions
+// and returns at most limit sessions. The best estimated one-rep max is taken
+// over the whole history.
+func buildExerciseHistory(exercise model.Exercise, sets []model.StrengthSet, limit int) model.ExerciseHistoryResponse {
+	resp := model.ExerciseHistoryResponse{
+		Exercise: exercise,
+		Sessions: []model.ExerciseSession{},
+	}
+	for _, s := range sets {
+		n := len(resp.Sessions)
+		if n == 0 || resp.Sessions[n-1].ActivityID != s.ActivityID {
+			resp.Sessions = append(resp.Sessions, model.ExerciseSession{ActivityID: s.ActivityID, Date: s.Timestamp})
+			n++
+		}
+		session := &resp.Sessions[n-1]
+		session.Sets = append(session.Sets, s)
+		session.VolumeKg += s.Volume()
+		session.TopSetWeightKg = math.Max(session.TopSetWeightKg, s.WeightKg)
+		if e1rm := s.EstimatedOneRepMax(); e1rm > session.EstimatedOneRepMax {
+			session.EstimatedOneRepMax = e1rm
+		}
+		if e1rm := s.EstimatedOneRepMax(); e1rm > resp.BestEstimatedOneRepMax {
+			resp.BestEstimatedOneRepMax = e1rm
+			date := s.Timestamp
+			resp.BestEstimatedOn = &date
+		}
+	}
+	if limit > 0 && len(resp.Sessions) > limit {
+		resp.Sessions = resp.Sessions[:limit]
+	}
+	return resp
+}
+
+// @Summary Search Exercises
+// @Description Look up exercises in the catalog by name and category
+// @Tags strength
+// @Produce json
+// @Param q query string false "Name search"
+// @Param category query string false "Category (e.g. legs, chest, back)"
+// @Success 200 {array} model.Exercise
+// @Router /api/activities/exercises [get]
+// @Security BearerAuth
+func GetExercisesHandler(c *gin.Context) {
+	if _, err := auth.ExtractUserID(c); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	exercises, err := db.SearchExercises(c.Query("q"), c.Query("category"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve exercises", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, exercises)
+}
+
+// @Summary Get Strength Sets
+// @Description Get the strength sets of an activity grouped by exercise
+// @Tags strength
+// @Produce json
+// @Param id path string true "Activity ID"
+// @Success 200 {object} model.StrengthWorkoutResponse
+// @Router /api/activities/{id}/sets [get]
+// @Security BearerAuth
+func GetStrengthSetsHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	activityID := c.Param("id")
+	activity, err := db.GetActivityByID(activityID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found", "details": err.Error()})
+		return
+	}
+	if activity.UserID.String() != user_id {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own activities"})
+		return
+	}
+
+	sets, err := db.GetStrengthSets(activityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sets", "details": err.Error()})
+		return
+	}
+	exercises, err := db.GetExercisesByIDs(exerciseIDs(sets))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve exercises", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildStrengthWorkout(activity.ID, sets, exercises))
+}
+
+// @Summary Put Strength Sets
+// @Description Replace the strength sets of an activity. Sets are numbered per exercise in the order given.
+// @Tags strength
+// @Accept json
+// @Produce json
+// @Param id path string true "Activity ID"
+// @Param sets body model.PutStrengthSetsRequest true "Sets"
+// @Success 200 {object} model.StrengthWorkoutResponse
+// @Router /api/activities/{id}/sets [put]
+// @Security BearerAuth
+func PutStrengthSetsHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	activityID := c.Param("id")
+
+	var req model.PutStrengthSetsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+	if len(req.Sets) > MaxStrengthSets {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": "too many sets"})
+		return
+	}
+
+	activity, err := db.GetActivityByID(activityID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found", "details": err.Error()})
+		return
+	}
+	if activity.UserID.String() != user_id {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own activities"})
+		return
+	}
+
+	sets := make([]model.StrengthSet, len(req.Sets))
+	setNumbers := make(map[uuid.UUID]int)
+	for i, in := range req.Sets {
+		setNumbers[in.ExerciseID]++
+		sets[i] = model.StrengthSet{
+			ID:         uuid.New(),
+			ActivityID: activity.ID,
+			UserID:     activity.UserID,
+			ExerciseID: in.ExerciseID,
+			Position:   i + 1,
+			SetNumber:  setNumbers[in.ExerciseID],
+			Reps:       in.Reps,
+			WeightKg:   in.WeightKg,
+			RPE:        in.RPE,
+			Timestamp:  activity.Timestamp,
+		}
+	}
+
+	exercises, err := db.GetExercisesByIDs(exerciseIDs(sets))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve exercises", "details": err.Error()})
+		return
+	}
+	for _, s := range sets {
+		if _, ok := exercises[s.ExerciseID]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown exercise", "details": s.ExerciseID.String()})
+			return
+		}
+	}
+
+	if err := db.ReplaceStrengthSets(activityID, sets); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store sets", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildStrengthWorkout(activity.ID, sets, exercises))
+}
+
+// @Summary Get Exercise History
+// @Description Get the authenticated user's sessions for an exercise with volume and estimated one-rep max
+// @Tags strength
+// @Produce json
+// @Param exercise_id path string true "Exercise ID"
+// @Param limit query int false "Maximum number of sessions (default: 20)"
+// @Success 200 {object} model.ExerciseHistoryResponse
+// @Router /api/activities/exercises/{exercise_id}/history [get]
+// @Security BearerAuth
+func GetExerciseHistoryHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	exerciseID, err := uuid.Parse(c.Param("exercise_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+
+	exercises, err := db.GetExercisesByIDs([]uuid.UUID{exerciseID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve exercise", "details": err.Error()})
+		return
+	}
+	exercise, ok := exercises[exerciseID]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+		return
+	}
+
+	sets, err := db.GetExerciseHistory(user_id, exerciseID.String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve exercise history", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildExerciseHistory(exercise, sets, limit))
+}
+
+func exerciseIDs(sets []model.StrengthSet) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool)
+	var ids []uuid.UUID
+	for _, s := range sets {
+		if !seen[s.ExerciseID] {
+			seen[s.ExerciseID] = true
+			ids = append(ids, s.ExerciseID)
+		}
+	}
+	return ids
+}
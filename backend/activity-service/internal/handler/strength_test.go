@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestPutStrengthSetsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validToken := "Bearer " + generateTestToken(t, uuid.New().String())
+	exerciseID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Invalid JWT token",
+			authHeader:     "Bearer invalid.token.here",
+			body:           `{"sets":[{"exercise_id":"` + exerciseID + `","reps":5,"weight_kg":100}]}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Unauthorized",
+		},
+		{
+			name:           "Missing sets",
+			authHeader:     validToken,
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request data",
+		},
+		{
+			name:           "Zero reps",
+			authHeader:     validToken,
+			body:           `{"sets":[{"exercise_id":"` + exerciseID + `","reps":0,"weight_kg":100}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request data",
+		},
+		{
+			name:           "Negative weight",
+			authHeader:     validToken,
+			body:           `{"sets":[{"exercise_id":"` + exerciseID + `","reps":5,"weight_kg":-1}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request data",
+		},
+		{
+			name:           "RPE out of range",
+			authHeader:     validToken,
+			body:           `{"sets":[{"exercise_id":"` + exerciseID + `","reps":5,"weight_kg":100,"rpe":11}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.PUT("/api/activities/:id/sets", PutStrengthSetsHandler)
+
+			req := httptest.NewRequest("PUT", "/api/activities/"+uuid.New().String()+"/sets", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", tt.authHeader)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedError) {
+				t.Errorf("Expected error message to contain '%s', got: %s", tt.expectedError, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetExerciseHistoryHandlerInvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/activities/exercises/:exercise_id/history", GetExerciseHistoryHandler)
+
+	req := httptest.NewRequest("GET", "/api/activities/exercises/not-a-uuid/history", nil)
+	req.Header.Set("Authorization", "Bearer "+generateTestToken(t, uuid.New().String()))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+	}
+}
+
+func TestBuildStrengthWorkout(t *testing.T) {
+	activityID := uuid.New()
+	squat := model.Exercise{ID: uuid.New(), Name: "Back Squat"}
+	bench := model.Exercise{ID: uuid.New(), Name: "Bench Press"}
+	exercises := map[uuid.UUID]model.Exercise{squat.ID: squat, bench.ID: bench}
+
+	sets := []model.StrengthSet{
+		{ExerciseID: squat.ID, SetNumber: 1, Reps: 5, WeightKg: 100},
+		{ExerciseID: bench.ID, SetNumber: 1, Reps: 8, WeightKg: 60},
+		{ExerciseID: squat.ID, SetNumber: 2, Reps: 3, WeightKg: 110},
+	}
+
+	resp := buildStrengthWorkout(activityID, sets, exercises)
+	if resp.TotalSets != 3 {
+		t.Errorf("Expected 3 sets, got %d", resp.TotalSets)
+	}
+	if resp.TotalVolumeKg != 1310 {
+		t.Errorf("Expected total volume 1310, got %.1f", resp.TotalVolumeKg)
+	}
+	if len(resp.Exercises) != 2 || resp.Exercises[0].Exercise.ID != squat.ID {
+		t.Fatalf("Expected squat then bench, got %+v", resp.Exercises)
+	}
+	if len(resp.Exercises[0].Sets) != 2 || resp.Exercises[0].VolumeKg != 830 {
+		t.Errorf("Unexpected squat group %+v", resp.Exercises[0])
+	}
+	if resp.Exercises[0].EstimatedOneRepMax != 121 {
+		t.Errorf("Expected best squat e1RM 121, got %.1f", resp.Exercises[0].EstimatedOneRepMax)
+	}
+}
+
+func TestBuildExerciseHistory(t *testing.T) {
+	exercise := model.Exercise{ID: uuid.New(), Name: "Deadlift"}
+	recent, older := uuid.New(), uuid.New()
+	recentDate := time.Date(2025, 6, 8, 18, 0, 0, 0, time.UTC)
+	olderDate := time.Date(2025, 6, 1, 18, 0, 0, 0, time.UTC)
+
+	sets := []model.StrengthSet{
+		{ActivityID: recent, Timestamp: recentDate, SetNumber: 1, Reps: 5, WeightKg: 140},
+		{ActivityID: recent, Timestamp: recentDate, SetNumber: 2, Reps: 5, WeightKg: 150},
+		{ActivityID: older, Timestamp: olderDate, SetNumber: 1, Reps: 1, WeightKg: 180},
+	}
+
+	resp := buildExerciseHistory(exercise, sets, 20)
+	if len(resp.Sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(resp.Sessions))
+	}
+	if resp.Sessions[0].TopSetWeightKg != 150 || resp.Sessions[0].VolumeKg != 1450 {
+		t.Errorf("Unexpected recent session %+v", resp.Sessions[0])
+	}
+	if resp.BestEstimatedOneRepMax != 180 || resp.BestEstimatedOn == nil || !resp.BestEstimatedOn.Equal(olderDate) {
+		t.Errorf("Expected best e1RM 180 on %v, got %.1f on %v", olderDate, resp.BestEstimatedOneRepMax, resp.BestEstimatedOn)
+	}
+
+	limited := buildExerciseHistory(exercise, sets, 1)
+	if len(limited.Sessions) != 1 || limited.BestEstimatedOneRepMax != 180 {
+		t.Errorf("Expected limit to truncate sessions but keep the overall best, got %+v", limited)
+	}
+}
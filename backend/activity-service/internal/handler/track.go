@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/auth"
+	"github.com/ffabious/healthy-summer/activity-service/internal/db"
+	"github.com/ffabious/healthy-summer/activity-service/internal/geo"
+	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultTrackToleranceM is the default Douglas-Peucker tolerance for the
+	// simplified polyline.
+	DefaultTrackToleranceM = 5.0
+	// MaxTrackPoints limits the size of an uploaded track.
+	MaxTrackPoints = 50000
+)
+
+func toGeoPoints(points []model.TrackPoint) []geo.Point {
+	out := make([]geo.Point, len(points))
+	for i, p := range points {
+		out[i] = geo.Point{Lat: p.Lat, Lon: p.Lon, Elevation: p.ElevationM, Time: p.Time}
+	}
+	return out
+}
+
+// buildTrackResponse computes the geometry summary returned by the track
+// endpoint from the stored points.
+func buildTrackResponse(activity *model.Activity, points []model.TrackPoint, toleranceM float64) model.GetActivityTrackResponse {
+	geoPoints := toGeoPoints(points)
+	simplified := geo.Simplify(geoPoints, toleranceM)
+
+	coords := make([][]float64, len(points))
+	times := make([]string, len(points))
+	for i, p := range points {
+		coord := []float64{p.Lon, p.Lat}
+		if p.ElevationM != nil {
+			coord = append(coord, *p.ElevationM)
+		}
+		coords[i] = coord
+		times[i] = p.Time.UTC().Format(time.RFC3339)
+	}
+
+	splits := geo.Splits(geoPoints, 1000)
+	if splits == nil {
+		splits = []geo.Split{}
+	}
+
+	return model.GetActivityTrackResponse{
+		ActivityID:  activity.ID,
+		PointCount:  len(points),
+		DistanceM:   math.Round(geo.TrackDistance(geoPoints)*10) / 10,
+		BoundingBox: geo.Bounds(geoPoints),
+		GeoJSON: model.GeoJSONFeature{
+			Type: "Feature",
+			Geometry: model.GeoJSONGeometry{
+				Type:        "LineString",
+				Coordinates: coords,
+			},
+			Properties: map[string]any{
+				"activity_id": activity.ID,
+				"type":        activity.Type,
+				"coordTimes":  times,
+			},
+		},
+		Polyline:             geo.EncodePolyline(simplified),
+		SimplifiedPointCount: len(simplified),
+		ToleranceM:           toleranceM,
+		Splits:               splits,
+	}
+}
+
+// @Summary Get Activity Track
+// @Description Get the GPS track of an activity as GeoJSON, a simplified encoded polyline, bounding box and per-kilometre splits
+// @Tags activities
+// @Produce json
+// @Param id path string true "Activity ID"
+// @Param tolerance query number false "Simplification tolerance in metres (default: 5)"
+// @Success 200 {object} model.GetActivityTrackResponse
+// @Router /api/activities/{id}/track [get]
+// @Security BearerAuth
+func GetActivityTrackHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	activityID := c.Param("id")
+	tolerance := DefaultTrackToleranceM
+	if raw := c.Query("tolerance"); raw != "" {
+		tolerance, err = strconv.ParseFloat(raw, 64)
+		if err != nil || tolerance < 0 || math.IsNaN(tolerance) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tolerance"})
+			return
+		}
+	}
+
+	activity, err := db.GetActivityByID(activityID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found", "details": err.Error()})
+		return
+	}
+	if activity.UserID.String() != user_id {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own activity tracks"})
+		return
+	}
+
+	points, err := db.GetTrackPoints(activityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve track", "details": err.Error()})
+		return
+	}
+	if len(points) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity has no track"})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildTrackResponse(activity, points, tolerance))
+}
+
+// @Summary Put Activity Track
+// @Description Store or replace the GPS track of an activity. The activity distance is updated to the track length.
+// @Tags activities
+// @Accept json
+// @Produce json
+// @Param id path string true "Activity ID"
+// @Param track body model.PutActivityTrackRequest true "Track points in recording order"
+// @Success 200 {object} model.GetActivityTrackResponse
+// @Router /api/activities/{id}/track [put]
+// @Security BearerAuth
+func PutActivityTrackHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	activityID := c.Param("id")
+
+	var req model.PutActivityTrackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+	if len(req.Points) > MaxTrackPoints {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": "too many track points"})
+		return
+	}
+	for i, p := range req.Points {
+		if !geo.ValidCoordinate(p.Lat, p.Lon) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": "point " + strconv.Itoa(i) + " has invalid coordinates"})
+			return
+		}
+		if i > 0 && p.Time.Before(req.Points[i-1].Time) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": "points must be in chronological order"})
+			return
+		}
+	}
+
+	activity, err := db.GetActivityByID(activityID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Activity not found", "details": err.Error()})
+		return
+	}
+	if activity.UserID.String() != user_id {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own activities"})
+		return
+	}
+
+	distance := math.Round(geo.TrackDistance(toGeoPoints(req.Points))*10) / 10
+	if err := db.ReplaceTrackPoints(activityID, req.Points, distance); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store track", "details": err.Error()})
+		return
+	}
+	activity.DistanceM = distance
+	refreshPersonalRecords(user_id)
+
+	c.JSON(http.StatusOK, buildTrackResponse(activity, req.Points, DefaultTrackToleranceM))
+}
@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestGetActivityTrackHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validToken := "Bearer " + generateTestToken(t, uuid.New().String())
+
+	tests := []struct {
+		name           string
+		query          string
+		authHeader     string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Missing authorization header",
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Unauthorized",
+		},
+		{
+			name:           "Invalid tolerance",
+			query:          "?tolerance=abc",
+			authHeader:     validToken,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid tolerance",
+		},
+		{
+			name:           "Negative tolerance",
+			query:          "?tolerance=-1",
+			authHeader:     validToken,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid tolerance",
+		},
+		{
+			name:           "Activity not found without database",
+			authHeader:     validToken,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Activity not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/api/activities/:id/track", GetActivityTrackHandler)
+
+			req := httptest.NewRequest("GET", "/api/activities/"+uuid.New().String()+"/track"+tt.query, nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedError != "" && !strings.Contains(w.Body.String(), tt.expectedError) {
+				t.Errorf("Expected error message to contain '%s', got: %s", tt.expectedError, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPutActivityTrackHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validToken := "Bearer " + generateTestToken(t, uuid.New().String())
+	start := time.Date(2025, 6, 1, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		authHeader     string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Missing authorization header",
+			requestBody:    model.PutActivityTrackRequest{},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Unauthorized",
+		},
+		{
+			name: "Too few points",
+			requestBody: model.PutActivityTrackRequest{Points: []model.TrackPoint{
+				{Time: start, Lat: 1, Lon: 1},
+			}},
+			authHeader:     validToken,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request data",
+		},
+		{
+			name: "Invalid coordinates",
+			requestBody: model.PutActivityTrackRequest{Points: []model.TrackPoint{
+				{Time: start, Lat: 1, Lon: 1},
+				{Time: start.Add(time.Minute), Lat: 100, Lon: 1},
+			}},
+			authHeader:     validToken,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid coordinates",
+		},
+		{
+			name: "Points out of order",
+			requestBody: model.PutActivityTrackRequest{Points: []model.TrackPoint{
+				{Time: start.Add(time.Minute), Lat: 1, Lon: 1},
+				{Time: start, Lat: 1.001, Lon: 1},
+			}},
+			authHeader:     validToken,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "chronological order",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.PUT("/api/activities/:id/track", PutActivityTrackHandler)
+
+			requestBody, err := json.Marshal(tt.requestBody)
+			if err != nil {
+				t.Fatalf("Failed to marshal request body: %v", err)
+			}
+			req := httptest.NewRequest("PUT", "/api/activities/"+uuid.New().String()+"/track", bytes.NewBuffer(requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedError != "" && !strings.Contains(w.Body.String(), tt.expectedError) {
+				t.Errorf("Expected error message to contain '%s', got: %s", tt.expectedError, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestBuildTrackResponse(t *testing.T) {
+	start := time.Date(2025, 6, 1, 7, 0, 0, 0, time.UTC)
+	ele := 120.0
+	activity := &model.Activity{ID: uuid.New(), Type: "running"}
+	points := []model.TrackPoint{
+		{Seq: 0, Time: start, Lat: 55.75, Lon: 37.62, ElevationM: &ele},
+		{Seq: 1, Time: start.Add(3 * time.Minute), Lat: 55.76, Lon: 37.62},
+		{Seq: 2, Time: start.Add(6 * time.Minute), Lat: 55.77, Lon: 37.63},
+	}
+
+	resp := buildTrackResponse(activity, points, DefaultTrackToleranceM)
+
+	if resp.ActivityID != activity.ID || resp.PointCount != 3 {
+		t.Errorf("Unexpected header fields %+v", resp)
+	}
+	if resp.GeoJSON.Type != "Feature" || resp.GeoJSON.Geometry.Type != "LineString" {
+		t.Errorf("Unexpected GeoJSON types %q/%q", resp.GeoJSON.Type, resp.GeoJSON.Geometry.Type)
+	}
+	first := resp.GeoJSON.Geometry.Coordinates[0]
+	if len(first) != 3 || first[0] != 37.62 || first[1] != 55.75 || first[2] != 120 {
+		t.Errorf("Expected [lon, lat, ele] coordinates, got %v", first)
+	}
+	if len(resp.GeoJSON.Geometry.Coordinates[1]) != 2 {
+		t.Errorf("Expected [lon, lat] for point without elevation, got %v", resp.GeoJSON.Geometry.Coordinates[1])
+	}
+	if resp.BoundingBox.MinLat != 55.75 || resp.BoundingBox.MaxLon != 37.63 {
+		t.Errorf("Unexpected bounding box %+v", resp.BoundingBox)
+	}
+	if resp.Polyline == "" || resp.SimplifiedPointCount < 2 {
+		t.Errorf("Expected a simplified polyline, got %q (%d points)", resp.Polyline, resp.SimplifiedPointCount)
+	}
+	if len(resp.Splits) != 3 {
+		t.Errorf("Expected 3 splits for a ~2.3km track, got %d", len(resp.Splits))
+	}
+}
+
+func TestActivityImageHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validToken := "Bearer " + generateTestToken(t, uuid.New().String())
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		authHeader     string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Attach without authorization header",
+			method:         "PUT",
+			body:           `{"image_id":"` + uuid.New().String() + `"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Unauthorized",
+		},
+		{
+			name:           "Attach without image ID",
+			method:         "PUT",
+			body:           `{}`,
+			authHeader:     validToken,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request data",
+		},
+		{
+			name:           "Attach activity not found without database",
+			method:         "PUT",
+			body:           `{"image_id":"` + uuid.New().String() + `"}`,
+			authHeader:     validToken,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Activity not found",
+		},
+		{
+			name:           "Remove without authorization header",
+			method:         "DELETE",
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Unauthorized",
+		},
+		{
+			name:           "Remove activity not found without database",
+			method:         "DELETE",
+			authHeader:     validToken,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Activity not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.PUT("/api/activities/:id/image", SetActivityImageHandler)
+			router.DELETE("/api/activities/:id/image", DeleteActivityImageHandler)
+
+			req := httptest.NewRequest(tt.method, "/api/activities/"+uuid.New().String()+"/image", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedError != "" && !strings.Contains(w.Body.String(), tt.expectedError) {
+				t.Errorf("Expected error message to contain '%s', got: %s", tt.expectedError, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUpdateActivityHandlerKeepsImage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupActivityTestDB(t)
+
+	imageID := uuid.New()
+	stored := updateActivity(t, model.Activity{
+		ID: uuid.New(), UserID: uuid.New(), Type: "cycling", DurationMin: 60,
+		Intensity: model.IntensityMedium, Timestamp: time.Now().UTC(),
+		ImageID: &imageID, ImageURL: "/uploads/ride.jpg", ThumbnailURL: "/uploads/ride_thumb.jpg",
+	}, model.UpdateActivityRequest{Type: "cycling", DurationMin: 75, Intensity: model.IntensityHigh})
+
+	if stored.DurationMin != 75 {
+		t.Errorf("Expected duration 75, got %d", stored.DurationMin)
+	}
+	if stored.ImageID == nil || *stored.ImageID != imageID || stored.ImageURL != "/uploads/ride.jpg" || stored.ThumbnailURL != "/uploads/ride_thumb.jpg" {
+		t.Errorf("Attached image was not kept: %+v", stored)
+	}
+}
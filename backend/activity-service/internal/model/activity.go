@@ -3,6 +3,7 @@ package model
 import (
 	"time"
 
+	"github.com/ffabious/healthy-summer/activity-service/internal/geo"
 	"github.com/google/uuid"
 )
 
@@ -33,6 +34,48 @@ type Activity struct {
 	Calories    int       `json:"calories" gorm:"not null"`
 	Location    string    `json:"location" gorm:"type:varchar(100)"`
 	Timestamp   time.Time `json:"timestamp" gorm:"not null"`
+
+	DistanceM      float64 `json:"distance_m" gorm:"not null;default:0"`
+	ElevationGainM float64 `json:"elevation_gain_m" gorm:"not null;default:0"`
+	AvgHeartRate   int     `json:"avg_heart_rate" gorm:"not null;default:0"`
+	MaxHeartRate   int     `json:"max_heart_rate" gorm:"not null;default:0"`
+
+	// ImageID refers to an upload in user-service; its URLs are copied
+	// when the image is attached.
+	ImageID      *uuid.UUID `json:"image_id,omitempty" gorm:"type:uuid"`
+	ImageURL     string     `json:"image_url,omitempty" gorm:"type:varchar(512)"`
+	ThumbnailURL string     `json:"thumbnail_url,omitempty" gorm:"type:varchar(512)"`
+}
+
+// AttachImageRequest attaches an image uploaded to user-service.
+// @name AttachImageRequest
+type AttachImageRequest struct {
+	ImageID uuid.UUID `json:"image_id" binding:"required"`
+}
+
+// @name TrackPoint
+type TrackPoint struct {
+	ID         uuid.UUID `json:"-" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	ActivityID uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+	Seq        int       `json:"seq" gorm:"not null"`
+	Time       time.Time `json:"time" gorm:"not null"`
+	Lat        float64   `json:"lat" gorm:"not null"`
+	Lon        float64   `json:"lon" gorm:"not null"`
+	ElevationM *float64  `json:"elevation_m,omitempty"`
+	HeartRate  *int      `json:"heart_rate,omitempty"`
+}
+
+// WorkoutImport records an uploaded workout file so that re-uploads of the
+// same file by the same user resolve to the activity created the first time.
+// @name WorkoutImport
+type WorkoutImport struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID     uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_workout_imports_user_hash"`
+	FileHash   string    `json:"file_hash" gorm:"type:varchar(64);not null;uniqueIndex:idx_workout_imports_user_hash"`
+	ActivityID uuid.UUID `json:"activity_id" gorm:"type:uuid;not null;index"`
+	FileName   string    `json:"file_name" gorm:"type:varchar(255)"`
+	Format     string    `json:"format" gorm:"type:varchar(10);not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"not null"`
 }
 
 // @name StepEntry
@@ -58,6 +101,16 @@ type ActivityPeriod struct {
 	Steps         int `json:"steps"`
 }
 
+// ActivitySummary is the recent activity of a user shown on their profile
+// by user-service.
+// @name ActivitySummary
+type ActivitySummary struct {
+	Week             ActivityPeriod `json:"week"`
+	Month            ActivityPeriod `json:"month"`
+	LastActivityType string         `json:"last_activity_type,omitempty" example:"running"`
+	LastActivityAt   *time.Time     `json:"last_activity_at,omitempty"`
+}
+
 type ActivityAnalyticsByType struct {
 	Type             string `json:"type"`
 	ActivityCount    int    `json:"activity_count"`
@@ -96,6 +149,14 @@ type PostActivityResponse struct {
 	Timestamp   time.Time `json:"timestamp"`
 }
 
+// ActivityPage is one page of a user's activities.
+// @name ActivityPage
+type ActivityPage struct {
+	Activities []Activity `json:"activities"`
+	Total      int64      `json:"total"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
 // @name GetActivitiesByUserIDRequest
 type GetActivitiesByUserIDRequest struct {
 	UserID uuid.UUID `json:"user_id" binding:"required"`
@@ -151,3 +212,62 @@ type GetStepsByUserIdRequest struct {
 type GetStepsByUserIdResponse struct {
 	Steps []StepEntry `json:"steps"`
 }
+
+// ImportWorkoutForm holds the optional fields sent with a workout file.
+// Their limits match the columns of Activity.
+type ImportWorkoutForm struct {
+	Type      string    `form:"type" binding:"max=50"`
+	Intensity Intensity `form:"intensity"`
+	Calories  *int      `form:"calories" binding:"omitempty,min=0"`
+	Location  string    `form:"location" binding:"max=100"`
+}
+
+// @name ImportWorkoutResponse
+type ImportWorkoutResponse struct {
+	Activity   Activity `json:"activity"`
+	Format     string   `json:"format"`
+	PointCount int      `json:"point_count"`
+	Duplicate  bool     `json:"duplicate"`
+
+	NewPersonalRecords []PersonalRecord `json:"new_personal_records,omitempty"`
+}
+
+// @name PutActivityTrackRequest
+type PutActivityTrackRequest struct {
+	Points []TrackPoint `json:"points" binding:"required,min=2"`
+}
+
+// @name GeoJSONGeometry
+type GeoJSONGeometry struct {
+	Type        string      `json:"type" example:"LineString"`
+	Coordinates [][]float64 `json:"coordinates"`
+}
+
+// @name GeoJSONFeature
+type GeoJSONFeature struct {
+	Type       string          `json:"type" example:"Feature"`
+	Geometry   GeoJSONGeometry `json:"geometry"`
+	Properties map[string]any  `json:"properties"`
+}
+
+// @name GetActivityTrackResponse
+type GetActivityTrackResponse struct {
+	ActivityID           uuid.UUID       `json:"activity_id"`
+	PointCount           int             `json:"point_count"`
+	DistanceM            float64         `json:"distance_m"`
+	BoundingBox          geo.BoundingBox `json:"bounding_box"`
+	GeoJSON              GeoJSONFeature  `json:"geojson"`
+	Polyline             string          `json:"polyline"`
+	SimplifiedPointCount int             `json:"simplified_point_count"`
+	ToleranceM           float64         `json:"tolerance_m"`
+	Splits               []geo.Split     `json:"splits"`
+}
+
+// PurgeUserDataResponse reports how many rows were removed from each table
+// when the data of a deleted account is purged.
+// @name PurgeUserDataResponse
+type PurgeUserDataResponse struct {
+	Service string           `json:"service" example:"activity-service"`
+	UserID  uuid.UUID        `json:"user_id"`
+	Deleted map[string]int64 `json:"deleted"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// @name RecordCategory
+type RecordCategory string
+
+const (
+	RecordLongestDuration RecordCategory = "longest_duration"
+	RecordMostCalories    RecordCategory = "most_calories"
+	RecordMostStepsDay    RecordCategory = "most_steps_day"
+	RecordFastest5K       RecordCategory = "fastest_5k"
+)
+
+// LowerIsBetter reports whether a smaller value beats a larger one.
+func (c RecordCategory) LowerIsBetter() bool {
+	return c == RecordFastest5K
+}
+
+// PersonalRecord is a user's best result in a category. Per-type categories
+// (longest_duration and fastest_5k) have one record per activity type; the
+// others use an empty ActivityType.
+// @name PersonalRecord
+type PersonalRecord struct {
+	ID           uuid.UUID      `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID       uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_personal_records_user_category_type"`
+	Category     RecordCategory `json:"category" gorm:"type:varchar(30);not null;uniqueIndex:idx_personal_records_user_category_type" example:"fastest_5k"`
+	ActivityType string         `json:"activity_type,omitempty" gorm:"type:varchar(50);not null;default:'';uniqueIndex:idx_personal_records_user_category_type"`
+	Value        float64        `json:"value" gorm:"not null"`
+	Unit         string         `json:"unit" gorm:"type:varchar(20);not null" example:"sec"`
+	ActivityID   *uuid.UUID     `json:"activity_id,omitempty" gorm:"type:uuid;index"`
+	AchievedAt   time.Time      `json:"achieved_at" gorm:"not null"`
+}
+
+// DailySteps is the total number of steps logged on one day.
+type DailySteps struct {
+	Date  time.Time `json:"date"`
+	Steps int       `json:"steps"`
+}
+
+// @name CreateActivityResponse
+type CreateActivityResponse struct {
+	Activity
+	NewPersonalRecords []PersonalRecord `json:"new_personal_records"`
+}
+
+// @name CreateStepEntryResponse
+type CreateStepEntryResponse struct {
+	StepEntry
+	NewPersonalRecords []PersonalRecord `json:"new_personal_records"`
+}
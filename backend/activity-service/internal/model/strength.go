@@ -0,0 +1,100 @@
+package model
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// @name Exercise
+type Exercise struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Name      string    `json:"name" gorm:"type:varchar(100);uniqueIndex;not null"`
+	Category  string    `json:"category" gorm:"type:varchar(50);not null" example:"legs"`
+	Equipment string    `json:"equipment" gorm:"type:varchar(50);not null" example:"barbell"`
+}
+
+// StrengthSet is a single set of an exercise performed during an Activity.
+// @name StrengthSet
+type StrengthSet struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	ActivityID uuid.UUID `json:"activity_id" gorm:"type:uuid;not null;index"`
+	UserID     uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index:idx_strength_sets_user_exercise"`
+	ExerciseID uuid.UUID `json:"exercise_id" gorm:"type:uuid;not null;index:idx_strength_sets_user_exercise"`
+	Position   int       `json:"position" gorm:"not null"`
+	SetNumber  int       `json:"set_number" gorm:"not null"`
+	Reps       int       `json:"reps" gorm:"not null"`
+	WeightKg   float64   `json:"weight_kg" gorm:"not null"`
+	RPE        *float64  `json:"rpe,omitempty"`
+	Timestamp  time.Time `json:"timestamp" gorm:"not null"`
+}
+
+// EstimatedOneRepMax returns the Epley estimate of the one-rep max for the set.
+// When RPE is recorded, reps in reserve (10 - RPE) are added to the reps so
+// that sub-maximal sets are not underestimated.
+func (s StrengthSet) EstimatedOneRepMax() float64 {
+	if s.Reps <= 0 || s.WeightKg <= 0 {
+		return 0
+	}
+	reps := float64(s.Reps)
+	if s.RPE != nil && *s.RPE >= 1 && *s.RPE <= 10 {
+		reps += 10 - *s.RPE
+	}
+	if reps <= 1 {
+		return s.WeightKg
+	}
+	return math.Round(s.WeightKg*(1+reps/30)*10) / 10
+}
+
+// Volume returns reps × weight for the set.
+func (s StrengthSet) Volume() float64 {
+	return float64(s.Reps) * s.WeightKg
+}
+
+// @name StrengthSetInput
+type StrengthSetInput struct {
+	ExerciseID uuid.UUID `json:"exercise_id" binding:"required"`
+	Reps       int       `json:"reps" binding:"required,gt=0,lte=1000"`
+	WeightKg   float64   `json:"weight_kg" binding:"min=0,lte=1000"`
+	RPE        *float64  `json:"rpe" binding:"omitempty,min=1,max=10"`
+}
+
+// @name PutStrengthSetsRequest
+type PutStrengthSetsRequest struct {
+	Sets []StrengthSetInput `json:"sets" binding:"required,dive"`
+}
+
+// @name ExerciseSets
+type ExerciseSets struct {
+	Exercise           Exercise      `json:"exercise"`
+	Sets               []StrengthSet `json:"sets"`
+	VolumeKg           float64       `json:"volume_kg"`
+	EstimatedOneRepMax float64       `json:"estimated_one_rep_max"`
+}
+
+// @name StrengthWorkoutResponse
+type StrengthWorkoutResponse struct {
+	ActivityID    uuid.UUID      `json:"activity_id"`
+	Exercises     []ExerciseSets `json:"exercises"`
+	TotalSets     int            `json:"total_sets"`
+	TotalVolumeKg float64        `json:"total_volume_kg"`
+}
+
+// @name ExerciseSession
+type ExerciseSession struct {
+	ActivityID         uuid.UUID     `json:"activity_id"`
+	Date               time.Time     `json:"date"`
+	Sets               []StrengthSet `json:"sets"`
+	VolumeKg           float64       `json:"volume_kg"`
+	TopSetWeightKg     float64       `json:"top_set_weight_kg"`
+	EstimatedOneRepMax float64       `json:"estimated_one_rep_max"`
+}
+
+// @name ExerciseHistoryResponse
+type ExerciseHistoryResponse struct {
+	Exercise               Exercise          `json:"exercise"`
+	Sessions               []ExerciseSession `json:"sessions"`
+	BestEstimatedOneRepMax float64           `json:"best_estimated_one_rep_max"`
+	BestEstimatedOn        *time.Time        `json:"best_estimated_on,omitempty"`
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestStrengthSetEstimatedOneRepMax(t *testing.T) {
+	rpe := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name     string
+		set      StrengthSet
+		expected float64
+	}{
+		{
+			name:     "Single rep",
+			set:      StrengthSet{Reps: 1, WeightKg: 140},
+			expected: 140,
+		},
+		{
+			name:     "Epley estimate",
+			set:      StrengthSet{Reps: 5, WeightKg: 100},
+			expected: 116.7,
+		},
+		{
+			name:     "RPE adds reps in reserve",
+			set:      StrengthSet{Reps: 5, WeightKg: 100, RPE: rpe(8)},
+			expected: 123.3,
+		},
+		{
+			name:     "Out of range RPE is ignored",
+			set:      StrengthSet{Reps: 5, WeightKg: 100, RPE: rpe(12)},
+			expected: 116.7,
+		},
+		{
+			name:     "Bodyweight set",
+			set:      StrengthSet{Reps: 12, WeightKg: 0},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.set.EstimatedOneRepMax(); got != tt.expected {
+				t.Errorf("Expected %.1f, got %.1f", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestStrengthSetVolume(t *testing.T) {
+	set := StrengthSet{Reps: 8, WeightKg: 62.5}
+	if got := set.Volume(); got != 500 {
+		t.Errorf("Expected volume 500, got %.1f", got)
+	}
+}
@@ -0,0 +1,144 @@
+// Package records derives personal records from a user's activities and step
+// entries.
+package records
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/geo"
+	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// FiveKM is the distance in metres of the fastest_5k record.
+const FiveKM = 5000.0
+
+type key struct {
+	category     model.RecordCategory
+	activityType string
+}
+
+// Compute returns the current personal records for a user. tracks holds the
+// recorded track of activities that have one, keyed by activity ID. Ties go to
+// the earliest result, so re-logging an equal effort does not take the record.
+func Compute(userID uuid.UUID, activities []model.Activity, tracks map[uuid.UUID][]model.TrackPoint, days []model.DailySteps) []model.PersonalRecord {
+	sorted := make([]model.Activity, len(activities))
+	copy(sorted, activities)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	best := make(map[key]model.PersonalRecord)
+	var order []key
+	offer := func(r model.PersonalRecord) {
+		k := key{r.Category, r.ActivityType}
+		current, ok := best[k]
+		if !ok {
+			order = append(order, k)
+		} else if !beats(r.Category, r.Value, current.Value) {
+			return
+		}
+		r.UserID = userID
+		best[k] = r
+	}
+
+	for _, a := range sorted {
+		activityID := a.ID
+		if a.DurationMin > 0 {
+			offer(model.PersonalRecord{
+				Category:     model.RecordLongestDuration,
+				ActivityType: a.Type,
+				Value:        float64(a.DurationMin),
+				Unit:         "min",
+				ActivityID:   &activityID,
+				AchievedAt:   a.Timestamp,
+			})
+		}
+		if a.Calories > 0 {
+			offer(model.PersonalRecord{
+				Category:   model.RecordMostCalories,
+				Value:      float64(a.Calories),
+				Unit:       "kcal",
+				ActivityID: &activityID,
+				AchievedAt: a.Timestamp,
+			})
+		}
+		if sec := FiveKMTime(a, tracks[a.ID]); sec > 0 {
+			offer(model.PersonalRecord{
+				Category:     model.RecordFastest5K,
+				ActivityType: a.Type,
+				Value:        sec,
+				Unit:         "sec",
+				ActivityID:   &activityID,
+				AchievedAt:   a.Timestamp,
+			})
+		}
+	}
+
+	sortedDays := make([]model.DailySteps, len(days))
+	copy(sortedDays, days)
+	sort.SliceStable(sortedDays, func(i, j int) bool {
+		return sortedDays[i].Date.Before(sortedDays[j].Date)
+	})
+	for _, d := range sortedDays {
+		if d.Steps > 0 {
+			offer(model.PersonalRecord{
+				Category:   model.RecordMostStepsDay,
+				Value:      float64(d.Steps),
+				Unit:       "steps",
+				AchievedAt: d.Date,
+			})
+		}
+	}
+
+	result := make([]model.PersonalRecord, 0, len(order))
+	for _, k := range order {
+		result = append(result, best[k])
+	}
+	return result
+}
+
+// FiveKMTime returns the 5 km time in seconds of an activity. With a timed
+// track it is the fastest 5 km anywhere on the track; otherwise it is
+// estimated from the average pace. It returns 0 for activities shorter than
+// 5 km.
+func FiveKMTime(a model.Activity, track []model.TrackPoint) float64 {
+	if len(track) > 1 {
+		points := make([]geo.Point, len(track))
+		for i, p := range track {
+			points[i] = geo.Point{Lat: p.Lat, Lon: p.Lon, Time: p.Time}
+		}
+		if best := geo.FastestSegment(points, FiveKM); best > 0 {
+			return math.Round(best.Seconds()*10) / 10
+		}
+	}
+	if a.DistanceM < FiveKM || a.DurationMin <= 0 {
+		return 0
+	}
+	return math.Round(float64(a.DurationMin)*60*FiveKM/a.DistanceM*10) / 10
+}
+
+// Improved returns the records in after that did not exist in before or that
+// beat the previous value.
+func Improved(before, after []model.PersonalRecord) []model.PersonalRecord {
+	previous := make(map[key]model.PersonalRecord, len(before))
+	for _, r := range before {
+		previous[key{r.Category, r.ActivityType}] = r
+	}
+	improved := []model.PersonalRecord{}
+	for _, r := range after {
+		old, ok := previous[key{r.Category, r.ActivityType}]
+		if !ok || beats(r.Category, r.Value, old.Value) {
+			improved = append(improved, r)
+		}
+	}
+	return improved
+}
+
+func beats(category model.RecordCategory, value, current float64) bool {
+	if category.LowerIsBetter() {
+		return value < current
+	}
+	return value > current
+}
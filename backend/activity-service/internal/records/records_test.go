@@ -0,0 +1,160 @@
+package records
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+	"github.com/google/uuid"
+)
+
+func find(records []model.PersonalRecord, category model.RecordCategory, activityType string) *model.PersonalRecord {
+	for i := range records {
+		if records[i].Category == category && records[i].ActivityType == activityType {
+			return &records[i]
+		}
+	}
+	return nil
+}
+
+func TestCompute(t *testing.T) {
+	userID := uuid.New()
+	day := func(d int) time.Time { return time.Date(2025, 6, d, 8, 0, 0, 0, time.UTC) }
+
+	longRun := model.Activity{ID: uuid.New(), Type: "running", DurationMin: 60, Calories: 700, DistanceM: 10000, Timestamp: day(1)}
+	fastRun := model.Activity{ID: uuid.New(), Type: "running", DurationMin: 25, Calories: 300, DistanceM: 5500, Timestamp: day(2)}
+	swim := model.Activity{ID: uuid.New(), Type: "swimming", DurationMin: 45, Calories: 700, Timestamp: day(3)}
+
+	got := Compute(userID, []model.Activity{swim, fastRun, longRun}, nil, []model.DailySteps{
+		{Date: day(1), Steps: 8000},
+		{Date: day(2), Steps: 12000},
+	})
+
+	if r := find(got, model.RecordLongestDuration, "running"); r == nil || r.Value != 60 || *r.ActivityID != longRun.ID {
+		t.Errorf("Unexpected running duration record %+v", r)
+	}
+	if r := find(got, model.RecordLongestDuration, "swimming"); r == nil || r.Value != 45 {
+		t.Errorf("Unexpected swimming duration record %+v", r)
+	}
+	// The swim ties the long run on calories; the earlier result keeps the record.
+	if r := find(got, model.RecordMostCalories, ""); r == nil || r.Value != 700 || *r.ActivityID != longRun.ID {
+		t.Errorf("Unexpected calories record %+v", r)
+	}
+	if r := find(got, model.RecordFastest5K, "running"); r == nil || r.Value != 1363.6 || *r.ActivityID != fastRun.ID {
+		t.Errorf("Unexpected 5k record %+v", r)
+	}
+	if r := find(got, model.RecordMostStepsDay, ""); r == nil || r.Value != 12000 || !r.AchievedAt.Equal(day(2)) || r.ActivityID != nil {
+		t.Errorf("Unexpected steps record %+v", r)
+	}
+	for _, r := range got {
+		if r.UserID != userID {
+			t.Errorf("Expected user ID %s, got %s", userID, r.UserID)
+		}
+	}
+
+	if got := Compute(userID, nil, nil, nil); len(got) != 0 {
+		t.Errorf("Expected no records for an empty history, got %d", len(got))
+	}
+}
+
+func TestComputeFastest5KPerType(t *testing.T) {
+	userID := uuid.New()
+	day := func(d int) time.Time { return time.Date(2025, 6, d, 8, 0, 0, 0, time.UTC) }
+
+	run := model.Activity{ID: uuid.New(), Type: "running", DurationMin: 25, DistanceM: 5000, Timestamp: day(1)}
+	// 20 km at 30 km/h averages a 10 minute 5k
+	ride := model.Activity{ID: uuid.New(), Type: "cycling", DurationMin: 40, DistanceM: 20000, Timestamp: day(2)}
+
+	got := Compute(userID, []model.Activity{run, ride}, nil, nil)
+	if r := find(got, model.RecordFastest5K, "running"); r == nil || r.Value != 1500 || *r.ActivityID != run.ID {
+		t.Errorf("Expected the ride not to take the running 5k record, got %+v", r)
+	}
+	if r := find(got, model.RecordFastest5K, "cycling"); r == nil || r.Value != 600 || *r.ActivityID != ride.ID {
+		t.Errorf("Unexpected cycling 5k record %+v", r)
+	}
+	if r := find(got, model.RecordFastest5K, ""); r != nil {
+		t.Errorf("Expected no 5k record without an activity type, got %+v", r)
+	}
+
+	improved := Improved(Compute(userID, []model.Activity{run}, nil, nil), got)
+	if len(improved) != 2 || find(improved, model.RecordFastest5K, "running") != nil {
+		t.Errorf("Expected the ride to improve only cycling records, got %+v", improved)
+	}
+}
+
+// straightTrack returns points 1 km apart due north, recorded after the
+// given times since start.
+func straightTrack(start time.Time, offsets ...time.Duration) []model.TrackPoint {
+	kmDeg := 1000 / (6371008.8 * math.Pi / 180)
+	track := make([]model.TrackPoint, len(offsets))
+	for i, d := range offsets {
+		track[i] = model.TrackPoint{Seq: i, Time: start.Add(d), Lat: float64(i) * kmDeg}
+	}
+	return track
+}
+
+func TestFiveKMTime(t *testing.T) {
+	start := time.Date(2025, 6, 1, 7, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		activity model.Activity
+		track    []model.TrackPoint
+		expected float64
+	}{
+		{name: "No distance", activity: model.Activity{DurationMin: 30}, expected: 0},
+		{name: "Shorter than 5k", activity: model.Activity{DurationMin: 20, DistanceM: 4000}, expected: 0},
+		{name: "Exactly 5k", activity: model.Activity{DurationMin: 25, DistanceM: 5000}, expected: 1500},
+		{name: "10k at 5:00/km", activity: model.Activity{DurationMin: 50, DistanceM: 10000}, expected: 1500},
+		{
+			// Slow first and last kilometres around a 5 km at 4:00/km
+			name:     "Fastest 5k of a track",
+			activity: model.Activity{DurationMin: 34, DistanceM: 7000},
+			track: straightTrack(start, 0, 7*time.Minute, 11*time.Minute, 15*time.Minute,
+				19*time.Minute, 23*time.Minute, 27*time.Minute, 34*time.Minute),
+			expected: 1200,
+		},
+		{
+			name:     "Track shorter than 5k",
+			activity: model.Activity{DurationMin: 25, DistanceM: 5000},
+			track:    straightTrack(start, 0, 5*time.Minute),
+			expected: 1500,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FiveKMTime(tt.activity, tt.track); got != tt.expected {
+				t.Errorf("Expected %.1f, got %.1f", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestImproved(t *testing.T) {
+	before := []model.PersonalRecord{
+		{Category: model.RecordMostCalories, Value: 500},
+		{Category: model.RecordFastest5K, ActivityType: "running", Value: 1500},
+		{Category: model.RecordLongestDuration, ActivityType: "running", Value: 60},
+	}
+	after := []model.PersonalRecord{
+		{Category: model.RecordMostCalories, Value: 500},
+		{Category: model.RecordFastest5K, ActivityType: "running", Value: 1450},
+		{Category: model.RecordLongestDuration, ActivityType: "running", Value: 60},
+		{Category: model.RecordLongestDuration, ActivityType: "cycling", Value: 90},
+	}
+
+	improved := Improved(before, after)
+	if len(improved) != 2 {
+		t.Fatalf("Expected 2 improved records, got %d: %+v", len(improved), improved)
+	}
+	if find(improved, model.RecordFastest5K, "running") == nil {
+		t.Error("Expected a faster 5k to count as an improvement")
+	}
+	if find(improved, model.RecordLongestDuration, "cycling") == nil {
+		t.Error("Expected a first cycling activity to set a record")
+	}
+
+	if got := Improved(after, before); len(got) != 0 {
+		t.Errorf("Expected a slower 5k not to count as an improvement, got %+v", got)
+	}
+}
@@ -0,0 +1,257 @@
+package trackfile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// FIT global message numbers and field numbers used by the decoder. See the
+// Garmin FIT SDK profile for the full list.
+const (
+	fitMesgSession = 18
+	fitMesgRecord  = 20
+
+	fitFieldTimestamp = 253
+
+	fitRecordLat         = 0
+	fitRecordLon         = 1
+	fitRecordAltitude    = 2
+	fitRecordHeartRate   = 3
+	fitRecordDistance    = 5
+	fitRecordEnhancedAlt = 78
+
+	fitSessionStartTime   = 2
+	fitSessionSport       = 5
+	fitSessionTimerTime   = 8
+	fitSessionDistance    = 9
+	fitSessionCalories    = 11
+	fitSessionAvgHR       = 16
+	fitSessionMaxHR       = 17
+	fitSessionTotalAscent = 22
+)
+
+// fitEpoch is the FIT timestamp origin (1989-12-31T00:00:00Z).
+var fitEpoch = time.Date(1989, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+const fitSemicircleToDeg = 180.0 / (1 << 31)
+
+var fitSports = map[uint64]string{
+	0:  "other",
+	1:  "running",
+	2:  "cycling",
+	5:  "swimming",
+	11: "walking",
+	17: "hiking",
+}
+
+type fitFieldDef struct {
+	Num  byte
+	Size int
+}
+
+type fitDefinition struct {
+	Global    uint16
+	Order     binary.ByteOrder
+	Fields    []fitFieldDef
+	DevSize   int
+	TotalSize int
+}
+
+// ParseFIT decodes record and session messages from a FIT activity file.
+// Other message types, developer fields and CRCs are skipped.
+func ParseFIT(data []byte) (*Workout, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("invalid FIT file: header too short")
+	}
+	headerSize := int(data[0])
+	if headerSize < 12 || len(data) < headerSize || string(data[8:12]) != ".FIT" {
+		return nil, fmt.Errorf("invalid FIT file: bad header")
+	}
+	dataSize := int(binary.LittleEndian.Uint32(data[4:8]))
+	end := headerSize + dataSize
+	if end > len(data) {
+		return nil, fmt.Errorf("invalid FIT file: truncated data")
+	}
+
+	defs := make(map[byte]*fitDefinition)
+	var (
+		samples       []sample
+		t             totals
+		lastTimestamp uint32
+	)
+
+	pos := headerSize
+	for pos < end {
+		header := data[pos]
+		pos++
+
+		var local byte
+		compressedOffset := -1
+		if header&0x80 != 0 {
+			// Compressed timestamp header: always a data message.
+			local = (header >> 5) & 0x03
+			compressedOffset = int(header & 0x1F)
+		} else {
+			local = header & 0x0F
+			if header&0x40 != 0 {
+				def, n, err := parseFITDefinition(data[pos:end], header&0x20 != 0)
+				if err != nil {
+					return nil, err
+				}
+				defs[local] = def
+				pos += n
+				continue
+			}
+		}
+
+		def, ok := defs[local]
+		if !ok {
+			return nil, fmt.Errorf("invalid FIT file: data message without definition")
+		}
+		if pos+def.TotalSize > end {
+			return nil, fmt.Errorf("invalid FIT file: truncated message")
+		}
+		fields := make(map[byte][]byte, len(def.Fields))
+		off := pos
+		for _, f := range def.Fields {
+			fields[f.Num] = data[off : off+f.Size]
+			off += f.Size
+		}
+		pos += def.TotalSize
+
+		if ts, ok := fitUint(fields[fitFieldTimestamp], def.Order); ok {
+			lastTimestamp = uint32(ts)
+		} else if compressedOffset >= 0 {
+			ts := (lastTimestamp &^ 0x1F) | uint32(compressedOffset)
+			if uint32(compressedOffset) < lastTimestamp&0x1F {
+				ts += 0x20
+			}
+			lastTimestamp = ts
+		}
+
+		switch def.Global {
+		case fitMesgRecord:
+			samples = append(samples, fitRecord(fields, def.Order, lastTimestamp))
+		case fitMesgSession:
+			fitSession(fields, def.Order, &t)
+		}
+	}
+
+	return summarize(FormatFIT, samples, t)
+}
+
+func parseFITDefinition(buf []byte, hasDevFields bool) (*fitDefinition, int, error) {
+	if len(buf) < 5 {
+		return nil, 0, fmt.Errorf("invalid FIT file: truncated definition")
+	}
+	def := &fitDefinition{Order: binary.LittleEndian}
+	if buf[1] == 1 {
+		def.Order = binary.BigEndian
+	}
+	def.Global = def.Order.Uint16(buf[2:4])
+	numFields := int(buf[4])
+	n := 5
+	if len(buf) < n+numFields*3 {
+		return nil, 0, fmt.Errorf("invalid FIT file: truncated definition")
+	}
+	for i := 0; i < numFields; i++ {
+		f := fitFieldDef{Num: buf[n], Size: int(buf[n+1])}
+		def.Fields = append(def.Fields, f)
+		def.TotalSize += f.Size
+		n += 3
+	}
+	if hasDevFields {
+		if len(buf) < n+1 {
+			return nil, 0, fmt.Errorf("invalid FIT file: truncated definition")
+		}
+		numDev := int(buf[n])
+		n++
+		if len(buf) < n+numDev*3 {
+			return nil, 0, fmt.Errorf("invalid FIT file: truncated definition")
+		}
+		for i := 0; i < numDev; i++ {
+			def.DevSize += int(buf[n+1])
+			n += 3
+		}
+		def.TotalSize += def.DevSize
+	}
+	return def, n, nil
+}
+
+func fitRecord(fields map[byte][]byte, order binary.ByteOrder, timestamp uint32) sample {
+	s := sample{Time: fitEpoch.Add(time.Duration(timestamp) * time.Second)}
+	lat, latOK := fitInt32(fields[fitRecordLat], order)
+	lon, lonOK := fitInt32(fields[fitRecordLon], order)
+	if latOK && lonOK {
+		s.Lat = float64(lat) * fitSemicircleToDeg
+		s.Lon = float64(lon) * fitSemicircleToDeg
+		s.HasPos = true
+	}
+	if alt, ok := fitUint(fields[fitRecordEnhancedAlt], order); ok {
+		ele := float64(alt)/5 - 500
+		s.Elevation = &ele
+	} else if alt, ok := fitUint(fields[fitRecordAltitude], order); ok {
+		ele := float64(alt)/5 - 500
+		s.Elevation = &ele
+	}
+	if hr, ok := fitUint(fields[fitRecordHeartRate], order); ok {
+		s.HeartRate = int(hr)
+	}
+	if dist, ok := fitUint(fields[fitRecordDistance], order); ok {
+		d := float64(dist) / 100
+		s.DistanceM = &d
+	}
+	return s
+}
+
+func fitSession(fields map[byte][]byte, order binary.ByteOrder, t *totals) {
+	if v, ok := fitUint(fields[fitSessionStartTime], order); ok {
+		t.StartTime = fitEpoch.Add(time.Duration(v) * time.Second)
+	}
+	if v, ok := fitUint(fields[fitSessionSport], order); ok {
+		t.Sport = fitSports[v]
+	}
+	if v, ok := fitUint(fields[fitSessionTimerTime], order); ok {
+		t.Duration += time.Duration(v) * time.Millisecond
+	}
+	if v, ok := fitUint(fields[fitSessionDistance], order); ok {
+		t.DistanceM += float64(v) / 100
+	}
+	if v, ok := fitUint(fields[fitSessionCalories], order); ok {
+		t.Calories += int(v)
+	}
+	if v, ok := fitUint(fields[fitSessionAvgHR], order); ok {
+		t.AvgHeartRate = int(v)
+	}
+	if v, ok := fitUint(fields[fitSessionMaxHR], order); ok && int(v) > t.MaxHeartRate {
+		t.MaxHeartRate = int(v)
+	}
+	if v, ok := fitUint(fields[fitSessionTotalAscent], order); ok {
+		t.ElevationGainM += float64(v)
+	}
+}
+
+// fitUint decodes an unsigned field of size 1, 2 or 4 bytes, treating the
+// all-ones bit pattern as the FIT "invalid" marker.
+func fitUint(b []byte, order binary.ByteOrder) (uint64, bool) {
+	switch len(b) {
+	case 1:
+		return uint64(b[0]), b[0] != 0xFF
+	case 2:
+		v := order.Uint16(b)
+		return uint64(v), v != 0xFFFF
+	case 4:
+		v := order.Uint32(b)
+		return uint64(v), v != 0xFFFFFFFF
+	}
+	return 0, false
+}
+
+func fitInt32(b []byte, order binary.ByteOrder) (int32, bool) {
+	if len(b) != 4 {
+		return 0, false
+	}
+	v := int32(order.Uint32(b))
+	return v, v != 0x7FFFFFFF
+}
@@ -0,0 +1,231 @@
+package trackfile
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/geo"
+	"github.com/ffabious/healthy-summer/activity-service/internal/model"
+)
+
+type Format string
+
+const (
+	FormatGPX Format = "gpx"
+	FormatTCX Format = "tcx"
+	FormatFIT Format = "fit"
+)
+
+// Workout is the summary and track extracted from a workout file.
+type Workout struct {
+	Format         Format
+	Sport          string
+	StartTime      time.Time
+	Duration       time.Duration
+	DistanceM      float64
+	ElevationGainM float64
+	AvgHeartRate   int
+	MaxHeartRate   int
+	Calories       int
+	Points         []model.TrackPoint
+}
+
+// sample is a single recorded point before it is split into the stored
+// track (positioned samples only) and the summary (all samples).
+type sample struct {
+	Time      time.Time
+	Lat       float64
+	Lon       float64
+	HasPos    bool
+	Elevation *float64
+	HeartRate int
+	DistanceM *float64
+}
+
+// totals holds summary values reported by the file itself. Zero values mean
+// "not reported" and are derived from the samples instead.
+type totals struct {
+	Sport          string
+	StartTime      time.Time
+	Duration       time.Duration
+	DistanceM      float64
+	ElevationGainM float64
+	AvgHeartRate   int
+	MaxHeartRate   int
+	Calories       int
+}
+
+// DetectFormat determines the file format from its content, falling back to
+// the file extension.
+func DetectFormat(filename string, data []byte) (Format, error) {
+	if len(data) >= 12 && string(data[8:12]) == ".FIT" {
+		return FormatFIT, nil
+	}
+	head := data
+	if len(head) > 2048 {
+		head = head[:2048]
+	}
+	if bytes.Contains(head, []byte("<gpx")) {
+		return FormatGPX, nil
+	}
+	if bytes.Contains(head, []byte("<TrainingCenterDatabase")) {
+		return FormatTCX, nil
+	}
+	switch strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), ".")) {
+	case "gpx":
+		return FormatGPX, nil
+	case "tcx":
+		return FormatTCX, nil
+	case "fit":
+		return FormatFIT, nil
+	}
+	return "", fmt.Errorf("unsupported workout file format")
+}
+
+// Parse detects the format of data and parses it into a Workout.
+func Parse(filename string, data []byte) (*Workout, error) {
+	format, err := DetectFormat(filename, data)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatGPX:
+		return ParseGPX(data)
+	case FormatTCX:
+		return ParseTCX(data)
+	default:
+		return ParseFIT(data)
+	}
+}
+
+func summarize(format Format, samples []sample, t totals) (*Workout, error) {
+	if len(samples) == 0 && t.StartTime.IsZero() {
+		return nil, fmt.Errorf("workout file contains no track data")
+	}
+
+	w := &Workout{
+		Format:         format,
+		Sport:          t.Sport,
+		StartTime:      t.StartTime,
+		Duration:       t.Duration,
+		DistanceM:      t.DistanceM,
+		ElevationGainM: t.ElevationGainM,
+		AvgHeartRate:   t.AvgHeartRate,
+		MaxHeartRate:   t.MaxHeartRate,
+		Calories:       t.Calories,
+	}
+
+	var (
+		first, last      time.Time
+		distance         float64
+		reportedDist     float64
+		gain             float64
+		prevEle          *float64
+		prevLat, prevLon float64
+		hasPrevPos       bool
+		hrSum, hrCount   int
+		maxHR            int
+	)
+	for _, s := range samples {
+		if !s.Time.IsZero() {
+			if first.IsZero() || s.Time.Before(first) {
+				first = s.Time
+			}
+			if s.Time.After(last) {
+				last = s.Time
+			}
+		}
+		if s.HasPos {
+			if hasPrevPos {
+				distance += geo.Distance(prevLat, prevLon, s.Lat, s.Lon)
+			}
+			prevLat, prevLon, hasPrevPos = s.Lat, s.Lon, true
+			w.Points = append(w.Points, model.TrackPoint{
+				Seq:        len(w.Points),
+				Time:       s.Time,
+				Lat:        s.Lat,
+				Lon:        s.Lon,
+				ElevationM: s.Elevation,
+				HeartRate:  heartRatePtr(s.HeartRate),
+			})
+		}
+		if s.DistanceM != nil && *s.DistanceM > reportedDist {
+			reportedDist = *s.DistanceM
+		}
+		if s.Elevation != nil {
+			if prevEle != nil && *s.Elevation > *prevEle {
+				gain += *s.Elevation - *prevEle
+			}
+			prevEle = s.Elevation
+		}
+		if s.HeartRate > 0 {
+			hrSum += s.HeartRate
+			hrCount++
+			if s.HeartRate > maxHR {
+				maxHR = s.HeartRate
+			}
+		}
+	}
+
+	if w.StartTime.IsZero() {
+		w.StartTime = first
+	}
+	if w.StartTime.IsZero() {
+		return nil, fmt.Errorf("workout file contains no timestamps")
+	}
+	if w.Duration == 0 && !first.IsZero() {
+		w.Duration = last.Sub(first)
+	}
+	if w.DistanceM == 0 {
+		// Device-recorded cumulative distance is more accurate than the
+		// straight-line sum of GPS fixes, so prefer it when present.
+		if reportedDist > 0 {
+			w.DistanceM = reportedDist
+		} else {
+			w.DistanceM = distance
+		}
+	}
+	if w.ElevationGainM == 0 {
+		w.ElevationGainM = gain
+	}
+	if w.AvgHeartRate == 0 && hrCount > 0 {
+		w.AvgHeartRate = int(math.Round(float64(hrSum) / float64(hrCount)))
+	}
+	if w.MaxHeartRate == 0 {
+		w.MaxHeartRate = maxHR
+	}
+	w.DistanceM = math.Round(w.DistanceM*10) / 10
+	w.ElevationGainM = math.Round(w.ElevationGainM*10) / 10
+	return w, nil
+}
+
+func heartRatePtr(hr int) *int {
+	if hr <= 0 {
+		return nil
+	}
+	return &hr
+}
+
+// ActivityType maps a sport name reported by a device to the activity type
+// names used by the app. Sports the app does not know become "other".
+func ActivityType(sport string) string {
+	switch strings.ToLower(strings.TrimSpace(sport)) {
+	case "running", "run", "trail_running":
+		return "running"
+	case "biking", "cycling", "bike", "ride":
+		return "cycling"
+	case "walking", "walk":
+		return "walking"
+	case "hiking", "hike":
+		return "hiking"
+	case "swimming", "swim":
+		return "swimming"
+	case "rowing", "row":
+		return "rowing"
+	}
+	return "other"
+}
@@ -0,0 +1,313 @@
+package trackfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+const sampleGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1"
+     xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">
+  <trk>
+    <type>running</type>
+    <trkseg>
+      <trkpt lat="55.750000" lon="37.620000">
+        <ele>150.0</ele>
+        <time>2025-06-01T07:00:00Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>120</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions>
+      </trkpt>
+      <trkpt lat="55.754500" lon="37.620000">
+        <ele>155.5</ele>
+        <time>2025-06-01T07:02:30Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>140</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions>
+      </trkpt>
+      <trkpt lat="55.759000" lon="37.620000">
+        <ele>152.0</ele>
+        <time>2025-06-01T07:05:00Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>160</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions>
+      </trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+const sampleTCX = `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+  <Activities>
+    <Activity Sport="Biking">
+      <Id>2025-06-02T18:00:00Z</Id>
+      <Lap StartTime="2025-06-02T18:00:00Z">
+        <TotalTimeSeconds>1800</TotalTimeSeconds>
+        <DistanceMeters>12000</DistanceMeters>
+        <Calories>450</Calories>
+        <MaximumHeartRateBpm><Value>171</Value></MaximumHeartRateBpm>
+        <Track>
+          <Trackpoint>
+            <Time>2025-06-02T18:00:00Z</Time>
+            <Position><LatitudeDegrees>59.93</LatitudeDegrees><LongitudeDegrees>30.33</LongitudeDegrees></Position>
+            <AltitudeMeters>10</AltitudeMeters>
+            <DistanceMeters>0</DistanceMeters>
+            <HeartRateBpm><Value>110</Value></HeartRateBpm>
+          </Trackpoint>
+          <Trackpoint>
+            <Time>2025-06-02T18:10:00Z</Time>
+            <AltitudeMeters>18</AltitudeMeters>
+            <DistanceMeters>4000</DistanceMeters>
+            <HeartRateBpm><Value>150</Value></HeartRateBpm>
+          </Trackpoint>
+          <Trackpoint>
+            <Time>2025-06-02T18:30:00Z</Time>
+            <Position><LatitudeDegrees>59.96</LatitudeDegrees><LongitudeDegrees>30.40</LongitudeDegrees></Position>
+            <AltitudeMeters>14</AltitudeMeters>
+            <DistanceMeters>12000</DistanceMeters>
+            <HeartRateBpm><Value>130</Value></HeartRateBpm>
+          </Trackpoint>
+        </Track>
+      </Lap>
+    </Activity>
+  </Activities>
+</TrainingCenterDatabase>`
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		expected Format
+		wantErr  bool
+	}{
+		{name: "GPX by content", filename: "upload", data: []byte(sampleGPX), expected: FormatGPX},
+		{name: "TCX by content", filename: "upload.xml", data: []byte(sampleTCX), expected: FormatTCX},
+		{name: "FIT by header", filename: "upload.bin", data: buildFIT(t, time.Now(), nil), expected: FormatFIT},
+		{name: "Extension fallback", filename: "RUN.GPX", data: []byte("garbage"), expected: FormatGPX},
+		{name: "Unknown", filename: "notes.txt", data: []byte("hello"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectFormat(tt.filename, tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected format %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseGPX(t *testing.T) {
+	w, err := ParseGPX([]byte(sampleGPX))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if w.Sport != "running" {
+		t.Errorf("Expected sport running, got %q", w.Sport)
+	}
+	if !w.StartTime.Equal(time.Date(2025, 6, 1, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected start time %v", w.StartTime)
+	}
+	if w.Duration != 5*time.Minute {
+		t.Errorf("Expected duration 5m, got %v", w.Duration)
+	}
+	// 0.009 degrees of latitude is roughly 1000.8 m.
+	if math.Abs(w.DistanceM-1000.8) > 1 {
+		t.Errorf("Expected distance ~1000.8m, got %.1f", w.DistanceM)
+	}
+	if w.ElevationGainM != 5.5 {
+		t.Errorf("Expected elevation gain 5.5, got %.1f", w.ElevationGainM)
+	}
+	if w.AvgHeartRate != 140 || w.MaxHeartRate != 160 {
+		t.Errorf("Expected HR avg 140 max 160, got avg %d max %d", w.AvgHeartRate, w.MaxHeartRate)
+	}
+	if len(w.Points) != 3 {
+		t.Fatalf("Expected 3 track points, got %d", len(w.Points))
+	}
+	if w.Points[2].Seq != 2 || w.Points[2].HeartRate == nil || *w.Points[2].HeartRate != 160 {
+		t.Errorf("Unexpected last track point %+v", w.Points[2])
+	}
+}
+
+func TestParseGPXErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "Malformed XML", data: "<gpx><trk>"},
+		{name: "No points", data: `<gpx><trk><trkseg></trkseg></trk></gpx>`},
+		{name: "Bad coordinate", data: `<gpx><trk><trkseg><trkpt lat="95" lon="0"><time>2025-06-01T07:00:00Z</time></trkpt></trkseg></trk></gpx>`},
+		{name: "Bad timestamp", data: `<gpx><trk><trkseg><trkpt lat="5" lon="0"><time>yesterday</time></trkpt></trkseg></trk></gpx>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseGPX([]byte(tt.data)); err == nil {
+				t.Error("Expected error, got none")
+			}
+		})
+	}
+}
+
+func TestParseTCX(t *testing.T) {
+	w, err := ParseTCX([]byte(sampleTCX))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if ActivityType(w.Sport) != "cycling" {
+		t.Errorf("Expected cycling, got %q", ActivityType(w.Sport))
+	}
+	if w.Duration != 30*time.Minute {
+		t.Errorf("Expected lap duration 30m, got %v", w.Duration)
+	}
+	if w.DistanceM != 12000 {
+		t.Errorf("Expected lap distance 12000, got %.1f", w.DistanceM)
+	}
+	if w.Calories != 450 {
+		t.Errorf("Expected 450 calories, got %d", w.Calories)
+	}
+	if w.ElevationGainM != 8 {
+		t.Errorf("Expected elevation gain 8, got %.1f", w.ElevationGainM)
+	}
+	if w.AvgHeartRate != 130 || w.MaxHeartRate != 171 {
+		t.Errorf("Expected HR avg 130 max 171, got avg %d max %d", w.AvgHeartRate, w.MaxHeartRate)
+	}
+	// The middle trackpoint has no position and is not part of the stored track.
+	if len(w.Points) != 2 {
+		t.Errorf("Expected 2 track points, got %d", len(w.Points))
+	}
+}
+
+type fitTestRecord struct {
+	Offset    time.Duration
+	Lat       float64
+	Lon       float64
+	Altitude  float64
+	HeartRate uint8
+	DistanceM float64
+}
+
+// buildFIT encodes a minimal FIT activity file with one record definition
+// and the given records.
+func buildFIT(t *testing.T, start time.Time, records []fitTestRecord) []byte {
+	t.Helper()
+	var body bytes.Buffer
+	le := binary.LittleEndian
+
+	// Definition message for local type 0 -> global record (20).
+	body.Write([]byte{0x40, 0, 0})
+	binary.Write(&body, le, uint16(fitMesgRecord))
+	body.WriteByte(6)
+	body.Write([]byte{
+		fitFieldTimestamp, 4, 0x86,
+		fitRecordLat, 4, 0x85,
+		fitRecordLon, 4, 0x85,
+		fitRecordAltitude, 2, 0x84,
+		fitRecordHeartRate, 1, 0x02,
+		fitRecordDistance, 4, 0x86,
+	})
+
+	for _, r := range records {
+		ts := uint32(start.Add(r.Offset).Sub(fitEpoch) / time.Second)
+		body.WriteByte(0x00)
+		binary.Write(&body, le, ts)
+		binary.Write(&body, le, int32(r.Lat/fitSemicircleToDeg))
+		binary.Write(&body, le, int32(r.Lon/fitSemicircleToDeg))
+		binary.Write(&body, le, uint16((r.Altitude+500)*5))
+		body.WriteByte(r.HeartRate)
+		binary.Write(&body, le, uint32(r.DistanceM*100))
+	}
+
+	var out bytes.Buffer
+	out.WriteByte(12)
+	out.WriteByte(0x10)
+	binary.Write(&out, le, uint16(2100))
+	binary.Write(&out, le, uint32(body.Len()))
+	out.WriteString(".FIT")
+	out.Write(body.Bytes())
+	out.Write([]byte{0, 0}) // CRC, not validated
+	return out.Bytes()
+}
+
+func TestParseFIT(t *testing.T) {
+	start := time.Date(2025, 6, 3, 6, 30, 0, 0, time.UTC)
+	data := buildFIT(t, start, []fitTestRecord{
+		{Offset: 0, Lat: 48.8566, Lon: 2.3522, Altitude: 35, HeartRate: 100, DistanceM: 0},
+		{Offset: 10 * time.Minute, Lat: 48.8600, Lon: 2.3600, Altitude: 40, HeartRate: 150, DistanceM: 1500},
+		{Offset: 20 * time.Minute, Lat: 48.8650, Lon: 2.3700, Altitude: 38, HeartRate: 170, DistanceM: 3000.5},
+	})
+
+	w, err := ParseFIT(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !w.StartTime.Equal(start) {
+		t.Errorf("Expected start %v, got %v", start, w.StartTime)
+	}
+	if w.Duration != 20*time.Minute {
+		t.Errorf("Expected duration 20m, got %v", w.Duration)
+	}
+	if w.DistanceM != 3000.5 {
+		t.Errorf("Expected recorded distance 3000.5, got %.1f", w.DistanceM)
+	}
+	if w.ElevationGainM != 5 {
+		t.Errorf("Expected elevation gain 5, got %.1f", w.ElevationGainM)
+	}
+	if w.AvgHeartRate != 140 || w.MaxHeartRate != 170 {
+		t.Errorf("Expected HR avg 140 max 170, got avg %d max %d", w.AvgHeartRate, w.MaxHeartRate)
+	}
+	if len(w.Points) != 3 {
+		t.Fatalf("Expected 3 track points, got %d", len(w.Points))
+	}
+	if math.Abs(w.Points[0].Lat-48.8566) > 1e-6 || math.Abs(w.Points[0].Lon-2.3522) > 1e-6 {
+		t.Errorf("Unexpected first point %+v", w.Points[0])
+	}
+}
+
+func TestParseFITErrors(t *testing.T) {
+	valid := buildFIT(t, time.Now(), []fitTestRecord{{Lat: 1, Lon: 1}})
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Too short", data: []byte{12, 0, 0}},
+		{name: "Bad signature", data: append([]byte{12, 0, 0, 0, 0, 0, 0, 0, 'X', 'F', 'I', 'T'}, valid[12:]...)},
+		{name: "Truncated", data: valid[:len(valid)-6]},
+		{name: "No records", data: buildFIT(t, time.Now(), nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseFIT(tt.data); err == nil {
+				t.Error("Expected error, got none")
+			}
+		})
+	}
+}
+
+func TestActivityType(t *testing.T) {
+	tests := map[string]string{
+		"Running": "running",
+		"Biking":  "cycling",
+		"walk":    "walking",
+		"":        "other",
+		"Rowing":  "rowing",
+		"Kitesurfing with a name longer than any activity type": "other",
+	}
+	for sport, expected := range tests {
+		if got := ActivityType(sport); got != expected {
+			t.Errorf("ActivityType(%q) = %q, expected %q", sport, got, expected)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package trackfile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ffabious/healthy-summer/activity-service/internal/geo"
+)
+
+type gpxFile struct {
+	XMLName xml.Name `xml:"gpx"`
+	Tracks  []struct {
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+type gpxPoint struct {
+	Lat        float64  `xml:"lat,attr"`
+	Lon        float64  `xml:"lon,attr"`
+	Elevation  *float64 `xml:"ele"`
+	Time       string   `xml:"time"`
+	Extensions struct {
+		TrackPointExtension struct {
+			HeartRate int `xml:"hr"`
+		} `xml:"TrackPointExtension"`
+	} `xml:"extensions"`
+}
+
+// ParseGPX parses a GPX 1.1 document. Heart rate is read from the Garmin
+// TrackPointExtension when present.
+func ParseGPX(data []byte) (*Workout, error) {
+	var doc gpxFile
+	if err := decodeXML(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid GPX file: %w", err)
+	}
+
+	var t totals
+	var samples []sample
+	for _, trk := range doc.Tracks {
+		if t.Sport == "" {
+			t.Sport = trk.Type
+		}
+		for _, seg := range trk.Segments {
+			for _, p := range seg.Points {
+				if !geo.ValidCoordinate(p.Lat, p.Lon) {
+					return nil, fmt.Errorf("invalid GPX file: coordinate out of range")
+				}
+				ts, err := parseXMLTime(p.Time)
+				if err != nil {
+					return nil, fmt.Errorf("invalid GPX file: %w", err)
+				}
+				samples = append(samples, sample{
+					Time:      ts,
+					Lat:       p.Lat,
+					Lon:       p.Lon,
+					HasPos:    true,
+					Elevation: p.Elevation,
+					HeartRate: p.Extensions.TrackPointExtension.HeartRate,
+				})
+			}
+		}
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("workout file contains no track data")
+	}
+	return summarize(FormatGPX, samples, t)
+}
+
+type tcxFile struct {
+	XMLName    xml.Name `xml:"TrainingCenterDatabase"`
+	Activities []struct {
+		Sport string `xml:"Sport,attr"`
+		ID    string `xml:"Id"`
+		Laps  []struct {
+			StartTime        string  `xml:"StartTime,attr"`
+			TotalTimeSeconds float64 `xml:"TotalTimeSeconds"`
+			DistanceMeters   float64 `xml:"DistanceMeters"`
+			Calories         int     `xml:"Calories"`
+			MaxHeartRate     struct {
+				Value int `xml:"Value"`
+			} `xml:"MaximumHeartRateBpm"`
+			Tracks []struct {
+				Points []tcxPoint `xml:"Trackpoint"`
+			} `xml:"Track"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+type tcxPoint struct {
+	Time     string `xml:"Time"`
+	Position *struct {
+		Lat float64 `xml:"LatitudeDegrees"`
+		Lon float64 `xml:"LongitudeDegrees"`
+	} `xml:"Position"`
+	Altitude       *float64 `xml:"AltitudeMeters"`
+	DistanceMeters *float64 `xml:"DistanceMeters"`
+	HeartRate      struct {
+		Value int `xml:"Value"`
+	} `xml:"HeartRateBpm"`
+}
+
+// ParseTCX parses a Garmin Training Center XML document. Lap totals for time,
+// distance and calories take precedence over values derived from trackpoints.
+func ParseTCX(data []byte) (*Workout, error) {
+	var doc tcxFile
+	if err := decodeXML(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid TCX file: %w", err)
+	}
+	if len(doc.Activities) == 0 {
+		return nil, fmt.Errorf("workout file contains no activities")
+	}
+
+	// Multi-activity files are rare; only the first activity is imported.
+	act := doc.Activities[0]
+	t := totals{Sport: act.Sport}
+	if ts, err := parseXMLTime(act.ID); err == nil {
+		t.StartTime = ts
+	}
+
+	var samples []sample
+	var lapSeconds float64
+	for _, lap := range act.Laps {
+		if t.StartTime.IsZero() {
+			if ts, err := parseXMLTime(lap.StartTime); err == nil {
+				t.StartTime = ts
+			}
+		}
+		lapSeconds += lap.TotalTimeSeconds
+		t.DistanceM += lap.DistanceMeters
+		t.Calories += lap.Calories
+		if lap.MaxHeartRate.Value > t.MaxHeartRate {
+			t.MaxHeartRate = lap.MaxHeartRate.Value
+		}
+		for _, trk := range lap.Tracks {
+			for _, p := range trk.Points {
+				ts, err := parseXMLTime(p.Time)
+				if err != nil {
+					return nil, fmt.Errorf("invalid TCX file: %w", err)
+				}
+				s := sample{
+					Time:      ts,
+					Elevation: p.Altitude,
+					HeartRate: p.HeartRate.Value,
+					DistanceM: p.DistanceMeters,
+				}
+				if p.Position != nil {
+					if !geo.ValidCoordinate(p.Position.Lat, p.Position.Lon) {
+						return nil, fmt.Errorf("invalid TCX file: coordinate out of range")
+					}
+					s.Lat, s.Lon, s.HasPos = p.Position.Lat, p.Position.Lon, true
+				}
+				samples = append(samples, s)
+			}
+		}
+	}
+	t.Duration = time.Duration(lapSeconds * float64(time.Second))
+	return summarize(FormatTCX, samples, t)
+}
+
+func decodeXML(data []byte, v any) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	// Some exporters declare non-UTF-8 charsets for ASCII-only content.
+	dec.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	return dec.Decode(v)
+}
+
+func parseXMLTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	ts, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+	}
+	return ts.UTC(), nil
+}
@@ -0,0 +1,73 @@
+package transfer
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Required returns the value of a field or an error if it is empty.
+func (r Record) Required(name string) (string, error) {
+	v := r.Fields[name]
+	if v == "" {
+		return "", fmt.Errorf("%s is required", name)
+	}
+	return v, nil
+}
+
+// Int parses an integer field. Empty optional fields yield 0.
+func (r Record) Int(name string, required bool) (int, error) {
+	v := r.Fields[name]
+	if v == "" {
+		if required {
+			return 0, fmt.Errorf("%s is required", name)
+		}
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		// Accept whole numbers written as floats, e.g. "30.0" from spreadsheets.
+		f, ferr := strconv.ParseFloat(v, 64)
+		if ferr != nil || f != float64(int(f)) {
+			return 0, fmt.Errorf("%s must be an integer", name)
+		}
+		n = int(f)
+	}
+	return n, nil
+}
+
+// Float parses a decimal field. Empty optional fields yield 0.
+func (r Record) Float(name string, required bool) (float64, error) {
+	v := r.Fields[name]
+	if v == "" {
+		if required {
+			return 0, fmt.Errorf("%s is required", name)
+		}
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number", name)
+	}
+	return f, nil
+}
+
+// Time parses an RFC 3339 timestamp or a YYYY-MM-DD date field.
+func (r Record) Time(name string) (time.Time, error) {
+	v, err := r.Required(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s must be an RFC 3339 timestamp or YYYY-MM-DD date", name)
+}
+
+// FormatFloat formats a number for CSV output without trailing zeros.
+func FormatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
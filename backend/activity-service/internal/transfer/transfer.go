@@ -0,0 +1,247 @@
+// Package transfer reads and writes the CSV/JSON files used for bulk data
+// export and import. Exports are zip archives with one file per dataset;
+// imports accept the same archives or a single dataset file.
+package transfer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Format is the file format of a dataset.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat validates a format query value. An empty value means CSV.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("format must be csv or json")
+}
+
+// Dataset is one exported file. Rows are used for CSV and Items for JSON.
+type Dataset struct {
+	Name    string
+	Columns []string
+	Rows    [][]string
+	Items   any
+}
+
+// WriteZip writes datasets as <name>.<format> entries of a zip archive.
+func WriteZip(w io.Writer, format Format, datasets []Dataset) error {
+	zw := zip.NewWriter(w)
+	for _, ds := range datasets {
+		f, err := zw.Create(ds.Name + "." + string(format))
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", ds.Name, err)
+		}
+		switch format {
+		case FormatJSON:
+			enc := json.NewEncoder(f)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(ds.Items); err != nil {
+				return fmt.Errorf("failed to write %s: %w", ds.Name, err)
+			}
+		default:
+			cw := csv.NewWriter(f)
+			if err := cw.Write(ds.Columns); err != nil {
+				return fmt.Errorf("failed to write %s: %w", ds.Name, err)
+			}
+			if err := cw.WriteAll(ds.Rows); err != nil {
+				return fmt.Errorf("failed to write %s: %w", ds.Name, err)
+			}
+		}
+	}
+	return zw.Close()
+}
+
+// Record is one row of an imported file with its fields keyed by column name.
+// Row is 1-based and counts data rows only.
+type Record struct {
+	Row    int
+	Fields map[string]string
+}
+
+// File is one imported dataset file.
+type File struct {
+	Name    string // dataset name, e.g. "activities"
+	Path    string // file name as uploaded
+	Records []Record
+}
+
+// RowError describes a row that could not be imported. Row 0 refers to the
+// file as a whole.
+type RowError struct {
+	File  string `json:"file"`
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// DatasetReport summarises the import of one dataset.
+type DatasetReport struct {
+	Dataset  string `json:"dataset"`
+	Rows     int    `json:"rows"`
+	Imported int    `json:"imported"`
+	Skipped  int    `json:"skipped"`
+	Failed   int    `json:"failed"`
+}
+
+// Report is returned by the import endpoints.
+type Report struct {
+	Datasets []DatasetReport `json:"datasets"`
+	Errors   []RowError      `json:"errors"`
+}
+
+// MaxEntrySize limits the uncompressed size of a single zip entry.
+const MaxEntrySize = 50 << 20
+
+// ReadFiles parses an uploaded zip archive or a single .csv/.json file.
+// Entries that are not CSV or JSON files are ignored.
+func ReadFiles(filename string, data []byte) ([]File, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid zip archive: %w", err)
+		}
+		var files []File
+		for _, entry := range zr.File {
+			if entry.FileInfo().IsDir() || !isDataFile(entry.Name) {
+				continue
+			}
+			rc, err := entry.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to open %s: %w", entry.Name, err)
+			}
+			content, err := io.ReadAll(io.LimitReader(rc, MaxEntrySize+1))
+			rc.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", entry.Name, err)
+			}
+			if len(content) > MaxEntrySize {
+				return nil, fmt.Errorf("%s is too large", entry.Name)
+			}
+			file, err := readFile(entry.Name, content)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, file)
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("zip archive contains no csv or json files")
+		}
+		return files, nil
+	}
+
+	if !isDataFile(filename) {
+		return nil, fmt.Errorf("file must be a .zip, .csv or .json file")
+	}
+	file, err := readFile(filename, data)
+	if err != nil {
+		return nil, err
+	}
+	return []File{file}, nil
+}
+
+func isDataFile(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".csv" || ext == ".json"
+}
+
+func readFile(name string, data []byte) (File, error) {
+	base := path.Base(strings.ReplaceAll(name, "\\", "/"))
+	ext := strings.ToLower(path.Ext(base))
+	file := File{
+		Name: strings.ToLower(strings.TrimSuffix(base, path.Ext(base))),
+		Path: name,
+	}
+	var err error
+	if ext == ".json" {
+		file.Records, err = readJSON(data)
+	} else {
+		file.Records, err = readCSV(data)
+	}
+	if err != nil {
+		return file, fmt.Errorf("%s: %w", name, err)
+	}
+	return file, nil
+}
+
+func readCSV(data []byte) ([]Record, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+
+	var records []Record
+	for row := 1; ; row++ {
+		values, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv at row %d: %w", row, err)
+		}
+		fields := make(map[string]string, len(header))
+		for i, col := range header {
+			if i < len(values) {
+				fields[col] = strings.TrimSpace(values[i])
+			}
+		}
+		records = append(records, Record{Row: row, Fields: fields})
+	}
+	return records, nil
+}
+
+func readJSON(data []byte) ([]Record, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var items []map[string]any
+	if err := dec.Decode(&items); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of objects: %w", err)
+	}
+	records := make([]Record, len(items))
+	for i, item := range items {
+		fields := make(map[string]string, len(item))
+		for k, v := range item {
+			switch v := v.(type) {
+			case nil:
+			case string:
+				fields[strings.ToLower(k)] = strings.TrimSpace(v)
+			case json.Number:
+				fields[strings.ToLower(k)] = v.String()
+			case bool:
+				fields[strings.ToLower(k)] = strconv.FormatBool(v)
+			default:
+				// Nested values are not part of any dataset; keep them as JSON.
+				b, _ := json.Marshal(v)
+				fields[strings.ToLower(k)] = string(b)
+			}
+		}
+		records[i] = Record{Row: i + 1, Fields: fields}
+	}
+	return records, nil
+}
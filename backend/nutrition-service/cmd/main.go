@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
+	_ "time/tzdata"
 
 	_ "github.com/ffabious/healthy-summer/nutrition-service/docs"
 	"github.com/ffabious/healthy-summer/nutrition-service/internal/auth"
 	"github.com/ffabious/healthy-summer/nutrition-service/internal/db"
 	"github.com/ffabious/healthy-summer/nutrition-service/internal/handler"
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/reminder"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -26,6 +30,13 @@ func main() {
 	}
 
 	db.Connect()
+	// Reject tokens revoked by user-service
+	auth.SessionValidator = db.ValidateSession
+
+	// Reminders are evaluated in users' timezones; tzdata is embedded
+	// because the runtime image has no zoneinfo.
+	scheduler := &reminder.Scheduler{Notifier: reminder.NewNotifierFromEnv()}
+	go scheduler.Start(context.Background(), time.Minute)
 
 	r := gin.Default()
 
@@ -44,11 +55,31 @@ func main() {
 	protected.GET("/meals", handler.GetMealsHandler)
 	protected.PUT("/meals/:id", handler.UpdateMealHandler)
 	protected.DELETE("/meals/:id", handler.DeleteMealHandler)
+	protected.PUT("/meals/:id/image", handler.SetMealImageHandler)
+	protected.DELETE("/meals/:id/image", handler.DeleteMealImageHandler)
 	protected.POST("/water", handler.PostWaterHandler)
 	protected.GET("/water", handler.GetWaterIntakeHandler)
 	protected.PUT("/water/:id", handler.UpdateWaterEntryHandler)
 	protected.DELETE("/water/:id", handler.DeleteWaterEntryHandler)
 	protected.GET("/stats", handler.GetNutritionStatsHandler)
+	protected.GET("/data/export", handler.ExportNutritionDataHandler)
+	protected.POST("/data/import", handler.ImportNutritionDataHandler)
+	protected.GET("/foods", handler.SearchFoodHandler)
+	protected.GET("/reminders", handler.GetRemindersHandler)
+	protected.POST("/reminders", handler.CreateReminderHandler)
+	protected.PUT("/reminders/:id", handler.UpdateReminderHandler)
+	protected.DELETE("/reminders/:id", handler.DeleteReminderHandler)
+
+	admin := r.Group("/api/admin")
+	admin.Use(auth.JWTMiddleware(), auth.RequireRole(auth.RoleAdmin))
+	admin.POST("/foods", handler.CreateFoodItemHandler)
+	admin.PUT("/foods/:id", handler.UpdateFoodItemHandler)
+	admin.DELETE("/foods/:id", handler.DeleteFoodItemHandler)
+
+	internal := r.Group("/internal")
+	internal.Use(auth.InternalMiddleware())
+	internal.DELETE("/users/:user_id", handler.PurgeUserDataHandler)
+	internal.GET("/users/:user_id/nutrition-period", handler.GetNutritionPeriodHandler)
 
 	runRegular(r, port)
 }
@@ -10,6 +10,13 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// SessionValidator, when set, is called by JWTMiddleware with the user ID and
+// token version from the claims. user-service bumps the version when a user
+// changes their password or email, is suspended or gets a new role, so
+// tokens issued before are rejected here too even though they have not
+// expired.
+var SessionValidator func(userID string, tokenVersion int) error
+
 func JWTMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -37,7 +44,17 @@ func JWTMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if SessionValidator != nil {
+			userID, _ := claims["user_id"].(string)
+			version, _ := claims["ver"].(float64)
+			if err := SessionValidator(userID, int(version)); err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session expired", "details": err.Error()})
+				return
+			}
+		}
+
 		c.Set("user_id", claims["user_id"])
+		c.Set("role", roleFromClaims(claims))
 		c.Next()
 	}
 }
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Roles carried in the "role" claim of access tokens issued by the user
+// service. Tokens without the claim belong to regular users.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+func roleFromClaims(claims jwt.MapClaims) string {
+	if role, ok := claims["role"].(string); ok && role != "" {
+		return role
+	}
+	return RoleUser
+}
+
+// RequireRole rejects requests whose token does not carry one of roles. It
+// must run after JWTMiddleware, which stores the verified role.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !slices.Contains(roles, c.GetString("role")) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
+		c.Next()
+	}
+}
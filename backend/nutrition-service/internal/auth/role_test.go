@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	originalSecret := os.Getenv("JWT_SECRET")
+	defer os.Setenv("JWT_SECRET", originalSecret)
+	os.Setenv("JWT_SECRET", "test-secret")
+
+	tests := []struct {
+		name           string
+		role           any
+		expectedStatus int
+	}{
+		{name: "Admin", role: RoleAdmin, expectedStatus: http.StatusOK},
+		{name: "Moderator", role: RoleModerator, expectedStatus: http.StatusOK},
+		{name: "Regular user", role: RoleUser, expectedStatus: http.StatusForbidden},
+		{name: "Token without role", role: nil, expectedStatus: http.StatusForbidden},
+		{name: "Unknown role", role: "superuser", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := jwt.MapClaims{"user_id": "user123", "exp": time.Now().Add(time.Hour).Unix()}
+			if tt.role != nil {
+				claims["role"] = tt.role
+			}
+			token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+
+			router := gin.New()
+			router.Use(JWTMiddleware(), RequireRole(RoleAdmin, RoleModerator))
+			router.GET("/admin", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/admin", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestRequireRoleWithoutJWTMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequireRole(RoleAdmin))
+	router.GET("/admin", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/admin", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
@@ -8,9 +8,11 @@ import (
 	"time"
 
 	"github.com/ffabious/healthy-summer/nutrition-service/internal/model"
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/pagination"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var DB *gorm.DB
@@ -51,7 +53,7 @@ func Connect() {
 		log.Fatalf("Failed to create extension uuid-ossp: %v", err)
 	}
 
-	if err := DB.AutoMigrate(&model.Meal{}, &model.Water{}); err != nil {
+	if err := DB.AutoMigrate(&model.Meal{}, &model.Water{}, &model.FoodItem{}, &model.ReminderRule{}); err != nil {
 		log.Fatalf("Failed to migrate database models: %v", err)
 	}
 
@@ -84,6 +86,34 @@ func GetMealsByUserID(userID string) ([]model.Meal, error) {
 	return meals, nil
 }
 
+// ListMeals returns one page of a user's meals ordered by timestamp,
+// optionally restricted to meals whose name contains query.
+func ListMeals(userID, query string, params pagination.Params) (*model.MealPage, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	q := DB.Model(&model.Meal{}).Where("user_id = ?", userID)
+	if query != "" {
+		q = q.Where("name ILIKE ?", "%"+query+"%")
+	}
+	q = params.Filter(q, "timestamp")
+
+	page := model.MealPage{Meals: []model.Meal{}}
+	if err := q.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count meals for user %s: %w", userID, err)
+	}
+	if err := params.Page(q, "timestamp").Find(&page.Meals).Error; err != nil {
+		return nil, fmt.Errorf("failed to list meals for user %s: %w", userID, err)
+	}
+	page.Meals, page.NextCursor = pagination.Trim(page.Meals, params.Limit, func(m model.Meal) pagination.Cursor {
+		return pagination.Cursor{Timestamp: m.Timestamp, ID: m.ID}
+	})
+	return &page, nil
+}
+
 func CreateWater(water *model.Water) error {
 	if DB == nil {
 		return fmt.Errorf("database connection is nil")
@@ -108,6 +138,30 @@ func GetWaterIntakeByUserID(userID string) ([]model.Water, error) {
 	return waterEntries, nil
 }
 
+// ListWaterEntries returns one page of a user's water entries ordered by
+// timestamp.
+func ListWaterEntries(userID string, params pagination.Params) (*model.WaterPage, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	q := params.Filter(DB.Model(&model.Water{}).Where("user_id = ?", userID), "timestamp")
+
+	page := model.WaterPage{WaterEntries: []model.Water{}}
+	if err := q.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count water entries for user %s: %w", userID, err)
+	}
+	if err := params.Page(q, "timestamp").Find(&page.WaterEntries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list water entries for user %s: %w", userID, err)
+	}
+	page.WaterEntries, page.NextCursor = pagination.Trim(page.WaterEntries, params.Limit, func(w model.Water) pagination.Cursor {
+		return pagination.Cursor{Timestamp: w.Timestamp, ID: w.ID}
+	})
+	return &page, nil
+}
+
 func GetNutritionStatsByUserID(userID string) (*model.NutritionStats, error) {
 	if DB == nil {
 		return nil, fmt.Errorf("database connection is nil")
@@ -150,6 +204,15 @@ func GetNutritionStatsByUserID(userID string) (*model.NutritionStats, error) {
 	return stats, nil
 }
 
+// GetNutritionPeriodByUserID totals the meals and water a user logged
+// between from (inclusive) and to (exclusive).
+func GetNutritionPeriodByUserID(userID string, from, to time.Time) (*model.NutritionPeriod, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	return calculatePeriodStats(userID, from, to)
+}
+
 func calculatePeriodStats(userID string, startTime, endTime time.Time) (*model.NutritionPeriod, error) {
 	if DB == nil {
 		return nil, fmt.Errorf("database connection is nil")
@@ -203,7 +266,7 @@ func SearchFood(query string) ([]model.FoodItem, error) {
 		return nil, fmt.Errorf("database connection is nil")
 	}
 	var foods []model.FoodItem
-	if err := DB.Where("name ILIKE ?", "%"+query+"%").Find(&foods).Error; err != nil {
+	if err := DB.Where("name ILIKE ?", "%"+query+"%").Order("name").Find(&foods).Error; err != nil {
 		return nil, fmt.Errorf("failed to search food items: %w", err)
 	}
 	return foods, nil
@@ -288,3 +351,72 @@ func DeleteWaterEntry(waterID, userID string) error {
 	}
 	return nil
 }
+
+// InsertMeals bulk-inserts meals, skipping rows whose ID already exists. It
+// returns the number of rows inserted.
+func InsertMeals(meals []model.Meal) (int64, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database connection is nil")
+	}
+	if len(meals) == 0 {
+		return 0, nil
+	}
+	result := DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&meals, 500)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to insert meals: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// InsertWaterEntries bulk-inserts water entries, skipping rows whose ID
+// already exists. It returns the number of rows inserted.
+func InsertWaterEntries(entries []model.Water) (int64, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database connection is nil")
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	result := DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&entries, 500)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to insert water entries: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// PurgeUserData deletes every meal, water entry and reminder owned by a user. It
+// returns the number of rows removed per table and is safe to call again once
+// the data is gone.
+func PurgeUserData(userID string) (map[string]int64, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	deleted := make(map[string]int64)
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		meals := tx.Where("user_id = ?", userID).Delete(&model.Meal{})
+		if meals.Error != nil {
+			return meals.Error
+		}
+		deleted["meals"] = meals.RowsAffected
+
+		waters := tx.Where("user_id = ?", userID).Delete(&model.Water{})
+		if waters.Error != nil {
+			return waters.Error
+		}
+		deleted["waters"] = waters.RowsAffected
+
+		reminders := tx.Where("user_id = ?", userID).Delete(&model.ReminderRule{})
+		if reminders.Error != nil {
+			return reminders.Error
+		}
+		deleted["reminder_rules"] = reminders.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge data for user %s: %w", userID, err)
+	}
+	return deleted, nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/ffabious/healthy-summer/nutrition-service/internal/model"
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/pagination"
 	"github.com/google/uuid"
 )
 
@@ -204,6 +205,20 @@ func TestGetNutritionStatsByUserIDWithNilDatabase(t *testing.T) {
 	}
 }
 
+func TestGetNutritionPeriodByUserID(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	now := time.Now()
+	if _, err := GetNutritionPeriodByUserID("test-user-id", now.AddDate(0, 0, -7), now); err == nil {
+		t.Error("Expected error when DB is nil, got nil")
+	}
+	if _, err := GetNutritionPeriodByUserID("", now.AddDate(0, 0, -7), now); err == nil {
+		t.Error("Expected error for empty user ID, got nil")
+	}
+}
+
 func TestGetNutritionStatsByUserIDWithInvalidUserID(t *testing.T) {
 	// Save original DB
 	originalDB := DB
@@ -510,3 +525,43 @@ func TestConnectEnvironmentVariables(t *testing.T) {
 		})
 	}
 }
+
+func TestListFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+
+	params := pagination.Params{Limit: pagination.DefaultLimit}
+	if _, err := ListMeals(uuid.New().String(), "", params); err == nil {
+		t.Error("Expected error from ListMeals when DB is nil, got nil")
+	}
+	if _, err := ListWaterEntries(uuid.New().String(), params); err == nil {
+		t.Error("Expected error from ListWaterEntries when DB is nil, got nil")
+	}
+}
+
+func TestInsertFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+
+	if _, err := InsertMeals([]model.Meal{{}}); err == nil {
+		t.Error("Expected error from InsertMeals when DB is nil, got nil")
+	}
+	if _, err := InsertWaterEntries([]model.Water{{}}); err == nil {
+		t.Error("Expected error from InsertWaterEntries when DB is nil, got nil")
+	}
+}
+
+func TestPurgeUserDataWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+
+	if _, err := PurgeUserData(uuid.New().String()); err == nil {
+		t.Error("Expected error from PurgeUserData when DB is nil, got nil")
+	}
+}
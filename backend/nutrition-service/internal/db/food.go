@@ -0,0 +1,71 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/model"
+	"gorm.io/gorm"
+)
+
+func CreateFoodItem(req *model.FoodItemRequest) (*model.FoodItem, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	food := model.FoodItem{
+		Name:          req.Name,
+		Calories:      req.Calories,
+		Protein:       req.Protein,
+		Carbohydrates: req.Carbohydrates,
+		Fats:          req.Fats,
+	}
+	if err := DB.Create(&food).Error; err != nil {
+		return nil, fmt.Errorf("failed to create food item: %w", err)
+	}
+	return &food, nil
+}
+
+// UpdateFoodItem replaces a catalog entry. The error wraps
+// gorm.ErrRecordNotFound if the item does not exist.
+func UpdateFoodItem(foodID string, req *model.FoodItemRequest) (*model.FoodItem, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	var food model.FoodItem
+	if err := DB.Where("id = ?", foodID).First(&food).Error; err != nil {
+		return nil, fmt.Errorf("food item not found: %w", err)
+	}
+
+	food.Name = req.Name
+	food.Calories = req.Calories
+	food.Protein = req.Protein
+	food.Carbohydrates = req.Carbohydrates
+	food.Fats = req.Fats
+
+	if err := DB.Save(&food).Error; err != nil {
+		return nil, fmt.Errorf("failed to update food item: %w", err)
+	}
+	return &food, nil
+}
+
+// DeleteFoodItem removes a catalog entry. Meals logged from it are not
+// affected, as they copy the nutrients. The error wraps
+// gorm.ErrRecordNotFound if the item does not exist.
+func DeleteFoodItem(foodID string) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	result := DB.Where("id = ?", foodID).Delete(&model.FoodItem{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete food item: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("food item not found: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/model"
+)
+
+func TestFoodItemFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	req := &model.FoodItemRequest{Name: "Apple", Calories: 95}
+
+	if _, err := CreateFoodItem(req); err == nil {
+		t.Error("Expected error from CreateFoodItem with nil database, got none")
+	}
+	if _, err := UpdateFoodItem("id", req); err == nil {
+		t.Error("Expected error from UpdateFoodItem with nil database, got none")
+	}
+	if err := DeleteFoodItem("id"); err == nil {
+		t.Error("Expected error from DeleteFoodItem with nil database, got none")
+	}
+	if _, err := SearchFood("apple"); err == nil {
+		t.Error("Expected error from SearchFood with nil database, got none")
+	}
+}
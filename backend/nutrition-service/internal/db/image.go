@@ -0,0 +1,57 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// ErrImageNotFound is returned when an image does not exist or belongs to
+// another user.
+var ErrImageNotFound = errors.New("image not found")
+
+// uploadURLs holds the columns of user-service's uploads table that are
+// copied onto a meal.
+type uploadURLs struct {
+	URL          string
+	ThumbnailURL string
+}
+
+// SetMealImage attaches one of the user's uploads to their meal, or removes
+// the image when imageID is nil. The error wraps gorm.ErrRecordNotFound if
+// the meal does not exist or belongs to another user.
+func SetMealImage(mealID, userID string, imageID *uuid.UUID) (*model.Meal, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var meal model.Meal
+	if err := DB.Where("id = ? AND user_id = ?", mealID, userID).First(&meal).Error; err != nil {
+		return nil, fmt.Errorf("meal not found or access denied: %w", err)
+	}
+
+	var urls uploadURLs
+	if imageID != nil {
+		var rows []uploadURLs
+		if err := DB.Table("uploads").
+			Select("url, thumbnail_url").
+			Where("id = ? AND user_id = ?", *imageID, userID).
+			Limit(1).
+			Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to get image: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil, ErrImageNotFound
+		}
+		urls = rows[0]
+	}
+
+	meal.ImageID = imageID
+	meal.ImageURL = urls.URL
+	meal.ThumbnailURL = urls.ThumbnailURL
+	if err := DB.Model(&meal).Select("image_id", "image_url", "thumbnail_url").Updates(&meal).Error; err != nil {
+		return nil, fmt.Errorf("failed to update meal image: %w", err)
+	}
+	return &meal, nil
+}
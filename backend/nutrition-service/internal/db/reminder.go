@@ -0,0 +1,164 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func applyReminderRequest(rule *model.ReminderRule, req *model.ReminderRuleRequest) {
+	rule.Type = req.Type
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+	rule.Timezone = req.Timezone
+	rule.StartHour = req.StartHour
+	rule.EndHour = req.EndHour
+	rule.IntervalMinutes = req.IntervalMinutes
+	rule.GoalMl = req.GoalMl
+}
+
+func CreateReminderRule(userID string, req *model.ReminderRuleRequest) (*model.ReminderRule, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+	rule := model.ReminderRule{ID: uuid.New(), UserID: uid}
+	applyReminderRequest(&rule, req)
+	// Select keeps an explicit enabled=false from being replaced by the
+	// column default.
+	if err := DB.Select("*").Create(&rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to create reminder: %w", err)
+	}
+	return &rule, nil
+}
+
+func GetReminderRulesByUserID(userID string) ([]model.ReminderRule, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var rules []model.ReminderRule
+	if err := DB.Where("user_id = ?", userID).Order("created_at").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get reminders: %w", err)
+	}
+	return rules, nil
+}
+
+// UpdateReminderRule replaces one of a user's reminders. The error wraps
+// gorm.ErrRecordNotFound if the user has no such reminder.
+func UpdateReminderRule(ruleID, userID string, req *model.ReminderRuleRequest) (*model.ReminderRule, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	var rule model.ReminderRule
+	if err := DB.Where("id = ? AND user_id = ?", ruleID, userID).First(&rule).Error; err != nil {
+		return nil, fmt.Errorf("reminder not found: %w", err)
+	}
+	applyReminderRequest(&rule, req)
+	if err := DB.Save(&rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to update reminder: %w", err)
+	}
+	return &rule, nil
+}
+
+// DeleteReminderRule removes one of a user's reminders. The error wraps
+// gorm.ErrRecordNotFound if the user has no such reminder.
+func DeleteReminderRule(ruleID, userID string) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	result := DB.Where("id = ? AND user_id = ?", ruleID, userID).Delete(&model.ReminderRule{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete reminder: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("reminder not found: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// GetEnabledReminderRules returns every enabled reminder for the scheduler.
+func GetEnabledReminderRules() ([]model.ReminderRule, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var rules []model.ReminderRule
+	if err := DB.Where("enabled = ?", true).Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get reminders: %w", err)
+	}
+	return rules, nil
+}
+
+// ClaimReminder records that a reminder is being sent at now. It only
+// succeeds if LastSentAt is still what the caller saw, so that when several
+// replicas run the scheduler only one of them sends each reminder.
+func ClaimReminder(rule *model.ReminderRule, now time.Time) (bool, error) {
+	if DB == nil {
+		return false, fmt.Errorf("database connection is nil")
+	}
+	q := DB.Model(&model.ReminderRule{}).Where("id = ?", rule.ID)
+	if rule.LastSentAt == nil {
+		q = q.Where("last_sent_at IS NULL")
+	} else {
+		q = q.Where("last_sent_at = ?", *rule.LastSentAt)
+	}
+	result := q.Update("last_sent_at", now)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim reminder: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// GetWaterSince returns the water a user logged from since until now.
+func GetWaterSince(userID string, since time.Time) (float64, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database connection is nil")
+	}
+	var total float64
+	if err := DB.Model(&model.Water{}).
+		Select("COALESCE(SUM(volume_ml), 0)").
+		Where("user_id = ? AND timestamp >= ?", userID, since).
+		Scan(&total).Error; err != nil {
+		return 0, fmt.Errorf("failed to sum water: %w", err)
+	}
+	return total, nil
+}
+
+// CountMealsSince returns how many meals a user logged from since until now.
+func CountMealsSince(userID string, since time.Time) (int64, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database connection is nil")
+	}
+	var count int64
+	if err := DB.Model(&model.Meal{}).
+		Where("user_id = ? AND timestamp >= ?", userID, since).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count meals: %w", err)
+	}
+	return count, nil
+}
+
+// CountActivitiesSince returns how many workouts a user logged from since
+// until now, using the activities table owned by activity-service.
+func CountActivitiesSince(userID string, since time.Time) (int64, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database connection is nil")
+	}
+	var count int64
+	if err := DB.Table("activities").
+		Where("user_id = ? AND timestamp >= ?", userID, since).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count activities: %w", err)
+	}
+	return count, nil
+}
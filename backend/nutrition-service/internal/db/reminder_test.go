@@ -0,0 +1,45 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/model"
+)
+
+func TestReminderFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	req := &model.ReminderRuleRequest{Type: model.ReminderMeal, Timezone: "UTC", StartHour: 9, EndHour: 21}
+	now := time.Now()
+
+	if _, err := CreateReminderRule("user", req); err == nil {
+		t.Error("Expected error from CreateReminderRule with nil database, got none")
+	}
+	if _, err := GetReminderRulesByUserID("user"); err == nil {
+		t.Error("Expected error from GetReminderRulesByUserID with nil database, got none")
+	}
+	if _, err := UpdateReminderRule("id", "user", req); err == nil {
+		t.Error("Expected error from UpdateReminderRule with nil database, got none")
+	}
+	if err := DeleteReminderRule("id", "user"); err == nil {
+		t.Error("Expected error from DeleteReminderRule with nil database, got none")
+	}
+	if _, err := GetEnabledReminderRules(); err == nil {
+		t.Error("Expected error from GetEnabledReminderRules with nil database, got none")
+	}
+	if _, err := ClaimReminder(&model.ReminderRule{}, now); err == nil {
+		t.Error("Expected error from ClaimReminder with nil database, got none")
+	}
+	if _, err := GetWaterSince("user", now); err == nil {
+		t.Error("Expected error from GetWaterSince with nil database, got none")
+	}
+	if _, err := CountMealsSince("user", now); err == nil {
+		t.Error("Expected error from CountMealsSince with nil database, got none")
+	}
+	if _, err := CountActivitiesSince("user", now); err == nil {
+		t.Error("Expected error from CountActivitiesSince with nil database, got none")
+	}
+}
@@ -0,0 +1,13 @@
+package db
+
+import "testing"
+
+func TestValidateSessionWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	if err := ValidateSession("user123", 0); err == nil {
+		t.Error("Expected error from ValidateSession with nil database, got none")
+	}
+}
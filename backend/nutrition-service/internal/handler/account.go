@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/db"
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/model"
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/pagination"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Purge User Data
+// @Description Internal endpoint called by user-service once an account deletion falls due. Removes every nutrition-service row owned by the user; calling it again is a no-op.
+// @Tags internal
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param X-Internal-Token header string true "Internal API token"
+// @Success 200 {object} model.PurgeUserDataResponse
+// @Router /internal/users/{user_id} [delete]
+func PurgeUserDataHandler(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID", "details": err.Error()})
+		return
+	}
+
+	deleted, err := db.PurgeUserData(userID.String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge user data", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.PurgeUserDataResponse{
+		Service: "nutrition-service",
+		UserID:  userID,
+		Deleted: deleted,
+	})
+}
+
+// @Summary Get Nutrition Period
+// @Description Internal endpoint used by user-service to build weekly digests. Totals the user's meals and water between from (inclusive) and to (exclusive).
+// @Tags internal
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param from query string true "Start of the period (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string true "End of the period (RFC 3339 or YYYY-MM-DD)"
+// @Param X-Internal-Token header string true "Internal API token"
+// @Success 200 {object} model.NutritionPeriod
+// @Router /internal/users/{user_id}/nutrition-period [get]
+func GetNutritionPeriodHandler(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID", "details": err.Error()})
+		return
+	}
+	params, err := pagination.FromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+	if params.From == nil || params.To == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": "from and to are required"})
+		return
+	}
+
+	period, err := db.GetNutritionPeriodByUserID(userID.String(), *params.From, *params.To)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get nutrition period", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, period)
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/auth"
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/db"
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/model"
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/transfer"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MaxImportFileSize limits the size of a bulk import upload.
+const MaxImportFileSize = 20 << 20
+
+var mealColumns = []string{"id", "name", "calories", "protein", "carbohydrates", "fats", "timestamp"}
+
+var waterColumns = []string{"id", "volume_ml", "timestamp"}
+
+// importID returns the ID an imported row is stored under. Rows exported by
+// us keep their ID; other rows get an ID derived from the user and the row
+// content, so uploading the same file twice does not create duplicates.
+func importID(userID uuid.UUID, dataset string, r transfer.Record) uuid.UUID {
+	if id, err := uuid.Parse(r.Fields["id"]); err == nil {
+		return id
+	}
+	keys := make([]string, 0, len(r.Fields))
+	for k := range r.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteString(dataset)
+	for _, k := range keys {
+		sb.WriteString("\x00" + k + "=" + r.Fields[k])
+	}
+	return uuid.NewSHA1(userID, []byte(sb.String()))
+}
+
+func mealFromRecord(userID uuid.UUID, r transfer.Record) (model.Meal, error) {
+	meal := model.Meal{ID: importID(userID, "meals", r), UserID: userID}
+	var err error
+	if meal.Name, err = r.Required("name"); err != nil {
+		return meal, err
+	}
+	if len(meal.Name) > 100 {
+		return meal, fmt.Errorf("name is too long")
+	}
+	if meal.Calories, err = r.Int("calories", true); err != nil {
+		return meal, err
+	}
+	if meal.Protein, err = r.Float("protein", false); err != nil {
+		return meal, err
+	}
+	if meal.Carbohydrates, err = r.Float("carbohydrates", false); err != nil {
+		return meal, err
+	}
+	if meal.Fats, err = r.Float("fats", false); err != nil {
+		return meal, err
+	}
+	if meal.Calories < 0 || meal.Protein < 0 || meal.Carbohydrates < 0 || meal.Fats < 0 {
+		return meal, fmt.Errorf("numeric values cannot be negative")
+	}
+	if meal.Timestamp, err = r.Time("timestamp"); err != nil {
+		return meal, err
+	}
+	return meal, nil
+}
+
+func waterFromRecord(userID uuid.UUID, r transfer.Record) (model.Water, error) {
+	water := model.Water{ID: importID(userID, "water", r), UserID: userID}
+	var err error
+	if water.VolumeMl, err = r.Float("volume_ml", true); err != nil {
+		return water, err
+	}
+	if water.VolumeMl <= 0 {
+		return water, fmt.Errorf("volume_ml must be greater than 0")
+	}
+	if water.Timestamp, err = r.Time("timestamp"); err != nil {
+		return water, err
+	}
+	return water, nil
+}
+
+// parseRecords converts the records of a file, collecting per-row errors and
+// dropping rows that repeat an ID already seen in the upload.
+func parseRecords[T any](file transfer.File, report *transfer.Report, seen map[uuid.UUID]bool,
+	parse func(transfer.Record) (T, error), id func(T) uuid.UUID) ([]T, transfer.DatasetReport) {
+	summary := transfer.DatasetReport{Dataset: file.Name, Rows: len(file.Records)}
+	var items []T
+	for _, r := range file.Records {
+		item, err := parse(r)
+		if err != nil {
+			summary.Failed++
+			report.Errors = append(report.Errors, transfer.RowError{File: file.Path, Row: r.Row, Error: err.Error()})
+			continue
+		}
+		if seen[id(item)] {
+			summary.Skipped++
+			continue
+		}
+		seen[id(item)] = true
+		items = append(items, item)
+	}
+	return items, summary
+}
+
+// @Summary Export nutrition data
+// @Description Download the user's meals and water entries as a zip of CSV or JSON files
+// @Tags Data
+// @Produce application/zip
+// @Param format query string false "csv (default) or json"
+// @Success 200 {file} file
+// @Router /api/data/export [get]
+// @Security BearerAuth
+func ExportNutritionDataHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+	format, err := transfer.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	meals, err := db.GetMealsByUserID(user_id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve meals", "details": err.Error()})
+		return
+	}
+	waterEntries, err := db.GetWaterIntakeByUserID(user_id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve water intake", "details": err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := transfer.WriteZip(&buf, format, nutritionDatasets(meals, waterEntries)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build export", "details": err.Error()})
+		return
+	}
+
+	filename := "nutrition-" + time.Now().UTC().Format("20060102") + ".zip"
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+func nutritionDatasets(meals []model.Meal, waterEntries []model.Water) []transfer.Dataset {
+	if meals == nil {
+		meals = []model.Meal{}
+	}
+	if waterEntries == nil {
+		waterEntries = []model.Water{}
+	}
+	sort.SliceStable(meals, func(i, j int) bool { return meals[i].Timestamp.Before(meals[j].Timestamp) })
+	sort.SliceStable(waterEntries, func(i, j int) bool { return waterEntries[i].Timestamp.Before(waterEntries[j].Timestamp) })
+
+	mealRows := make([][]string, len(meals))
+	for i, m := range meals {
+		mealRows[i] = []string{
+			m.ID.String(), m.Name, strconv.Itoa(m.Calories), transfer.FormatFloat(m.Protein),
+			transfer.FormatFloat(m.Carbohydrates), transfer.FormatFloat(m.Fats),
+			m.Timestamp.UTC().Format(time.RFC3339),
+		}
+	}
+	waterRows := make([][]string, len(waterEntries))
+	for i, w := range waterEntries {
+		waterRows[i] = []string{w.ID.String(), transfer.FormatFloat(w.VolumeMl), w.Timestamp.UTC().Format(time.RFC3339)}
+	}
+
+	return []transfer.Dataset{
+		{Name: "meals", Columns: mealColumns, Rows: mealRows, Items: meals},
+		{Name: "water", Columns: waterColumns, Rows: waterRows, Items: waterEntries},
+	}
+}
+
+// @Summary Import nutrition data
+// @Description Import meals and water entries from a zip produced by the export endpoint or from a single meals/water .csv or .json file. Invalid rows are reported and skipped; rows that were already imported are not duplicated.
+// @Tags Data
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Zip, CSV or JSON file"
+// @Success 200 {object} transfer.Report
+// @Router /api/data/import [post]
+// @Security BearerAuth
+func ImportNutritionDataHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+	userID := uuid.MustParse(user_id)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Import file is required", "details": err.Error()})
+		return
+	}
+	if fileHeader.Size > MaxImportFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Import file is too large"})
+		return
+	}
+	f, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read import file", "details": err.Error()})
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(f, MaxImportFileSize+1))
+	f.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read import file", "details": err.Error()})
+		return
+	}
+
+	files, err := transfer.ReadFiles(fileHeader.Filename, data)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid import file", "details": err.Error()})
+		return
+	}
+
+	report := transfer.Report{Datasets: []transfer.DatasetReport{}, Errors: []transfer.RowError{}}
+	seen := make(map[uuid.UUID]bool)
+	for _, file := range files {
+		var summary transfer.DatasetReport
+		var inserted int64
+		switch file.Name {
+		case "meals":
+			var meals []model.Meal
+			meals, summary = parseRecords(file, &report, seen,
+				func(r transfer.Record) (model.Meal, error) { return mealFromRecord(userID, r) },
+				func(m model.Meal) uuid.UUID { return m.ID })
+			inserted, err = db.InsertMeals(meals)
+			summary.Skipped += len(meals) - int(inserted)
+		case "water":
+			var entries []model.Water
+			entries, summary = parseRecords(file, &report, seen,
+				func(r transfer.Record) (model.Water, error) { return waterFromRecord(userID, r) },
+				func(w model.Water) uuid.UUID { return w.ID })
+			inserted, err = db.InsertWaterEntries(entries)
+			summary.Skipped += len(entries) - int(inserted)
+		default:
+			report.Errors = append(report.Errors, transfer.RowError{File: file.Path, Error: "unknown dataset, expected meals or water"})
+			continue
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import data", "details": err.Error(), "report": report})
+			return
+		}
+		summary.Imported = int(inserted)
+		report.Datasets = append(report.Datasets, summary)
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/transfer"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestMealFromRecord(t *testing.T) {
+	userID := uuid.New()
+	valid := map[string]string{
+		"name": "Oatmeal", "calories": "350", "protein": "12.5", "carbohydrates": "60",
+		"fats": "7", "timestamp": "2025-06-01T08:00:00Z",
+	}
+
+	meal, err := mealFromRecord(userID, transfer.Record{Row: 1, Fields: valid})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if meal.UserID != userID || meal.Calories != 350 || meal.Protein != 12.5 {
+		t.Errorf("Unexpected meal %+v", meal)
+	}
+
+	tests := []struct {
+		name     string
+		override map[string]string
+		errPart  string
+	}{
+		{name: "Missing name", override: map[string]string{"name": ""}, errPart: "name is required"},
+		{name: "Missing calories", override: map[string]string{"calories": ""}, errPart: "calories is required"},
+		{name: "Negative fats", override: map[string]string{"fats": "-1"}, errPart: "negative"},
+		{name: "Bad timestamp", override: map[string]string{"timestamp": "breakfast"}, errPart: "timestamp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := make(map[string]string)
+			for k, v := range valid {
+				fields[k] = v
+			}
+			for k, v := range tt.override {
+				fields[k] = v
+			}
+			_, err := mealFromRecord(userID, transfer.Record{Fields: fields})
+			if err == nil || !strings.Contains(err.Error(), tt.errPart) {
+				t.Errorf("Expected error containing %q, got %v", tt.errPart, err)
+			}
+		})
+	}
+}
+
+func TestWaterFromRecord(t *testing.T) {
+	userID := uuid.New()
+	if _, err := waterFromRecord(userID, transfer.Record{Fields: map[string]string{"volume_ml": "250", "timestamp": "2025-06-01"}}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if _, err := waterFromRecord(userID, transfer.Record{Fields: map[string]string{"volume_ml": "0", "timestamp": "2025-06-01"}}); err == nil {
+		t.Error("Expected error for zero volume")
+	}
+}
+
+func TestImportIDIsStable(t *testing.T) {
+	userID := uuid.New()
+	fields := map[string]string{"volume_ml": "250", "timestamp": "2025-06-01T08:00:00Z"}
+	if importID(userID, "water", transfer.Record{Row: 1, Fields: fields}) != importID(userID, "water", transfer.Record{Row: 3, Fields: fields}) {
+		t.Error("Expected the same row to map to the same ID")
+	}
+}
+
+func TestImportNutritionDataHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validToken := "Bearer " + generateTestToken(t, uuid.New().String())
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		filename       string
+		content        string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Invalid JWT token",
+			authHeader:     "Bearer invalid.token.here",
+			filename:       "meals.csv",
+			content:        "name\nsalad\n",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Missing file",
+			authHeader:     validToken,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Import file is required",
+		},
+		{
+			name:           "Invalid JSON",
+			authHeader:     validToken,
+			filename:       "water.json",
+			content:        `{"volume_ml": 250}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "Invalid import file",
+		},
+		{
+			name:           "Unknown dataset",
+			authHeader:     validToken,
+			filename:       "activities.csv",
+			content:        "type\nrunning\n",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "unknown dataset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/api/data/import", ImportNutritionDataHandler)
+
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			if tt.filename != "" {
+				part, _ := writer.CreateFormFile("file", tt.filename)
+				part.Write([]byte(tt.content))
+			}
+			writer.Close()
+
+			req := httptest.NewRequest("POST", "/api/data/import", &body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			req.Header.Set("Authorization", tt.authHeader)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain '%s', got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
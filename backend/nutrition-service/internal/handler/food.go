@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/db"
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// @Summary Search the food catalog
+// @Description Search catalog food items by name. An empty query lists the whole catalog.
+// @Tags Food
+// @Produce json
+// @Param q query string false "Name to search for"
+// @Success 200 {object} model.SearchFoodResponse
+// @Router /api/foods [get]
+// @Security BearerAuth
+func SearchFoodHandler(c *gin.Context) {
+	foods, err := db.SearchFood(c.Query("q"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search food items", "details": err.Error()})
+		return
+	}
+	if foods == nil {
+		foods = []model.FoodItem{}
+	}
+	c.JSON(http.StatusOK, model.SearchFoodResponse{Foods: foods})
+}
+
+// @Summary Add a food item
+// @Description Add an item to the food catalog (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param food body model.FoodItemRequest true "Food item"
+// @Success 201 {object} model.FoodItem
+// @Router /api/admin/foods [post]
+// @Security BearerAuth
+func CreateFoodItemHandler(c *gin.Context) {
+	var req model.FoodItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	food, err := db.CreateFoodItem(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create food item", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, food)
+}
+
+// @Summary Update a food item
+// @Description Replace a food catalog item (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Food item ID"
+// @Param food body model.FoodItemRequest true "Food item"
+// @Success 200 {object} model.FoodItem
+// @Router /api/admin/foods/{id} [put]
+// @Security BearerAuth
+func UpdateFoodItemHandler(c *gin.Context) {
+	foodID := c.Param("id")
+	if _, err := uuid.Parse(foodID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid food item ID"})
+		return
+	}
+
+	var req model.FoodItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	food, err := db.UpdateFoodItem(foodID, &req)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Food item not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update food item", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, food)
+}
+
+// @Summary Delete a food item
+// @Description Remove an item from the food catalog (admin only)
+// @Tags Admin
+// @Param id path string true "Food item ID"
+// @Success 204
+// @Router /api/admin/foods/{id} [delete]
+// @Security BearerAuth
+func DeleteFoodItemHandler(c *gin.Context) {
+	foodID := c.Param("id")
+	if _, err := uuid.Parse(foodID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid food item ID"})
+		return
+	}
+
+	err := db.DeleteFoodItem(foodID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Food item not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete food item", "details": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestFoodItemHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/foods", SearchFoodHandler)
+	router.POST("/api/admin/foods", CreateFoodItemHandler)
+	router.PUT("/api/admin/foods/:id", UpdateFoodItemHandler)
+	router.DELETE("/api/admin/foods/:id", DeleteFoodItemHandler)
+
+	foodID := uuid.New().String()
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Search without database",
+			method:         "GET",
+			path:           "/api/foods?q=apple",
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to search food items",
+		},
+		{
+			name:           "Create without name",
+			method:         "POST",
+			path:           "/api/admin/foods",
+			body:           `{"calories":95}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request data",
+		},
+		{
+			name:           "Create with negative calories",
+			method:         "POST",
+			path:           "/api/admin/foods",
+			body:           `{"name":"Apple","calories":-1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request data",
+		},
+		{
+			name:           "Create without database",
+			method:         "POST",
+			path:           "/api/admin/foods",
+			body:           `{"name":"Apple","calories":95}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to create food item",
+		},
+		{
+			name:           "Update with invalid ID",
+			method:         "PUT",
+			path:           "/api/admin/foods/not-a-uuid",
+			body:           `{"name":"Apple","calories":95}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid food item ID",
+		},
+		{
+			name:           "Update without database",
+			method:         "PUT",
+			path:           "/api/admin/foods/" + foodID,
+			body:           `{"name":"Apple","calories":95}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to update food item",
+		},
+		{
+			name:           "Delete with invalid ID",
+			method:         "DELETE",
+			path:           "/api/admin/foods/not-a-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid food item ID",
+		},
+		{
+			name:           "Delete without database",
+			method:         "DELETE",
+			path:           "/api/admin/foods/" + foodID,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to delete food item",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedError) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedError, w.Body.String())
+			}
+		})
+	}
+}
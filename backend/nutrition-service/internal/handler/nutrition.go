@@ -1,14 +1,17 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/ffabious/healthy-summer/nutrition-service/internal/auth"
 	"github.com/ffabious/healthy-summer/nutrition-service/internal/db"
 	"github.com/ffabious/healthy-summer/nutrition-service/internal/model"
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/pagination"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // @Summary Post a new meal
@@ -52,10 +55,16 @@ func PostMealHandler(c *gin.Context) {
 }
 
 // @Summary Get meals for a user
-// @Description Retrieve all meals for a user
+// @Description Retrieve a page of meals for a user
 // @Tags Nutrition
 // @Produce json
-// @Success 200 {array} model.Meal
+// @Param limit query int false "Page size (default: 50, max: 200)"
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Param from query string false "Start of the range, RFC 3339 timestamp or YYYY-MM-DD (inclusive)"
+// @Param to query string false "End of the range, RFC 3339 timestamp or YYYY-MM-DD (inclusive day)"
+// @Param q query string false "Filter by meal name"
+// @Param sort query string false "Sort by timestamp: desc (default) or asc"
+// @Success 200 {object} model.MealPage
 // @Router /api/meals [get]
 // @Security BearerAuth
 func GetMealsHandler(c *gin.Context) {
@@ -65,13 +74,19 @@ func GetMealsHandler(c *gin.Context) {
 		return
 	}
 
-	meals, err := db.GetMealsByUserID(user_id)
+	params, err := pagination.FromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	page, err := db.ListMeals(user_id, c.Query("q"), params)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve meals", "details": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, meals)
+	c.JSON(http.StatusOK, page)
 }
 
 // @Summary Post a new water entry
@@ -134,10 +149,15 @@ func GetNutritionStatsHandler(c *gin.Context) {
 }
 
 // @Summary Get water intake for a user
-// @Description Retrieve all water intake entries for a user
+// @Description Retrieve a page of water intake entries for a user
 // @Tags Nutrition
 // @Produce json
-// @Success 200 {array} model.Water
+// @Param limit query int false "Page size (default: 50, max: 200)"
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Param from query string false "Start of the range, RFC 3339 timestamp or YYYY-MM-DD (inclusive)"
+// @Param to query string false "End of the range, RFC 3339 timestamp or YYYY-MM-DD (inclusive day)"
+// @Param sort query string false "Sort by timestamp: desc (default) or asc"
+// @Success 200 {object} model.WaterPage
 // @Router /api/water [get]
 // @Security BearerAuth
 func GetWaterIntakeHandler(c *gin.Context) {
@@ -147,13 +167,19 @@ func GetWaterIntakeHandler(c *gin.Context) {
 		return
 	}
 
-	waterEntries, err := db.GetWaterIntakeByUserID(user_id)
+	params, err := pagination.FromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	page, err := db.ListWaterEntries(user_id, params)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve water intake", "details": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"water_entries": waterEntries})
+	c.JSON(http.StatusOK, page)
 }
 
 // @Summary Update a meal
@@ -222,6 +248,65 @@ func DeleteMealHandler(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// @Summary Attach an image to a meal
+// @Description Attach an image uploaded through POST /api/uploads to a meal, replacing any previous image
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Param id path string true "Meal ID"
+// @Param image body model.AttachImageRequest true "Uploaded image"
+// @Success 200 {object} model.Meal
+// @Router /api/meals/{id}/image [put]
+// @Security BearerAuth
+func SetMealImageHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	var req model.AttachImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	meal, err := db.SetMealImage(c.Param("id"), user_id, &req.ImageID)
+	respondMealImage(c, meal, err)
+}
+
+// @Summary Remove a meal's image
+// @Description Detach the image from a meal. The upload itself is kept.
+// @Tags Nutrition
+// @Produce json
+// @Param id path string true "Meal ID"
+// @Success 200 {object} model.Meal
+// @Router /api/meals/{id}/image [delete]
+// @Security BearerAuth
+func DeleteMealImageHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	meal, err := db.SetMealImage(c.Param("id"), user_id, nil)
+	respondMealImage(c, meal, err)
+}
+
+func respondMealImage(c *gin.Context, meal *model.Meal, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Meal not found"})
+	case errors.Is(err, db.ErrImageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update meal image", "details": err.Error()})
+	default:
+		c.JSON(http.StatusOK, meal)
+	}
+}
+
 // @Summary Update a water entry
 // @Description Update an existing water entry for a user
 // @Tags Nutrition
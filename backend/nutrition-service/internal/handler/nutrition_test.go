@@ -10,6 +10,7 @@ import (
 
 	"github.com/ffabious/healthy-summer/nutrition-service/internal/model"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
@@ -759,3 +760,118 @@ func TestWaterRequestValidation(t *testing.T) {
 		})
 	}
 }
+
+func generateTestToken(t *testing.T, userID string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": userID})
+	signed, err := token.SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func TestListHandlersInvalidQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	token := "Bearer " + generateTestToken(t, uuid.New().String())
+
+	handlers := map[string]gin.HandlerFunc{
+		"/api/meals": GetMealsHandler,
+		"/api/water": GetWaterIntakeHandler,
+	}
+	for path, h := range handlers {
+		for _, query := range []string{"limit=abc", "cursor=not-a-cursor", "to=06/01/2025", "sort=random"} {
+			t.Run(path+"?"+query, func(t *testing.T) {
+				router := gin.New()
+				router.GET(path, h)
+
+				req := httptest.NewRequest("GET", path+"?"+query, nil)
+				req.Header.Set("Authorization", token)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				if w.Code != http.StatusBadRequest {
+					t.Errorf("Expected status %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+				}
+				if !strings.Contains(w.Body.String(), "Invalid query parameters") {
+					t.Errorf("Unexpected body: %s", w.Body.String())
+				}
+			})
+		}
+	}
+}
+
+func TestMealImageHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	token := "Bearer " + generateTestToken(t, uuid.New().String())
+	mealID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		method         string
+		authHeader     string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Attach without authorization",
+			method:         "PUT",
+			body:           `{"image_id":"` + uuid.New().String() + `"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Unauthorized",
+		},
+		{
+			name:           "Attach without image ID",
+			method:         "PUT",
+			authHeader:     token,
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request data",
+		},
+		{
+			name:           "Attach without database",
+			method:         "PUT",
+			authHeader:     token,
+			body:           `{"image_id":"` + uuid.New().String() + `"}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to update meal image",
+		},
+		{
+			name:           "Remove without authorization",
+			method:         "DELETE",
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Unauthorized",
+		},
+		{
+			name:           "Remove without database",
+			method:         "DELETE",
+			authHeader:     token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to update meal image",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.PUT("/api/meals/:id/image", SetMealImageHandler)
+			router.DELETE("/api/meals/:id/image", DeleteMealImageHandler)
+
+			req := httptest.NewRequest(tt.method, "/api/meals/"+mealID+"/image", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedError) {
+				t.Errorf("Expected error message to contain '%s', got: %s", tt.expectedError, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/auth"
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/db"
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// minHydrationInterval keeps hydration reminders from turning into spam.
+const minHydrationInterval = 15
+
+// bindReminderRequest reads and checks a reminder, writing the error
+// response if it is invalid.
+func bindReminderRequest(c *gin.Context) (*model.ReminderRuleRequest, bool) {
+	var req model.ReminderRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return nil, false
+	}
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid timezone", "details": err.Error()})
+		return nil, false
+	}
+	if req.Type == model.ReminderHydration {
+		if req.IntervalMinutes == 0 {
+			req.IntervalMinutes = model.DefaultHydrationInterval
+		}
+		if req.IntervalMinutes < minHydrationInterval {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Hydration reminders must be at least 15 minutes apart"})
+			return nil, false
+		}
+	}
+	return &req, true
+}
+
+// @Summary List reminders
+// @Description List the reminders configured by the user
+// @Tags Reminders
+// @Produce json
+// @Success 200 {array} model.ReminderRule
+// @Router /api/reminders [get]
+// @Security BearerAuth
+func GetRemindersHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	rules, err := db.GetReminderRulesByUserID(user_id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reminders", "details": err.Error()})
+		return
+	}
+	if rules == nil {
+		rules = []model.ReminderRule{}
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// @Summary Create a reminder
+// @Description Create a reminder, e.g. to drink every 2 hours between 9 and 21 while under 2000 ml, or an evening nudge when no meals were logged. Hours are in the given IANA timezone.
+// @Tags Reminders
+// @Accept json
+// @Produce json
+// @Param reminder body model.ReminderRuleRequest true "Reminder"
+// @Success 201 {object} model.ReminderRule
+// @Router /api/reminders [post]
+// @Security BearerAuth
+func CreateReminderHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	req, ok := bindReminderRequest(c)
+	if !ok {
+		return
+	}
+
+	rule, err := db.CreateReminderRule(user_id, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reminder", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+// @Summary Update a reminder
+// @Description Replace one of the user's reminders
+// @Tags Reminders
+// @Accept json
+// @Produce json
+// @Param id path string true "Reminder ID"
+// @Param reminder body model.ReminderRuleRequest true "Reminder"
+// @Success 200 {object} model.ReminderRule
+// @Router /api/reminders/{id} [put]
+// @Security BearerAuth
+func UpdateReminderHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	ruleID := c.Param("id")
+	if _, err := uuid.Parse(ruleID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reminder ID"})
+		return
+	}
+
+	req, ok := bindReminderRequest(c)
+	if !ok {
+		return
+	}
+
+	rule, err := db.UpdateReminderRule(ruleID, user_id, req)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reminder not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reminder", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// @Summary Delete a reminder
+// @Description Delete one of the user's reminders
+// @Tags Reminders
+// @Param id path string true "Reminder ID"
+// @Success 204
+// @Router /api/reminders/{id} [delete]
+// @Security BearerAuth
+func DeleteReminderHandler(c *gin.Context) {
+	user_id, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	ruleID := c.Param("id")
+	if _, err := uuid.Parse(ruleID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reminder ID"})
+		return
+	}
+
+	err = db.DeleteReminderRule(ruleID, user_id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reminder not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete reminder", "details": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestReminderHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/reminders", GetRemindersHandler)
+	router.POST("/api/reminders", CreateReminderHandler)
+	router.PUT("/api/reminders/:id", UpdateReminderHandler)
+	router.DELETE("/api/reminders/:id", DeleteReminderHandler)
+
+	token := "Bearer " + generateTestToken(t, uuid.New().String())
+	reminderID := uuid.New().String()
+	hydration := `{"type":"hydration","timezone":"Europe/Moscow","start_hour":9,"end_hour":21,"goal_ml":2000}`
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		authHeader     string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "List without authorization",
+			method:         "GET",
+			path:           "/api/reminders",
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Unauthorized",
+		},
+		{
+			name:           "List without database",
+			method:         "GET",
+			path:           "/api/reminders",
+			authHeader:     token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to retrieve reminders",
+		},
+		{
+			name:           "Create with unknown type",
+			method:         "POST",
+			path:           "/api/reminders",
+			authHeader:     token,
+			body:           `{"type":"sleep","timezone":"UTC","start_hour":9,"end_hour":21}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request data",
+		},
+		{
+			name:           "Create with window ending before it starts",
+			method:         "POST",
+			path:           "/api/reminders",
+			authHeader:     token,
+			body:           `{"type":"meal","timezone":"UTC","start_hour":21,"end_hour":9}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request data",
+		},
+		{
+			name:           "Create with unknown timezone",
+			method:         "POST",
+			path:           "/api/reminders",
+			authHeader:     token,
+			body:           `{"type":"meal","timezone":"Mars/Olympus","start_hour":9,"end_hour":21}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid timezone",
+		},
+		{
+			name:           "Create hydration reminder too often",
+			method:         "POST",
+			path:           "/api/reminders",
+			authHeader:     token,
+			body:           `{"type":"hydration","timezone":"UTC","start_hour":9,"end_hour":21,"interval_minutes":5}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "at least 15 minutes apart",
+		},
+		{
+			name:           "Create without database",
+			method:         "POST",
+			path:           "/api/reminders",
+			authHeader:     token,
+			body:           hydration,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to create reminder",
+		},
+		{
+			name:           "Update with invalid ID",
+			method:         "PUT",
+			path:           "/api/reminders/not-a-uuid",
+			authHeader:     token,
+			body:           hydration,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid reminder ID",
+		},
+		{
+			name:           "Update without database",
+			method:         "PUT",
+			path:           "/api/reminders/" + reminderID,
+			authHeader:     token,
+			body:           hydration,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to update reminder",
+		},
+		{
+			name:           "Delete without authorization",
+			method:         "DELETE",
+			path:           "/api/reminders/" + reminderID,
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Unauthorized",
+		},
+		{
+			name:           "Delete with invalid ID",
+			method:         "DELETE",
+			path:           "/api/reminders/not-a-uuid",
+			authHeader:     token,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid reminder ID",
+		},
+		{
+			name:           "Delete without database",
+			method:         "DELETE",
+			path:           "/api/reminders/" + reminderID,
+			authHeader:     token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to delete reminder",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedError) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedError, w.Body.String())
+			}
+		})
+	}
+}
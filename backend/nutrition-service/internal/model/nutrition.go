@@ -15,6 +15,12 @@ type Meal struct {
 	Carbohydrates float64   `json:"carbohydrates" gorm:"not null"`
 	Fats          float64   `json:"fats" gorm:"not null"`
 	Timestamp     time.Time `json:"timestamp" gorm:"not null"`
+
+	// ImageID refers to an upload in user-service; its URLs are copied
+	// when the image is attached.
+	ImageID      *uuid.UUID `json:"image_id,omitempty" gorm:"type:uuid"`
+	ImageURL     string     `json:"image_url,omitempty" gorm:"type:varchar(512)"`
+	ThumbnailURL string     `json:"thumbnail_url,omitempty" gorm:"type:varchar(512)"`
 }
 
 type Water struct {
@@ -24,6 +30,20 @@ type Water struct {
 	Timestamp time.Time `json:"timestamp" gorm:"not null"`
 }
 
+// MealPage is one page of a user's meals.
+type MealPage struct {
+	Meals      []Meal `json:"meals"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// WaterPage is one page of a user's water entries.
+type WaterPage struct {
+	WaterEntries []Water `json:"water_entries"`
+	Total        int64   `json:"total"`
+	NextCursor   string  `json:"next_cursor,omitempty"`
+}
+
 type NutritionStats struct {
 	Today NutritionPeriod `json:"today"`
 	Week  NutritionPeriod `json:"week"`
@@ -48,12 +68,25 @@ type SearchFoodResponse struct {
 	Foods []FoodItem `json:"foods"`
 }
 
+// FoodItem is an entry of the food catalog maintained by admins. Nutrients
+// are per serving.
 type FoodItem struct {
-	Name          string  `json:"name"`
-	Calories      int     `json:"calories"`
-	Protein       float64 `json:"protein"`
-	Carbohydrates float64 `json:"carbohydrates"`
-	Fats          float64 `json:"fats"`
+	ID            uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Name          string    `json:"name" gorm:"type:varchar(100);not null;index"`
+	Calories      int       `json:"calories" gorm:"not null"`
+	Protein       float64   `json:"protein" gorm:"not null"`
+	Carbohydrates float64   `json:"carbohydrates" gorm:"not null"`
+	Fats          float64   `json:"fats" gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type FoodItemRequest struct {
+	Name          string  `json:"name" binding:"required,max=100"`
+	Calories      int     `json:"calories" binding:"min=0"`
+	Protein       float64 `json:"protein" binding:"min=0"`
+	Carbohydrates float64 `json:"carbohydrates" binding:"min=0"`
+	Fats          float64 `json:"fats" binding:"min=0"`
 }
 
 type PostMealRequest struct {
@@ -67,3 +100,17 @@ type PostMealRequest struct {
 type PostWaterRequest struct {
 	VolumeMl float64 `json:"volume_ml" binding:"required,gt=0"`
 }
+
+// AttachImageRequest attaches an image uploaded to user-service.
+type AttachImageRequest struct {
+	ImageID uuid.UUID `json:"image_id" binding:"required"`
+}
+
+// PurgeUserDataResponse reports how many rows were removed from each table
+// when the data of a deleted account is purged.
+// @name PurgeUserDataResponse
+type PurgeUserDataResponse struct {
+	Service string           `json:"service" example:"nutrition-service"`
+	UserID  uuid.UUID        `json:"user_id"`
+	Deleted map[string]int64 `json:"deleted"`
+}
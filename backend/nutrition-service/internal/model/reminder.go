@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reminder types. Hydration reminders repeat every IntervalMinutes while the
+// day's water is under GoalMl; meal and workout reminders are sent at most
+// once a day, when nothing has been logged yet that day.
+const (
+	ReminderHydration = "hydration"
+	ReminderMeal      = "meal"
+	ReminderWorkout   = "workout"
+)
+
+// DefaultHydrationInterval is used when a hydration reminder has no
+// interval.
+const DefaultHydrationInterval = 120
+
+// ReminderRule is a nudge a user asked for. Reminders are only sent between
+// StartHour and EndHour in the user's timezone.
+type ReminderRule struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID          uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Type            string     `json:"type" gorm:"type:varchar(20);not null" example:"hydration"`
+	Enabled         bool       `json:"enabled" gorm:"not null;default:true;index"`
+	Timezone        string     `json:"timezone" gorm:"type:varchar(64);not null" example:"Europe/Moscow"`
+	StartHour       int        `json:"start_hour" gorm:"not null" example:"9"`
+	EndHour         int        `json:"end_hour" gorm:"not null" example:"21"`
+	IntervalMinutes int        `json:"interval_minutes" gorm:"not null;default:0" example:"120"`
+	GoalMl          float64    `json:"goal_ml" gorm:"not null;default:0" example:"2000"`
+	LastSentAt      *time.Time `json:"last_sent_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// ReminderRuleRequest creates or replaces a reminder. EndHour is exclusive,
+// so 9 to 21 covers 9:00 to 20:59. Enabled defaults to true.
+type ReminderRuleRequest struct {
+	Type            string  `json:"type" binding:"required,oneof=hydration meal workout" example:"hydration"`
+	Enabled         *bool   `json:"enabled"`
+	Timezone        string  `json:"timezone" binding:"required,max=64" example:"Europe/Moscow"`
+	StartHour       int     `json:"start_hour" binding:"min=0,max=23" example:"9"`
+	EndHour         int     `json:"end_hour" binding:"required,max=24,gtfield=StartHour" example:"21"`
+	IntervalMinutes int     `json:"interval_minutes" binding:"min=0,max=1440" example:"120"`
+	GoalMl          float64 `json:"goal_ml" binding:"min=0" example:"2000"`
+}
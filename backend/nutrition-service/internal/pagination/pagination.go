@@ -0,0 +1,156 @@
+// Package pagination implements keyset (cursor) pagination with date range
+// filters and sort order for the list endpoints.
+package pagination
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// Cursor identifies the last row of a page by its sort key.
+type Cursor struct {
+	Timestamp time.Time
+	ID        uuid.UUID
+}
+
+// Encode returns the opaque string form of the cursor.
+func (c Cursor) Encode() string {
+	raw := c.Timestamp.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &Cursor{Timestamp: timestamp, ID: parsedID}, nil
+}
+
+// Params are the paging, range and sort options of a list request.
+type Params struct {
+	Limit     int
+	After     *Cursor
+	From      *time.Time // inclusive
+	To        *time.Time // exclusive
+	Ascending bool
+}
+
+// FromQuery reads limit, cursor, from, to and sort from the query string.
+// from and to accept RFC 3339 timestamps or YYYY-MM-DD dates; a date in to
+// includes the whole day.
+func FromQuery(c *gin.Context) (Params, error) {
+	p := Params{Limit: DefaultLimit}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return p, fmt.Errorf("limit must be a positive integer")
+		}
+		p.Limit = min(limit, MaxLimit)
+	}
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := DecodeCursor(raw)
+		if err != nil {
+			return p, err
+		}
+		p.After = cursor
+	}
+	if raw := c.Query("from"); raw != "" {
+		from, _, err := parseTime(raw)
+		if err != nil {
+			return p, fmt.Errorf("from must be an RFC 3339 timestamp or YYYY-MM-DD date")
+		}
+		p.From = &from
+	}
+	if raw := c.Query("to"); raw != "" {
+		to, dateOnly, err := parseTime(raw)
+		if err != nil {
+			return p, fmt.Errorf("to must be an RFC 3339 timestamp or YYYY-MM-DD date")
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		p.To = &to
+	}
+	if p.From != nil && p.To != nil && !p.From.Before(*p.To) {
+		return p, fmt.Errorf("from must be before to")
+	}
+	switch strings.ToLower(c.DefaultQuery("sort", "desc")) {
+	case "desc":
+	case "asc":
+		p.Ascending = true
+	default:
+		return p, fmt.Errorf("sort must be asc or desc")
+	}
+	return p, nil
+}
+
+func parseTime(s string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	return t, true, err
+}
+
+// Filter restricts q to the requested time range on column.
+func (p Params) Filter(q *gorm.DB, column string) *gorm.DB {
+	if p.From != nil {
+		q = q.Where(column+" >= ?", *p.From)
+	}
+	if p.To != nil {
+		q = q.Where(column+" < ?", *p.To)
+	}
+	return q
+}
+
+// Page orders q by column and id, skips rows up to the cursor and fetches
+// one row more than the limit so that Trim can tell whether a next page
+// exists.
+func (p Params) Page(q *gorm.DB, column string) *gorm.DB {
+	op, dir := "<", "DESC"
+	if p.Ascending {
+		op, dir = ">", "ASC"
+	}
+	if p.After != nil {
+		q = q.Where("("+column+" "+op+" ? OR ("+column+" = ? AND id "+op+" ?))",
+			p.After.Timestamp, p.After.Timestamp, p.After.ID)
+	}
+	return q.Order(column + " " + dir).Order("id " + dir).Limit(p.Limit + 1)
+}
+
+// Trim drops the extra row fetched by Page and returns the cursor of the
+// next page, or "" on the last page.
+func Trim[T any](items []T, limit int, key func(T) Cursor) ([]T, string) {
+	if len(items) <= limit {
+		return items, ""
+	}
+	items = items[:limit]
+	return items, key(items[len(items)-1]).Encode()
+}
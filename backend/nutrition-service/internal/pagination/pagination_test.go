@@ -0,0 +1,119 @@
+package pagination
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{
+		Timestamp: time.Date(2025, 6, 1, 7, 30, 0, 123456000, time.UTC),
+		ID:        uuid.New(),
+	}
+	decoded, err := DecodeCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("Failed to decode cursor: %v", err)
+	}
+	if !decoded.Timestamp.Equal(cursor.Timestamp) || decoded.ID != cursor.ID {
+		t.Errorf("Expected %+v, got %+v", cursor, decoded)
+	}
+
+	for _, invalid := range []string{"!!!", "bm90LWEtY3Vyc29y", Cursor{}.Encode()[:10]} {
+		if _, err := DecodeCursor(invalid); err == nil {
+			t.Errorf("Expected error decoding %q, got none", invalid)
+		}
+	}
+}
+
+func TestFromQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		query       string
+		expectError bool
+		check       func(t *testing.T, p Params)
+	}{
+		{
+			name:  "Defaults",
+			query: "",
+			check: func(t *testing.T, p Params) {
+				if p.Limit != DefaultLimit || p.Ascending || p.After != nil || p.From != nil || p.To != nil {
+					t.Errorf("Unexpected defaults %+v", p)
+				}
+			},
+		},
+		{
+			name:  "Limit is capped",
+			query: "limit=1000&sort=asc",
+			check: func(t *testing.T, p Params) {
+				if p.Limit != MaxLimit || !p.Ascending {
+					t.Errorf("Expected capped ascending params, got %+v", p)
+				}
+			},
+		},
+		{
+			name:  "Date-only to includes the whole day",
+			query: "from=2025-06-01&to=2025-06-07",
+			check: func(t *testing.T, p Params) {
+				if !p.From.Equal(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)) {
+					t.Errorf("Unexpected from %v", p.From)
+				}
+				if !p.To.Equal(time.Date(2025, 6, 8, 0, 0, 0, 0, time.UTC)) {
+					t.Errorf("Unexpected to %v", p.To)
+				}
+			},
+		},
+		{
+			name:  "RFC 3339 range",
+			query: "from=2025-06-01T10:00:00Z&to=2025-06-01T12:00:00Z",
+			check: func(t *testing.T, p Params) {
+				if !p.To.Equal(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)) {
+					t.Errorf("Unexpected to %v", p.To)
+				}
+			},
+		},
+		{name: "Zero limit", query: "limit=0", expectError: true},
+		{name: "Invalid cursor", query: "cursor=abc", expectError: true},
+		{name: "Invalid from", query: "from=yesterday", expectError: true},
+		{name: "Reversed range", query: "from=2025-06-07&to=2025-06-01", expectError: true},
+		{name: "Invalid sort", query: "sort=sideways", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/?"+tt.query, nil)
+
+			p, err := FromQuery(c)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tt.check(t, p)
+		})
+	}
+}
+
+func TestTrim(t *testing.T) {
+	key := func(i int) Cursor { return Cursor{ID: uuid.NewSHA1(uuid.Nil, []byte{byte(i)})} }
+
+	items, next := Trim([]int{1, 2, 3}, 3, key)
+	if len(items) != 3 || next != "" {
+		t.Errorf("Expected last page without cursor, got %v %q", items, next)
+	}
+
+	items, next = Trim([]int{1, 2, 3, 4}, 3, key)
+	if len(items) != 3 || next != key(3).Encode() {
+		t.Errorf("Expected 3 items and a cursor at item 3, got %v %q", items, next)
+	}
+}
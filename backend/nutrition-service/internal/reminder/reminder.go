@@ -0,0 +1,152 @@
+// Package reminder sends the hydration, meal logging and workout nudges
+// users configure. Rules are evaluated on a timer against the day's water,
+// meals and activities in each user's timezone, and reminders are delivered
+// as notifications through user-service.
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/db"
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/model"
+)
+
+// Progress is what a user has logged so far today.
+type Progress struct {
+	WaterMl    float64
+	Meals      int64
+	Activities int64
+}
+
+// Due reports whether a rule may fire at now: the local time is inside its
+// hours and it has not fired too recently. It also returns the start of the
+// user's local day, which progress is counted from.
+func Due(rule model.ReminderRule, now time.Time) (dayStart time.Time, due bool, err error) {
+	loc, err := time.LoadLocation(rule.Timezone)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid timezone %q: %w", rule.Timezone, err)
+	}
+	local := now.In(loc)
+	dayStart = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	if hour := local.Hour(); hour < rule.StartHour || hour >= rule.EndHour {
+		return dayStart, false, nil
+	}
+	if rule.LastSentAt == nil {
+		return dayStart, true, nil
+	}
+
+	if rule.Type == model.ReminderHydration {
+		interval := rule.IntervalMinutes
+		if interval <= 0 {
+			interval = model.DefaultHydrationInterval
+		}
+		return dayStart, now.Sub(*rule.LastSentAt) >= time.Duration(interval)*time.Minute, nil
+	}
+	// Meal and workout reminders fire once a day.
+	return dayStart, rule.LastSentAt.Before(dayStart), nil
+}
+
+// Message returns the reminder text for a due rule, or false if the user is
+// already on track and should not be nudged.
+func Message(rule model.ReminderRule, progress Progress) (string, bool) {
+	switch rule.Type {
+	case model.ReminderHydration:
+		if rule.GoalMl > 0 {
+			if progress.WaterMl >= rule.GoalMl {
+				return "", false
+			}
+			return fmt.Sprintf("You've had %.0f of %.0f ml of water today. Time for a glass!", progress.WaterMl, rule.GoalMl), true
+		}
+		return "Time for a glass of water!", true
+	case model.ReminderMeal:
+		if progress.Meals > 0 {
+			return "", false
+		}
+		return "You haven't logged any meals today. Take a minute to add them.", true
+	case model.ReminderWorkout:
+		if progress.Activities > 0 {
+			return "", false
+		}
+		return "No workout logged today yet. How about a short walk?", true
+	}
+	return "", false
+}
+
+// progress loads what the user has logged since dayStart, skipping the
+// queries the rule does not need.
+func progress(rule model.ReminderRule, dayStart time.Time) (Progress, error) {
+	var p Progress
+	var err error
+	userID := rule.UserID.String()
+	switch rule.Type {
+	case model.ReminderHydration:
+		if rule.GoalMl > 0 {
+			p.WaterMl, err = db.GetWaterSince(userID, dayStart)
+		}
+	case model.ReminderMeal:
+		p.Meals, err = db.CountMealsSince(userID, dayStart)
+	case model.ReminderWorkout:
+		p.Activities, err = db.CountActivitiesSince(userID, dayStart)
+	}
+	return p, err
+}
+
+// Scheduler evaluates enabled rules and sends the reminders that are due.
+type Scheduler struct {
+	Notifier Notifier
+}
+
+// RunDue sends every reminder due at now. Failures are logged per rule so
+// that one broken rule does not hold up the others.
+func (s *Scheduler) RunDue(ctx context.Context, now time.Time) {
+	rules, err := db.GetEnabledReminderRules()
+	if err != nil {
+		log.Printf("Failed to load reminders: %v", err)
+		return
+	}
+	for _, rule := range rules {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.run(ctx, rule, now); err != nil {
+			log.Printf("Failed to run %s reminder %s: %v", rule.Type, rule.ID, err)
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, rule model.ReminderRule, now time.Time) error {
+	dayStart, due, err := Due(rule, now)
+	if err != nil || !due {
+		return err
+	}
+	p, err := progress(rule, dayStart)
+	if err != nil {
+		return err
+	}
+	message, ok := Message(rule, p)
+	if !ok {
+		return nil
+	}
+	claimed, err := db.ClaimReminder(&rule, now)
+	if err != nil || !claimed {
+		return err
+	}
+	return s.Notifier.Notify(ctx, rule.UserID.String(), message)
+}
+
+// Start runs the scheduler every interval until ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.RunDue(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package reminder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/auth"
+	"github.com/ffabious/healthy-summer/nutrition-service/internal/model"
+)
+
+func TestDue(t *testing.T) {
+	// 10:30 in Moscow (UTC+3) on 1 July 2025.
+	now := time.Date(2025, 7, 1, 7, 30, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(-d)
+		return &t
+	}
+	hydration := model.ReminderRule{Type: model.ReminderHydration, Timezone: "Europe/Moscow", StartHour: 9, EndHour: 21, IntervalMinutes: 120}
+	meal := model.ReminderRule{Type: model.ReminderMeal, Timezone: "Europe/Moscow", StartHour: 10, EndHour: 22}
+
+	tests := []struct {
+		name string
+		rule model.ReminderRule
+		last *time.Time
+		want bool
+	}{
+		{name: "Never sent", rule: hydration, want: true},
+		{name: "Interval not elapsed", rule: hydration, last: at(90 * time.Minute), want: false},
+		{name: "Interval elapsed", rule: hydration, last: at(2 * time.Hour), want: true},
+		{name: "Before window in local time", rule: model.ReminderRule{Type: model.ReminderHydration, Timezone: "America/New_York", StartHour: 9, EndHour: 21}, want: false},
+		{name: "After window", rule: model.ReminderRule{Type: model.ReminderMeal, Timezone: "Europe/Moscow", StartHour: 6, EndHour: 10}, want: false},
+		{name: "Daily reminder sent yesterday", rule: meal, last: at(20 * time.Hour), want: true},
+		{name: "Daily reminder sent today", rule: meal, last: at(10 * time.Minute), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.LastSentAt = tt.last
+			_, got, err := Due(tt.rule, now)
+			if err != nil {
+				t.Fatalf("Due failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Due = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	dayStart, _, _ := Due(hydration, now)
+	if want := time.Date(2025, 6, 30, 21, 0, 0, 0, time.UTC); !dayStart.Equal(want) {
+		t.Errorf("Expected day to start at %v, got %v", want, dayStart.UTC())
+	}
+	if _, _, err := Due(model.ReminderRule{Timezone: "Mars/Olympus"}, now); err == nil {
+		t.Error("Expected error for an unknown timezone")
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     model.ReminderRule
+		progress Progress
+		want     bool
+	}{
+		{name: "Hydration under goal", rule: model.ReminderRule{Type: model.ReminderHydration, GoalMl: 2000}, progress: Progress{WaterMl: 500}, want: true},
+		{name: "Hydration goal reached", rule: model.ReminderRule{Type: model.ReminderHydration, GoalMl: 2000}, progress: Progress{WaterMl: 2000}, want: false},
+		{name: "Hydration without goal", rule: model.ReminderRule{Type: model.ReminderHydration}, progress: Progress{WaterMl: 5000}, want: true},
+		{name: "No meals logged", rule: model.ReminderRule{Type: model.ReminderMeal}, want: true},
+		{name: "Meal logged", rule: model.ReminderRule{Type: model.ReminderMeal}, progress: Progress{Meals: 1}, want: false},
+		{name: "No workout logged", rule: model.ReminderRule{Type: model.ReminderWorkout}, progress: Progress{Meals: 3}, want: true},
+		{name: "Workout logged", rule: model.ReminderRule{Type: model.ReminderWorkout}, progress: Progress{Activities: 1}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, got := Message(tt.rule, tt.progress)
+			if got != tt.want {
+				t.Errorf("Message = %v, want %v", got, tt.want)
+			}
+			if got && message == "" {
+				t.Error("Expected a message")
+			}
+		})
+	}
+
+	message, _ := Message(model.ReminderRule{Type: model.ReminderHydration, GoalMl: 2000}, Progress{WaterMl: 750})
+	if want := "You've had 750 of 2000 ml of water today. Time for a glass!"; message != want {
+		t.Errorf("Expected %q, got %q", want, message)
+	}
+}
+
+func TestClientNotify(t *testing.T) {
+	var path, token string
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, token = r.URL.Path, r.Header.Get(auth.InternalTokenHeader)
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["message"] == "fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := &Client{URL: server.URL + "/", Token: "internal-secret", Client: server.Client()}
+	if err := client.Notify(context.Background(), "user-1", "Drink water"); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if path != "/internal/users/user-1/notifications" || token != "internal-secret" {
+		t.Errorf("Unexpected request to %s with token %q", path, token)
+	}
+	if body["type"] != "reminder" || body["message"] != "Drink water" {
+		t.Errorf("Unexpected body %v", body)
+	}
+	if err := client.Notify(context.Background(), "user-1", "fail"); err == nil {
+		t.Error("Expected error for a failed request")
+	}
+}
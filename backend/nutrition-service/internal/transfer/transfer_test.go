@@ -0,0 +1,158 @@
+package transfer
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Format
+		wantErr  bool
+	}{
+		{input: "", expected: FormatCSV},
+		{input: "CSV", expected: FormatCSV},
+		{input: "json", expected: FormatJSON},
+		{input: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.expected {
+			t.Errorf("ParseFormat(%q) = %q, %v", tt.input, got, err)
+		}
+	}
+}
+
+func TestZipRoundTrip(t *testing.T) {
+	type item struct {
+		Name  string  `json:"name"`
+		Value float64 `json:"value"`
+	}
+	datasets := []Dataset{
+		{
+			Name:    "things",
+			Columns: []string{"name", "value"},
+			Rows:    [][]string{{"a, with comma", "1.5"}, {"b", "2"}},
+			Items:   []item{{"a, with comma", 1.5}, {"b", 2}},
+		},
+		{Name: "empty", Columns: []string{"id"}, Rows: [][]string{}, Items: []item{}},
+	}
+
+	for _, format := range []Format{FormatCSV, FormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteZip(&buf, format, datasets); err != nil {
+				t.Fatalf("WriteZip failed: %v", err)
+			}
+			files, err := ReadFiles("export.zip", buf.Bytes())
+			if err != nil {
+				t.Fatalf("ReadFiles failed: %v", err)
+			}
+			if len(files) != 2 || files[0].Name != "things" || files[1].Name != "empty" {
+				t.Fatalf("Unexpected files %+v", files)
+			}
+			records := files[0].Records
+			if len(records) != 2 {
+				t.Fatalf("Expected 2 records, got %d", len(records))
+			}
+			if records[0].Row != 1 || records[0].Fields["name"] != "a, with comma" || records[0].Fields["value"] != "1.5" {
+				t.Errorf("Unexpected first record %+v", records[0])
+			}
+			if len(files[1].Records) != 0 {
+				t.Errorf("Expected no records in empty dataset, got %d", len(files[1].Records))
+			}
+		})
+	}
+}
+
+func TestReadFilesSingleFile(t *testing.T) {
+	csvData := "\xef\xbb\xbfType, Duration_Min\nrunning,30\ncycling\n"
+	files, err := ReadFiles("Activities.CSV", []byte(csvData))
+	if err != nil {
+		t.Fatalf("ReadFiles failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Name != "activities" {
+		t.Fatalf("Unexpected files %+v", files)
+	}
+	records := files[0].Records
+	if len(records) != 2 || records[0].Fields["duration_min"] != "30" || records[1].Fields["type"] != "cycling" {
+		t.Errorf("Unexpected records %+v", records)
+	}
+	if _, ok := records[1].Fields["duration_min"]; ok {
+		t.Error("Expected missing trailing column to be absent")
+	}
+
+	files, err = ReadFiles("steps.json", []byte(`[{"date":"2025-06-01","steps":12000,"note":null}]`))
+	if err != nil {
+		t.Fatalf("ReadFiles failed: %v", err)
+	}
+	if got := files[0].Records[0].Fields; got["steps"] != "12000" || got["date"] != "2025-06-01" {
+		t.Errorf("Unexpected JSON fields %+v", got)
+	}
+}
+
+func TestReadFilesErrors(t *testing.T) {
+	var emptyZip bytes.Buffer
+	zw := zip.NewWriter(&emptyZip)
+	w, _ := zw.Create("readme.txt")
+	w.Write([]byte("hello"))
+	zw.Close()
+
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		errPart  string
+	}{
+		{name: "Unsupported extension", filename: "data.xml", data: []byte("<a/>"), errPart: "must be"},
+		{name: "Invalid JSON", filename: "steps.json", data: []byte(`{"steps":1}`), errPart: "JSON array"},
+		{name: "Zip without data files", filename: "export.zip", data: emptyZip.Bytes(), errPart: "no csv or json"},
+		{name: "Broken CSV", filename: "steps.csv", data: []byte("date,steps\n\"2025-06-01,1\n"), errPart: "invalid csv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadFiles(tt.filename, tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.errPart) {
+				t.Errorf("Expected error containing %q, got %v", tt.errPart, err)
+			}
+		})
+	}
+}
+
+func TestRecordFields(t *testing.T) {
+	r := Record{Fields: map[string]string{
+		"int": "30", "float_int": "30.0", "fraction": "30.5", "text": "abc",
+		"ts": "2025-06-01T07:00:00Z", "date": "2025-06-01",
+	}}
+
+	if v, err := r.Int("int", true); err != nil || v != 30 {
+		t.Errorf("Int(int) = %d, %v", v, err)
+	}
+	if v, err := r.Int("float_int", true); err != nil || v != 30 {
+		t.Errorf("Int(float_int) = %d, %v", v, err)
+	}
+	if _, err := r.Int("fraction", true); err == nil {
+		t.Error("Expected error for fractional integer")
+	}
+	if v, err := r.Int("missing", false); err != nil || v != 0 {
+		t.Errorf("Int(missing, optional) = %d, %v", v, err)
+	}
+	if _, err := r.Int("missing", true); err == nil {
+		t.Error("Expected error for missing required integer")
+	}
+	if _, err := r.Float("text", false); err == nil {
+		t.Error("Expected error for non-numeric float")
+	}
+	if _, err := r.Time("ts"); err != nil {
+		t.Errorf("Time(ts) failed: %v", err)
+	}
+	if _, err := r.Time("date"); err != nil {
+		t.Errorf("Time(date) failed: %v", err)
+	}
+	if _, err := r.Time("text"); err == nil {
+		t.Error("Expected error for invalid time")
+	}
+}
@@ -1,15 +1,21 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net"
 	"os"
+	"time"
 
 	"github.com/ffabious/healthy-summer/social-service/internal/db"
 	"github.com/ffabious/healthy-summer/social-service/internal/auth"
 	"github.com/ffabious/healthy-summer/social-service/internal/handler"
+	"github.com/ffabious/healthy-summer/social-service/internal/notification"
+	pb "github.com/ffabious/healthy-summer/social-service/proto"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 
 	_ "github.com/ffabious/healthy-summer/social-service/docs"
 	swaggerFiles "github.com/swaggo/files"
@@ -19,11 +25,43 @@ import (
 func main() {
 	// Connect to database
 	db.Connect()
+	// Reject tokens revoked by user-service
+	auth.SessionValidator = db.ValidateSession
+	handler.Messaging.Notifier = notification.NewNotifierFromEnv()
+
+	// Push updates to clients connected to /api/events
+	go handler.Watcher.Start(context.Background(), 5*time.Second)
+
+	// Start gRPC server
+	go startGRPCServer()
 
 	// Start HTTP server
 	startHTTPServer()
 }
 
+func startGRPCServer() {
+	port := os.Getenv("GRPC_PORT")
+	if port == "" {
+		port = "50051"
+	}
+
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Fatalf("Failed to listen on :%s: %v", port, err)
+	}
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(auth.UnaryInterceptor()),
+		grpc.StreamInterceptor(auth.StreamInterceptor()),
+	)
+	// The REST messaging routes share this implementation.
+	pb.RegisterMessagingServiceServer(s, handler.Messaging)
+
+	log.Printf("Starting gRPC server on :%s", port)
+	if err := s.Serve(lis); err != nil {
+		log.Fatalf("Failed to start gRPC server: %v", err)
+	}
+}
+
 func startHTTPServer() {
 	r := gin.Default()
 
@@ -42,6 +80,37 @@ func startHTTPServer() {
 	{
 		// Feed routes
 		api.GET("/feed", handler.GetFeed)
+
+		// Real-time updates
+		api.GET("/events", handler.StreamEvents)
+
+		// Messaging routes
+		api.POST("/messages", handler.SendMessage)
+		api.POST("/messages/read", handler.MarkMessagesAsRead)
+		api.GET("/messages/stream", handler.StreamMessages)
+		api.GET("/conversations", handler.GetConversations)
+		api.POST("/conversations", handler.CreateConversation)
+		api.GET("/conversations/:id/messages", handler.GetMessages)
+		api.POST("/conversations/:id/read", handler.MarkConversationAsRead)
+		api.POST("/conversations/:id/members", handler.AddConversationMembers)
+		api.DELETE("/conversations/:id/members/me", handler.LeaveConversation)
+		api.POST("/conversations/:id/typing", handler.SendTyping)
+
+		// Report routes
+		api.POST("/reports", handler.CreateReport)
+	}
+
+	// Moderation routes
+	admin := r.Group("/api/admin", auth.JWTMiddleware(), auth.RequireRole(auth.RoleModerator, auth.RoleAdmin))
+	{
+		admin.GET("/reports", handler.ListReports)
+		admin.POST("/reports/:id/review", handler.ReviewReport)
+	}
+
+	// Service-to-service routes
+	internal := r.Group("/internal", auth.InternalMiddleware())
+	{
+		internal.DELETE("/users/:user_id", handler.PurgeUserData)
 	}
 
 	port := os.Getenv("PORT")
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type userIDKey struct{}
+
+// ContextWithUserID returns a copy of ctx carrying the authenticated user.
+// The gRPC interceptors use it, and so can other transports that call the
+// messaging service directly.
+func ContextWithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext returns the user stored by ContextWithUserID.
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userIDKey{}).(string)
+	return userID, ok && userID != ""
+}
+
+// authenticate verifies the bearer token in the "authorization" metadata,
+// the gRPC counterpart of JWTMiddleware.
+func authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || !strings.HasPrefix(values[0], "Bearer ") {
+		return nil, status.Error(codes.Unauthenticated, "missing or invalid token")
+	}
+	token, err := parseToken(strings.TrimPrefix(values[0], "Bearer "))
+	if err != nil || !token.Valid {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid claims")
+	}
+	userID, ok := claims["user_id"].(string)
+	if !ok || userID == "" {
+		return nil, status.Error(codes.Unauthenticated, "invalid claims")
+	}
+	if SessionValidator != nil {
+		version, _ := claims["ver"].(float64)
+		if err := SessionValidator(userID, int(version)); err != nil {
+			return nil, status.Error(codes.Unauthenticated, "session expired")
+		}
+	}
+	return ContextWithUserID(ctx, userID), nil
+}
+
+// UnaryInterceptor rejects unary calls without a valid access token.
+func UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// StreamInterceptor rejects streaming calls without a valid access token.
+func StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
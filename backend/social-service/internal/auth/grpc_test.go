@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryInterceptor(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	originalValidator := SessionValidator
+	defer func() { SessionValidator = originalValidator }()
+	SessionValidator = func(userID string, tokenVersion int) error {
+		if tokenVersion != 2 {
+			return fmt.Errorf("stale token version %d", tokenVersion)
+		}
+		return nil
+	}
+
+	sign := func(claims jwt.MapClaims, secret string) string {
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		return "Bearer " + token
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		expectedCode  codes.Code
+	}{
+		{"Valid token", sign(jwt.MapClaims{"user_id": "user123", "ver": 2}, "test-secret"), codes.OK},
+		{"Missing token", "", codes.Unauthenticated},
+		{"Wrong secret", sign(jwt.MapClaims{"user_id": "user123", "ver": 2}, "other-secret"), codes.Unauthenticated},
+		{"Missing user ID", sign(jwt.MapClaims{"ver": 2}, "test-secret"), codes.Unauthenticated},
+		{"Revoked version", sign(jwt.MapClaims{"user_id": "user123", "ver": 1}, "test-secret"), codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.authorization))
+			}
+			var userID string
+			_, err := UnaryInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+				userID, _ = UserIDFromContext(ctx)
+				return nil, nil
+			})
+			if code := status.Code(err); code != tt.expectedCode {
+				t.Fatalf("Expected code %v, got %v", tt.expectedCode, code)
+			}
+			if tt.expectedCode == codes.OK && userID != "user123" {
+				t.Errorf("Expected user123 in the context, got %q", userID)
+			}
+		})
+	}
+}
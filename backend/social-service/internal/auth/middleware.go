@@ -10,6 +10,23 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// SessionValidator, when set, is called by JWTMiddleware with the user ID and
+// token version from the claims. user-service bumps the version when a user
+// changes their password or email, is suspended or gets a new role, so
+// tokens issued before are rejected here too even though they have not
+// expired.
+var SessionValidator func(userID string, tokenVersion int) error
+
+// parseToken verifies an access token signed with JWT_SECRET.
+func parseToken(tokenStr string) (*jwt.Token, error) {
+	return jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+}
+
 func JWTMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -19,13 +36,7 @@ func JWTMiddleware() gin.HandlerFunc {
 		}
 
 		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method")
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-
+		token, err := parseToken(tokenStr)
 		if err != nil || !token.Valid {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
@@ -37,7 +48,17 @@ func JWTMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if SessionValidator != nil {
+			userID, _ := claims["user_id"].(string)
+			version, _ := claims["ver"].(float64)
+			if err := SessionValidator(userID, int(version)); err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session expired", "details": err.Error()})
+				return
+			}
+		}
+
 		c.Set("user_id", claims["user_id"])
+		c.Set("role", roleFromClaims(claims))
 		c.Next()
 	}
 }
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"fmt"
+)
+
+// IsBlocked reports whether either user has blocked the other, using the
+// blocks table owned by user-service. Messaging checks it before delivering
+// a message so that blocked users cannot reach the user who blocked them.
+func IsBlocked(userID1, userID2 string) (bool, error) {
+	if DB == nil {
+		return false, fmt.Errorf("database connection is nil")
+	}
+	var count int64
+	if err := DB.Table("blocks").Where(
+		"(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)",
+		userID1, userID2, userID2, userID1,
+	).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check blocks: %w", err)
+	}
+	return count > 0, nil
+}
@@ -53,6 +53,10 @@ func Connect() {
 		log.Printf("Failed to create uuid-ossp extension (might already exist): %v", err)
 	}
 
+	if err := DB.AutoMigrate(&model.Report{}, &model.Conversation{}, &model.ConversationMember{}, &model.Message{}); err != nil {
+		log.Fatalf("Failed to migrate database models: %v", err)
+	}
+
 	log.Println("Database connected successfully")
 
 }
@@ -77,12 +81,16 @@ func GetFeedByFriends(userID string, friends []model.Friend) (model.Feed, error)
 		Total  float64
 	}
 
+	// Friends who hide their profile or nutrition from others are left out.
+	// Missing privacy settings fall back to user-service's defaults.
 	var results []WaterResult
 	err := DB.Table("waters").
-		Select("user_id, SUM(amount) as total").
-		Where("user_id IN ?", friendIDs).
-		Group("user_id").
-		Having("SUM(amount) >= ?", 2000).
+		Select("waters.user_id, SUM(waters.amount) as total").
+		Joins("LEFT JOIN privacy_settings ps ON ps.user_id = waters.user_id").
+		Where("waters.user_id IN ?", friendIDs).
+		Where("COALESCE(ps.profile_visibility, 'friends') <> 'private' AND COALESCE(ps.share_nutrition, TRUE)").
+		Group("waters.user_id").
+		Having("SUM(waters.amount) >= ?", 2000).
 		Scan(&results).Error
 	if err != nil {
 		return feed, err
@@ -97,3 +105,72 @@ func GetFeedByFriends(userID string, friends []model.Friend) (model.Feed, error)
 
 	return feed, nil
 }
+
+// PurgeUserData removes a user from the social graph: friendships in either
+// direction, friend requests they sent or received, blocks in either
+// direction, reports they filed, their direct conversations, the messages
+// they sent and their group memberships.
+// Reports about the user are kept for moderators.
+func PurgeUserData(userID string) (map[string]int64, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	deleted := make(map[string]int64)
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		friends := tx.Exec("DELETE FROM friends WHERE user_id = ? OR friend_id = ?", userID, userID)
+		if friends.Error != nil {
+			return friends.Error
+		}
+		deleted["friends"] = friends.RowsAffected
+
+		requests := tx.Exec("DELETE FROM friend_requests WHERE sender_id = ? OR receiver_id = ?", userID, userID)
+		if requests.Error != nil {
+			return requests.Error
+		}
+		deleted["friend_requests"] = requests.RowsAffected
+
+		blocks := tx.Exec("DELETE FROM blocks WHERE blocker_id = ? OR blocked_id = ?", userID, userID)
+		if blocks.Error != nil {
+			return blocks.Error
+		}
+		deleted["blocks"] = blocks.RowsAffected
+
+		reports := tx.Where("reporter_id = ?", userID).Delete(&model.Report{})
+		if reports.Error != nil {
+			return reports.Error
+		}
+		deleted["reports"] = reports.RowsAffected
+
+		// Direct conversations are useless with one side gone.
+		direct := tx.Model(&model.Conversation{}).Select("id").
+			Where("type = ? AND direct_key LIKE ?", model.ConversationDirect, "%"+userID+"%")
+		if err := tx.Where("conversation_id IN (?)", direct).Delete(&model.Message{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("conversation_id IN (?)", direct).Delete(&model.ConversationMember{}).Error; err != nil {
+			return err
+		}
+		conversations := tx.Where("type = ? AND direct_key LIKE ?", model.ConversationDirect, "%"+userID+"%").Delete(&model.Conversation{})
+		if conversations.Error != nil {
+			return conversations.Error
+		}
+		deleted["conversations"] = conversations.RowsAffected
+
+		messages := tx.Where("sender_id = ?", userID).Delete(&model.Message{})
+		if messages.Error != nil {
+			return messages.Error
+		}
+		deleted["messages"] = messages.RowsAffected
+
+		members := tx.Where("user_id = ?", userID).Delete(&model.ConversationMember{})
+		if members.Error != nil {
+			return members.Error
+		}
+		deleted["conversation_members"] = members.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge data for user %s: %w", userID, err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/model"
+)
+
+// GetPendingFriendRequests returns the pending friend requests a user sent or
+// received, oldest first, from user-service's friend_requests table.
+func GetPendingFriendRequests(userID string) ([]model.FriendRequest, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	requests := []model.FriendRequest{}
+	if err := DB.Table("friend_requests").
+		Select("id, sender_id, receiver_id, status, created_at").
+		Where("status = 'pending' AND (sender_id = ? OR receiver_id = ?)", userID, userID).
+		Order("created_at ASC, id ASC").
+		Scan(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to get friend requests: %w", err)
+	}
+	return requests, nil
+}
+
+// CountUnreadNotifications counts a user's unread notifications in
+// user-service's notifications table.
+func CountUnreadNotifications(userID string) (int64, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database connection is nil")
+	}
+	var count int64
+	if err := DB.Table("notifications").
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,299 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// directKey identifies the direct conversation of two users regardless of
+// who started it.
+func directKey(a, b uuid.UUID) string {
+	if a.String() > b.String() {
+		a, b = b, a
+	}
+	return a.String() + ":" + b.String()
+}
+
+func addMembers(tx *gorm.DB, conversationID uuid.UUID, userIDs []uuid.UUID, now time.Time) error {
+	members := make([]model.ConversationMember, 0, len(userIDs))
+	for _, userID := range userIDs {
+		members = append(members, model.ConversationMember{ConversationID: conversationID, UserID: userID, JoinedAt: now})
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error
+}
+
+// GetOrCreateDirectConversation returns the direct conversation of two
+// users, creating it on first use.
+func GetOrCreateDirectConversation(userID, otherID uuid.UUID) (*model.Conversation, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	key := directKey(userID, otherID)
+	var conversation model.Conversation
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		candidate := model.Conversation{ID: uuid.New(), Type: model.ConversationDirect, DirectKey: &key, CreatedBy: userID, CreatedAt: now}
+		created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&candidate)
+		if created.Error != nil {
+			return created.Error
+		}
+		if created.RowsAffected == 1 {
+			conversation = candidate
+			return addMembers(tx, conversation.ID, []uuid.UUID{userID, otherID}, now)
+		}
+		return tx.Where("direct_key = ?", key).First(&conversation).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get direct conversation: %w", err)
+	}
+	conversation.MemberIDs = []uuid.UUID{userID, otherID}
+	return &conversation, nil
+}
+
+// CreateGroupConversation starts a group with the creator and memberIDs.
+func CreateGroupConversation(creatorID uuid.UUID, title string, memberIDs []uuid.UUID) (*model.Conversation, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	now := time.Now()
+	conversation := model.Conversation{ID: uuid.New(), Type: model.ConversationGroup, Title: title, CreatedBy: creatorID, CreatedAt: now}
+	members := append([]uuid.UUID{creatorID}, memberIDs...)
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&conversation).Error; err != nil {
+			return err
+		}
+		return addMembers(tx, conversation.ID, members, now)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversation: %w", err)
+	}
+	return GetConversation(conversation.ID)
+}
+
+// GetConversation returns a conversation with its member IDs. It wraps
+// gorm.ErrRecordNotFound if there is no such conversation.
+func GetConversation(conversationID uuid.UUID) (*model.Conversation, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var conversation model.Conversation
+	if err := DB.First(&conversation, "id = ?", conversationID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get conversation %s: %w", conversationID, err)
+	}
+	members, err := GetConversationMembers(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		conversation.MemberIDs = append(conversation.MemberIDs, m.UserID)
+	}
+	return &conversation, nil
+}
+
+// GetConversationMembers returns the members of a conversation in the order
+// they joined.
+func GetConversationMembers(conversationID uuid.UUID) ([]model.ConversationMember, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var members []model.ConversationMember
+	if err := DB.Where("conversation_id = ?", conversationID).Order("joined_at ASC, user_id ASC").Find(&members).Error; err != nil {
+		return nil, fmt.Errorf("failed to get members of conversation %s: %w", conversationID, err)
+	}
+	return members, nil
+}
+
+// AddConversationMembers adds users to a conversation. Users who already
+// are members are skipped.
+func AddConversationMembers(conversationID uuid.UUID, userIDs []uuid.UUID) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	if err := addMembers(DB, conversationID, userIDs, time.Now()); err != nil {
+		return fmt.Errorf("failed to add members to conversation %s: %w", conversationID, err)
+	}
+	return nil
+}
+
+// RemoveConversationMember removes a user from a conversation. It wraps
+// gorm.ErrRecordNotFound if the user is not a member.
+func RemoveConversationMember(conversationID, userID uuid.UUID) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	result := DB.Where("conversation_id = ? AND user_id = ?", conversationID, userID).Delete(&model.ConversationMember{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove member from conversation %s: %w", conversationID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user %s is not a member of conversation %s: %w", userID, conversationID, gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// CreateMessage stores a message and moves the conversation to the top of
+// its members' lists. The sender has read everything up to their own
+// message.
+func CreateMessage(conversationID, senderID uuid.UUID, content, messageType string) (*model.Message, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	message := model.Message{
+		ID:             uuid.New(),
+		ConversationID: conversationID,
+		SenderID:       senderID,
+		Content:        content,
+		MessageType:    messageType,
+		CreatedAt:      time.Now(),
+	}
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&message).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Conversation{}).Where("id = ?", conversationID).
+			Update("last_message_at", message.CreatedAt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.ConversationMember{}).
+			Where("conversation_id = ? AND user_id = ?", conversationID, senderID).
+			Update("last_read_at", message.CreatedAt).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create message: %w", err)
+	}
+	return &message, nil
+}
+
+// GetMessages returns up to limit messages of a conversation, newest first,
+// starting after beforeID if given. The second result reports whether
+// older messages remain.
+func GetMessages(conversationID uuid.UUID, beforeID *uuid.UUID, limit int) ([]model.Message, bool, error) {
+	if DB == nil {
+		return nil, false, fmt.Errorf("database connection is nil")
+	}
+	query := DB.Where("conversation_id = ?", conversationID)
+	if beforeID != nil {
+		var before model.Message
+		if err := DB.Select("id, created_at").Where("id = ? AND conversation_id = ?", *beforeID, conversationID).First(&before).Error; err != nil {
+			return nil, false, fmt.Errorf("failed to find message %s: %w", *beforeID, err)
+		}
+		query = query.Where("(created_at < ?) OR (created_at = ? AND id < ?)", before.CreatedAt, before.CreatedAt, before.ID)
+	}
+	messages := []model.Message{}
+	if err := query.Order("created_at DESC, id DESC").Limit(limit + 1).Find(&messages).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to get messages of conversation %s: %w", conversationID, err)
+	}
+	if len(messages) > limit {
+		return messages[:limit], true, nil
+	}
+	return messages, false, nil
+}
+
+// GetMessagesByIDs returns the messages with the given IDs.
+func GetMessagesByIDs(ids []uuid.UUID) ([]model.Message, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var messages []model.Message
+	if err := DB.Where("id IN ?", ids).Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get messages: %w", err)
+	}
+	return messages, nil
+}
+
+// MarkConversationRead moves a member's read marker forward to until. It
+// reports whether the marker moved; it never moves back.
+func MarkConversationRead(conversationID, userID uuid.UUID, until time.Time) (bool, error) {
+	if DB == nil {
+		return false, fmt.Errorf("database connection is nil")
+	}
+	result := DB.Model(&model.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ? AND (last_read_at IS NULL OR last_read_at < ?)", conversationID, userID, until).
+		Update("last_read_at", until)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to mark conversation %s as read: %w", conversationID, result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetConversations lists a user's conversations, most recently active
+// first, with their last message and how many messages the user has not
+// read. It also returns the total number of conversations.
+func GetConversations(userID uuid.UUID, limit, offset int) ([]model.ConversationSummary, int64, error) {
+	if DB == nil {
+		return nil, 0, fmt.Errorf("database connection is nil")
+	}
+	mine := DB.Table("conversations c").
+		Joins("JOIN conversation_members cm ON cm.conversation_id = c.id AND cm.user_id = ?", userID).
+		Session(&gorm.Session{})
+	var total int64
+	if err := mine.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count conversations: %w", err)
+	}
+
+	var rows []model.Conversation
+	if err := mine.Select("c.*").
+		Order("COALESCE(c.last_message_at, c.created_at) DESC, c.id").
+		Limit(limit).Offset(offset).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list conversations: %w", err)
+	}
+	summaries := make([]model.ConversationSummary, 0, len(rows))
+	if len(rows) == 0 {
+		return summaries, total, nil
+	}
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+
+	var members []model.ConversationMember
+	if err := DB.Where("conversation_id IN ?", ids).Order("joined_at ASC, user_id ASC").Find(&members).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get conversation members: %w", err)
+	}
+	var lastMessages []model.Message
+	if err := DB.Raw(`SELECT DISTINCT ON (conversation_id) * FROM messages
+		WHERE conversation_id IN ? ORDER BY conversation_id, created_at DESC, id DESC`, ids).
+		Scan(&lastMessages).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get last messages: %w", err)
+	}
+	var unread []struct {
+		ConversationID uuid.UUID
+		Count          int64
+	}
+	if err := DB.Table("messages m").
+		Select("m.conversation_id, COUNT(*) AS count").
+		Joins("JOIN conversation_members cm ON cm.conversation_id = m.conversation_id AND cm.user_id = ?", userID).
+		Where("m.conversation_id IN ? AND m.sender_id <> ? AND (cm.last_read_at IS NULL OR m.created_at > cm.last_read_at)", ids, userID).
+		Group("m.conversation_id").
+		Scan(&unread).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+
+	for _, row := range rows {
+		summary := model.ConversationSummary{Conversation: row}
+		for _, m := range members {
+			if m.ConversationID == row.ID {
+				summary.Conversation.MemberIDs = append(summary.Conversation.MemberIDs, m.UserID)
+				summary.Members = append(summary.Members, m)
+			}
+		}
+		for i := range lastMessages {
+			if lastMessages[i].ConversationID == row.ID {
+				summary.LastMessage = &lastMessages[i]
+			}
+		}
+		for _, u := range unread {
+			if u.ConversationID == row.ID {
+				summary.UnreadCount = u.Count
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, total, nil
+}
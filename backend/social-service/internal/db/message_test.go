@@ -0,0 +1,179 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupMessagingDB points DB at an in-memory SQLite database with the
+// messaging tables, created by hand since AutoMigrate relies on
+// Postgres-only defaults.
+func setupMessagingDB(t *testing.T) {
+	t.Helper()
+	originalDB := DB
+	t.Cleanup(func() { DB = originalDB })
+
+	var err error
+	DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to setup test database: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE conversations (id TEXT PRIMARY KEY, type TEXT, direct_key TEXT UNIQUE, title TEXT,
+			created_by TEXT, created_at DATETIME, last_message_at DATETIME)`,
+		`CREATE TABLE conversation_members (conversation_id TEXT, user_id TEXT, joined_at DATETIME,
+			last_read_at DATETIME, PRIMARY KEY (conversation_id, user_id))`,
+		`CREATE TABLE messages (id TEXT PRIMARY KEY, conversation_id TEXT, sender_id TEXT, content TEXT,
+			message_type TEXT, created_at DATETIME)`,
+	} {
+		if err := DB.Exec(stmt).Error; err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+}
+
+func TestMessagingFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	id := uuid.New()
+	if _, err := GetOrCreateDirectConversation(id, uuid.New()); err == nil {
+		t.Error("Expected error from GetOrCreateDirectConversation with nil database, got none")
+	}
+	if _, err := GetConversation(id); err == nil {
+		t.Error("Expected error from GetConversation with nil database, got none")
+	}
+	if _, err := CreateMessage(id, id, "hi", model.MessageText); err == nil {
+		t.Error("Expected error from CreateMessage with nil database, got none")
+	}
+	if _, _, err := GetMessages(id, nil, 10); err == nil {
+		t.Error("Expected error from GetMessages with nil database, got none")
+	}
+	if _, _, err := GetConversations(id, 10, 0); err == nil {
+		t.Error("Expected error from GetConversations with nil database, got none")
+	}
+}
+
+func TestGetOrCreateDirectConversation(t *testing.T) {
+	setupMessagingDB(t)
+
+	alice, bob := uuid.New(), uuid.New()
+	first, err := GetOrCreateDirectConversation(alice, bob)
+	if err != nil {
+		t.Fatalf("GetOrCreateDirectConversation failed: %v", err)
+	}
+	second, err := GetOrCreateDirectConversation(bob, alice)
+	if err != nil {
+		t.Fatalf("GetOrCreateDirectConversation failed: %v", err)
+	}
+	if first.ID != second.ID {
+		t.Errorf("Expected one conversation per pair, got %s and %s", first.ID, second.ID)
+	}
+
+	conversation, err := GetConversation(first.ID)
+	if err != nil {
+		t.Fatalf("GetConversation failed: %v", err)
+	}
+	if conversation.Type != model.ConversationDirect || len(conversation.MemberIDs) != 2 {
+		t.Errorf("Unexpected conversation %+v", conversation)
+	}
+	if _, err := GetConversation(uuid.New()); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected gorm.ErrRecordNotFound for an unknown conversation, got %v", err)
+	}
+}
+
+func TestGetMessagesPaging(t *testing.T) {
+	setupMessagingDB(t)
+
+	alice, bob := uuid.New(), uuid.New()
+	conversation, err := GetOrCreateDirectConversation(alice, bob)
+	if err != nil {
+		t.Fatalf("GetOrCreateDirectConversation failed: %v", err)
+	}
+	var sent []uuid.UUID
+	for _, content := range []string{"one", "two", "three", "four", "five"} {
+		message, err := CreateMessage(conversation.ID, alice, content, model.MessageText)
+		if err != nil {
+			t.Fatalf("CreateMessage failed: %v", err)
+		}
+		sent = append(sent, message.ID)
+		time.Sleep(time.Millisecond)
+	}
+
+	// Newest first, two at a time.
+	expected := [][]uuid.UUID{{sent[4], sent[3]}, {sent[2], sent[1]}, {sent[0]}}
+	var before *uuid.UUID
+	for page, ids := range expected {
+		messages, more, err := GetMessages(conversation.ID, before, 2)
+		if err != nil {
+			t.Fatalf("GetMessages failed on page %d: %v", page, err)
+		}
+		if len(messages) != len(ids) {
+			t.Fatalf("Expected %d messages on page %d, got %d", len(ids), page, len(messages))
+		}
+		for i := range ids {
+			if messages[i].ID != ids[i] {
+				t.Errorf("Page %d, message %d: expected %s, got %s", page, i, ids[i], messages[i].ID)
+			}
+		}
+		if wantMore := page < len(expected)-1; more != wantMore {
+			t.Errorf("Page %d: expected more=%v, got %v", page, wantMore, more)
+		}
+		before = &messages[len(messages)-1].ID
+	}
+
+	unknown := uuid.New()
+	if _, _, err := GetMessages(conversation.ID, &unknown, 2); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected gorm.ErrRecordNotFound for an unknown cursor, got %v", err)
+	}
+}
+
+func TestMarkConversationRead(t *testing.T) {
+	setupMessagingDB(t)
+
+	alice, bob := uuid.New(), uuid.New()
+	conversation, err := GetOrCreateDirectConversation(alice, bob)
+	if err != nil {
+		t.Fatalf("GetOrCreateDirectConversation failed: %v", err)
+	}
+	now := time.Now().UTC()
+
+	changed, err := MarkConversationRead(conversation.ID, bob, now)
+	if err != nil || !changed {
+		t.Fatalf("Expected the read marker to move, got changed=%v err=%v", changed, err)
+	}
+	changed, err = MarkConversationRead(conversation.ID, bob, now.Add(-time.Minute))
+	if err != nil || changed {
+		t.Errorf("Expected the read marker not to move back, got changed=%v err=%v", changed, err)
+	}
+}
+
+func TestRemoveConversationMember(t *testing.T) {
+	setupMessagingDB(t)
+
+	alice, bob := uuid.New(), uuid.New()
+	conversation, err := CreateGroupConversation(alice, "Runners", []uuid.UUID{bob})
+	if err != nil {
+		t.Fatalf("CreateGroupConversation failed: %v", err)
+	}
+	if err := RemoveConversationMember(conversation.ID, bob); err != nil {
+		t.Fatalf("RemoveConversationMember failed: %v", err)
+	}
+	if err := RemoveConversationMember(conversation.ID, bob); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected gorm.ErrRecordNotFound for a former member, got %v", err)
+	}
+	conversation, err = GetConversation(conversation.ID)
+	if err != nil {
+		t.Fatalf("GetConversation failed: %v", err)
+	}
+	if len(conversation.MemberIDs) != 1 || conversation.MemberIDs[0] != alice {
+		t.Errorf("Expected only the creator to remain, got %v", conversation.MemberIDs)
+	}
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrReportNotFound is returned when a report does not exist or was already
+// reviewed.
+var ErrReportNotFound = errors.New("report not found or already reviewed")
+
+// CreateReport files a report. A user reporting the same target again while
+// their earlier report is still open gets the existing report back.
+func CreateReport(reporterID uuid.UUID, req model.CreateReportRequest) (*model.Report, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var report model.Report
+	err := DB.Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status = ?",
+		reporterID, req.TargetType, req.TargetID, model.ReportStatusOpen).First(&report).Error
+	if err == nil {
+		return &report, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to look up report: %w", err)
+	}
+
+	report = model.Report{
+		ID:         uuid.New(),
+		ReporterID: reporterID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+		Details:    req.Details,
+		Status:     model.ReportStatusOpen,
+	}
+	if err := DB.Create(&report).Error; err != nil {
+		return nil, fmt.Errorf("failed to create report: %w", err)
+	}
+	return &report, nil
+}
+
+// ListReports returns reports with the given status, oldest first so that
+// the queue is worked in order. An empty status lists all reports.
+func ListReports(status string, limit int) ([]model.Report, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	q := DB.Model(&model.Report{})
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var reports []model.Report
+	if err := q.Order("created_at ASC").Limit(limit).Find(&reports).Error; err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+	return reports, nil
+}
+
+// ReviewReport closes an open report with the moderator's decision.
+func ReviewReport(reportID, reviewerID uuid.UUID, status, note string) (*model.Report, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	now := time.Now()
+	result := DB.Model(&model.Report{}).
+		Where("id = ? AND status = ?", reportID, model.ReportStatusOpen).
+		Updates(map[string]any{
+			"status":          status,
+			"reviewed_by":     reviewerID,
+			"reviewed_at":     now,
+			"resolution_note": note,
+			"updated_at":      now,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to review report: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrReportNotFound
+	}
+	var report model.Report
+	if err := DB.First(&report, "id = ?", reportID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load report: %w", err)
+	}
+	return &report, nil
+}
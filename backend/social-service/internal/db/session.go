@@ -0,0 +1,34 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSessionRevoked is returned by ValidateSession for tokens issued before
+// the user's sessions were revoked, and for suspended or deleted users.
+var ErrSessionRevoked = errors.New("session has been revoked")
+
+// ValidateSession checks a token version against the user's current one in
+// user-service's users table, and that the user is not suspended.
+func ValidateSession(userID string, tokenVersion int) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	var users []struct {
+		TokenVersion int
+		SuspendedAt  *time.Time
+	}
+	if err := DB.Table("users").
+		Select("token_version, suspended_at").
+		Where("id = ?", userID).
+		Limit(1).
+		Scan(&users).Error; err != nil {
+		return fmt.Errorf("failed to validate session: %w", err)
+	}
+	if len(users) == 0 || users[0].TokenVersion != tokenVersion || users[0].SuspendedAt != nil {
+		return ErrSessionRevoked
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/db"
+	"github.com/ffabious/healthy-summer/social-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Purge User Data
+// @Description Internal endpoint called by user-service once an account deletion falls due. Removes the user's friendships, friend requests and filed reports; calling it again is a no-op.
+// @Tags internal
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param X-Internal-Token header string true "Internal API token"
+// @Success 200 {object} model.PurgeUserDataResponse
+// @Router /internal/users/{user_id} [delete]
+func PurgeUserData(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID", "details": err.Error()})
+		return
+	}
+
+	deleted, err := db.PurgeUserData(userID.String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge user data", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.PurgeUserDataResponse{
+		Service: "social-service",
+		UserID:  userID,
+		Deleted: deleted,
+	})
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/auth"
+	"github.com/ffabious/healthy-summer/social-service/internal/model"
+	"github.com/ffabious/healthy-summer/social-service/internal/realtime"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
+)
+
+// Hub delivers real-time events to the clients connected to StreamEvents.
+var Hub = realtime.NewHub()
+
+// Watcher publishes changes to the feed, friend requests and notifications
+// of connected users. main starts its polling loop.
+var Watcher = &realtime.Watcher{Hub: Hub, Source: realtime.DBSource{}}
+
+// HeartbeatInterval is how often an idle stream gets a heartbeat event, so
+// proxies keep it open and clients can tell a dead connection apart from a
+// quiet one.
+var HeartbeatInterval = 25 * time.Second
+
+// @Summary StreamEvents
+// @Description Server-Sent Events stream of updates for the current user. It starts with the full state (feed, friend_requests and notifications events), followed by an event whenever one of them changes, a message event for new messages, read receipts and typing indicators, and a heartbeat event when idle. A user can keep several streams open, e.g. one per device.
+// @Tags Events
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Success 200 {object} model.Event
+// @Failure 429 {object} map[string]string
+// @Router /api/events [get]
+func StreamEvents(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	snapshot, err := Watcher.Source.Snapshot(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load events"})
+		return
+	}
+	conn, err := Hub.Subscribe(userID)
+	if errors.Is(err, realtime.ErrTooManyConnections) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many open connections"})
+		return
+	}
+	defer Hub.Unsubscribe(conn)
+	Watcher.Track(userID, snapshot)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Stop nginx from buffering the stream.
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	for _, event := range realtime.Events(nil, snapshot) {
+		c.SSEvent(event.Type, event.Data)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(HeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-conn.Events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects.
+				return
+			}
+			sendEvent(c, event)
+		case now := <-heartbeat.C:
+			c.SSEvent(model.EventHeartbeat, gin.H{"time": now.UTC()})
+		}
+		c.Writer.Flush()
+	}
+}
+
+// sendEvent writes event to the stream. Messaging events carry protobuf
+// messages, which are encoded like the REST messaging responses.
+func sendEvent(c *gin.Context, event model.Event) {
+	message, ok := event.Data.(proto.Message)
+	if !ok {
+		c.SSEvent(event.Type, event.Data)
+		return
+	}
+	data, err := protoJSON.Marshal(message)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", event.Type, err)
+		return
+	}
+	c.SSEvent(event.Type, json.RawMessage(data))
+}
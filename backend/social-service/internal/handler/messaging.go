@@ -0,0 +1,387 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/auth"
+	"github.com/ffabious/healthy-summer/social-service/internal/messaging"
+	"github.com/ffabious/healthy-summer/social-service/internal/model"
+	"github.com/ffabious/healthy-summer/social-service/internal/notification"
+	pb "github.com/ffabious/healthy-summer/social-service/proto"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Messaging implements the messaging API. main serves it over gRPC and sets
+// its Notifier; the handlers below expose it as REST for clients without
+// gRPC.
+var Messaging = messaging.NewServer(Hub, notification.LogNotifier{})
+
+// protoJSON encodes protobuf messages with their proto field names and
+// zero values, so e.g. is_read is always present.
+var protoJSON = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
+// httpStatus maps the gRPC codes returned by the messaging server.
+var httpStatus = map[codes.Code]int{
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.NotFound:           http.StatusNotFound,
+	codes.FailedPrecondition: http.StatusConflict,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+}
+
+// messagingContext authenticates the request for the messaging server.
+func messagingContext(c *gin.Context) (context.Context, bool) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+	return auth.ContextWithUserID(c.Request.Context(), userID), true
+}
+
+// bindProto decodes the JSON body into req. Both proto and camelCase field
+// names are accepted.
+func bindProto(c *gin.Context, req proto.Message) bool {
+	body, err := io.ReadAll(c.Request.Body)
+	if err == nil {
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(body, req)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return false
+	}
+	return true
+}
+
+// queryInt32 reads an optional integer query parameter into dst.
+func queryInt32(c *gin.Context, name string, dst *int32) bool {
+	value := c.Query(name)
+	if value == "" {
+		return true
+	}
+	n, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": "invalid " + name})
+		return false
+	}
+	*dst = int32(n)
+	return true
+}
+
+// respondProto writes the result of a messaging call.
+func respondProto(c *gin.Context, code int, resp proto.Message, err error) {
+	if err != nil {
+		st := status.Convert(err)
+		httpCode, ok := httpStatus[st.Code()]
+		if !ok {
+			httpCode = http.StatusInternalServerError
+		}
+		if st.Code() == codes.InvalidArgument {
+			c.JSON(httpCode, gin.H{"error": "Invalid request data", "details": st.Message()})
+			return
+		}
+		c.JSON(httpCode, gin.H{"error": st.Message()})
+		return
+	}
+	data, err := protoJSON.Marshal(resp)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode response"})
+		return
+	}
+	c.Data(code, "application/json; charset=utf-8", data)
+}
+
+// @Summary SendMessage
+// @Description Send a message to a conversation (conversation_id), or to a user in your direct conversation with them (receiver_id). message_type is text (default) or image.
+// @Tags Messaging
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param message body pb.SendMessageRequest true "Message"
+// @Success 201 {object} pb.SendMessageResponse
+// @Failure 403 {object} map[string]string
+// @Router /api/messages [post]
+func SendMessage(c *gin.Context) {
+	ctx, ok := messagingContext(c)
+	if !ok {
+		return
+	}
+	var req pb.SendMessageRequest
+	if !bindProto(c, &req) {
+		return
+	}
+	resp, err := Messaging.SendMessage(ctx, &req)
+	respondProto(c, http.StatusCreated, resp, err)
+}
+
+// @Summary MarkMessagesAsRead
+// @Description Mark messages as read: everything up to the given message_ids in their conversations, or the whole conversation_id
+// @Tags Messaging
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param read body pb.MarkAsReadRequest true "Messages"
+// @Success 200 {object} pb.MarkAsReadResponse
+// @Router /api/messages/read [post]
+func MarkMessagesAsRead(c *gin.Context) {
+	ctx, ok := messagingContext(c)
+	if !ok {
+		return
+	}
+	var req pb.MarkAsReadRequest
+	if !bindProto(c, &req) {
+		return
+	}
+	resp, err := Messaging.MarkAsRead(ctx, &req)
+	respondProto(c, http.StatusOK, resp, err)
+}
+
+// @Summary MarkConversationAsRead
+// @Description Mark every message of a conversation as read
+// @Tags Messaging
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Success 200 {object} pb.MarkAsReadResponse
+// @Router /api/conversations/{id}/read [post]
+func MarkConversationAsRead(c *gin.Context) {
+	ctx, ok := messagingContext(c)
+	if !ok {
+		return
+	}
+	resp, err := Messaging.MarkAsRead(ctx, &pb.MarkAsReadRequest{ConversationId: c.Param("id")})
+	respondProto(c, http.StatusOK, resp, err)
+}
+
+// @Summary GetConversations
+// @Description List your conversations, most recently active first, with their last message and unread count
+// @Tags Messaging
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of conversations to skip"
+// @Success 200 {object} pb.GetConversationsResponse
+// @Router /api/conversations [get]
+func GetConversations(c *gin.Context) {
+	ctx, ok := messagingContext(c)
+	if !ok {
+		return
+	}
+	var req pb.GetConversationsRequest
+	if !queryInt32(c, "limit", &req.Limit) || !queryInt32(c, "offset", &req.Offset) {
+		return
+	}
+	resp, err := Messaging.GetConversations(ctx, &req)
+	respondProto(c, http.StatusOK, resp, err)
+}
+
+// @Summary CreateConversation
+// @Description Start a group conversation, or get your direct conversation with a user. type defaults to direct for one member and group for more.
+// @Tags Messaging
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param conversation body pb.CreateConversationRequest true "Conversation"
+// @Success 201 {object} pb.CreateConversationResponse
+// @Router /api/conversations [post]
+func CreateConversation(c *gin.Context) {
+	ctx, ok := messagingContext(c)
+	if !ok {
+		return
+	}
+	var req pb.CreateConversationRequest
+	if !bindProto(c, &req) {
+		return
+	}
+	resp, err := Messaging.CreateConversation(ctx, &req)
+	respondProto(c, http.StatusCreated, resp, err)
+}
+
+// @Summary GetMessages
+// @Description Page through the messages of a conversation, newest first. Pass next_cursor as before_id to get older messages.
+// @Tags Messaging
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param before_id query string false "Cursor from the previous page"
+// @Success 200 {object} pb.GetMessagesResponse
+// @Router /api/conversations/{id}/messages [get]
+func GetMessages(c *gin.Context) {
+	ctx, ok := messagingContext(c)
+	if !ok {
+		return
+	}
+	req := pb.GetMessagesRequest{ConversationId: c.Param("id"), BeforeId: c.Query("before_id")}
+	if !queryInt32(c, "limit", &req.Limit) {
+		return
+	}
+	resp, err := Messaging.GetMessages(ctx, &req)
+	respondProto(c, http.StatusOK, resp, err)
+}
+
+// @Summary AddConversationMembers
+// @Description Add users to a group conversation you are in
+// @Tags Messaging
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Param members body pb.AddMembersRequest true "Users to add"
+// @Success 200 {object} pb.AddMembersResponse
+// @Router /api/conversations/{id}/members [post]
+func AddConversationMembers(c *gin.Context) {
+	ctx, ok := messagingContext(c)
+	if !ok {
+		return
+	}
+	var req pb.AddMembersRequest
+	if !bindProto(c, &req) {
+		return
+	}
+	req.ConversationId = c.Param("id")
+	resp, err := Messaging.AddMembers(ctx, &req)
+	respondProto(c, http.StatusOK, resp, err)
+}
+
+// @Summary LeaveConversation
+// @Description Leave a group conversation
+// @Tags Messaging
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Success 200 {object} pb.LeaveConversationResponse
+// @Router /api/conversations/{id}/members/me [delete]
+func LeaveConversation(c *gin.Context) {
+	ctx, ok := messagingContext(c)
+	if !ok {
+		return
+	}
+	resp, err := Messaging.LeaveConversation(ctx, &pb.LeaveConversationRequest{ConversationId: c.Param("id")})
+	respondProto(c, http.StatusOK, resp, err)
+}
+
+// @Summary SendTyping
+// @Description Tell the other members of a conversation that you started ({"typing": true}) or stopped typing
+// @Tags Messaging
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Param typing body pb.SendTypingRequest true "Typing state"
+// @Success 200 {object} pb.SendTypingResponse
+// @Router /api/conversations/{id}/typing [post]
+func SendTyping(c *gin.Context) {
+	ctx, ok := messagingContext(c)
+	if !ok {
+		return
+	}
+	var req pb.SendTypingRequest
+	if !bindProto(c, &req) {
+		return
+	}
+	req.ConversationId = c.Param("id")
+	resp, err := Messaging.SendTyping(ctx, &req)
+	respondProto(c, http.StatusOK, resp, err)
+}
+
+// sseMessageStream lets StreamMessages write to a Server-Sent Events
+// response. StreamMessages only uses Context, SendHeader and Send; the
+// embedded grpc.ServerStream is nil.
+type sseMessageStream struct {
+	grpc.ServerStream
+	c   *gin.Context
+	ctx context.Context
+
+	// mu serializes writes with the heartbeat.
+	mu     sync.Mutex
+	opened bool
+}
+
+func (s *sseMessageStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *sseMessageStream) SendHeader(metadata.MD) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.c.Header("Content-Type", "text/event-stream")
+	s.c.Header("Cache-Control", "no-cache")
+	s.c.Header("Connection", "keep-alive")
+	// Stop nginx from buffering the stream.
+	s.c.Header("X-Accel-Buffering", "no")
+	s.c.Status(http.StatusOK)
+	s.c.Writer.Flush()
+	s.opened = true
+	return nil
+}
+
+func (s *sseMessageStream) Send(event *pb.MessageEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sendEvent(s.c, model.Event{Type: model.EventMessage, Data: event})
+	s.c.Writer.Flush()
+	return s.ctx.Err()
+}
+
+func (s *sseMessageStream) heartbeat(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.opened {
+		s.c.SSEvent(model.EventHeartbeat, gin.H{"time": now.UTC()})
+		s.c.Writer.Flush()
+	}
+}
+
+// @Summary StreamMessages
+// @Description Server-Sent Events stream of message events for the current user: new_message, message_read, typing_started and typing_stopped, each sent as a message event, plus a heartbeat event when idle. The same events are also part of /api/events.
+// @Tags Messaging
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Success 200 {object} pb.MessageEvent
+// @Failure 429 {object} map[string]string
+// @Router /api/messages/stream [get]
+func StreamMessages(c *gin.Context) {
+	ctx, ok := messagingContext(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stream := &sseMessageStream{c: c, ctx: ctx}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(HeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				stream.heartbeat(now)
+			}
+		}
+	}()
+
+	err := Messaging.StreamMessages(&pb.StreamRequest{}, stream)
+	cancel()
+	<-done
+	if err != nil && !stream.opened {
+		respondProto(c, http.StatusOK, nil, err)
+	}
+	// Once open, the stream just ends; the client reconnects.
+}
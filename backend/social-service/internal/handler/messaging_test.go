@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/model"
+	pb "github.com/ffabious/healthy-summer/social-service/proto"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func generateTestToken(t *testing.T, userID string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return token
+}
+
+func TestRespondProto(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		resp           *pb.SendTypingResponse
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Success", &pb.SendTypingResponse{}, nil, http.StatusOK, `"success":false`},
+		{"Invalid argument", nil, status.Error(codes.InvalidArgument, "invalid conversation_id"), http.StatusBadRequest, "invalid conversation_id"},
+		{"Permission denied", nil, status.Error(codes.PermissionDenied, "cannot message user"), http.StatusForbidden, "cannot message user"},
+		{"Not found", nil, status.Error(codes.NotFound, "conversation not found"), http.StatusNotFound, "conversation not found"},
+		{"Failed precondition", nil, status.Error(codes.FailedPrecondition, "direct conversations cannot be left"), http.StatusConflict, "cannot be left"},
+		{"Resource exhausted", nil, status.Error(codes.ResourceExhausted, "too many open connections"), http.StatusTooManyRequests, "too many open connections"},
+		{"Internal", nil, status.Error(codes.Internal, "failed to send message"), http.StatusInternalServerError, "failed to send message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			respondProto(c, http.StatusOK, tt.resp, tt.err)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestBindProto(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	conversationID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{"Proto field names", `{"conversation_id":"` + conversationID + `","content":"hi"}`, http.StatusOK},
+		{"JSON field names", `{"conversationId":"` + conversationID + `","content":"hi"}`, http.StatusOK},
+		{"Unknown fields", `{"conversation_id":"` + conversationID + `","content":"hi","client":"web"}`, http.StatusOK},
+		{"Invalid JSON", `{"conversation_id":`, http.StatusBadRequest},
+		{"Wrong type", `{"conversation_id":42}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/test", func(c *gin.Context) {
+				var req pb.SendMessageRequest
+				if !bindProto(c, &req) {
+					return
+				}
+				if req.GetConversationId() != conversationID || req.GetContent() != "hi" {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "unexpected request " + req.String()})
+					return
+				}
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("POST", "/test", strings.NewReader(tt.body)))
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestStreamMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/messages/stream", StreamMessages)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	userID := uuid.New().String()
+	open := func() *http.Response {
+		req, _ := http.NewRequest("GET", server.URL+"/api/messages/stream", nil)
+		req.Header.Set("Authorization", "Bearer "+generateTestToken(t, userID))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp
+	}
+
+	t.Run("Missing authorization header", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/api/messages/stream")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+		}
+	})
+
+	t.Run("Error before the stream opens", func(t *testing.T) {
+		originalLimit := Hub.MaxConnsPerUser
+		defer func() { Hub.MaxConnsPerUser = originalLimit }()
+		Hub.MaxConnsPerUser = 0
+
+		resp := open()
+		defer resp.Body.Close()
+		body, _ := bufio.NewReader(resp.Body).ReadString('}')
+		if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Content-Type") != "application/json; charset=utf-8" {
+			t.Errorf("Expected a 429 JSON error, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		if !strings.Contains(body, "too many open connections") {
+			t.Errorf("Unexpected body: %s", body)
+		}
+	})
+
+	t.Run("Events after the stream opens", func(t *testing.T) {
+		resp := open()
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("Expected an event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+
+		Hub.Publish(userID, model.Event{Type: model.EventFeed, Data: model.FeedEvent{}})
+		Hub.Publish(userID, model.Event{Type: model.EventMessage, Data: &pb.MessageEvent{
+			EventType:      "typing_started",
+			ConversationId: "conversation-1",
+		}})
+
+		reader := bufio.NewReader(resp.Body)
+		event, _ := reader.ReadString('\n')
+		data, _ := reader.ReadString('\n')
+		if event != "event:message\n" {
+			t.Errorf("Expected only message events, got %q", event)
+		}
+		if !strings.Contains(data, `"event_type":"typing_started"`) || !strings.Contains(data, `"conversation_id":"conversation-1"`) {
+			t.Errorf("Expected the event encoded with proto field names, got %q", data)
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/auth"
+	"github.com/ffabious/healthy-summer/social-service/internal/db"
+	"github.com/ffabious/healthy-summer/social-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary CreateReport
+// @Description Report a user or their content to the moderators
+// @Tags Reports
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param report body model.CreateReportRequest true "Report"
+// @Success 201 {object} model.Report
+// @Router /api/reports [post]
+func CreateReport(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req model.CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+	reporterID := uuid.MustParse(userID)
+	if req.TargetType == "user" && req.TargetID == reporterID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot report yourself"})
+		return
+	}
+
+	report, err := db.CreateReport(reporterID, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create report"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+// @Summary ListReports
+// @Description List reports for moderators, oldest first
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "open (default), resolved, dismissed or all"
+// @Param limit query int false "Maximum number of reports (default 50)"
+// @Success 200 {object} model.ListReportsResponse
+// @Router /api/admin/reports [get]
+func ListReports(c *gin.Context) {
+	status := c.DefaultQuery("status", model.ReportStatusOpen)
+	switch status {
+	case model.ReportStatusOpen, model.ReportStatusResolved, model.ReportStatusDismissed:
+	case "all":
+		status = ""
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+
+	reports, err := db.ListReports(status, min(limit, 200))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reports"})
+		return
+	}
+	if reports == nil {
+		reports = []model.Report{}
+	}
+
+	c.JSON(http.StatusOK, model.ListReportsResponse{Reports: reports})
+}
+
+// @Summary ReviewReport
+// @Description Close an open report as resolved or dismissed
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Report ID"
+// @Param review body model.ReviewReportRequest true "Decision"
+// @Success 200 {object} model.Report
+// @Router /api/admin/reports/{id}/review [post]
+func ReviewReport(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+		return
+	}
+
+	var req model.ReviewReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	report, err := db.ReviewReport(reportID, uuid.MustParse(userID), req.Status, req.Note)
+	if errors.Is(err, db.ErrReportNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report not found or already reviewed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
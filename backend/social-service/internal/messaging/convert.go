@@ -0,0 +1,61 @@
+package messaging
+
+import (
+	"github.com/ffabious/healthy-summer/social-service/internal/model"
+	pb "github.com/ffabious/healthy-summer/social-service/proto"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toProtoConversation(c *model.Conversation) *pb.Conversation {
+	memberIDs := make([]string, 0, len(c.MemberIDs))
+	for _, id := range c.MemberIDs {
+		memberIDs = append(memberIDs, id.String())
+	}
+	return &pb.Conversation{
+		Id:        c.ID.String(),
+		Type:      c.Type,
+		Title:     c.Title,
+		MemberIds: memberIDs,
+		CreatedBy: c.CreatedBy.String(),
+		CreatedAt: timestamppb.New(c.CreatedAt),
+	}
+}
+
+// toProtoMessage converts a message as seen by viewer. Its own messages are
+// read once every other member has read them; messages from others once the
+// viewer has.
+func toProtoMessage(m *model.Message, conversationType string, viewer uuid.UUID, members []model.ConversationMember) *pb.Message {
+	message := &pb.Message{
+		Id:             m.ID.String(),
+		SenderId:       m.SenderID.String(),
+		Content:        m.Content,
+		MessageType:    m.MessageType,
+		IsRead:         isRead(m, viewer, members),
+		CreatedAt:      timestamppb.New(m.CreatedAt),
+		ConversationId: m.ConversationID.String(),
+	}
+	if conversationType == model.ConversationDirect {
+		for _, member := range members {
+			if member.UserID != m.SenderID {
+				message.ReceiverId = member.UserID.String()
+			}
+		}
+	}
+	return message
+}
+
+func isRead(m *model.Message, viewer uuid.UUID, members []model.ConversationMember) bool {
+	for _, member := range members {
+		if m.SenderID == viewer && member.UserID == viewer {
+			continue
+		}
+		if m.SenderID != viewer && member.UserID != viewer {
+			continue
+		}
+		if member.LastReadAt == nil || member.LastReadAt.Before(m.CreatedAt) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,588 @@
+// Package messaging implements the MessagingService defined in
+// proto/messaging.proto. Conversations and messages are stored in the
+// shared database; new messages, read receipts and typing indicators are
+// delivered through the realtime hub to every open connection of the
+// members.
+package messaging
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/auth"
+	"github.com/ffabious/healthy-summer/social-service/internal/db"
+	"github.com/ffabious/healthy-summer/social-service/internal/model"
+	"github.com/ffabious/healthy-summer/social-service/internal/notification"
+	"github.com/ffabious/healthy-summer/social-service/internal/realtime"
+	pb "github.com/ffabious/healthy-summer/social-service/proto"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// MessageEvent types.
+const (
+	EventNewMessage    = "new_message"
+	EventMessageRead   = "message_read"
+	EventTypingStarted = "typing_started"
+	EventTypingStopped = "typing_stopped"
+)
+
+// Page sizes of GetConversations and GetMessages.
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Server implements pb.MessagingServiceServer. The caller is taken from the
+// context, see auth.UserIDFromContext.
+type Server struct {
+	pb.UnimplementedMessagingServiceServer
+	Hub *realtime.Hub
+	// Notifier tells recipients about new messages in the app and on their
+	// devices.
+	Notifier notification.Notifier
+}
+
+func NewServer(hub *realtime.Hub, notifier notification.Notifier) *Server {
+	return &Server{Hub: hub, Notifier: notifier}
+}
+
+func currentUser(ctx context.Context) (uuid.UUID, error) {
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok {
+		return uuid.Nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.Unauthenticated, "invalid user ID")
+	}
+	return id, nil
+}
+
+func parseID(value, field string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid %s", field)
+	}
+	return id, nil
+}
+
+func parseIDs(values []string, field string) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(values))
+	for _, value := range values {
+		id, err := parseID(value, field)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func pageLimit(limit int32) (int, error) {
+	switch {
+	case limit < 0:
+		return 0, status.Error(codes.InvalidArgument, "limit must not be negative")
+	case limit == 0:
+		return DefaultLimit, nil
+	case limit > MaxLimit:
+		return MaxLimit, nil
+	}
+	return int(limit), nil
+}
+
+// internalError logs err and hides it from the client.
+func internalError(msg string, err error) error {
+	log.Printf("%s: %v", msg, err)
+	return status.Error(codes.Internal, msg)
+}
+
+// conversationOf returns the conversation if userID is one of its members.
+// Conversations of others are reported as not found.
+func conversationOf(conversationID, userID uuid.UUID) (*model.Conversation, error) {
+	conversation, err := db.GetConversation(conversationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.NotFound, "conversation not found")
+	}
+	if err != nil {
+		return nil, internalError("failed to get conversation", err)
+	}
+	if !slices.Contains(conversation.MemberIDs, userID) {
+		return nil, status.Error(codes.NotFound, "conversation not found")
+	}
+	return conversation, nil
+}
+
+// checkNotBlocked fails if userID and any of others blocked one another.
+func checkNotBlocked(userID uuid.UUID, others []uuid.UUID) error {
+	for _, other := range others {
+		blocked, err := db.IsBlocked(userID.String(), other.String())
+		if err != nil {
+			return internalError("failed to check blocks", err)
+		}
+		if blocked {
+			return status.Errorf(codes.PermissionDenied, "cannot message user %s", other)
+		}
+	}
+	return nil
+}
+
+// publish sends event to every open connection of userIDs.
+func (s *Server) publish(userIDs []uuid.UUID, event *pb.MessageEvent) {
+	for _, userID := range userIDs {
+		s.Hub.Publish(userID.String(), model.Event{Type: model.EventMessage, Data: event})
+	}
+}
+
+func others(memberIDs []uuid.UUID, userID uuid.UUID) []uuid.UUID {
+	return slices.DeleteFunc(slices.Clone(memberIDs), func(id uuid.UUID) bool { return id == userID })
+}
+
+// notify tells recipients about a new message. It runs after the message
+// was delivered, and failures are only logged.
+func (s *Server) notify(recipients []uuid.UUID, senderID, messageID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	for _, recipientID := range recipients {
+		err := s.Notifier.Notify(ctx, recipientID.String(), senderID.String(), model.NotificationMessage, messageID.String())
+		if err != nil {
+			log.Printf("Failed to create %s notification for user %s: %v", model.NotificationMessage, recipientID, err)
+		}
+	}
+}
+
+// SendMessage sends a message to a conversation, or to a user in their
+// direct conversation.
+func (s *Server) SendMessage(ctx context.Context, req *pb.SendMessageRequest) (*pb.SendMessageResponse, error) {
+	userID, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.TrimSpace(req.GetContent())
+	if content == "" {
+		return nil, status.Error(codes.InvalidArgument, "content is required")
+	}
+	if utf8.RuneCountInString(content) > model.MaxMessageLength {
+		return nil, status.Errorf(codes.InvalidArgument, "content must be at most %d characters", model.MaxMessageLength)
+	}
+	messageType := req.GetMessageType()
+	if messageType == "" {
+		messageType = model.MessageText
+	}
+	if messageType != model.MessageText && messageType != model.MessageImage {
+		return nil, status.Error(codes.InvalidArgument, "invalid message_type")
+	}
+
+	var conversation *model.Conversation
+	switch {
+	case req.GetConversationId() != "":
+		conversationID, err := parseID(req.GetConversationId(), "conversation_id")
+		if err != nil {
+			return nil, err
+		}
+		if conversation, err = conversationOf(conversationID, userID); err != nil {
+			return nil, err
+		}
+		if conversation.Type == model.ConversationDirect {
+			if err := checkNotBlocked(userID, others(conversation.MemberIDs, userID)); err != nil {
+				return nil, err
+			}
+		}
+	case req.GetReceiverId() != "":
+		receiverID, err := parseID(req.GetReceiverId(), "receiver_id")
+		if err != nil {
+			return nil, err
+		}
+		if receiverID == userID {
+			return nil, status.Error(codes.InvalidArgument, "cannot message yourself")
+		}
+		if err := checkNotBlocked(userID, []uuid.UUID{receiverID}); err != nil {
+			return nil, err
+		}
+		if conversation, err = db.GetOrCreateDirectConversation(userID, receiverID); err != nil {
+			return nil, internalError("failed to get conversation", err)
+		}
+	default:
+		return nil, status.Error(codes.InvalidArgument, "conversation_id or receiver_id is required")
+	}
+
+	message, err := db.CreateMessage(conversation.ID, userID, content, messageType)
+	if err != nil {
+		return nil, internalError("failed to send message", err)
+	}
+	recipients := others(conversation.MemberIDs, userID)
+	go s.notify(recipients, userID, message.ID)
+
+	// Nobody else has read the message yet; only the sender's read marker is
+	// set.
+	members := []model.ConversationMember{{UserID: userID, LastReadAt: &message.CreatedAt}}
+	for _, recipientID := range recipients {
+		members = append(members, model.ConversationMember{UserID: recipientID})
+	}
+	result := toProtoMessage(message, conversation.Type, userID, members)
+	s.publish(conversation.MemberIDs, &pb.MessageEvent{
+		EventType:      EventNewMessage,
+		Message:        result,
+		ConversationId: conversation.ID.String(),
+		UserId:         userID.String(),
+	})
+	return &pb.SendMessageResponse{Message: result, Success: true}, nil
+}
+
+// StreamMessages streams the message events of the caller until the client
+// goes away.
+func (s *Server) StreamMessages(req *pb.StreamRequest, stream pb.MessagingService_StreamMessagesServer) error {
+	userID, err := currentUser(stream.Context())
+	if err != nil {
+		return err
+	}
+	if req.GetUserId() != "" && req.GetUserId() != userID.String() {
+		return status.Error(codes.PermissionDenied, "cannot stream messages of another user")
+	}
+	conn, err := s.Hub.Subscribe(userID.String())
+	if errors.Is(err, realtime.ErrTooManyConnections) {
+		return status.Error(codes.ResourceExhausted, "too many open connections")
+	}
+	defer s.Hub.Unsubscribe(conn)
+	// Tell the client the stream is open before the first event arrives.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-conn.Events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects.
+				return status.Error(codes.Unavailable, "stream fell behind")
+			}
+			messageEvent, ok := event.Data.(*pb.MessageEvent)
+			if event.Type != model.EventMessage || !ok {
+				continue
+			}
+			if err := stream.Send(messageEvent); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// MarkAsRead marks messages as read, either a whole conversation or
+// everything up to the given messages. The other members are told with a
+// message_read event.
+func (s *Server) MarkAsRead(ctx context.Context, req *pb.MarkAsReadRequest) (*pb.MarkAsReadResponse, error) {
+	userID, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The newest message to mark per conversation.
+	latest := make(map[uuid.UUID]model.Message)
+	conversations := make(map[uuid.UUID]*model.Conversation)
+	switch {
+	case req.GetConversationId() != "":
+		conversationID, err := parseID(req.GetConversationId(), "conversation_id")
+		if err != nil {
+			return nil, err
+		}
+		if conversations[conversationID], err = conversationOf(conversationID, userID); err != nil {
+			return nil, err
+		}
+		messages, _, err := db.GetMessages(conversationID, nil, 1)
+		if err != nil {
+			return nil, internalError("failed to get messages", err)
+		}
+		if len(messages) > 0 {
+			latest[conversationID] = messages[0]
+		}
+	case len(req.GetMessageIds()) > 0:
+		ids, err := parseIDs(req.GetMessageIds(), "message_ids")
+		if err != nil {
+			return nil, err
+		}
+		messages, err := db.GetMessagesByIDs(ids)
+		if err != nil {
+			return nil, internalError("failed to get messages", err)
+		}
+		if len(messages) != len(ids) {
+			return nil, status.Error(codes.NotFound, "message not found")
+		}
+		for _, message := range messages {
+			if last, ok := latest[message.ConversationID]; !ok || message.CreatedAt.After(last.CreatedAt) {
+				latest[message.ConversationID] = message
+			}
+		}
+		// Messages of conversations the caller is not in do not exist for
+		// them.
+		for conversationID := range latest {
+			conversation, err := conversationOf(conversationID, userID)
+			if status.Code(err) == codes.NotFound {
+				return nil, status.Error(codes.NotFound, "message not found")
+			}
+			if err != nil {
+				return nil, err
+			}
+			conversations[conversationID] = conversation
+		}
+	default:
+		return nil, status.Error(codes.InvalidArgument, "conversation_id or message_ids is required")
+	}
+
+	for conversationID, message := range latest {
+		changed, err := db.MarkConversationRead(conversationID, userID, message.CreatedAt)
+		if err != nil {
+			return nil, internalError("failed to mark messages as read", err)
+		}
+		if !changed {
+			continue
+		}
+		s.publish(others(conversations[conversationID].MemberIDs, userID), &pb.MessageEvent{
+			EventType:      EventMessageRead,
+			Message:        &pb.Message{Id: message.ID.String(), ConversationId: conversationID.String()},
+			ConversationId: conversationID.String(),
+			UserId:         userID.String(),
+		})
+	}
+	return &pb.MarkAsReadResponse{Success: true}, nil
+}
+
+// CreateConversation starts a group conversation with the caller and the
+// given members, or returns the caller's direct conversation with a user.
+func (s *Server) CreateConversation(ctx context.Context, req *pb.CreateConversationRequest) (*pb.CreateConversationResponse, error) {
+	userID, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	memberIDs, err := parseIDs(req.GetMemberIds(), "member_ids")
+	if err != nil {
+		return nil, err
+	}
+	memberIDs = others(memberIDs, userID)
+	if len(memberIDs) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "member_ids must name another user")
+	}
+	title := strings.TrimSpace(req.GetTitle())
+	if utf8.RuneCountInString(title) > model.MaxConversationTitle {
+		return nil, status.Errorf(codes.InvalidArgument, "title must be at most %d characters", model.MaxConversationTitle)
+	}
+	if err := checkNotBlocked(userID, memberIDs); err != nil {
+		return nil, err
+	}
+
+	conversationType := req.GetType()
+	if conversationType == "" {
+		conversationType = model.ConversationGroup
+		if len(memberIDs) == 1 {
+			conversationType = model.ConversationDirect
+		}
+	}
+
+	var conversation *model.Conversation
+	switch conversationType {
+	case model.ConversationDirect:
+		if len(memberIDs) != 1 {
+			return nil, status.Error(codes.InvalidArgument, "a direct conversation has exactly one other member")
+		}
+		conversation, err = db.GetOrCreateDirectConversation(userID, memberIDs[0])
+	case model.ConversationGroup:
+		if len(memberIDs)+1 > model.MaxGroupMembers {
+			return nil, status.Errorf(codes.InvalidArgument, "a group has at most %d members", model.MaxGroupMembers)
+		}
+		conversation, err = db.CreateGroupConversation(userID, title, memberIDs)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "invalid type")
+	}
+	if err != nil {
+		return nil, internalError("failed to create conversation", err)
+	}
+	return &pb.CreateConversationResponse{Conversation: toProtoConversation(conversation)}, nil
+}
+
+// GetConversations lists the caller's conversations, most recently active
+// first.
+func (s *Server) GetConversations(ctx context.Context, req *pb.GetConversationsRequest) (*pb.GetConversationsResponse, error) {
+	userID, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := pageLimit(req.GetLimit())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetOffset() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset must not be negative")
+	}
+	summaries, total, err := db.GetConversations(userID, limit, int(req.GetOffset()))
+	if err != nil {
+		return nil, internalError("failed to get conversations", err)
+	}
+	resp := &pb.GetConversationsResponse{Conversations: make([]*pb.ConversationSummary, 0, len(summaries)), Total: total}
+	for _, summary := range summaries {
+		item := &pb.ConversationSummary{
+			Conversation: toProtoConversation(&summary.Conversation),
+			UnreadCount:  summary.UnreadCount,
+		}
+		if summary.LastMessage != nil {
+			item.LastMessage = toProtoMessage(summary.LastMessage, summary.Conversation.Type, userID, summary.Members)
+		}
+		resp.Conversations = append(resp.Conversations, item)
+	}
+	return resp, nil
+}
+
+// GetMessages pages through a conversation, newest message first. Pass the
+// returned next_cursor as before_id to get older messages.
+func (s *Server) GetMessages(ctx context.Context, req *pb.GetMessagesRequest) (*pb.GetMessagesResponse, error) {
+	userID, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conversationID, err := parseID(req.GetConversationId(), "conversation_id")
+	if err != nil {
+		return nil, err
+	}
+	limit, err := pageLimit(req.GetLimit())
+	if err != nil {
+		return nil, err
+	}
+	var beforeID *uuid.UUID
+	if req.GetBeforeId() != "" {
+		id, err := parseID(req.GetBeforeId(), "before_id")
+		if err != nil {
+			return nil, err
+		}
+		beforeID = &id
+	}
+	conversation, err := conversationOf(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, more, err := db.GetMessages(conversationID, beforeID, limit)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.InvalidArgument, "invalid before_id")
+	}
+	if err != nil {
+		return nil, internalError("failed to get messages", err)
+	}
+	members, err := db.GetConversationMembers(conversationID)
+	if err != nil {
+		return nil, internalError("failed to get messages", err)
+	}
+	resp := &pb.GetMessagesResponse{Messages: make([]*pb.Message, 0, len(messages))}
+	for i := range messages {
+		resp.Messages = append(resp.Messages, toProtoMessage(&messages[i], conversation.Type, userID, members))
+	}
+	if more {
+		resp.NextCursor = messages[len(messages)-1].ID.String()
+	}
+	return resp, nil
+}
+
+// AddMembers adds users to a group conversation the caller belongs to.
+func (s *Server) AddMembers(ctx context.Context, req *pb.AddMembersRequest) (*pb.AddMembersResponse, error) {
+	userID, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conversationID, err := parseID(req.GetConversationId(), "conversation_id")
+	if err != nil {
+		return nil, err
+	}
+	userIDs, err := parseIDs(req.GetUserIds(), "user_ids")
+	if err != nil {
+		return nil, err
+	}
+	if len(userIDs) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_ids is required")
+	}
+	conversation, err := conversationOf(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Type != model.ConversationGroup {
+		return nil, status.Error(codes.FailedPrecondition, "members can only be added to group conversations")
+	}
+	added := slices.DeleteFunc(userIDs, func(id uuid.UUID) bool { return slices.Contains(conversation.MemberIDs, id) })
+	if len(conversation.MemberIDs)+len(added) > model.MaxGroupMembers {
+		return nil, status.Errorf(codes.InvalidArgument, "a group has at most %d members", model.MaxGroupMembers)
+	}
+	if err := checkNotBlocked(userID, added); err != nil {
+		return nil, err
+	}
+	if len(added) > 0 {
+		if err := db.AddConversationMembers(conversationID, added); err != nil {
+			return nil, internalError("failed to add members", err)
+		}
+		if conversation, err = db.GetConversation(conversationID); err != nil {
+			return nil, internalError("failed to get conversation", err)
+		}
+	}
+	return &pb.AddMembersResponse{Conversation: toProtoConversation(conversation)}, nil
+}
+
+// LeaveConversation removes the caller from a group conversation.
+func (s *Server) LeaveConversation(ctx context.Context, req *pb.LeaveConversationRequest) (*pb.LeaveConversationResponse, error) {
+	userID, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conversationID, err := parseID(req.GetConversationId(), "conversation_id")
+	if err != nil {
+		return nil, err
+	}
+	conversation, err := conversationOf(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Type != model.ConversationGroup {
+		return nil, status.Error(codes.FailedPrecondition, "direct conversations cannot be left")
+	}
+	err = db.RemoveConversationMember(conversationID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.NotFound, "conversation not found")
+	}
+	if err != nil {
+		return nil, internalError("failed to leave conversation", err)
+	}
+	return &pb.LeaveConversationResponse{Success: true}, nil
+}
+
+// SendTyping tells the other members of a conversation that the caller
+// started or stopped typing. Nothing is stored.
+func (s *Server) SendTyping(ctx context.Context, req *pb.SendTypingRequest) (*pb.SendTypingResponse, error) {
+	userID, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conversationID, err := parseID(req.GetConversationId(), "conversation_id")
+	if err != nil {
+		return nil, err
+	}
+	conversation, err := conversationOf(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	eventType := EventTypingStopped
+	if req.GetTyping() {
+		eventType = EventTypingStarted
+	}
+	s.publish(others(conversation.MemberIDs, userID), &pb.MessageEvent{
+		EventType:      eventType,
+		ConversationId: conversationID.String(),
+		UserId:         userID.String(),
+	})
+	return &pb.SendTypingResponse{Success: true}, nil
+}
@@ -0,0 +1,282 @@
+package messaging
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/auth"
+	"github.com/ffabious/healthy-summer/social-service/internal/db"
+	"github.com/ffabious/healthy-summer/social-service/internal/model"
+	"github.com/ffabious/healthy-summer/social-service/internal/realtime"
+	pb "github.com/ffabious/healthy-summer/social-service/proto"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeNotifier records the notifications of a test.
+type fakeNotifier struct {
+	sent chan string
+}
+
+func (n fakeNotifier) Notify(_ context.Context, userID, _, _, _ string) error {
+	n.sent <- userID
+	return nil
+}
+
+// setupServer returns a Server backed by an in-memory SQLite database with
+// the messaging and blocks tables.
+func setupServer(t *testing.T) (*Server, fakeNotifier) {
+	t.Helper()
+	originalDB := db.DB
+	t.Cleanup(func() { db.DB = originalDB })
+
+	var err error
+	db.DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to setup test database: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE conversations (id TEXT PRIMARY KEY, type TEXT, direct_key TEXT UNIQUE, title TEXT,
+			created_by TEXT, created_at DATETIME, last_message_at DATETIME)`,
+		`CREATE TABLE conversation_members (conversation_id TEXT, user_id TEXT, joined_at DATETIME,
+			last_read_at DATETIME, PRIMARY KEY (conversation_id, user_id))`,
+		`CREATE TABLE messages (id TEXT PRIMARY KEY, conversation_id TEXT, sender_id TEXT, content TEXT,
+			message_type TEXT, created_at DATETIME)`,
+		`CREATE TABLE blocks (blocker_id TEXT, blocked_id TEXT)`,
+	} {
+		if err := db.DB.Exec(stmt).Error; err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+	notifier := fakeNotifier{sent: make(chan string, 10)}
+	return NewServer(realtime.NewHub(), notifier), notifier
+}
+
+func block(t *testing.T, blockerID, blockedID uuid.UUID) {
+	t.Helper()
+	if err := db.DB.Exec(`INSERT INTO blocks (blocker_id, blocked_id) VALUES (?, ?)`, blockerID, blockedID).Error; err != nil {
+		t.Fatalf("Failed to seed block: %v", err)
+	}
+}
+
+func as(userID uuid.UUID) context.Context {
+	return auth.ContextWithUserID(context.Background(), userID.String())
+}
+
+func TestPageLimit(t *testing.T) {
+	tests := []struct {
+		limit    int32
+		expected int
+		code     codes.Code
+	}{
+		{0, DefaultLimit, codes.OK},
+		{10, 10, codes.OK},
+		{MaxLimit + 1, MaxLimit, codes.OK},
+		{-1, 0, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		limit, err := pageLimit(tt.limit)
+		if status.Code(err) != tt.code || limit != tt.expected {
+			t.Errorf("pageLimit(%d) = %d, %v; expected %d, %v", tt.limit, limit, err, tt.expected, tt.code)
+		}
+	}
+}
+
+func TestParseIDs(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	ids, err := parseIDs([]string{a.String(), b.String(), a.String()}, "member_ids")
+	if err != nil {
+		t.Fatalf("parseIDs failed: %v", err)
+	}
+	if !slices.Equal(ids, []uuid.UUID{a, b}) {
+		t.Errorf("Expected duplicates to be dropped, got %v", ids)
+	}
+
+	_, err = parseIDs([]string{a.String(), "not-a-uuid"}, "member_ids")
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestOthers(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	members := []uuid.UUID{a, b, c}
+	if got := others(members, b); !slices.Equal(got, []uuid.UUID{a, c}) {
+		t.Errorf("Expected %v, got %v", []uuid.UUID{a, c}, got)
+	}
+	if !slices.Equal(members, []uuid.UUID{a, b, c}) {
+		t.Errorf("others modified its input: %v", members)
+	}
+}
+
+func TestIsRead(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	sentAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	before, after := sentAt.Add(-time.Minute), sentAt.Add(time.Minute)
+	message := &model.Message{SenderID: alice, CreatedAt: sentAt}
+
+	tests := []struct {
+		name     string
+		viewer   uuid.UUID
+		members  []model.ConversationMember
+		expected bool
+	}{
+		{"Own message, unread by recipient", alice, []model.ConversationMember{{UserID: alice, LastReadAt: &after}, {UserID: bob, LastReadAt: &before}}, false},
+		{"Own message, read by recipient", alice, []model.ConversationMember{{UserID: alice}, {UserID: bob, LastReadAt: &after}}, true},
+		{"Received message, unread", bob, []model.ConversationMember{{UserID: alice, LastReadAt: &after}, {UserID: bob}}, false},
+		{"Received message, read", bob, []model.ConversationMember{{UserID: alice}, {UserID: bob, LastReadAt: &sentAt}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRead(message, tt.viewer, tt.members); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestConversationOf(t *testing.T) {
+	setupServer(t)
+	alice, bob, eve := uuid.New(), uuid.New(), uuid.New()
+	conversation, err := db.GetOrCreateDirectConversation(alice, bob)
+	if err != nil {
+		t.Fatalf("GetOrCreateDirectConversation failed: %v", err)
+	}
+
+	if _, err := conversationOf(conversation.ID, alice); err != nil {
+		t.Errorf("Expected a member to get the conversation, got %v", err)
+	}
+	if _, err := conversationOf(conversation.ID, eve); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for a non-member, got %v", err)
+	}
+	if _, err := conversationOf(uuid.New(), alice); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for an unknown conversation, got %v", err)
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	server, notifier := setupServer(t)
+	alice, bob, eve := uuid.New(), uuid.New(), uuid.New()
+	block(t, eve, alice)
+	blockedConversation, err := db.GetOrCreateDirectConversation(alice, eve)
+	if err != nil {
+		t.Fatalf("GetOrCreateDirectConversation failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  *pb.SendMessageRequest
+		code codes.Code
+	}{
+		{"Empty content", &pb.SendMessageRequest{ReceiverId: bob.String(), Content: "  "}, codes.InvalidArgument},
+		{"Unknown message type", &pb.SendMessageRequest{ReceiverId: bob.String(), Content: "hi", MessageType: "video"}, codes.InvalidArgument},
+		{"No recipient", &pb.SendMessageRequest{Content: "hi"}, codes.InvalidArgument},
+		{"Message to yourself", &pb.SendMessageRequest{ReceiverId: alice.String(), Content: "hi"}, codes.InvalidArgument},
+		{"Blocked receiver", &pb.SendMessageRequest{ReceiverId: eve.String(), Content: "hi"}, codes.PermissionDenied},
+		{"Blocked direct conversation", &pb.SendMessageRequest{ConversationId: blockedConversation.ID.String(), Content: "hi"}, codes.PermissionDenied},
+		{"Direct message", &pb.SendMessageRequest{ReceiverId: bob.String(), Content: "hi"}, codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := server.SendMessage(as(alice), tt.req)
+			if status.Code(err) != tt.code {
+				t.Fatalf("Expected %v, got %v", tt.code, err)
+			}
+			if tt.code != codes.OK {
+				return
+			}
+			if resp.GetMessage().GetReceiverId() != bob.String() || resp.GetMessage().GetMessageType() != model.MessageText {
+				t.Errorf("Unexpected message %v", resp.GetMessage())
+			}
+			select {
+			case userID := <-notifier.sent:
+				if userID != bob.String() {
+					t.Errorf("Expected %s to be notified, got %s", bob, userID)
+				}
+			case <-time.After(time.Second):
+				t.Error("Expected the receiver to be notified")
+			}
+		})
+	}
+}
+
+func TestCreateConversation(t *testing.T) {
+	server, _ := setupServer(t)
+	alice, bob, carol, eve := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	block(t, alice, eve)
+
+	tests := []struct {
+		name         string
+		req          *pb.CreateConversationRequest
+		code         codes.Code
+		expectedType string
+	}{
+		{"No other member", &pb.CreateConversationRequest{MemberIds: []string{alice.String()}}, codes.InvalidArgument, ""},
+		{"Direct with two members", &pb.CreateConversationRequest{MemberIds: []string{bob.String(), carol.String()}, Type: model.ConversationDirect}, codes.InvalidArgument, ""},
+		{"Unknown type", &pb.CreateConversationRequest{MemberIds: []string{bob.String()}, Type: "channel"}, codes.InvalidArgument, ""},
+		{"Direct with blocked user", &pb.CreateConversationRequest{MemberIds: []string{eve.String()}}, codes.PermissionDenied, ""},
+		{"Group with blocked user", &pb.CreateConversationRequest{MemberIds: []string{bob.String(), eve.String()}}, codes.PermissionDenied, ""},
+		{"Direct by default", &pb.CreateConversationRequest{MemberIds: []string{bob.String()}}, codes.OK, model.ConversationDirect},
+		{"Group by default", &pb.CreateConversationRequest{MemberIds: []string{bob.String(), carol.String()}, Title: "Runners"}, codes.OK, model.ConversationGroup},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := server.CreateConversation(as(alice), tt.req)
+			if status.Code(err) != tt.code {
+				t.Fatalf("Expected %v, got %v", tt.code, err)
+			}
+			if tt.code == codes.OK && resp.GetConversation().GetType() != tt.expectedType {
+				t.Errorf("Expected a %s conversation, got %v", tt.expectedType, resp.GetConversation())
+			}
+		})
+	}
+}
+
+func TestGetMessages(t *testing.T) {
+	server, _ := setupServer(t)
+	alice, bob, eve := uuid.New(), uuid.New(), uuid.New()
+	var conversationID string
+	for _, content := range []string{"one", "two", "three"} {
+		resp, err := server.SendMessage(as(alice), &pb.SendMessageRequest{ReceiverId: bob.String(), Content: content})
+		if err != nil {
+			t.Fatalf("SendMessage failed: %v", err)
+		}
+		conversationID = resp.GetMessage().GetConversationId()
+		time.Sleep(time.Millisecond)
+	}
+
+	var contents []string
+	cursor := ""
+	for page := 0; page < 3; page++ {
+		resp, err := server.GetMessages(as(bob), &pb.GetMessagesRequest{ConversationId: conversationID, Limit: 2, BeforeId: cursor})
+		if err != nil {
+			t.Fatalf("GetMessages failed: %v", err)
+		}
+		for _, message := range resp.GetMessages() {
+			contents = append(contents, message.GetContent())
+			if message.GetIsRead() {
+				t.Errorf("Expected %q to be unread by the receiver", message.GetContent())
+			}
+		}
+		if cursor = resp.GetNextCursor(); cursor == "" {
+			break
+		}
+	}
+	if !slices.Equal(contents, []string{"three", "two", "one"}) {
+		t.Errorf("Expected messages newest first across pages, got %v", contents)
+	}
+
+	_, err := server.GetMessages(as(bob), &pb.GetMessagesRequest{ConversationId: conversationID, BeforeId: uuid.New().String()})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown cursor, got %v", err)
+	}
+	_, err = server.GetMessages(as(eve), &pb.GetMessagesRequest{ConversationId: conversationID})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for a non-member, got %v", err)
+	}
+}
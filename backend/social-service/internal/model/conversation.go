@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Conversation types. A direct conversation has exactly two members and
+// there is at most one per pair of users; group conversations can grow and
+// members can leave.
+const (
+	ConversationDirect = "direct"
+	ConversationGroup  = "group"
+)
+
+// Message types.
+const (
+	MessageText  = "text"
+	MessageImage = "image"
+)
+
+// Limits on conversations and messages.
+const (
+	MaxGroupMembers      = 50
+	MaxMessageLength     = 4000
+	MaxConversationTitle = 100
+)
+
+// Conversation is a direct or group chat.
+type Conversation struct {
+	ID   uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Type string    `json:"type" gorm:"type:varchar(10);not null" example:"group"`
+	// DirectKey is "<smaller user ID>:<larger user ID>" for direct
+	// conversations, so each pair of users shares a single one.
+	DirectKey     *string     `json:"-" gorm:"type:varchar(73);uniqueIndex"`
+	Title         string      `json:"title,omitempty" gorm:"type:varchar(100)"`
+	CreatedBy     uuid.UUID   `json:"created_by" gorm:"type:uuid;not null"`
+	CreatedAt     time.Time   `json:"created_at"`
+	LastMessageAt *time.Time  `json:"last_message_at,omitempty" gorm:"index"`
+	MemberIDs     []uuid.UUID `json:"member_ids" gorm:"-"`
+}
+
+// ConversationMember is a user taking part in a conversation. Messages sent
+// after LastReadAt by other members are unread.
+type ConversationMember struct {
+	ConversationID uuid.UUID  `json:"conversation_id" gorm:"type:uuid;primaryKey"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	JoinedAt       time.Time  `json:"joined_at" gorm:"not null"`
+	LastReadAt     *time.Time `json:"last_read_at,omitempty"`
+}
+
+// Message is a message in a conversation.
+type Message struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	ConversationID uuid.UUID `json:"conversation_id" gorm:"type:uuid;not null;index:idx_messages_conversation_created,priority:1"`
+	SenderID       uuid.UUID `json:"sender_id" gorm:"type:uuid;not null;index"`
+	Content        string    `json:"content" gorm:"type:text;not null"`
+	MessageType    string    `json:"message_type" gorm:"type:varchar(20);not null" example:"text"`
+	CreatedAt      time.Time `json:"created_at" gorm:"index:idx_messages_conversation_created,priority:2"`
+}
+
+// ConversationSummary is a conversation as listed for one of its members.
+type ConversationSummary struct {
+	Conversation Conversation `json:"conversation"`
+	LastMessage  *Message     `json:"last_message,omitempty"`
+	UnreadCount  int64        `json:"unread_count"`
+	// Members carries the read markers used to tell whether LastMessage
+	// is read.
+	Members []ConversationMember `json:"-"`
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types pushed to clients connected to GET /api/events.
+const (
+	EventFeed           = "feed"
+	EventFriendRequests = "friend_requests"
+	EventNotifications  = "notifications"
+	EventHeartbeat      = "heartbeat"
+	// EventMessage carries a messaging MessageEvent: a new message, a read
+	// receipt or a typing indicator.
+	EventMessage = "message"
+)
+
+// Event is a single server-sent event. Data is encoded as JSON.
+type Event struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// FriendRequest mirrors a row of user-service's friend_requests table.
+type FriendRequest struct {
+	ID         uuid.UUID `json:"id"`
+	SenderID   uuid.UUID `json:"sender_id"`
+	ReceiverID uuid.UUID `json:"receiver_id"`
+	Status     string    `json:"status" example:"pending"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// FeedEvent carries the feed items that appeared since the last event.
+type FeedEvent struct {
+	Items []FeedItem `json:"items"`
+}
+
+// FriendRequestsEvent lists the pending friend requests the user sent or
+// received, replacing the previous list.
+type FriendRequestsEvent struct {
+	Pending []FriendRequest `json:"pending"`
+}
+
+// NotificationsEvent carries the number of unread notifications.
+type NotificationsEvent struct {
+	UnreadCount int64 `json:"unread_count"`
+}
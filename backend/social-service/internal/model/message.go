@@ -25,4 +25,12 @@ type Friend struct {
 	FriendID  uuid.UUID `json:"friend_id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
-}
\ No newline at end of file
+}
+
+// PurgeUserDataResponse reports how many rows were removed from each table
+// when the data of a deleted account is purged.
+type PurgeUserDataResponse struct {
+	Service string           `json:"service" example:"social-service"`
+	UserID  uuid.UUID        `json:"user_id"`
+	Deleted map[string]int64 `json:"deleted"`
+}
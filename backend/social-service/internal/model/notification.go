@@ -0,0 +1,8 @@
+package model
+
+// Notification types produced by this service. Notifications are created
+// through user-service's internal API, which stores and pushes them.
+const (
+	NotificationMessage = "message"
+	NotificationComment = "comment"
+)
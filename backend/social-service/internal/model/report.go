@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Report statuses. A report starts open and is closed by a moderator as
+// either resolved (action was taken) or dismissed.
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+// Report is a user's complaint about another user or their content, queued
+// for moderators.
+type Report struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	ReporterID     uuid.UUID  `json:"reporter_id" gorm:"type:uuid;not null;index"`
+	TargetType     string     `json:"target_type" gorm:"type:varchar(20);not null" example:"user"`
+	TargetID       uuid.UUID  `json:"target_id" gorm:"type:uuid;not null;index"`
+	Reason         string     `json:"reason" gorm:"type:varchar(50);not null" example:"spam"`
+	Details        string     `json:"details,omitempty" gorm:"type:text"`
+	Status         string     `json:"status" gorm:"type:varchar(20);not null;default:open;index"`
+	ReviewedBy     *uuid.UUID `json:"reviewed_by,omitempty" gorm:"type:uuid"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+	ResolutionNote string     `json:"resolution_note,omitempty" gorm:"type:text"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type CreateReportRequest struct {
+	TargetType string    `json:"target_type" binding:"required,oneof=user activity meal message" example:"user"`
+	TargetID   uuid.UUID `json:"target_id" binding:"required"`
+	Reason     string    `json:"reason" binding:"required,oneof=spam harassment inappropriate other" example:"spam"`
+	Details    string    `json:"details" binding:"max=2000"`
+}
+
+type ReviewReportRequest struct {
+	Status string `json:"status" binding:"required,oneof=resolved dismissed" example:"resolved"`
+	Note   string `json:"note" binding:"max=2000"`
+}
+
+type ListReportsResponse struct {
+	Reports []Report `json:"reports"`
+}
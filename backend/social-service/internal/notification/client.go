@@ -0,0 +1,84 @@
+// Package notification creates notifications through user-service's
+// internal API, which stores them and pushes them to the user's devices.
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/auth"
+)
+
+// Notifier tells userID that actorID did something concerning entityID,
+// e.g. sent the message with that ID.
+type Notifier interface {
+	Notify(ctx context.Context, userID, actorID, notificationType, entityID string) error
+}
+
+// NewNotifierFromEnv returns a Client for USER_SERVICE_URL. Without it
+// notifications are only written to the log.
+func NewNotifierFromEnv() Notifier {
+	url := os.Getenv("USER_SERVICE_URL")
+	if url == "" {
+		log.Println("USER_SERVICE_URL not set, notifications will be logged instead of sent")
+		return LogNotifier{}
+	}
+	return &Client{
+		URL:    url,
+		Token:  os.Getenv("INTERNAL_API_TOKEN"),
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Client calls user-service's internal notifications endpoint.
+type Client struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+func (c *Client) Notify(ctx context.Context, userID, actorID, notificationType, entityID string) error {
+	body, err := json.Marshal(map[string]string{
+		"type":      notificationType,
+		"actor_id":  actorID,
+		"entity_id": entityID,
+	})
+	if err != nil {
+		return err
+	}
+	url := strings.TrimRight(c.URL, "/") + "/internal/users/" + userID + "/notifications"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(auth.InternalTokenHeader, c.Token)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// LogNotifier writes notifications to the standard logger instead of
+// sending them.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(_ context.Context, userID, actorID, notificationType, entityID string) error {
+	log.Printf("%s notification for %s from %s about %s", notificationType, userID, actorID, entityID)
+	return nil
+}
@@ -0,0 +1,40 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/auth"
+)
+
+func TestClientNotify(t *testing.T) {
+	var path, token string
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, token = r.URL.Path, r.Header.Get(auth.InternalTokenHeader)
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["entity_id"] == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := &Client{URL: server.URL + "/", Token: "internal-secret", Client: server.Client()}
+	if err := client.Notify(context.Background(), "user-1", "user-2", "message", "message-1"); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if path != "/internal/users/user-1/notifications" || token != "internal-secret" {
+		t.Errorf("Unexpected request to %s with token %q", path, token)
+	}
+	if body["type"] != "message" || body["actor_id"] != "user-2" || body["entity_id"] != "message-1" {
+		t.Errorf("Unexpected body %v", body)
+	}
+	if err := client.Notify(context.Background(), "user-1", "user-2", "message", "fail"); err == nil {
+		t.Error("Expected error for a failed request")
+	}
+}
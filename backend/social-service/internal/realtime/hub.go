@@ -0,0 +1,119 @@
+// Package realtime pushes updates to clients that keep a connection open to
+// social-service. A Hub fans events out to every connection of a user, and
+// a Watcher notices changes to data owned by other services by polling the
+// shared database for the users who are connected.
+package realtime
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/model"
+)
+
+// DefaultMaxConnsPerUser limits how many tabs and devices of one user can be
+// connected at the same time.
+const DefaultMaxConnsPerUser = 10
+
+// DefaultBuffer is how many events may wait for a slow connection before it
+// is dropped.
+const DefaultBuffer = 32
+
+// ErrTooManyConnections is returned by Subscribe when the user already has
+// MaxConnsPerUser connections.
+var ErrTooManyConnections = errors.New("too many connections")
+
+// Conn is one client connection. Events is closed when the connection is
+// unsubscribed or dropped for falling behind.
+type Conn struct {
+	UserID string
+	Events <-chan model.Event
+
+	events chan model.Event
+	closed bool
+}
+
+// Hub keeps track of the open connections of each user.
+type Hub struct {
+	MaxConnsPerUser int
+	Buffer          int
+
+	mu    sync.Mutex
+	conns map[string]map[*Conn]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		MaxConnsPerUser: DefaultMaxConnsPerUser,
+		Buffer:          DefaultBuffer,
+		conns:           make(map[string]map[*Conn]struct{}),
+	}
+}
+
+// Subscribe opens a connection for userID.
+func (h *Hub) Subscribe(userID string) (*Conn, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.conns[userID]) >= h.MaxConnsPerUser {
+		return nil, ErrTooManyConnections
+	}
+	events := make(chan model.Event, h.Buffer)
+	conn := &Conn{UserID: userID, Events: events, events: events}
+	if h.conns[userID] == nil {
+		h.conns[userID] = make(map[*Conn]struct{})
+	}
+	h.conns[userID][conn] = struct{}{}
+	return conn, nil
+}
+
+// Unsubscribe closes a connection. It is safe to call more than once.
+func (h *Hub) Unsubscribe(conn *Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(conn)
+}
+
+func (h *Hub) remove(conn *Conn) {
+	if conn.closed {
+		return
+	}
+	conn.closed = true
+	close(conn.events)
+	delete(h.conns[conn.UserID], conn)
+	if len(h.conns[conn.UserID]) == 0 {
+		delete(h.conns, conn.UserID)
+	}
+}
+
+// Publish sends an event to every connection of userID. It never blocks:
+// a connection whose buffer is full is dropped, and the client is expected
+// to reconnect and start again from a fresh snapshot.
+func (h *Hub) Publish(userID string, event model.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for conn := range h.conns[userID] {
+		select {
+		case conn.events <- event:
+		default:
+			h.remove(conn)
+		}
+	}
+}
+
+// Users returns the IDs of the users with at least one connection.
+func (h *Hub) Users() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	users := make([]string, 0, len(h.conns))
+	for userID := range h.conns {
+		users = append(users, userID)
+	}
+	return users
+}
+
+// Connections returns how many connections userID has.
+func (h *Hub) Connections(userID string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.conns[userID])
+}
@@ -0,0 +1,94 @@
+package realtime
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/model"
+)
+
+func TestHubConnectionLimit(t *testing.T) {
+	hub := NewHub()
+	hub.MaxConnsPerUser = 2
+
+	first, err := hub.Subscribe("alice")
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if _, err := hub.Subscribe("alice"); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if _, err := hub.Subscribe("alice"); !errors.Is(err, ErrTooManyConnections) {
+		t.Errorf("Expected ErrTooManyConnections, got %v", err)
+	}
+	if _, err := hub.Subscribe("bob"); err != nil {
+		t.Errorf("Expected the limit to be per user, got %v", err)
+	}
+
+	hub.Unsubscribe(first)
+	if _, err := hub.Subscribe("alice"); err != nil {
+		t.Errorf("Expected a freed slot to be reusable, got %v", err)
+	}
+}
+
+func TestHubPublish(t *testing.T) {
+	hub := NewHub()
+	phone, _ := hub.Subscribe("alice")
+	laptop, _ := hub.Subscribe("alice")
+	other, _ := hub.Subscribe("bob")
+
+	hub.Publish("alice", model.Event{Type: model.EventFeed})
+	for _, conn := range []*Conn{phone, laptop} {
+		if event := <-conn.Events; event.Type != model.EventFeed {
+			t.Errorf("Expected a feed event, got %+v", event)
+		}
+	}
+	select {
+	case event := <-other.Events:
+		t.Errorf("Expected no event for another user, got %+v", event)
+	default:
+	}
+}
+
+func TestHubDropsSlowConnections(t *testing.T) {
+	hub := NewHub()
+	hub.Buffer = 2
+	slow, _ := hub.Subscribe("alice")
+	fast, _ := hub.Subscribe("alice")
+
+	for i := 0; i < 3; i++ {
+		hub.Publish("alice", model.Event{Type: model.EventNotifications})
+		<-fast.Events
+	}
+
+	received := 0
+	for range slow.Events {
+		received++
+	}
+	if received != 2 {
+		t.Errorf("Expected the buffered events before the connection was closed, got %d", received)
+	}
+	if hub.Connections("alice") != 1 {
+		t.Errorf("Expected only the fast connection to remain, got %d", hub.Connections("alice"))
+	}
+}
+
+func TestHubUnsubscribeTwice(t *testing.T) {
+	hub := NewHub()
+	conn, _ := hub.Subscribe("alice")
+
+	hub.Unsubscribe(conn)
+	hub.Unsubscribe(conn)
+	if _, ok := <-conn.Events; ok {
+		t.Error("Expected the events channel to be closed")
+	}
+	if hub.Connections("alice") != 0 || len(hub.Users()) != 0 {
+		t.Errorf("Expected no connections left, got %d for users %v", hub.Connections("alice"), hub.Users())
+	}
+
+	// A connection dropped by Publish can still be unsubscribed.
+	hub.Buffer = 0
+	dropped, _ := hub.Subscribe("alice")
+	hub.Publish("alice", model.Event{Type: model.EventFeed})
+	hub.Unsubscribe(dropped)
+}
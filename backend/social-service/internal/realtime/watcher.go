@@ -0,0 +1,164 @@
+package realtime
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/db"
+	"github.com/ffabious/healthy-summer/social-service/internal/model"
+)
+
+// Snapshot is the state of a user that clients are kept up to date with.
+type Snapshot struct {
+	Feed                []model.FeedItem
+	FriendRequests      []model.FriendRequest
+	UnreadNotifications int64
+}
+
+// Source loads a user's current snapshot.
+type Source interface {
+	Snapshot(userID string) (*Snapshot, error)
+}
+
+// DBSource reads snapshots from the shared database.
+type DBSource struct{}
+
+func (DBSource) Snapshot(userID string) (*Snapshot, error) {
+	friends, err := db.GetFriends(userID)
+	if err != nil {
+		return nil, err
+	}
+	feed, err := db.GetFeedByFriends(userID, friends)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := db.GetPendingFriendRequests(userID)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := db.CountUnreadNotifications(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Feed: feed.Items, FriendRequests: requests, UnreadNotifications: unread}, nil
+}
+
+// Events returns the events that bring a client from previous to current.
+// Without a previous snapshot the client is sent everything.
+func Events(previous, current *Snapshot) []model.Event {
+	feed := model.Event{Type: model.EventFeed, Data: model.FeedEvent{Items: nonNil(current.Feed)}}
+	requests := model.Event{Type: model.EventFriendRequests, Data: model.FriendRequestsEvent{Pending: nonNil(current.FriendRequests)}}
+	notifications := model.Event{Type: model.EventNotifications, Data: model.NotificationsEvent{UnreadCount: current.UnreadNotifications}}
+	if previous == nil {
+		return []model.Event{feed, requests, notifications}
+	}
+
+	var events []model.Event
+	if items := newFeedItems(previous.Feed, current.Feed); len(items) > 0 {
+		events = append(events, model.Event{Type: model.EventFeed, Data: model.FeedEvent{Items: items}})
+	}
+	if !sameRequests(previous.FriendRequests, current.FriendRequests) {
+		events = append(events, requests)
+	}
+	if previous.UnreadNotifications != current.UnreadNotifications {
+		events = append(events, notifications)
+	}
+	return events
+}
+
+// newFeedItems returns the items of current whose friend was not in previous.
+// Feed items are regenerated on every read, so friends identify them.
+func newFeedItems(previous, current []model.FeedItem) []model.FeedItem {
+	var items []model.FeedItem
+	for _, item := range current {
+		if !slices.ContainsFunc(previous, func(p model.FeedItem) bool { return p.FriendID == item.FriendID }) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func sameRequests(a, b []model.FriendRequest) bool {
+	return slices.EqualFunc(a, b, func(x, y model.FriendRequest) bool {
+		return x.ID == y.ID && x.Status == y.Status
+	})
+}
+
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// Watcher polls the snapshots of connected users and publishes what changed.
+type Watcher struct {
+	Hub    *Hub
+	Source Source
+
+	mu   sync.Mutex
+	last map[string]*Snapshot
+}
+
+// Track records the snapshot the first connection of a user was sent, so
+// that the next poll only publishes what changed after it. Users who are
+// already tracked keep the state their other connections have seen.
+func (w *Watcher) Track(userID string, snapshot *Snapshot) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.last == nil {
+		w.last = make(map[string]*Snapshot)
+	}
+	if _, ok := w.last[userID]; !ok {
+		w.last[userID] = snapshot
+	}
+}
+
+// Poll publishes changes for every connected user and forgets users who
+// disconnected.
+func (w *Watcher) Poll() {
+	for _, userID := range w.Hub.Users() {
+		current, err := w.Source.Snapshot(userID)
+		if err != nil {
+			log.Printf("Failed to load realtime snapshot for user %s: %v", userID, err)
+			continue
+		}
+		w.mu.Lock()
+		previous, ok := w.last[userID]
+		if w.last == nil {
+			w.last = make(map[string]*Snapshot)
+		}
+		w.last[userID] = current
+		w.mu.Unlock()
+		if ok {
+			for _, event := range Events(previous, current) {
+				w.Hub.Publish(userID, event)
+			}
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for userID := range w.last {
+		if w.Hub.Connections(userID) == 0 {
+			delete(w.last, userID)
+		}
+	}
+}
+
+// Start polls every interval until ctx is cancelled.
+func (w *Watcher) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Poll()
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package realtime
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ffabious/healthy-summer/social-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// fakeSource serves snapshots from a map.
+type fakeSource map[string]*Snapshot
+
+func (s fakeSource) Snapshot(userID string) (*Snapshot, error) {
+	snapshot, ok := s[userID]
+	if !ok {
+		return nil, errors.New("no snapshot")
+	}
+	return snapshot, nil
+}
+
+func eventTypes(events []model.Event) []string {
+	types := make([]string, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestEvents(t *testing.T) {
+	friend, otherFriend := uuid.New(), uuid.New()
+	request := model.FriendRequest{ID: uuid.New(), Status: "pending"}
+	base := &Snapshot{
+		Feed:                []model.FeedItem{{ActivityID: uuid.New(), FriendID: friend}},
+		FriendRequests:      []model.FriendRequest{request},
+		UnreadNotifications: 1,
+	}
+
+	tests := []struct {
+		name     string
+		previous *Snapshot
+		current  *Snapshot
+		expected []string
+	}{
+		{
+			name:     "Initial snapshot",
+			current:  &Snapshot{},
+			expected: []string{model.EventFeed, model.EventFriendRequests, model.EventNotifications},
+		},
+		{
+			name:     "Nothing changed",
+			previous: base,
+			// Feed items get new IDs on every read.
+			current: &Snapshot{
+				Feed:                []model.FeedItem{{ActivityID: uuid.New(), FriendID: friend}},
+				FriendRequests:      []model.FriendRequest{request},
+				UnreadNotifications: 1,
+			},
+		},
+		{
+			name:     "Friend joined the feed",
+			previous: base,
+			current: &Snapshot{
+				Feed:                []model.FeedItem{{FriendID: friend}, {FriendID: otherFriend}},
+				FriendRequests:      []model.FriendRequest{request},
+				UnreadNotifications: 1,
+			},
+			expected: []string{model.EventFeed},
+		},
+		{
+			name:     "Friend left the feed",
+			previous: base,
+			current:  &Snapshot{FriendRequests: []model.FriendRequest{request}, UnreadNotifications: 1},
+		},
+		{
+			name:     "Request answered",
+			previous: base,
+			current: &Snapshot{
+				Feed:                base.Feed,
+				FriendRequests:      []model.FriendRequest{{ID: request.ID, Status: "accepted"}},
+				UnreadNotifications: 1,
+			},
+			expected: []string{model.EventFriendRequests},
+		},
+		{
+			name:     "Request withdrawn and notification read",
+			previous: base,
+			current:  &Snapshot{Feed: base.Feed},
+			expected: []string{model.EventFriendRequests, model.EventNotifications},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := eventTypes(Events(tt.previous, tt.current))
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected events %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Expected events %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+
+	events := Events(base, &Snapshot{Feed: []model.FeedItem{{FriendID: friend}, {FriendID: otherFriend}}, FriendRequests: []model.FriendRequest{request}, UnreadNotifications: 1})
+	if items := events[0].Data.(model.FeedEvent).Items; len(items) != 1 || items[0].FriendID != otherFriend {
+		t.Errorf("Expected only the new friend's item, got %+v", items)
+	}
+}
+
+func TestWatcherPoll(t *testing.T) {
+	hub := NewHub()
+	source := fakeSource{"alice": {UnreadNotifications: 1}}
+	watcher := &Watcher{Hub: hub, Source: source}
+
+	conn, _ := hub.Subscribe("alice")
+	watcher.Track("alice", source["alice"])
+	// A second connection does not reset what the first one has seen.
+	watcher.Track("alice", &Snapshot{})
+
+	watcher.Poll()
+	select {
+	case event := <-conn.Events:
+		t.Errorf("Expected no event without changes, got %+v", event)
+	default:
+	}
+
+	source["alice"] = &Snapshot{UnreadNotifications: 2}
+	watcher.Poll()
+	event := <-conn.Events
+	if data, ok := event.Data.(model.NotificationsEvent); !ok || data.UnreadCount != 2 {
+		t.Errorf("Expected an unread count of 2, got %+v", event)
+	}
+
+	hub.Unsubscribe(conn)
+	watcher.Poll()
+	if _, ok := watcher.last["alice"]; ok {
+		t.Error("Expected a disconnected user to be forgotten")
+	}
+}
@@ -1,13 +1,24 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	_ "github.com/ffabious/healthy-summer/user-service/docs"
+	"github.com/ffabious/healthy-summer/user-service/internal/activity"
 	"github.com/ffabious/healthy-summer/user-service/internal/auth"
 	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/deletion"
+	"github.com/ffabious/healthy-summer/user-service/internal/digest"
 	"github.com/ffabious/healthy-summer/user-service/internal/handler"
+	"github.com/ffabious/healthy-summer/user-service/internal/lockout"
+	"github.com/ffabious/healthy-summer/user-service/internal/mail"
+	"github.com/ffabious/healthy-summer/user-service/internal/oidc"
+	"github.com/ffabious/healthy-summer/user-service/internal/push"
+	"github.com/ffabious/healthy-summer/user-service/internal/upload"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -25,36 +36,155 @@ func main() {
 	}
 
 	db.Connect()
+	handler.Mailer = mail.NewSenderFromEnv()
+	auth.SessionValidator = db.ValidateSession
+	handler.LoginLimiter = newLoginLimiter()
+	handler.OIDCProviders = oidc.ProvidersFromEnv()
+	handler.ActivityClient = activity.NewClientFromEnv()
+	handler.UploadStorage = upload.StorageFromEnv()
+	handler.PushDispatcher = push.NewDispatcherFromEnv(push.NewPostgresStore(db.DB))
+	if v := os.Getenv("ADMIN_EMAILS"); v != "" {
+		if err := db.GrantRoleByEmail(strings.Split(v, ","), auth.RoleAdmin); err != nil {
+			log.Printf("Failed to grant admin role: %v", err)
+		}
+	}
+	go pruneOIDCLoginStates(context.Background(), time.Hour)
+
+	processor := deletion.NewProcessorFromEnv()
+	processor.Uploads = handler.UploadStorage
+	go processor.Start(context.Background(), time.Minute)
+
+	handler.DigestBuilder = digest.NewBuilderFromEnv()
+	digests := &digest.Job{Builder: handler.DigestBuilder, Mailer: handler.Mailer}
+	go digests.Start(context.Background(), 15*time.Minute)
 
 	r := gin.Default()
+	// Client IPs feed the login lockout, so forwarded headers are only
+	// honoured from known proxies.
+	var proxies []string
+	if v := os.Getenv("TRUSTED_PROXIES"); v != "" {
+		proxies = strings.Split(v, ",")
+	}
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Accept"},
+		ExposeHeaders:    []string{"Retry-After"},
 		AllowCredentials: true,
 	}))
 
 	r.GET("/api/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	r.POST("/api/users/login", handler.LoginHandler)
+	r.POST("/api/users/login/mfa", handler.MFALoginHandler)
+	r.GET("/api/users/oidc/providers", handler.ListOIDCProvidersHandler)
+	r.POST("/api/users/oidc/:provider/authorize", handler.OIDCAuthorizeHandler)
+	r.POST("/api/users/oidc/:provider/callback", handler.OIDCCallbackHandler)
 	r.POST("/api/users/register", handler.RegisterHandler)
+	r.POST("/api/users/verify-email", handler.VerifyEmailHandler)
+	r.POST("/api/users/password/forgot", handler.ForgotPasswordHandler)
+	r.POST("/api/users/password/reset", handler.ResetPasswordHandler)
+	r.POST("/api/users/email/confirm", handler.ConfirmEmailChangeHandler)
+	r.GET("/api/uploads/files/*key", handler.ServeUploadHandler)
+	r.GET("/api/users/push/web-config", handler.GetWebPushConfigHandler)
 
 	protected := r.Group("/api/users")
 	protected.Use(auth.JWTMiddleware())
 
 	protected.GET("/me", handler.GetCurrentUserHandler)
+	protected.POST("/verify-email/resend", handler.ResendVerificationHandler)
+	protected.DELETE("/me", handler.DeleteCurrentUserHandler)
+	protected.GET("/me/deletion", handler.GetAccountDeletionHandler)
+	protected.POST("/me/deletion/cancel", handler.CancelAccountDeletionHandler)
+	protected.PUT("/me/avatar", handler.SetAvatarHandler)
+	protected.DELETE("/me/avatar", handler.DeleteAvatarHandler)
+	protected.GET("/me/devices", handler.GetDevicesHandler)
+	protected.POST("/me/devices", handler.RegisterDeviceHandler)
+	protected.DELETE("/me/devices", handler.UnregisterDeviceHandler)
+	protected.GET("/me/digest", handler.GetWeeklyDigestHandler)
 	protected.GET("/profile", handler.GetProfileHandler)
 	protected.PUT("/profile", handler.UpdateProfileHandler)
+	protected.PUT("/password", handler.ChangePasswordHandler)
+	protected.POST("/email", handler.ChangeEmailHandler)
+	protected.GET("/privacy", handler.GetPrivacySettingsHandler)
+	protected.PUT("/privacy", handler.UpdatePrivacySettingsHandler)
+	protected.GET("/mfa", handler.GetMFAStatusHandler)
+	protected.POST("/mfa/totp/setup", handler.SetupTOTPHandler)
+	protected.POST("/mfa/totp/confirm", handler.ConfirmTOTPHandler)
+	protected.POST("/mfa/totp/disable", handler.DisableTOTPHandler)
+	protected.POST("/mfa/recovery-codes", handler.RegenerateRecoveryCodesHandler)
 	protected.GET("/friends", handler.GetFriendsHandler)
 	protected.POST("/friends/request", handler.SendFriendRequestHandler)
 	protected.GET("/friends/requests", handler.GetPendingFriendRequestsHandler)
+	protected.GET("/friends/suggestions", handler.GetFriendSuggestionsHandler)
+	protected.GET("/friends/requests/sent", handler.GetSentFriendRequestsHandler)
+	protected.DELETE("/friends/requests/:id", handler.CancelFriendRequestHandler)
 	protected.POST("/friends/respond", handler.RespondToFriendRequestHandler)
+	protected.DELETE("/friends/:id", handler.RemoveFriendHandler)
+	protected.GET("/blocks", handler.GetBlockedUsersHandler)
+	protected.POST("/blocks", handler.BlockUserHandler)
+	protected.DELETE("/blocks/:id", handler.UnblockUserHandler)
 	protected.GET("/search", handler.SearchUsersHandler)
 	protected.POST("/achievements", handler.AddAchievementHandler)
+	protected.GET("/data/export", handler.ExportUserDataHandler)
+	protected.POST("/data/import", handler.ImportUserDataHandler)
+	protected.GET("/:id", handler.GetUserProfileHandler)
+
+	uploads := r.Group("/api/uploads")
+	uploads.Use(auth.JWTMiddleware())
+	uploads.POST("", handler.UploadImageHandler)
+
+	notifications := r.Group("/api/notifications")
+	notifications.Use(auth.JWTMiddleware())
+	notifications.GET("", handler.GetNotificationsHandler)
+	notifications.GET("/unread-count", handler.GetUnreadNotificationCountHandler)
+	notifications.POST("/read-all", handler.MarkAllNotificationsReadHandler)
+	notifications.POST("/:id/read", handler.MarkNotificationReadHandler)
+
+	internal := r.Group("/internal")
+	internal.Use(auth.InternalMiddleware())
+	internal.POST("/users/:user_id/notifications", handler.CreateNotificationHandler)
+
+	admin := r.Group("/api/admin")
+	admin.Use(auth.JWTMiddleware(), auth.RequireRole(auth.RoleAdmin))
+	admin.GET("/users", handler.AdminListUsersHandler)
+	admin.POST("/users/:id/suspend", handler.AdminSuspendUserHandler)
+	admin.POST("/users/:id/unsuspend", handler.AdminUnsuspendUserHandler)
+	admin.PUT("/users/:id/role", handler.AdminSetUserRoleHandler)
 
 	runRegular(r, port)
 }
 
+// newLoginLimiter keeps login attempts in Postgres so replicas share them,
+// unless LOGIN_ATTEMPT_STORE=memory.
+func newLoginLimiter() *lockout.Limiter {
+	if os.Getenv("LOGIN_ATTEMPT_STORE") == "memory" {
+		return lockout.NewLimiter(lockout.NewMemoryStore())
+	}
+	store := lockout.NewPostgresStore(db.DB)
+	go store.PruneEvery(context.Background(), time.Hour, lockout.DefaultIPPolicy.Window)
+	return lockout.NewLimiter(store)
+}
+
+// pruneOIDCLoginStates deletes logins that were started but never completed.
+func pruneOIDCLoginStates(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := db.DeleteExpiredOIDCLoginStates(now); err != nil {
+				log.Printf("Failed to prune OIDC login states: %v", err)
+			}
+		}
+	}
+}
+
 func runRegular(r *gin.Engine, port string) {
 	log.Printf("Starting user service on :%s", port)
 	if err := r.Run("0.0.0.0:" + port); err != nil {
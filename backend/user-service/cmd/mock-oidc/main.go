@@ -0,0 +1,39 @@
+// Command mock-oidc runs a local OpenID Connect issuer that signs in a fixed
+// user, for trying social login without a real provider. Configure the
+// user-service with OIDC_PROVIDERS=local and OIDC_LOCAL_ISSUER pointing here.
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/oidc/oidctest"
+)
+
+func getenv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func main() {
+	addr := getenv("MOCK_OIDC_ADDR", ":8090")
+	issuerURL := getenv("MOCK_OIDC_ISSUER", "http://localhost:8090")
+
+	iss, err := oidctest.New(issuerURL, getenv("MOCK_OIDC_CLIENT_ID", "healthy-summer"), getenv("MOCK_OIDC_CLIENT_SECRET", "secret"))
+	if err != nil {
+		log.Fatalf("Failed to create issuer: %v", err)
+	}
+	iss.SetUser(oidctest.User{
+		Subject:       getenv("MOCK_OIDC_SUBJECT", "local-user"),
+		Email:         getenv("MOCK_OIDC_EMAIL", "local.user@example.com"),
+		EmailVerified: true,
+		GivenName:     "Local",
+		FamilyName:    "User",
+	})
+
+	log.Printf("Mock OIDC issuer %s listening on %s", issuerURL, addr)
+	log.Fatal(http.ListenAndServe(addr, iss.Handler()))
+}
@@ -0,0 +1,84 @@
+// Package activity fetches activity data that user-service shows alongside a
+// user's profile or in their weekly digest from activity-service's internal
+// API.
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// Client calls activity-service's internal endpoints.
+type Client struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+// NewClientFromEnv configures a Client from ACTIVITY_SERVICE_URL and
+// INTERNAL_API_TOKEN. It returns nil when the URL is not set.
+func NewClientFromEnv() *Client {
+	url := os.Getenv("ACTIVITY_SERVICE_URL")
+	if url == "" {
+		return nil
+	}
+	return &Client{
+		URL:    url,
+		Token:  os.Getenv("INTERNAL_API_TOKEN"),
+		Client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Summary returns the user's recent activity.
+func (c *Client) Summary(ctx context.Context, userID uuid.UUID) (*model.ActivitySummary, error) {
+	var summary model.ActivitySummary
+	if err := c.get(ctx, "/internal/users/"+userID.String()+"/activity-summary", &summary); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// Period totals the user's activities and steps between from (inclusive)
+// and to (exclusive).
+func (c *Client) Period(ctx context.Context, userID uuid.UUID, from, to time.Time) (*model.ActivityPeriod, error) {
+	query := url.Values{"from": {from.Format(time.RFC3339)}, "to": {to.Format(time.RFC3339)}}
+	var period model.ActivityPeriod
+	if err := c.get(ctx, "/internal/users/"+userID.String()+"/activity-period?"+query.Encode(), &period); err != nil {
+		return nil, err
+	}
+	return &period, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(c.URL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(auth.InternalTokenHeader, c.Token)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
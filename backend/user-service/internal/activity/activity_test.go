@@ -0,0 +1,82 @@
+package activity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/google/uuid"
+)
+
+func TestSummary(t *testing.T) {
+	userID := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/internal/users/"+userID.String()+"/activity-summary" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get(auth.InternalTokenHeader) != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"week":{"activity_count":3,"duration_min":90},"month":{"activity_count":10},"last_activity_type":"running"}`))
+	}))
+	defer server.Close()
+
+	client := &Client{URL: server.URL + "/", Token: "secret", Client: server.Client()}
+	summary, err := client.Summary(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if summary.Week.ActivityCount != 3 || summary.Week.DurationMin != 90 || summary.Month.ActivityCount != 10 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if summary.LastActivityType != "running" || summary.LastActivityAt != nil {
+		t.Errorf("Unexpected last activity: %+v", summary)
+	}
+
+	client.Token = "wrong"
+	if _, err := client.Summary(context.Background(), userID); err == nil {
+		t.Error("Expected error for rejected token, got none")
+	}
+}
+
+func TestPeriod(t *testing.T) {
+	userID := uuid.New()
+	from := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/internal/users/"+userID.String()+"/activity-period" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("from") != "2025-06-02T00:00:00Z" || r.URL.Query().Get("to") != "2025-06-09T00:00:00Z" {
+			t.Errorf("Unexpected query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"activity_count":4,"duration_min":150,"calories":1200,"steps":52000}`))
+	}))
+	defer server.Close()
+
+	client := &Client{URL: server.URL, Token: "secret", Client: server.Client()}
+	period, err := client.Period(context.Background(), userID, from, from.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if period.ActivityCount != 4 || period.DurationMin != 150 || period.Calories != 1200 || period.Steps != 52000 {
+		t.Errorf("Unexpected period: %+v", period)
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv("ACTIVITY_SERVICE_URL", "")
+	if NewClientFromEnv() != nil {
+		t.Error("Expected no client without ACTIVITY_SERVICE_URL")
+	}
+
+	t.Setenv("ACTIVITY_SERVICE_URL", "http://activity-service:8081")
+	t.Setenv("INTERNAL_API_TOKEN", "secret")
+	client := NewClientFromEnv()
+	if client == nil || client.URL != "http://activity-service:8081" || client.Token != "secret" {
+		t.Errorf("Unexpected client: %+v", client)
+	}
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InternalTokenHeader carries the shared secret (INTERNAL_API_TOKEN) on
+// service-to-service calls.
+const InternalTokenHeader = "X-Internal-Token"
+
+// InternalMiddleware guards routes that are only meant to be called by other
+// services. The request must carry INTERNAL_API_TOKEN in the
+// X-Internal-Token header; when the variable is unset every request is
+// rejected.
+func InternalMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		expected := os.Getenv("INTERNAL_API_TOKEN")
+		token := c.GetHeader(InternalTokenHeader)
+		if expected == "" || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid internal token"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"fmt"
 	"os"
 	"time"
 
@@ -10,12 +11,65 @@ import (
 
 var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
 
-func GenerateJWT(userID uuid.UUID) (string, error) {
+// SessionValidator, when set, is called by JWTMiddleware with the user ID and
+// token version from the claims. It lets tokens issued before a password
+// change be rejected even though they have not expired.
+var SessionValidator func(userID string, tokenVersion int) error
+
+// GenerateJWT issues a 24 hour token. tokenVersion must be the user's current
+// token version for the token to pass SessionValidator. role is read by
+// RequireRole in every service, so changing it must bump the token version.
+func GenerateJWT(userID uuid.UUID, tokenVersion int, role string) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id": userID.String(),
+		"ver":     tokenVersion,
+		"role":    role,
 		"exp":     time.Now().Add(24 * time.Hour).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtSecret)
 }
+
+// MFAChallengeTTL is how long the user has to enter their second factor
+// after the password was accepted.
+const MFAChallengeTTL = 5 * time.Minute
+
+// GenerateMFAChallenge issues the token returned by login when the second
+// factor is still missing. It has no user_id claim, so JWTMiddleware does not
+// accept it as an access token.
+func GenerateMFAChallenge(userID uuid.UUID, tokenVersion int) (string, error) {
+	claims := jwt.MapClaims{
+		"sub":     userID.String(),
+		"purpose": "mfa",
+		"ver":     tokenVersion,
+		"exp":     time.Now().Add(MFAChallengeTTL).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret)
+}
+
+// ParseMFAChallenge verifies a challenge token and returns its user ID and
+// token version.
+func ParseMFAChallenge(tokenStr string) (uuid.UUID, int, error) {
+	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return jwtSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return uuid.Nil, 0, fmt.Errorf("invalid challenge token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != "mfa" {
+		return uuid.Nil, 0, fmt.Errorf("invalid challenge token")
+	}
+	sub, _ := claims["sub"].(string)
+	userID, err := uuid.Parse(sub)
+	if err != nil {
+		return uuid.Nil, 0, fmt.Errorf("invalid challenge token")
+	}
+	version, _ := claims["ver"].(float64)
+	return userID, int(version), nil
+}
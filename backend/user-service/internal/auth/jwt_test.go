@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestMFAChallengeRoundTrip(t *testing.T) {
+	originalSecret := jwtSecret
+	defer func() { jwtSecret = originalSecret }()
+	jwtSecret = []byte("test-secret")
+
+	userID := uuid.New()
+	token, err := GenerateMFAChallenge(userID, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	gotID, version, err := ParseMFAChallenge(token)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotID != userID || version != 3 {
+		t.Errorf("Expected %s version 3, got %s version %d", userID, gotID, version)
+	}
+}
+
+func TestParseMFAChallengeRejectsAccessTokens(t *testing.T) {
+	originalSecret := jwtSecret
+	defer func() { jwtSecret = originalSecret }()
+	jwtSecret = []byte("test-secret")
+
+	access, err := GenerateJWT(uuid.New(), 0, RoleUser)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, _, err := ParseMFAChallenge(access); err == nil {
+		t.Error("Expected access token to be rejected as MFA challenge")
+	}
+	if _, _, err := ParseMFAChallenge("garbage"); err == nil {
+		t.Error("Expected malformed token to be rejected")
+	}
+}
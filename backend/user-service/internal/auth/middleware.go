@@ -37,7 +37,17 @@ func JWTMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if SessionValidator != nil {
+			userID, _ := claims["user_id"].(string)
+			version, _ := claims["ver"].(float64)
+			if err := SessionValidator(userID, int(version)); err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session expired", "details": err.Error()})
+				return
+			}
+		}
+
 		c.Set("user_id", claims["user_id"])
+		c.Set("role", roleFromClaims(claims))
 		c.Next()
 	}
 }
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -227,3 +228,54 @@ func generateUnverifiedTokenWithEmptyUserID() string {
 	tokenString, _ := token.SignedString([]byte("any-secret"))
 	return tokenString
 }
+
+func TestJWTMiddlewareSessionValidator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	originalSecret := os.Getenv("JWT_SECRET")
+	defer os.Setenv("JWT_SECRET", originalSecret)
+	os.Setenv("JWT_SECRET", "test-secret")
+
+	originalValidator := SessionValidator
+	defer func() { SessionValidator = originalValidator }()
+	SessionValidator = func(userID string, tokenVersion int) error {
+		if tokenVersion != 2 {
+			return fmt.Errorf("stale token version %d", tokenVersion)
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name           string
+		version        any
+		expectedStatus int
+	}{
+		{name: "Current version", version: 2, expectedStatus: http.StatusOK},
+		{name: "Revoked version", version: 1, expectedStatus: http.StatusUnauthorized},
+		{name: "Token without version", version: nil, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := jwt.MapClaims{"user_id": "user123", "exp": time.Now().Add(time.Hour).Unix()}
+			if tt.version != nil {
+				claims["ver"] = tt.version
+			}
+			token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+
+			router := gin.New()
+			router.Use(JWTMiddleware())
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
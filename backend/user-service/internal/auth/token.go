@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// GenerateOpaqueToken returns a random URL-safe token to hand to the user
+// and its hash to store. Only the hash is persisted, so a leaked database
+// does not reveal usable tokens.
+func GenerateOpaqueToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashOpaqueToken(token), nil
+}
+
+// HashOpaqueToken returns the hex SHA-256 of a token.
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RecoveryCodeCount is the number of recovery codes issued at a time.
+const RecoveryCodeCount = 10
+
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// GenerateRecoveryCodes returns RecoveryCodeCount codes of the form
+// xxxxx-xxxxx and their hashes. The alphabet leaves out characters that are
+// easily confused when copied by hand.
+func GenerateRecoveryCodes() (codes, hashes []string, err error) {
+	// Bytes at or above limit are discarded so every character is equally
+	// likely.
+	limit := 256 - 256%len(recoveryAlphabet)
+	buf := make([]byte, 1)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		code := make([]byte, 0, 11)
+		for len(code) < 11 {
+			if len(code) == 5 {
+				code = append(code, '-')
+				continue
+			}
+			if _, err := rand.Read(buf); err != nil {
+				return nil, nil, err
+			}
+			if int(buf[0]) >= limit {
+				continue
+			}
+			code = append(code, recoveryAlphabet[int(buf[0])%len(recoveryAlphabet)])
+		}
+		codes = append(codes, string(code))
+		hashes = append(hashes, HashRecoveryCode(string(code)))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode normalises a recovery code as typed by the user and
+// returns its hash.
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	return HashOpaqueToken(code)
+}
@@ -0,0 +1,56 @@
+package auth
+
+import "testing"
+
+func TestGenerateOpaqueToken(t *testing.T) {
+	token, hash, err := GenerateOpaqueToken()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(token) != 43 {
+		t.Errorf("Expected 43 character token, got %d", len(token))
+	}
+	if hash != HashOpaqueToken(token) {
+		t.Error("Expected returned hash to match HashOpaqueToken")
+	}
+	if len(hash) != 64 {
+		t.Errorf("Expected 64 character hex hash, got %d", len(hash))
+	}
+
+	other, _, _ := GenerateOpaqueToken()
+	if other == token {
+		t.Error("Expected tokens to be unique")
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(codes) != RecoveryCodeCount || len(hashes) != RecoveryCodeCount {
+		t.Fatalf("Expected %d codes and hashes, got %d and %d", RecoveryCodeCount, len(codes), len(hashes))
+	}
+	seen := map[string]bool{}
+	for i, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("Expected code of the form xxxxx-xxxxx, got %q", code)
+		}
+		if hashes[i] != HashRecoveryCode(code) {
+			t.Errorf("Expected hash %d to match HashRecoveryCode", i)
+		}
+		if seen[code] {
+			t.Errorf("Duplicate recovery code %q", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestHashRecoveryCodeNormalises(t *testing.T) {
+	expected := HashRecoveryCode("abcde-fghjk")
+	for _, typed := range []string{"ABCDE-FGHJK", "abcdefghjk", " abcde fghjk "} {
+		if HashRecoveryCode(typed) != expected {
+			t.Errorf("Expected %q to hash like the canonical code", typed)
+		}
+	}
+}
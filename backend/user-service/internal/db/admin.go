@@ -0,0 +1,126 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserFilter narrows the admin user list
+type UserFilter struct {
+	Query     string
+	Role      string
+	Suspended *bool
+	Limit     int
+	Offset    int
+}
+
+// ListUsers returns a page of users matching filter, newest first, and the
+// total number of matches
+func ListUsers(filter UserFilter) ([]model.User, int64, error) {
+	if DB == nil {
+		return nil, 0, fmt.Errorf("database connection is nil")
+	}
+	q := DB.Model(&model.User{})
+	if filter.Query != "" {
+		pattern := "%" + filter.Query + "%"
+		q = q.Where("email ILIKE ? OR CONCAT(first_name, ' ', last_name) ILIKE ?", pattern, pattern)
+	}
+	if filter.Role != "" {
+		q = q.Where("role = ?", filter.Role)
+	}
+	if filter.Suspended != nil {
+		if *filter.Suspended {
+			q = q.Where("suspended_at IS NOT NULL")
+		} else {
+			q = q.Where("suspended_at IS NULL")
+		}
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var users []model.User
+	if err := q.Order("created_at DESC").Order("id").
+		Limit(filter.Limit).Offset(filter.Offset).
+		Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// updateUserAndRevoke applies updates to a user, bumps the token version so
+// that existing tokens stop working, and returns the updated user
+func updateUserAndRevoke(userID uuid.UUID, updates map[string]any) (*model.User, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	updates["token_version"] = gorm.Expr("token_version + 1")
+	updates["updated_at"] = time.Now()
+	var user model.User
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).Where("id = ?", userID).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.First(&user, "id = ?", userID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SuspendUser blocks a user from logging in and signs out their sessions
+func SuspendUser(userID uuid.UUID, reason string) (*model.User, error) {
+	return updateUserAndRevoke(userID, map[string]any{
+		"suspended_at":      time.Now(),
+		"suspension_reason": reason,
+	})
+}
+
+// UnsuspendUser lifts a suspension
+func UnsuspendUser(userID uuid.UUID) (*model.User, error) {
+	return updateUserAndRevoke(userID, map[string]any{
+		"suspended_at":      nil,
+		"suspension_reason": "",
+	})
+}
+
+// SetUserRole changes a user's role. Their sessions are revoked so that the
+// next token they get carries the new role
+func SetUserRole(userID uuid.UUID, role string) (*model.User, error) {
+	return updateUserAndRevoke(userID, map[string]any{"role": role})
+}
+
+// GrantRoleByEmail gives role to the users with the given emails, for
+// bootstrapping the first admins. Unknown emails are ignored
+func GrantRoleByEmail(emails []string, role string) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	var lowered []string
+	for _, email := range emails {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			lowered = append(lowered, email)
+		}
+	}
+	if len(lowered) == 0 {
+		return nil
+	}
+	return DB.Model(&model.User{}).
+		Where("LOWER(email) IN ? AND role <> ?", lowered, role).
+		Updates(map[string]any{
+			"role":          role,
+			"token_version": gorm.Expr("token_version + 1"),
+			"updated_at":    time.Now(),
+		}).Error
+}
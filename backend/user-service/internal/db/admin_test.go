@@ -0,0 +1,31 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAdminFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	userID := uuid.New()
+
+	if _, _, err := ListUsers(UserFilter{Limit: 10}); err == nil {
+		t.Error("Expected error from ListUsers with nil database, got none")
+	}
+	if _, err := SuspendUser(userID, "spam"); err == nil {
+		t.Error("Expected error from SuspendUser with nil database, got none")
+	}
+	if _, err := UnsuspendUser(userID); err == nil {
+		t.Error("Expected error from UnsuspendUser with nil database, got none")
+	}
+	if _, err := SetUserRole(userID, "admin"); err == nil {
+		t.Error("Expected error from SetUserRole with nil database, got none")
+	}
+	if err := GrantRoleByEmail([]string{"admin@example.com"}, "admin"); err == nil {
+		t.Error("Expected error from GrantRoleByEmail with nil database, got none")
+	}
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -9,9 +10,11 @@ import (
 
 	"github.com/ffabious/healthy-summer/user-service/internal/model"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var DB *gorm.DB
@@ -52,7 +55,7 @@ func Connect() {
 		log.Fatalf("Failed to create extension uuid-ossp: %v", err)
 	}
 
-	if err := DB.AutoMigrate(&model.User{}, &model.Friend{}, &model.FriendRequest{}, &model.Achievement{}); err != nil {
+	if err := DB.AutoMigrate(&model.User{}, &model.Friend{}, &model.FriendRequest{}, &model.Achievement{}, &model.AccountDeletion{}, &model.DeletionStep{}, &model.UserToken{}, &model.LoginAttempt{}, &model.RecoveryCode{}, &model.UserIdentity{}, &model.OIDCLoginState{}, &model.PrivacySettings{}, &model.Block{}, &model.Upload{}, &model.Notification{}, &model.DeviceToken{}, &model.DigestDelivery{}); err != nil {
 		log.Fatalf("Failed to migrate database models: %v", err)
 	}
 
@@ -152,6 +155,15 @@ func SendFriendRequest(senderID, receiverID uuid.UUID) (*model.FriendRequest, er
 		return nil, fmt.Errorf("user not found")
 	}
 
+	// Check if either user has blocked the other
+	blocked, err := IsBlocked(senderID, receiverID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrBlocked
+	}
+
 	// Check if already friends
 	alreadyFriends, err := CheckExistingFriendship(senderID, receiverID)
 	if err != nil {
@@ -212,6 +224,9 @@ func GetPendingFriendRequests(userID uuid.UUID) ([]model.FriendRequestResponse,
 
 // GetSentFriendRequests retrieves all friend requests sent by a user
 func GetSentFriendRequests(userID uuid.UUID) ([]model.FriendRequest, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
 	var requests []model.FriendRequest
 	if err := DB.Where("sender_id = ?", userID).Find(&requests).Error; err != nil {
 		return nil, err
@@ -292,20 +307,33 @@ func RejectFriendRequest(requestID uuid.UUID, userID uuid.UUID) (*model.FriendRe
 	return &request, nil
 }
 
-// SearchUsers searches for users by email or name
-func SearchUsers(query string, excludeUserID uuid.UUID) ([]model.User, error) {
+// SearchUsers searches for users by email or name. Users with a private
+// profile are only found by email, and only users who allow it are found by
+// email. Emails are left out of the results for users who do not allow it.
+// Users who blocked or were blocked by the searcher are never found
+func SearchUsers(query string, excludeUserID uuid.UUID) ([]model.SearchUsersResponse, error) {
 	if DB == nil {
 		return nil, fmt.Errorf("database connection is nil")
 	}
-	var users []model.User
+	var results []model.SearchUsersResponse
 	searchPattern := "%" + query + "%"
-	if err := DB.Where(
-		"(email ILIKE ? OR CONCAT(first_name, ' ', last_name) ILIKE ?) AND id != ?",
-		searchPattern, searchPattern, excludeUserID,
-	).Find(&users).Error; err != nil {
+	if err := DB.Model(&model.User{}).
+		Select("users.id, users.first_name, users.last_name, "+
+			"CASE WHEN COALESCE(p.searchable_by_email, TRUE) THEN users.email ELSE '' END AS email").
+		Joins("LEFT JOIN privacy_settings p ON p.user_id = users.id").
+		Where("users.id != ?", excludeUserID).
+		Where("NOT EXISTS (SELECT 1 FROM blocks b WHERE "+
+			"(b.blocker_id = ? AND b.blocked_id = users.id) OR (b.blocker_id = users.id AND b.blocked_id = ?))",
+			excludeUserID, excludeUserID).
+		Where(
+			"(COALESCE(p.profile_visibility, ?) <> ? AND CONCAT(users.first_name, ' ', users.last_name) ILIKE ?) OR "+
+				"(COALESCE(p.searchable_by_email, TRUE) AND users.email ILIKE ?)",
+			model.ProfileVisibilityFriends, model.ProfileVisibilityPrivate, searchPattern, searchPattern,
+		).
+		Scan(&results).Error; err != nil {
 		return nil, err
 	}
-	return users, nil
+	return results, nil
 }
 
 // CheckExistingFriendRequest checks if a friend request already exists between two users
@@ -330,4 +358,98 @@ func CheckExistingFriendship(userID1, userID2 uuid.UUID) (bool, error) {
 		return false, err
 	}
 	return count > 0, nil
-}
\ No newline at end of file
+}
+
+// GetAchievementsByUserID returns a user's achievements, oldest first
+func GetAchievementsByUserID(userID uuid.UUID) ([]model.Achievement, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var achievements []model.Achievement
+	if err := DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&achievements).Error; err != nil {
+		return nil, err
+	}
+	return achievements, nil
+}
+
+// InsertAchievements bulk-inserts achievements, skipping rows whose ID already exists
+func InsertAchievements(achievements []model.Achievement) (int64, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database connection is nil")
+	}
+	if len(achievements) == 0 {
+		return 0, nil
+	}
+	result := DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&achievements, 500)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// ErrSessionRevoked is returned when a token was issued before the user's
+// sessions were revoked
+var ErrSessionRevoked = errors.New("session has been revoked")
+
+// ValidateSession checks a token version against the user's current one
+func ValidateSession(userID string, tokenVersion int) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	var user model.User
+	if err := DB.Select("id", "token_version").First(&user, "id = ?", userID).Error; err != nil {
+		return err
+	}
+	if user.TokenVersion != tokenVersion {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// ChangePassword stores a new password hash and bumps the token version so
+// that every previously issued token is rejected
+func ChangePassword(userID uuid.UUID, passwordHash string) (*model.User, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var user model.User
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).Updates(map[string]any{
+			"password":      passwordHash,
+			"token_version": gorm.Expr("token_version + 1"),
+			"updated_at":    time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.First(&user, "id = ?", userID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// EmailInUse reports whether an account other than excludeUserID uses the email
+func EmailInUse(email string, excludeUserID uuid.UUID) (bool, error) {
+	if DB == nil {
+		return false, fmt.Errorf("database connection is nil")
+	}
+	return emailInUse(DB, email, excludeUserID)
+}
+
+func emailInUse(tx *gorm.DB, email string, excludeUserID uuid.UUID) (bool, error) {
+	var count int64
+	if err := tx.Model(&model.User{}).
+		Where("LOWER(email) = LOWER(?) AND id <> ?", email, excludeUserID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint
+// violation, such as on users.email
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
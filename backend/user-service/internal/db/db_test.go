@@ -388,3 +388,34 @@ func TestSearchUsersWithDifferentQueries(t *testing.T) {
 		})
 	}
 }
+
+func TestAchievementFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+
+	if _, err := GetAchievementsByUserID(uuid.New()); err == nil {
+		t.Error("Expected error from GetAchievementsByUserID with nil database")
+	}
+	if _, err := InsertAchievements([]model.Achievement{{}}); err == nil {
+		t.Error("Expected error from InsertAchievements with nil database")
+	}
+}
+
+func TestCredentialFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+
+	if err := ValidateSession(uuid.New().String(), 0); err == nil {
+		t.Error("Expected error from ValidateSession with nil database, got none")
+	}
+	if _, err := ChangePassword(uuid.New(), "hash"); err == nil {
+		t.Error("Expected error from ChangePassword with nil database, got none")
+	}
+	if _, err := EmailInUse("test@example.com", uuid.New()); err == nil {
+		t.Error("Expected error from EmailInUse with nil database, got none")
+	}
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNoScheduledDeletion is returned when a user has no deletion that can
+// still be cancelled
+var ErrNoScheduledDeletion = errors.New("no scheduled account deletion")
+
+func orderedSteps(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC")
+}
+
+// CreateAccountDeletion schedules the deletion of a user's account with one
+// pending step per service. If a deletion is already scheduled or running it
+// is returned unchanged
+func CreateAccountDeletion(userID uuid.UUID, scheduledFor time.Time, services []string) (*model.AccountDeletion, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var deletion model.AccountDeletion
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Preload("Steps", orderedSteps).
+			Where("user_id = ? AND status IN ?", userID, []string{model.DeletionStatusScheduled, model.DeletionStatusInProgress}).
+			First(&deletion).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		now := time.Now()
+		deletion = model.AccountDeletion{
+			ID:           uuid.New(),
+			UserID:       userID,
+			Status:       model.DeletionStatusScheduled,
+			RequestedAt:  now,
+			ScheduledFor: scheduledFor,
+		}
+		for i, service := range services {
+			deletion.Steps = append(deletion.Steps, model.DeletionStep{
+				ID:        uuid.New(),
+				Position:  i,
+				Service:   service,
+				Status:    model.DeletionStepPending,
+				UpdatedAt: now,
+			})
+		}
+		return tx.Create(&deletion).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// GetLatestAccountDeletion returns the most recent deletion request of a user
+func GetLatestAccountDeletion(userID uuid.UUID) (*model.AccountDeletion, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var deletion model.AccountDeletion
+	if err := DB.Preload("Steps", orderedSteps).
+		Where("user_id = ?", userID).
+		Order("requested_at DESC").
+		First(&deletion).Error; err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// CancelAccountDeletion cancels a deletion that has not started yet
+func CancelAccountDeletion(userID uuid.UUID) (*model.AccountDeletion, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	now := time.Now()
+	result := DB.Model(&model.AccountDeletion{}).
+		Where("user_id = ? AND status = ?", userID, model.DeletionStatusScheduled).
+		Updates(map[string]any{"status": model.DeletionStatusCancelled, "cancelled_at": now})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrNoScheduledDeletion
+	}
+	return GetLatestAccountDeletion(userID)
+}
+
+// GetDueAccountDeletions returns deletions whose grace period has ended and
+// that have not completed yet, including ones with failed steps to retry
+func GetDueAccountDeletions(now time.Time) ([]model.AccountDeletion, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var deletions []model.AccountDeletion
+	if err := DB.Preload("Steps", orderedSteps).
+		Where("status IN ? AND scheduled_for <= ?", []string{model.DeletionStatusScheduled, model.DeletionStatusInProgress}, now).
+		Order("scheduled_for ASC").
+		Find(&deletions).Error; err != nil {
+		return nil, err
+	}
+	return deletions, nil
+}
+
+// SetAccountDeletionStatus updates the status of a deletion, stamping
+// CompletedAt when it completes
+func SetAccountDeletionStatus(deletionID uuid.UUID, status string) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	updates := map[string]any{"status": status}
+	if status == model.DeletionStatusCompleted {
+		updates["completed_at"] = time.Now()
+	}
+	return DB.Model(&model.AccountDeletion{}).Where("id = ?", deletionID).Updates(updates).Error
+}
+
+// SaveDeletionStep stores the outcome of a deletion step
+func SaveDeletionStep(step *model.DeletionStep) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	return DB.Save(step).Error
+}
+
+// PurgeUserAccount deletes a user's achievements, recovery codes, linked
+// identities, upload records, notifications, push tokens and digest
+// deliveries, anonymises the user row and revokes their sessions. The row
+// itself is kept so that the deletion record still refers to a user.
+// Uploaded files must be removed from storage beforehand. It returns the
+// number of rows deleted or anonymised
+func PurgeUserAccount(userID uuid.UUID) (int64, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database connection is nil")
+	}
+	var affected int64
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		achievements := tx.Where("user_id = ?", userID).Delete(&model.Achievement{})
+		if achievements.Error != nil {
+			return achievements.Error
+		}
+		codes := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{})
+		if codes.Error != nil {
+			return codes.Error
+		}
+		identities := tx.Where("user_id = ?", userID).Delete(&model.UserIdentity{})
+		if identities.Error != nil {
+			return identities.Error
+		}
+		uploads := tx.Where("user_id = ?", userID).Delete(&model.Upload{})
+		if uploads.Error != nil {
+			return uploads.Error
+		}
+		notifications := deleteNotifications(tx, userID)
+		if notifications.Error != nil {
+			return notifications.Error
+		}
+		devices := tx.Where("user_id = ?", userID).Delete(&model.DeviceToken{})
+		if devices.Error != nil {
+			return devices.Error
+		}
+		digests := tx.Where("user_id = ?", userID).Delete(&model.DigestDelivery{})
+		if digests.Error != nil {
+			return digests.Error
+		}
+		// The anonymised row stays, so hide it from search and friends.
+		hidden := model.PrivacySettings{UserID: userID, ProfileVisibility: model.ProfileVisibilityPrivate, UpdatedAt: time.Now()}
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&hidden).Error; err != nil {
+			return err
+		}
+		user := tx.Model(&model.User{}).Where("id = ?", userID).Updates(map[string]any{
+			"email":         fmt.Sprintf("deleted-%s@deleted.invalid", userID),
+			"password":      "",
+			"first_name":    "Deleted",
+			"last_name":     "User",
+			"avatar_url":    "",
+			"totp_enabled":  false,
+			"totp_secret":   "",
+			"token_version": gorm.Expr("token_version + 1"),
+			"updated_at":    time.Now(),
+		})
+		if user.Error != nil {
+			return user.Error
+		}
+		affected = achievements.RowsAffected + codes.RowsAffected + identities.RowsAffected + uploads.RowsAffected + notifications.RowsAffected + devices.RowsAffected + digests.RowsAffected + user.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
+func TestAccountDeletionFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+
+	userID := uuid.New()
+	if _, err := CreateAccountDeletion(userID, time.Now(), []string{"user-service"}); err == nil {
+		t.Error("Expected error from CreateAccountDeletion with nil database, got none")
+	}
+	if _, err := GetLatestAccountDeletion(userID); err == nil {
+		t.Error("Expected error from GetLatestAccountDeletion with nil database, got none")
+	}
+	if _, err := CancelAccountDeletion(userID); err == nil {
+		t.Error("Expected error from CancelAccountDeletion with nil database, got none")
+	}
+	if _, err := GetDueAccountDeletions(time.Now()); err == nil {
+		t.Error("Expected error from GetDueAccountDeletions with nil database, got none")
+	}
+	if err := SetAccountDeletionStatus(uuid.New(), model.DeletionStatusCompleted); err == nil {
+		t.Error("Expected error from SetAccountDeletionStatus with nil database, got none")
+	}
+	if err := SaveDeletionStep(&model.DeletionStep{}); err == nil {
+		t.Error("Expected error from SaveDeletionStep with nil database, got none")
+	}
+	if _, err := PurgeUserAccount(userID); err == nil {
+		t.Error("Expected error from PurgeUserAccount with nil database, got none")
+	}
+}
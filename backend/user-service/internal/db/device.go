@@ -0,0 +1,68 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RegisterDeviceToken adds a push token for a user. Registering a token that
+// is already known moves it to this user
+func RegisterDeviceToken(userID uuid.UUID, platform, token string) (*model.DeviceToken, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	now := time.Now()
+	device := model.DeviceToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Platform:  platform,
+		Token:     token,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "platform"}, {Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "updated_at"}),
+	}).Create(&device).Error; err != nil {
+		return nil, err
+	}
+	// On conflict the existing row keeps its ID, so read it back.
+	if err := DB.First(&device, "platform = ? AND token = ?", platform, token).Error; err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// UnregisterDeviceToken removes one of a user's push tokens. It returns
+// gorm.ErrRecordNotFound if the user has no such token
+func UnregisterDeviceToken(userID uuid.UUID, platform, token string) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	result := DB.Where("user_id = ? AND platform = ? AND token = ?", userID, platform, token).Delete(&model.DeviceToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetDeviceTokens returns a user's push tokens, most recently registered
+// first
+func GetDeviceTokens(userID uuid.UUID) ([]model.DeviceToken, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var devices []model.DeviceToken
+	if err := DB.Where("user_id = ?", userID).Order("updated_at DESC").Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
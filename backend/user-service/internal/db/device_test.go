@@ -0,0 +1,26 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
+func TestDeviceTokenFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	userID := uuid.New()
+
+	if _, err := RegisterDeviceToken(userID, model.PlatformAndroid, "token"); err == nil {
+		t.Error("Expected error from RegisterDeviceToken with nil database, got none")
+	}
+	if err := UnregisterDeviceToken(userID, model.PlatformAndroid, "token"); err == nil {
+		t.Error("Expected error from UnregisterDeviceToken with nil database, got none")
+	}
+	if _, err := GetDeviceTokens(userID); err == nil {
+		t.Error("Expected error from GetDeviceTokens with nil database, got none")
+	}
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// GetAchievementsBetween returns the achievements a user earned between from
+// (inclusive) and to (exclusive), oldest first
+func GetAchievementsBetween(userID uuid.UUID, from, to time.Time) ([]model.Achievement, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	achievements := []model.Achievement{}
+	if err := DB.Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, from, to).
+		Order("created_at ASC").
+		Find(&achievements).Error; err != nil {
+		return nil, err
+	}
+	return achievements, nil
+}
+
+// GetDigestRecipients returns up to limit users ordered by ID after afterID
+// who should get the digest of the week starting at weekStart: their email
+// is verified, they are not suspended or being deleted, they signed up
+// before the week ended and they have not been sent it yet
+func GetDigestRecipients(weekStart time.Time, afterID uuid.UUID, limit int) ([]model.User, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var users []model.User
+	if err := DB.Where("email_verified AND suspended_at IS NULL AND created_at < ? AND id > ?", weekStart.AddDate(0, 0, 7), afterID).
+		Where("NOT EXISTS (SELECT 1 FROM account_deletions d WHERE d.user_id = users.id AND d.status IN ?)",
+			[]string{model.DeletionStatusScheduled, model.DeletionStatusInProgress, model.DeletionStatusCompleted}).
+		Where("NOT EXISTS (SELECT 1 FROM digest_deliveries s WHERE s.user_id = users.id AND s.week_start = ?)", weekStart).
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// ClaimDigestDelivery records that the digest of the week starting at
+// weekStart is being sent to a user. It returns false if it already was
+func ClaimDigestDelivery(userID uuid.UUID, weekStart, now time.Time) (bool, error) {
+	if DB == nil {
+		return false, fmt.Errorf("database connection is nil")
+	}
+	delivery := model.DigestDelivery{UserID: userID, WeekStart: weekStart, SentAt: now}
+	result := DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&delivery)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseDigestDelivery undoes ClaimDigestDelivery after the email could not
+// be sent, so the next run tries again
+func ReleaseDigestDelivery(userID uuid.UUID, weekStart time.Time) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	return DB.Where("user_id = ? AND week_start = ?", userID, weekStart).Delete(&model.DigestDelivery{}).Error
+}
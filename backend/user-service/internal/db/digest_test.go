@@ -0,0 +1,30 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDigestFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	userID := uuid.New()
+	weekStart := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+
+	if _, err := GetAchievementsBetween(userID, weekStart, weekStart.AddDate(0, 0, 7)); err == nil {
+		t.Error("Expected error from GetAchievementsBetween with nil database, got none")
+	}
+	if _, err := GetDigestRecipients(weekStart, uuid.Nil, 100); err == nil {
+		t.Error("Expected error from GetDigestRecipients with nil database, got none")
+	}
+	if _, err := ClaimDigestDelivery(userID, weekStart, time.Now()); err == nil {
+		t.Error("Expected error from ClaimDigestDelivery with nil database, got none")
+	}
+	if err := ReleaseDigestDelivery(userID, weekStart); err == nil {
+		t.Error("Expected error from ReleaseDigestDelivery with nil database, got none")
+	}
+}
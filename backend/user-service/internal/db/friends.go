@@ -0,0 +1,164 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrNotFriends is returned when removing a friend the user does not have
+	ErrNotFriends = errors.New("users are not friends")
+	// ErrFriendRequestNotPending is returned when a request has already been
+	// answered or cancelled
+	ErrFriendRequestNotPending = errors.New("friend request is not pending")
+	// ErrBlocked is returned when one of two users has blocked the other. It
+	// does not say which one did
+	ErrBlocked = errors.New("cannot interact with this user")
+	// ErrNotBlocked is returned when unblocking a user who is not blocked
+	ErrNotBlocked = errors.New("user is not blocked")
+)
+
+func deleteFriendship(tx *gorm.DB, userID1, userID2 uuid.UUID) (int64, error) {
+	result := tx.Where(
+		"(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)",
+		userID1, userID2, userID2, userID1,
+	).Delete(&model.Friend{})
+	return result.RowsAffected, result.Error
+}
+
+// RemoveFriend deletes the friendship between two users in both directions
+func RemoveFriend(userID, friendID uuid.UUID) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	removed, err := deleteFriendship(DB, userID, friendID)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return ErrNotFriends
+	}
+	return nil
+}
+
+// CancelFriendRequest withdraws a pending friend request sent by senderID
+func CancelFriendRequest(requestID, senderID uuid.UUID) (*model.FriendRequest, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var request model.FriendRequest
+	if err := DB.First(&request, "id = ? AND sender_id = ?", requestID, senderID).Error; err != nil {
+		return nil, err
+	}
+	if request.Status != model.FriendRequestPending {
+		return nil, ErrFriendRequestNotPending
+	}
+
+	request.Status = model.FriendRequestCancelled
+	request.UpdatedAt = time.Now()
+	result := DB.Model(&request).
+		Where("status = ?", model.FriendRequestPending).
+		Updates(map[string]any{"status": request.Status, "updated_at": request.UpdatedAt})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrFriendRequestNotPending
+	}
+	return &request, nil
+}
+
+// BlockUser blocks blockedID for blockerID, ending their friendship and
+// cancelling pending requests between them. Blocking someone twice is not an
+// error
+func BlockUser(blockerID, blockedID uuid.UUID) (*model.Block, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if blockerID == blockedID {
+		return nil, fmt.Errorf("cannot block yourself")
+	}
+	if _, err := GetUserByID(blockedID); err != nil {
+		return nil, err
+	}
+
+	block := model.Block{
+		ID:        uuid.New(),
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+		CreatedAt: time.Now(),
+	}
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&block).Error; err != nil {
+			return err
+		}
+		if _, err := deleteFriendship(tx, blockerID, blockedID); err != nil {
+			return err
+		}
+		return tx.Model(&model.FriendRequest{}).
+			Where("((sender_id = ? AND receiver_id = ?) OR (sender_id = ? AND receiver_id = ?)) AND status = ?",
+				blockerID, blockedID, blockedID, blockerID, model.FriendRequestPending).
+			Updates(map[string]any{"status": model.FriendRequestCancelled, "updated_at": time.Now()}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	// On a repeated block the existing row wins; return that one
+	if err := DB.First(&block, "blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Error; err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// UnblockUser removes a block. It does not restore the friendship
+func UnblockUser(blockerID, blockedID uuid.UUID) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	result := DB.Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Delete(&model.Block{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotBlocked
+	}
+	return nil
+}
+
+// GetBlockedUsers lists the users blockerID has blocked, most recent first
+func GetBlockedUsers(blockerID uuid.UUID) ([]model.BlockedUserResponse, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	blocked := []model.BlockedUserResponse{}
+	if err := DB.Table("blocks").
+		Select("blocks.blocked_id AS user_id, users.first_name, users.last_name, blocks.created_at AS blocked_at").
+		Joins("JOIN users ON users.id = blocks.blocked_id").
+		Where("blocks.blocker_id = ?", blockerID).
+		Order("blocks.created_at DESC").
+		Scan(&blocked).Error; err != nil {
+		return nil, err
+	}
+	return blocked, nil
+}
+
+// IsBlocked reports whether either user has blocked the other
+func IsBlocked(userID1, userID2 uuid.UUID) (bool, error) {
+	if DB == nil {
+		return false, fmt.Errorf("database connection is nil")
+	}
+	var count int64
+	if err := DB.Model(&model.Block{}).Where(
+		"(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)",
+		userID1, userID2, userID2, userID1,
+	).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestFriendManagementFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	userID := uuid.New()
+	otherID := uuid.New()
+
+	if err := RemoveFriend(userID, otherID); err == nil {
+		t.Error("Expected error from RemoveFriend with nil database, got none")
+	}
+	if _, err := CancelFriendRequest(uuid.New(), userID); err == nil {
+		t.Error("Expected error from CancelFriendRequest with nil database, got none")
+	}
+	if _, err := BlockUser(userID, otherID); err == nil {
+		t.Error("Expected error from BlockUser with nil database, got none")
+	}
+	if err := UnblockUser(userID, otherID); err == nil {
+		t.Error("Expected error from UnblockUser with nil database, got none")
+	}
+	if _, err := GetBlockedUsers(userID); err == nil {
+		t.Error("Expected error from GetBlockedUsers with nil database, got none")
+	}
+	if _, err := IsBlocked(userID, otherID); err == nil {
+		t.Error("Expected error from IsBlocked with nil database, got none")
+	}
+	if _, err := GetFriendSuggestions(userID, 10); err == nil {
+		t.Error("Expected error from GetFriendSuggestions with nil database, got none")
+	}
+	if _, err := GetPublicProfile(otherID, userID); err == nil {
+		t.Error("Expected error from GetPublicProfile with nil database, got none")
+	}
+	if _, err := GetSentFriendRequests(userID); err == nil {
+		t.Error("Expected error from GetSentFriendRequests with nil database, got none")
+	}
+}
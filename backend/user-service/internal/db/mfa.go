@@ -0,0 +1,145 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrTOTPAlreadyEnabled is returned when setting up TOTP for a user who has
+// already confirmed it
+var ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+
+// ErrTOTPNotEnabled is returned by operations that need TOTP to be enabled
+var ErrTOTPNotEnabled = errors.New("two-factor authentication is not enabled")
+
+// SetPendingTOTPSecret stores a secret that becomes active once the user
+// confirms it with a code. Calling it again replaces the pending secret
+func SetPendingTOTPSecret(userID uuid.UUID, secret string) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	result := DB.Model(&model.User{}).
+		Where("id = ? AND totp_enabled = ?", userID, false).
+		Updates(map[string]any{"totp_secret": secret, "updated_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTOTPAlreadyEnabled
+	}
+	return nil
+}
+
+func insertRecoveryCodes(tx *gorm.DB, userID uuid.UUID, hashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	now := time.Now()
+	codes := make([]model.RecoveryCode, len(hashes))
+	for i, hash := range hashes {
+		codes[i] = model.RecoveryCode{ID: uuid.New(), UserID: userID, CodeHash: hash, CreatedAt: now}
+	}
+	return tx.Create(&codes).Error
+}
+
+// EnableTOTP activates the pending secret, records step as used and stores
+// a fresh set of recovery codes
+func EnableTOTP(userID uuid.UUID, step int64, recoveryHashes []string) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	return DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).
+			Where("id = ? AND totp_enabled = ? AND totp_secret <> ''", userID, false).
+			Updates(map[string]any{"totp_enabled": true, "totp_last_step": step, "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTOTPAlreadyEnabled
+		}
+		return insertRecoveryCodes(tx, userID, recoveryHashes)
+	})
+}
+
+// UseTOTPStep records step as the last accepted one. It returns false if a
+// code from that step or a later one was already used
+func UseTOTPStep(userID uuid.UUID, step int64) (bool, error) {
+	if DB == nil {
+		return false, fmt.Errorf("database connection is nil")
+	}
+	result := DB.Model(&model.User{}).
+		Where("id = ? AND totp_enabled = ? AND totp_last_step < ?", userID, true, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UseRecoveryCode marks an unused recovery code as used. It returns false if
+// the code is unknown or already used
+func UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error) {
+	if DB == nil {
+		return false, fmt.Errorf("database connection is nil")
+	}
+	result := DB.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ReplaceRecoveryCodes discards the user's recovery codes and stores new ones
+func ReplaceRecoveryCodes(userID uuid.UUID, recoveryHashes []string) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	return DB.Transaction(func(tx *gorm.DB) error {
+		return insertRecoveryCodes(tx, userID, recoveryHashes)
+	})
+}
+
+// CountRecoveryCodes returns how many unused recovery codes the user has
+func CountRecoveryCodes(userID uuid.UUID) (int64, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database connection is nil")
+	}
+	var count int64
+	err := DB.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// DisableTOTP turns off two-factor authentication and deletes the secret and
+// recovery codes
+func DisableTOTP(userID uuid.UUID) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	return DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).
+			Where("id = ? AND totp_enabled = ?", userID, true).
+			Updates(map[string]any{
+				"totp_enabled":   false,
+				"totp_secret":    "",
+				"totp_last_step": 0,
+				"updated_at":     time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTOTPNotEnabled
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error
+	})
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestMFAFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	userID := uuid.New()
+
+	if err := SetPendingTOTPSecret(userID, "SECRET"); err == nil {
+		t.Error("Expected error from SetPendingTOTPSecret with nil database, got none")
+	}
+	if err := EnableTOTP(userID, 1, []string{"hash"}); err == nil {
+		t.Error("Expected error from EnableTOTP with nil database, got none")
+	}
+	if ok, err := UseTOTPStep(userID, 1); err == nil || ok {
+		t.Error("Expected error from UseTOTPStep with nil database, got none")
+	}
+	if ok, err := UseRecoveryCode(userID, "hash"); err == nil || ok {
+		t.Error("Expected error from UseRecoveryCode with nil database, got none")
+	}
+	if err := ReplaceRecoveryCodes(userID, []string{"hash"}); err == nil {
+		t.Error("Expected error from ReplaceRecoveryCodes with nil database, got none")
+	}
+	if _, err := CountRecoveryCodes(userID); err == nil {
+		t.Error("Expected error from CountRecoveryCodes with nil database, got none")
+	}
+	if err := DisableTOTP(userID); err == nil {
+		t.Error("Expected error from DisableTOTP with nil database, got none")
+	}
+}
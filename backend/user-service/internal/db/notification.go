@@ -0,0 +1,105 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateNotification stores a new unread notification
+func CreateNotification(notification *model.Notification) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	if notification.ID == uuid.Nil {
+		notification.ID = uuid.New()
+	}
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+	return DB.Create(notification).Error
+}
+
+// GetNotifications returns a page of a user's notifications, newest first,
+// and the total number matching
+func GetNotifications(userID uuid.UUID, unreadOnly bool, limit, offset int) ([]model.Notification, int64, error) {
+	if DB == nil {
+		return nil, 0, fmt.Errorf("database connection is nil")
+	}
+	q := DB.Model(&model.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		q = q.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var notifications []model.Notification
+	if err := q.Order("created_at DESC").Order("id").
+		Limit(limit).Offset(offset).
+		Find(&notifications).Error; err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+// CountUnreadNotifications returns how many notifications a user has not
+// read yet
+func CountUnreadNotifications(userID uuid.UUID) (int64, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database connection is nil")
+	}
+	var count int64
+	if err := DB.Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// MarkNotificationRead marks one of a user's notifications as read. Reading
+// a notification twice keeps the first read time. It returns
+// gorm.ErrRecordNotFound if the notification belongs to someone else
+func MarkNotificationRead(notificationID, userID uuid.UUID) (*model.Notification, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var notification model.Notification
+	if err := DB.First(&notification, "id = ? AND user_id = ?", notificationID, userID).Error; err != nil {
+		return nil, err
+	}
+	if notification.ReadAt == nil {
+		now := time.Now()
+		if err := DB.Model(&notification).Update("read_at", now).Error; err != nil {
+			return nil, err
+		}
+		notification.ReadAt = &now
+	}
+	return &notification, nil
+}
+
+// MarkAllNotificationsRead marks every unread notification of a user as
+// read and returns how many were updated
+func MarkAllNotificationsRead(userID uuid.UUID) (int64, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database connection is nil")
+	}
+	result := DB.Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// deleteNotifications removes the notifications a user received and the
+// ones their actions caused for others
+func deleteNotifications(tx *gorm.DB, userID uuid.UUID) *gorm.DB {
+	return tx.Where("user_id = ? OR actor_id = ?", userID, userID).Delete(&model.Notification{})
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
+func TestNotificationFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	userID := uuid.New()
+
+	if err := CreateNotification(&model.Notification{UserID: userID, Type: model.NotificationFriendRequest}); err == nil {
+		t.Error("Expected error from CreateNotification with nil database, got none")
+	}
+	if _, _, err := GetNotifications(userID, false, 20, 0); err == nil {
+		t.Error("Expected error from GetNotifications with nil database, got none")
+	}
+	if _, err := CountUnreadNotifications(userID); err == nil {
+		t.Error("Expected error from CountUnreadNotifications with nil database, got none")
+	}
+	if _, err := MarkNotificationRead(uuid.New(), userID); err == nil {
+		t.Error("Expected error from MarkNotificationRead with nil database, got none")
+	}
+	if _, err := MarkAllNotificationsRead(userID); err == nil {
+		t.Error("Expected error from MarkAllNotificationsRead with nil database, got none")
+	}
+}
+
+func TestNotificationText(t *testing.T) {
+	tests := []struct {
+		notificationType string
+		expected         string
+	}{
+		{model.NotificationFriendRequest, "Jane Doe sent you a friend request"},
+		{model.NotificationFriendRequestAccepted, "Jane Doe accepted your friend request"},
+		{model.NotificationMessage, "Jane Doe sent you a message"},
+		{model.NotificationComment, "Jane Doe commented on your post"},
+	}
+	for _, tt := range tests {
+		if got := model.NotificationText(tt.notificationType, "Jane Doe"); got != tt.expected {
+			t.Errorf("NotificationText(%q) = %q, want %q", tt.notificationType, got, tt.expected)
+		}
+	}
+}
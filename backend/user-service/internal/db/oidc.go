@@ -0,0 +1,133 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrUnverifiedEmail is returned when a provider does not vouch for the
+// email of an identity that is not linked yet
+var ErrUnverifiedEmail = errors.New("identity provider did not return a verified email")
+
+// CreateOIDCLoginState stores a started login until its callback arrives
+func CreateOIDCLoginState(state *model.OIDCLoginState) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	state.CreatedAt = time.Now()
+	return DB.Create(state).Error
+}
+
+// ConsumeOIDCLoginState deletes and returns an unexpired login state. Each
+// state can complete one login only
+func ConsumeOIDCLoginState(stateHash, provider string) (*model.OIDCLoginState, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var state model.OIDCLoginState
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("state_hash = ? AND provider = ? AND expires_at > ?", stateHash, provider, time.Now()).
+			First(&state).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidToken
+		}
+		if err != nil {
+			return err
+		}
+		result := tx.Where("state_hash = ?", stateHash).Delete(&model.OIDCLoginState{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidToken
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// DeleteExpiredOIDCLoginStates removes logins that were never completed
+func DeleteExpiredOIDCLoginStates(now time.Time) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	return DB.Where("expires_at <= ?", now).Delete(&model.OIDCLoginState{}).Error
+}
+
+// LoginWithIdentity returns the user linked to identity. An identity seen
+// for the first time is linked to the account with the same verified email,
+// or to a new passwordless account if there is none.
+//
+// When linking to an account whose email was never verified, its password
+// is cleared and its sessions revoked: whoever registered the address
+// without proving they own it loses access to the provider's user
+func LoginWithIdentity(identity model.UserIdentity, emailVerified bool, firstName, lastName string) (*model.User, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var user model.User
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var linked model.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).First(&linked).Error
+		if err == nil {
+			return tx.First(&user, "id = ?", linked.UserID).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if identity.Email == "" || !emailVerified {
+			return ErrUnverifiedEmail
+		}
+
+		now := time.Now()
+		err = tx.Where("LOWER(email) = LOWER(?)", identity.Email).First(&user).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			user = model.User{
+				ID:              uuid.New(),
+				Email:           identity.Email,
+				FirstName:       firstName,
+				LastName:        lastName,
+				EmailVerified:   true,
+				EmailVerifiedAt: &now,
+				CreatedAt:       now,
+				UpdatedAt:       now,
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case !user.EmailVerified:
+			if err := tx.Model(&user).Updates(map[string]any{
+				"password":          "",
+				"email_verified":    true,
+				"email_verified_at": now,
+				"token_version":     gorm.Expr("token_version + 1"),
+				"updated_at":        now,
+			}).Error; err != nil {
+				return err
+			}
+			if err := tx.First(&user, "id = ?", user.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		identity.ID = uuid.New()
+		identity.UserID = user.ID
+		identity.CreatedAt = now
+		return tx.Create(&identity).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+)
+
+func TestOIDCFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+
+	if err := CreateOIDCLoginState(&model.OIDCLoginState{StateHash: "hash", Provider: "google"}); err == nil {
+		t.Error("Expected error from CreateOIDCLoginState with nil database, got none")
+	}
+	if _, err := ConsumeOIDCLoginState("hash", "google"); err == nil {
+		t.Error("Expected error from ConsumeOIDCLoginState with nil database, got none")
+	}
+	if err := DeleteExpiredOIDCLoginStates(time.Now()); err == nil {
+		t.Error("Expected error from DeleteExpiredOIDCLoginStates with nil database, got none")
+	}
+	_, err := LoginWithIdentity(model.UserIdentity{Provider: "google", Subject: "123", Email: "a@example.com"}, true, "A", "B")
+	if err == nil || errors.Is(err, ErrUnverifiedEmail) {
+		t.Errorf("Expected database error from LoginWithIdentity with nil database, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func getPrivacySettings(tx *gorm.DB, userID uuid.UUID) (*model.PrivacySettings, error) {
+	var settings model.PrivacySettings
+	err := tx.First(&settings, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		settings = model.DefaultPrivacySettings(userID)
+		return &settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// GetPrivacySettings returns the user's privacy settings, or the defaults if
+// they never changed them
+func GetPrivacySettings(userID uuid.UUID) (*model.PrivacySettings, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	return getPrivacySettings(DB, userID)
+}
+
+// UpdatePrivacySettings applies the fields present in req on top of the
+// current settings
+func UpdatePrivacySettings(userID uuid.UUID, req model.UpdatePrivacySettingsRequest) (*model.PrivacySettings, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var settings *model.PrivacySettings
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		settings, err = getPrivacySettings(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID)
+		if err != nil {
+			return err
+		}
+		if req.ProfileVisibility != nil {
+			settings.ProfileVisibility = *req.ProfileVisibility
+		}
+		if req.ShareActivities != nil {
+			settings.ShareActivities = *req.ShareActivities
+		}
+		if req.ShareNutrition != nil {
+			settings.ShareNutrition = *req.ShareNutrition
+		}
+		if req.ShareWeight != nil {
+			settings.ShareWeight = *req.ShareWeight
+		}
+		if req.SearchableByEmail != nil {
+			settings.SearchableByEmail = *req.SearchableByEmail
+		}
+		settings.UpdatedAt = time.Now()
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(settings).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
+func TestPrivacySettingsFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	userID := uuid.New()
+	share := false
+
+	if _, err := GetPrivacySettings(userID); err == nil {
+		t.Error("Expected error from GetPrivacySettings with nil database, got none")
+	}
+	if _, err := UpdatePrivacySettings(userID, model.UpdatePrivacySettingsRequest{ShareWeight: &share}); err == nil {
+		t.Error("Expected error from UpdatePrivacySettings with nil database, got none")
+	}
+}
+
+func TestDefaultPrivacySettings(t *testing.T) {
+	userID := uuid.New()
+	settings := model.DefaultPrivacySettings(userID)
+
+	if settings.UserID != userID {
+		t.Errorf("Expected user ID %s, got %s", userID, settings.UserID)
+	}
+	if settings.ProfileVisibility != model.ProfileVisibilityFriends {
+		t.Errorf("Expected friends visibility by default, got %s", settings.ProfileVisibility)
+	}
+	if !settings.ShareActivities || !settings.ShareNutrition || settings.ShareWeight || !settings.SearchableByEmail {
+		t.Errorf("Unexpected default sharing flags: %+v", settings)
+	}
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// GetPublicProfile builds the profile card of userID as seen by viewerID. It
+// does not check privacy settings or blocks
+func GetPublicProfile(userID, viewerID uuid.UUID) (*model.PublicProfile, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	profile := model.PublicProfile{
+		ID:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		AvatarURL: user.AvatarURL,
+		JoinedAt:  user.CreatedAt,
+	}
+
+	if err := DB.Model(&model.Achievement{}).Where("user_id = ?", userID).Count(&profile.AchievementCount).Error; err != nil {
+		return nil, err
+	}
+	if err := DB.Model(&model.Friend{}).Where("user_id = ?", userID).Count(&profile.FriendCount).Error; err != nil {
+		return nil, err
+	}
+	if userID == viewerID {
+		return &profile, nil
+	}
+
+	if err := DB.Table("friends f1").
+		Joins("JOIN friends f2 ON f2.friend_id = f1.friend_id AND f2.user_id = ?", viewerID).
+		Where("f1.user_id = ?", userID).
+		Count(&profile.MutualFriendCount).Error; err != nil {
+		return nil, err
+	}
+	var friends int64
+	if err := DB.Model(&model.Friend{}).Where("user_id = ? AND friend_id = ?", viewerID, userID).Count(&friends).Error; err != nil {
+		return nil, err
+	}
+	profile.IsFriend = friends > 0
+	return &profile, nil
+}
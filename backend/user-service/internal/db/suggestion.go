@@ -0,0 +1,45 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// GetFriendSuggestions returns up to limit friends of the user's friends,
+// ranked by how many mutual friends they share with the user. Users who are
+// already friends, have a pending or rejected request with the user in either
+// direction, are blocked either way, have a private profile or are suspended
+// are left out
+func GetFriendSuggestions(userID uuid.UUID, limit int) ([]model.FriendSuggestion, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	suggestions := []model.FriendSuggestion{}
+	if err := DB.Table("friends f1").
+		Select("users.id AS user_id, users.first_name, users.last_name, COUNT(*) AS mutual_friends").
+		Joins("JOIN friends f2 ON f2.user_id = f1.friend_id").
+		Joins("JOIN users ON users.id = f2.friend_id").
+		Joins("LEFT JOIN privacy_settings p ON p.user_id = users.id").
+		Where("f1.user_id = ? AND f2.friend_id <> ?", userID, userID).
+		Where("NOT EXISTS (SELECT 1 FROM friends f3 WHERE f3.user_id = ? AND f3.friend_id = users.id)", userID).
+		Where("NOT EXISTS (SELECT 1 FROM friend_requests r WHERE "+
+			"((r.sender_id = ? AND r.receiver_id = users.id) OR (r.sender_id = users.id AND r.receiver_id = ?)) AND r.status IN ?)",
+			userID, userID, []string{model.FriendRequestPending, model.FriendRequestRejected}).
+		Where("NOT EXISTS (SELECT 1 FROM blocks b WHERE "+
+			"(b.blocker_id = ? AND b.blocked_id = users.id) OR (b.blocker_id = users.id AND b.blocked_id = ?))",
+			userID, userID).
+		Where("COALESCE(p.profile_visibility, ?) <> ?", model.ProfileVisibilityFriends, model.ProfileVisibilityPrivate).
+		Where("users.suspended_at IS NULL").
+		Group("users.id, users.first_name, users.last_name").
+		Order("mutual_friends DESC").Order("users.id").
+		Limit(limit).
+		Scan(&suggestions).Error; err != nil {
+		return nil, err
+	}
+	for i := range suggestions {
+		suggestions[i].Explanation = model.MutualFriendsExplanation(suggestions[i].MutualFriends)
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestGetFriendSuggestionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+
+	suggestions, err := GetFriendSuggestions(uuid.New(), 10)
+	if err == nil {
+		t.Error("Expected error with nil database, got none")
+	}
+	if suggestions != nil {
+		t.Error("Expected nil result with nil database")
+	}
+}
+
+// postgresTestDB opens the database named by TEST_DATABASE_DSN and returns a
+// migrated transaction that is rolled back when the test ends. The test is
+// skipped when the variable is not set
+func postgresTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set, skipping Postgres test")
+	}
+
+	conn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := conn.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`).Error; err != nil {
+		t.Fatalf("Failed to create extension uuid-ossp: %v", err)
+	}
+	tx := conn.Begin()
+	t.Cleanup(func() {
+		tx.Rollback()
+		if sqlDB, err := conn.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := tx.AutoMigrate(&model.User{}, &model.Friend{}, &model.FriendRequest{}, &model.PrivacySettings{}, &model.Block{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	return tx
+}
+
+func TestGetFriendSuggestionsPostgres(t *testing.T) {
+	tx := postgresTestDB(t)
+	originalDB := DB
+	defer func() { DB = originalDB }()
+	DB = tx
+
+	seedUser := func(name string) uuid.UUID {
+		t.Helper()
+		id := uuid.New()
+		user := model.User{ID: id, Email: id.String() + "@example.com", Password: "x", FirstName: name, LastName: "Test"}
+		if err := tx.Create(&user).Error; err != nil {
+			t.Fatalf("Failed to seed user %s: %v", name, err)
+		}
+		return id
+	}
+	seedFriends := func(a, b uuid.UUID) {
+		t.Helper()
+		friends := []model.Friend{{ID: uuid.New(), UserID: a, FriendID: b}, {ID: uuid.New(), UserID: b, FriendID: a}}
+		if err := tx.Create(&friends).Error; err != nil {
+			t.Fatalf("Failed to seed friendship: %v", err)
+		}
+	}
+
+	me := seedUser("Me")
+	alice := seedUser("Alice")
+	bob := seedUser("Bob")
+	carol := seedUser("Carol")
+	for _, friend := range []uuid.UUID{alice, bob, carol} {
+		seedFriends(me, friend)
+	}
+	// Alice and Carol know each other, but Carol is already a friend
+	seedFriends(alice, carol)
+
+	// Candidates ranked by mutual friends: Xavier 3, Zoe 2, Yann 1
+	xavier := seedUser("Xavier")
+	zoe := seedUser("Zoe")
+	yann := seedUser("Yann")
+	seedFriends(xavier, alice)
+	seedFriends(xavier, bob)
+	seedFriends(xavier, carol)
+	seedFriends(zoe, alice)
+	seedFriends(zoe, bob)
+	seedFriends(yann, bob)
+
+	// Friends of all three friends that must still be left out
+	pending := seedUser("Pending")
+	rejected := seedUser("Rejected")
+	blocker := seedUser("Blocker")
+	blocked := seedUser("Blocked")
+	private := seedUser("Private")
+	suspended := seedUser("Suspended")
+	for _, id := range []uuid.UUID{pending, rejected, blocker, blocked, private, suspended} {
+		seedFriends(id, alice)
+		seedFriends(id, bob)
+		seedFriends(id, carol)
+	}
+	requests := []model.FriendRequest{
+		{ID: uuid.New(), SenderID: me, ReceiverID: pending, Status: model.FriendRequestPending},
+		{ID: uuid.New(), SenderID: rejected, ReceiverID: me, Status: model.FriendRequestRejected},
+	}
+	if err := tx.Create(&requests).Error; err != nil {
+		t.Fatalf("Failed to seed friend requests: %v", err)
+	}
+	blocks := []model.Block{
+		{ID: uuid.New(), BlockerID: blocker, BlockedID: me},
+		{ID: uuid.New(), BlockerID: me, BlockedID: blocked},
+	}
+	if err := tx.Create(&blocks).Error; err != nil {
+		t.Fatalf("Failed to seed blocks: %v", err)
+	}
+	settings := []model.PrivacySettings{
+		{UserID: private, ProfileVisibility: model.ProfileVisibilityPrivate},
+		{UserID: zoe, ProfileVisibility: model.ProfileVisibilityPublic},
+	}
+	if err := tx.Create(&settings).Error; err != nil {
+		t.Fatalf("Failed to seed privacy settings: %v", err)
+	}
+	if err := tx.Model(&model.User{}).Where("id = ?", suspended).Update("suspended_at", time.Now()).Error; err != nil {
+		t.Fatalf("Failed to suspend user: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		limit    int
+		expected []uuid.UUID
+		mutual   []int
+	}{
+		{name: "All suggestions", limit: 10, expected: []uuid.UUID{xavier, zoe, yann}, mutual: []int{3, 2, 1}},
+		{name: "Limited", limit: 2, expected: []uuid.UUID{xavier, zoe}, mutual: []int{3, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestions, err := GetFriendSuggestions(me, tt.limit)
+			if err != nil {
+				t.Fatalf("GetFriendSuggestions returned error: %v", err)
+			}
+			if len(suggestions) != len(tt.expected) {
+				t.Fatalf("Expected %d suggestions, got %d: %+v", len(tt.expected), len(suggestions), suggestions)
+			}
+			for i, s := range suggestions {
+				if s.UserID != tt.expected[i] {
+					t.Errorf("Suggestion %d: expected %s, got %s (%s)", i, tt.expected[i], s.UserID, s.FirstName)
+				}
+				if s.MutualFriends != tt.mutual[i] {
+					t.Errorf("Suggestion %d: expected %d mutual friends, got %d", i, tt.mutual[i], s.MutualFriends)
+				}
+				if s.Explanation != model.MutualFriendsExplanation(tt.mutual[i]) {
+					t.Errorf("Suggestion %d: unexpected explanation %q", i, s.Explanation)
+				}
+			}
+		})
+	}
+
+	// Someone without friends gets an empty list rather than nil
+	loner := seedUser("Loner")
+	suggestions, err := GetFriendSuggestions(loner, 10)
+	if err != nil {
+		t.Fatalf("GetFriendSuggestions returned error: %v", err)
+	}
+	if suggestions == nil || len(suggestions) != 0 {
+		t.Errorf("Expected an empty list, got %+v", suggestions)
+	}
+}
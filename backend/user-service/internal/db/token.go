@@ -0,0 +1,157 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidToken is returned when a token is unknown, expired or already used
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// ErrEmailTaken is returned when an email address belongs to another account
+var ErrEmailTaken = errors.New("email is already in use")
+
+// CreateUserToken stores a new token. Earlier unused tokens of the same
+// purpose are revoked so that only the latest emailed link works
+func CreateUserToken(token *model.UserToken) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	now := time.Now()
+	token.ID = uuid.New()
+	token.CreatedAt = now
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// consumeToken marks a valid token as used and returns it
+func consumeToken(tx *gorm.DB, purpose, tokenHash string) (*model.UserToken, error) {
+	now := time.Now()
+	var token model.UserToken
+	err := tx.Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, now).
+		First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Guard against the same token being redeemed concurrently.
+	result := tx.Model(&model.UserToken{}).
+		Where("id = ? AND used_at IS NULL", token.ID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidToken
+	}
+	token.UsedAt = &now
+	return &token, nil
+}
+
+// VerifyEmailWithToken redeems an email verification token and marks the
+// user's email as verified
+func VerifyEmailWithToken(tokenHash string) (*model.User, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var user model.User
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		token, err := consumeToken(tx, model.TokenPurposeEmailVerification, tokenHash)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if err := tx.Model(&model.User{}).Where("id = ?", token.UserID).Updates(map[string]any{
+			"email_verified":    true,
+			"email_verified_at": now,
+			"updated_at":        now,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.First(&user, "id = ?", token.UserID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ResetPasswordWithToken redeems a password reset token, replaces the user's
+// password hash and revokes their existing sessions
+func ResetPasswordWithToken(tokenHash, passwordHash string) (*model.User, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var user model.User
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		token, err := consumeToken(tx, model.TokenPurposePasswordReset, tokenHash)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&model.User{}).Where("id = ?", token.UserID).Updates(map[string]any{
+			"password":      passwordHash,
+			"token_version": gorm.Expr("token_version + 1"),
+			"updated_at":    time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.First(&user, "id = ?", token.UserID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ConfirmEmailChangeWithToken redeems an email change token and moves the
+// account to the new, now verified, address
+func ConfirmEmailChangeWithToken(tokenHash string) (*model.User, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var user model.User
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		token, err := consumeToken(tx, model.TokenPurposeEmailChange, tokenHash)
+		if err != nil {
+			return err
+		}
+		// The address may have been registered since the change was requested.
+		taken, err := emailInUse(tx, token.Email, token.UserID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrEmailTaken
+		}
+		now := time.Now()
+		if err := tx.Model(&model.User{}).Where("id = ?", token.UserID).Updates(map[string]any{
+			"email":             token.Email,
+			"email_verified":    true,
+			"email_verified_at": now,
+			"updated_at":        now,
+		}).Error; err != nil {
+			if isUniqueViolation(err) {
+				return ErrEmailTaken
+			}
+			return err
+		}
+		return tx.First(&user, "id = ?", token.UserID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,29 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
+func TestUserTokenFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+
+	if err := CreateUserToken(&model.UserToken{UserID: uuid.New(), Purpose: model.TokenPurposePasswordReset, TokenHash: "hash", ExpiresAt: time.Now().Add(time.Hour)}); err == nil {
+		t.Error("Expected error from CreateUserToken with nil database, got none")
+	}
+	if _, err := VerifyEmailWithToken("hash"); err == nil {
+		t.Error("Expected error from VerifyEmailWithToken with nil database, got none")
+	}
+	if _, err := ResetPasswordWithToken("hash", "password"); err == nil {
+		t.Error("Expected error from ResetPasswordWithToken with nil database, got none")
+	}
+	if _, err := ConfirmEmailChangeWithToken("hash"); err == nil {
+		t.Error("Expected error from ConfirmEmailChangeWithToken with nil database, got none")
+	}
+}
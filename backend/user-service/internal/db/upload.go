@@ -0,0 +1,53 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// CreateUpload records an uploaded image
+func CreateUpload(upload *model.Upload) error {
+	if DB == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+	return DB.Create(upload).Error
+}
+
+// GetUpload returns an upload owned by userID
+func GetUpload(uploadID, userID uuid.UUID) (*model.Upload, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var upload model.Upload
+	if err := DB.First(&upload, "id = ? AND user_id = ?", uploadID, userID).Error; err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// GetUploadsByUserID returns every upload of a user
+func GetUploadsByUserID(userID uuid.UUID) ([]model.Upload, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	var uploads []model.Upload
+	if err := DB.Where("user_id = ?", userID).Find(&uploads).Error; err != nil {
+		return nil, err
+	}
+	return uploads, nil
+}
+
+// SetUserAvatar sets or, with an empty URL, clears a user's avatar
+func SetUserAvatar(userID uuid.UUID, avatarURL string) (*model.User, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+	if err := DB.Model(&model.User{}).Where("id = ?", userID).
+		Updates(map[string]any{"avatar_url": avatarURL, "updated_at": time.Now()}).Error; err != nil {
+		return nil, err
+	}
+	return GetUserByID(userID)
+}
@@ -0,0 +1,29 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
+func TestUploadFunctionsWithNilDatabase(t *testing.T) {
+	originalDB := DB
+	defer func() { DB = originalDB }()
+
+	DB = nil
+	userID := uuid.New()
+
+	if err := CreateUpload(&model.Upload{ID: uuid.New(), UserID: userID}); err == nil {
+		t.Error("Expected error from CreateUpload with nil database, got none")
+	}
+	if _, err := GetUpload(uuid.New(), userID); err == nil {
+		t.Error("Expected error from GetUpload with nil database, got none")
+	}
+	if _, err := GetUploadsByUserID(userID); err == nil {
+		t.Error("Expected error from GetUploadsByUserID with nil database, got none")
+	}
+	if _, err := SetUserAvatar(userID, "/api/uploads/files/a.jpg"); err == nil {
+		t.Error("Expected error from SetUserAvatar with nil database, got none")
+	}
+}
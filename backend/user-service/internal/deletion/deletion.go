@@ -0,0 +1,253 @@
+// Package deletion carries out account deletions once their grace period has
+// passed: each service that stores data keyed by the user is asked to purge
+// it through its internal API, and user-service finally anonymises the
+// account itself.
+package deletion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/ffabious/healthy-summer/user-service/internal/upload"
+	"github.com/google/uuid"
+)
+
+// LocalService is the step name of the purge done by user-service itself. It
+// always runs last so the account stays identifiable until every other
+// service has confirmed.
+const LocalService = "user-service"
+
+// DefaultGracePeriod is how long a deletion request can be cancelled.
+const DefaultGracePeriod = 30 * 24 * time.Hour
+
+// Service is a downstream service exposing DELETE /internal/users/:user_id.
+type Service struct {
+	Name string
+	URL  string
+}
+
+// downstream maps each service holding user data to the environment variable
+// with its base URL.
+var downstream = []struct {
+	name   string
+	urlEnv string
+}{
+	{"activity-service", "ACTIVITY_SERVICE_URL"},
+	{"nutrition-service", "NUTRITION_SERVICE_URL"},
+	{"social-service", "SOCIAL_SERVICE_URL"},
+}
+
+// StepNames returns the steps of a deletion in the order they run.
+func StepNames() []string {
+	names := make([]string, 0, len(downstream)+1)
+	for _, d := range downstream {
+		names = append(names, d.name)
+	}
+	return append(names, LocalService)
+}
+
+// GracePeriod returns ACCOUNT_DELETION_GRACE_DAYS as a duration, falling back
+// to DefaultGracePeriod when unset or invalid.
+func GracePeriod() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("ACCOUNT_DELETION_GRACE_DAYS"))
+	if err != nil || days < 0 {
+		return DefaultGracePeriod
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// Processor runs due account deletions.
+type Processor struct {
+	Services []Service
+	Token    string
+	Client   *http.Client
+	// Uploads holds the user's uploaded files, which are deleted before the
+	// account itself is purged.
+	Uploads upload.Storage
+}
+
+// NewProcessorFromEnv configures a Processor from the *_SERVICE_URL variables
+// and INTERNAL_API_TOKEN.
+func NewProcessorFromEnv() *Processor {
+	p := &Processor{
+		Token:  os.Getenv("INTERNAL_API_TOKEN"),
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, d := range downstream {
+		p.Services = append(p.Services, Service{Name: d.name, URL: os.Getenv(d.urlEnv)})
+	}
+	return p
+}
+
+func (p *Processor) service(name string) (Service, bool) {
+	for _, s := range p.Services {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Service{}, false
+}
+
+// Purge asks a downstream service to delete the user's data and returns the
+// total number of rows it removed.
+func (p *Processor) Purge(ctx context.Context, svc Service, userID uuid.UUID) (int64, error) {
+	if svc.URL == "" {
+		return 0, fmt.Errorf("%s URL is not configured", svc.Name)
+	}
+	url := strings.TrimRight(svc.URL, "/") + "/internal/users/" + userID.String()
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set(auth.InternalTokenHeader, p.Token)
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return 0, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var result struct {
+		Deleted map[string]int64 `json:"deleted"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("invalid response: %w", err)
+	}
+	var total int64
+	for _, n := range result.Deleted {
+		total += n
+	}
+	return total, nil
+}
+
+// deleteUploads removes the user's uploaded files from storage.
+func (p *Processor) deleteUploads(ctx context.Context, userID uuid.UUID) error {
+	if p.Uploads == nil {
+		return nil
+	}
+	uploads, err := db.GetUploadsByUserID(userID)
+	if err != nil {
+		return err
+	}
+	for _, u := range uploads {
+		for _, key := range []string{u.Key, u.ThumbnailKey} {
+			if err := p.Uploads.Delete(ctx, key); err != nil && !errors.Is(err, upload.ErrNotFound) {
+				return fmt.Errorf("failed to delete %s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Process runs every unfinished step of a deletion and records each outcome.
+// Failed steps are retried on the next run; the local step only runs once all
+// downstream services have succeeded.
+func (p *Processor) Process(ctx context.Context, deletion *model.AccountDeletion) error {
+	if deletion.Status == model.DeletionStatusScheduled {
+		if err := db.SetAccountDeletionStatus(deletion.ID, model.DeletionStatusInProgress); err != nil {
+			return err
+		}
+		deletion.Status = model.DeletionStatusInProgress
+	}
+
+	var failed error
+	for i := range deletion.Steps {
+		step := &deletion.Steps[i]
+		if step.Status == model.DeletionStepCompleted {
+			continue
+		}
+
+		var rows int64
+		var err error
+		switch svc, ok := p.service(step.Service); {
+		case step.Service == LocalService:
+			if failed != nil {
+				continue
+			}
+			if err = p.deleteUploads(ctx, deletion.UserID); err == nil {
+				rows, err = db.PurgeUserAccount(deletion.UserID)
+			}
+		case ok:
+			rows, err = p.Purge(ctx, svc, deletion.UserID)
+		default:
+			err = fmt.Errorf("unknown service %q", step.Service)
+		}
+
+		step.Attempts++
+		step.UpdatedAt = time.Now()
+		if err != nil {
+			step.Status = model.DeletionStepFailed
+			step.LastError = truncate(err.Error(), 500)
+			failed = errors.Join(failed, fmt.Errorf("%s: %w", step.Service, err))
+		} else {
+			step.Status = model.DeletionStepCompleted
+			step.RowsDeleted = rows
+			step.LastError = ""
+		}
+		if err := db.SaveDeletionStep(step); err != nil {
+			return err
+		}
+	}
+	if failed != nil {
+		return failed
+	}
+
+	if err := db.SetAccountDeletionStatus(deletion.ID, model.DeletionStatusCompleted); err != nil {
+		return err
+	}
+	deletion.Status = model.DeletionStatusCompleted
+	return nil
+}
+
+// RunDue processes every deletion whose grace period ended before now.
+func (p *Processor) RunDue(ctx context.Context, now time.Time) {
+	deletions, err := db.GetDueAccountDeletions(now)
+	if err != nil {
+		log.Printf("Failed to load due account deletions: %v", err)
+		return
+	}
+	for i := range deletions {
+		if err := p.Process(ctx, &deletions[i]); err != nil {
+			log.Printf("Account deletion %s for user %s incomplete: %v", deletions[i].ID, deletions[i].UserID, err)
+			continue
+		}
+		log.Printf("Account deletion %s for user %s completed", deletions[i].ID, deletions[i].UserID)
+	}
+}
+
+// Start runs due deletions every interval until ctx is cancelled.
+func (p *Processor) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		p.RunDue(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
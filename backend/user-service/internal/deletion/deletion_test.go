@@ -0,0 +1,114 @@
+package deletion
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
+func TestStepNames(t *testing.T) {
+	names := StepNames()
+	if len(names) != 4 {
+		t.Fatalf("Expected 4 steps, got %v", names)
+	}
+	if names[len(names)-1] != LocalService {
+		t.Errorf("Expected %s to run last, got %v", LocalService, names)
+	}
+}
+
+func TestGracePeriod(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{name: "Unset", value: "", expected: DefaultGracePeriod},
+		{name: "Configured", value: "7", expected: 7 * 24 * time.Hour},
+		{name: "Immediate", value: "0", expected: 0},
+		{name: "Invalid", value: "soon", expected: DefaultGracePeriod},
+		{name: "Negative", value: "-1", expected: DefaultGracePeriod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ACCOUNT_DELETION_GRACE_DAYS", tt.value)
+			if got := GracePeriod(); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPurge(t *testing.T) {
+	userID := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(auth.InternalTokenHeader) != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"Invalid internal token"}`))
+			return
+		}
+		if r.Method != http.MethodDelete || r.URL.Path != "/internal/users/"+userID.String() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"service":"test","deleted":{"meals":3,"waters":2}}`))
+	}))
+	defer server.Close()
+
+	p := &Processor{Token: "secret", Client: server.Client()}
+
+	rows, err := p.Purge(context.Background(), Service{Name: "test", URL: server.URL + "/"}, userID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rows != 5 {
+		t.Errorf("Expected 5 rows deleted, got %d", rows)
+	}
+
+	p.Token = "wrong"
+	if _, err := p.Purge(context.Background(), Service{Name: "test", URL: server.URL}, userID); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected unauthorized error, got %v", err)
+	}
+
+	if _, err := p.Purge(context.Background(), Service{Name: "test"}, userID); err == nil {
+		t.Error("Expected error for unconfigured service URL, got none")
+	}
+}
+
+func TestProcessRecordsFailedStep(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	p := &Processor{
+		Services: []Service{{Name: "activity-service", URL: server.URL}},
+		Client:   server.Client(),
+	}
+	deletion := &model.AccountDeletion{
+		ID:     uuid.New(),
+		UserID: uuid.New(),
+		Status: model.DeletionStatusInProgress,
+		Steps: []model.DeletionStep{
+			{Service: "activity-service", Status: model.DeletionStepPending},
+			{Service: LocalService, Status: model.DeletionStepPending},
+		},
+	}
+
+	// Saving the step needs a database, so Process stops there, but the
+	// failure must already be recorded on the step.
+	if err := p.Process(context.Background(), deletion); err == nil {
+		t.Fatal("Expected error, got none")
+	}
+	step := deletion.Steps[0]
+	if step.Status != model.DeletionStepFailed || step.Attempts != 1 || !strings.Contains(step.LastError, "500") {
+		t.Errorf("Expected failed step with recorded error, got %+v", step)
+	}
+}
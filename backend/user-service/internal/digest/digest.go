@@ -0,0 +1,134 @@
+// Package digest builds the weekly recap of a user's activity, nutrition and
+// achievements, and emails it to every user on Mondays.
+package digest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/activity"
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/ffabious/healthy-summer/user-service/internal/nutrition"
+	"github.com/google/uuid"
+)
+
+// WeekStart returns the Monday 00:00 UTC that starts the week containing t.
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// LastWeek returns the start of the last full week before t.
+func LastWeek(t time.Time) time.Time {
+	return WeekStart(t).AddDate(0, 0, -7)
+}
+
+// ActivitySource totals a user's activities over a period.
+type ActivitySource interface {
+	Period(ctx context.Context, userID uuid.UUID, from, to time.Time) (*model.ActivityPeriod, error)
+}
+
+// NutritionSource totals a user's meals and water over a period.
+type NutritionSource interface {
+	Period(ctx context.Context, userID uuid.UUID, from, to time.Time) (*model.NutritionPeriod, error)
+}
+
+// Builder gathers the numbers of a digest. Sources that are nil are left out
+// of it.
+type Builder struct {
+	Activity     ActivitySource
+	Nutrition    NutritionSource
+	Achievements func(userID uuid.UUID, from, to time.Time) ([]model.Achievement, error)
+}
+
+// NewBuilderFromEnv reads activity and nutrition totals from the services
+// configured by ACTIVITY_SERVICE_URL and NUTRITION_SERVICE_URL, and
+// achievements from the database.
+func NewBuilderFromEnv() *Builder {
+	b := &Builder{Achievements: db.GetAchievementsBetween}
+	if c := activity.NewClientFromEnv(); c != nil {
+		b.Activity = c
+	}
+	if c := nutrition.NewClientFromEnv(); c != nil {
+		b.Nutrition = c
+	}
+	return b
+}
+
+// Build compares the week starting at weekStart with the week before it.
+func (b *Builder) Build(ctx context.Context, user *model.User, weekStart time.Time) (*model.WeeklyDigest, error) {
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	previous := weekStart.AddDate(0, 0, -7)
+	digest := &model.WeeklyDigest{
+		UserID:       user.ID,
+		FirstName:    user.FirstName,
+		WeekStart:    weekStart,
+		WeekEnd:      weekEnd,
+		Metrics:      []model.DigestMetric{},
+		Achievements: []model.Achievement{},
+	}
+
+	if b.Activity != nil {
+		this, err := b.Activity.Period(ctx, user.ID, weekStart, weekEnd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get activity: %w", err)
+		}
+		last, err := b.Activity.Period(ctx, user.ID, previous, weekStart)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get activity: %w", err)
+		}
+		digest.Activity = &model.ActivityComparison{ThisWeek: *this, PreviousWeek: *last}
+		digest.Metrics = append(digest.Metrics,
+			metric("workouts", "Workouts", "", float64(this.ActivityCount), float64(last.ActivityCount)),
+			metric("active_minutes", "Active time", "min", float64(this.DurationMin), float64(last.DurationMin)),
+			metric("calories_burned", "Calories burned", "kcal", float64(this.Calories), float64(last.Calories)),
+			metric("steps", "Steps", "", float64(this.Steps), float64(last.Steps)),
+		)
+	}
+
+	if b.Nutrition != nil {
+		this, err := b.Nutrition.Period(ctx, user.ID, weekStart, weekEnd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get nutrition: %w", err)
+		}
+		last, err := b.Nutrition.Period(ctx, user.ID, previous, weekStart)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get nutrition: %w", err)
+		}
+		digest.Nutrition = &model.NutritionComparison{ThisWeek: *this, PreviousWeek: *last}
+		digest.Metrics = append(digest.Metrics,
+			metric("meals", "Meals logged", "", float64(this.MealCount), float64(last.MealCount)),
+			metric("calories_eaten", "Calories eaten", "kcal", float64(this.TotalCalories), float64(last.TotalCalories)),
+			metric("water", "Water", "ml", this.TotalWaterMl, last.TotalWaterMl),
+		)
+	}
+
+	if b.Achievements != nil {
+		achievements, err := b.Achievements(user.ID, weekStart, weekEnd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get achievements: %w", err)
+		}
+		digest.Achievements = achievements
+	}
+	return digest, nil
+}
+
+func metric(key, label, unit string, current, previous float64) model.DigestMetric {
+	m := model.DigestMetric{
+		Key:      key,
+		Label:    label,
+		Unit:     unit,
+		Current:  current,
+		Previous: previous,
+		Change:   current - previous,
+	}
+	if previous != 0 {
+		percent := math.Round(m.Change/previous*1000) / 10
+		m.ChangePercent = &percent
+	}
+	return m
+}
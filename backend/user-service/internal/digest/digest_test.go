@@ -0,0 +1,203 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/mail"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
+type fakeActivity map[time.Time]model.ActivityPeriod
+
+func (f fakeActivity) Period(_ context.Context, _ uuid.UUID, from, _ time.Time) (*model.ActivityPeriod, error) {
+	period, ok := f[from]
+	if !ok {
+		return nil, errors.New("unavailable")
+	}
+	return &period, nil
+}
+
+type fakeNutrition map[time.Time]model.NutritionPeriod
+
+func (f fakeNutrition) Period(_ context.Context, _ uuid.UUID, from, _ time.Time) (*model.NutritionPeriod, error) {
+	period := f[from]
+	return &period, nil
+}
+
+func TestWeekStart(t *testing.T) {
+	monday := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		t    time.Time
+	}{
+		{"Monday midnight", monday},
+		{"Wednesday", time.Date(2025, 6, 4, 15, 30, 0, 0, time.UTC)},
+		{"Sunday night", time.Date(2025, 6, 8, 23, 59, 0, 0, time.UTC)},
+		{"Monday morning in Moscow is still Sunday in UTC", time.Date(2025, 6, 9, 2, 0, 0, 0, time.FixedZone("MSK", 3*3600))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WeekStart(tt.t); !got.Equal(monday) {
+				t.Errorf("Expected %v, got %v", monday, got)
+			}
+		})
+	}
+	if got := LastWeek(time.Date(2025, 6, 9, 6, 0, 0, 0, time.UTC)); !got.Equal(monday) {
+		t.Errorf("Expected last week to start %v, got %v", monday, got)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	weekStart := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	previous := weekStart.AddDate(0, 0, -7)
+	user := &model.User{ID: uuid.New(), FirstName: "Anna"}
+	achievement := model.Achievement{Name: "First 10k", Details: "Ran 10 km"}
+
+	b := &Builder{
+		Activity: fakeActivity{
+			weekStart: {ActivityCount: 4, DurationMin: 150, Calories: 1200, Steps: 50000},
+			previous:  {ActivityCount: 2, DurationMin: 150, Calories: 0, Steps: 40000},
+		},
+		Nutrition: fakeNutrition{
+			weekStart: {MealCount: 18, TotalCalories: 14000, TotalWaterMl: 10500},
+			previous:  {MealCount: 20, TotalCalories: 15000, TotalWaterMl: 12000},
+		},
+		Achievements: func(userID uuid.UUID, from, to time.Time) ([]model.Achievement, error) {
+			if userID != user.ID || !from.Equal(weekStart) || !to.Equal(weekStart.AddDate(0, 0, 7)) {
+				t.Errorf("Unexpected achievements query for %s from %v to %v", userID, from, to)
+			}
+			return []model.Achievement{achievement}, nil
+		},
+	}
+	digest, err := b.Build(context.Background(), user, weekStart)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if digest.Activity == nil || digest.Activity.ThisWeek.Steps != 50000 || digest.Activity.PreviousWeek.Steps != 40000 {
+		t.Errorf("Unexpected activity comparison: %+v", digest.Activity)
+	}
+	if digest.Nutrition == nil || digest.Nutrition.ThisWeek.MealCount != 18 {
+		t.Errorf("Unexpected nutrition comparison: %+v", digest.Nutrition)
+	}
+	if len(digest.Achievements) != 1 || digest.Achievements[0].Name != "First 10k" {
+		t.Errorf("Unexpected achievements: %+v", digest.Achievements)
+	}
+
+	metrics := map[string]model.DigestMetric{}
+	for _, m := range digest.Metrics {
+		metrics[m.Key] = m
+	}
+	if len(metrics) != 7 {
+		t.Fatalf("Expected 7 metrics, got %d", len(metrics))
+	}
+	tests := []struct {
+		key     string
+		change  float64
+		percent *float64
+	}{
+		{"workouts", 2, ptr(100)},
+		{"active_minutes", 0, ptr(0)},
+		{"calories_burned", 1200, nil},
+		{"steps", 10000, ptr(25)},
+		{"water", -1500, ptr(-12.5)},
+		{"calories_eaten", -1000, ptr(-6.7)},
+	}
+	for _, tt := range tests {
+		m := metrics[tt.key]
+		if m.Change != tt.change {
+			t.Errorf("Expected %s to change by %v, got %v", tt.key, tt.change, m.Change)
+		}
+		if (m.ChangePercent == nil) != (tt.percent == nil) || (tt.percent != nil && *m.ChangePercent != *tt.percent) {
+			t.Errorf("Expected %s to change by %v%%, got %v", tt.key, tt.percent, m.ChangePercent)
+		}
+	}
+
+	// Unconfigured services are left out.
+	digest, err = (&Builder{}).Build(context.Background(), user, weekStart)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if digest.Activity != nil || digest.Nutrition != nil || len(digest.Metrics) != 0 || digest.Achievements == nil {
+		t.Errorf("Expected an empty digest, got %+v", digest)
+	}
+
+	b.Activity = fakeActivity{}
+	if _, err := b.Build(context.Background(), user, weekStart); err == nil {
+		t.Error("Expected error when activity-service fails, got none")
+	}
+}
+
+func TestEmail(t *testing.T) {
+	weekStart := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	digest := &model.WeeklyDigest{
+		FirstName: "Anna",
+		WeekStart: weekStart,
+		WeekEnd:   weekStart.AddDate(0, 0, 7),
+		Metrics: []model.DigestMetric{
+			metric("steps", "Steps", "", 50000, 40000),
+			metric("water", "Water", "ml", 10500.5, 12000),
+			metric("calories_burned", "Calories burned", "kcal", 1200, 0),
+			metric("workouts", "Workouts", "", 3, 3),
+		},
+		Achievements: []model.Achievement{{Name: "<b>Early bird</b>", Details: "5 workouts before 7am"}},
+	}
+
+	msg, err := Email(digest, "anna@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if msg.To != "anna@example.com" || msg.Subject != "Your Healthy Summer week: Jun 2 to Jun 8" {
+		t.Errorf("Unexpected message header: %q to %q", msg.Subject, msg.To)
+	}
+	for _, expected := range []string{
+		"Hi Anna,",
+		"Steps: 50000 (+25%)",
+		"Water: 10500.5 ml (-12.5%)",
+		"Calories burned: 1200 kcal (up from 0)",
+		"Workouts: 3 (same as last week)",
+		"- <b>Early bird</b>: 5 workouts before 7am",
+	} {
+		if !strings.Contains(msg.Body, expected) {
+			t.Errorf("Expected text body to contain %q, got:\n%s", expected, msg.Body)
+		}
+	}
+	for _, expected := range []string{
+		"<td><b>50000</b></td>",
+		"<td>12000 ml</td>",
+		"&lt;b&gt;Early bird&lt;/b&gt;",
+	} {
+		if !strings.Contains(msg.HTML, expected) {
+			t.Errorf("Expected HTML body to contain %q, got:\n%s", expected, msg.HTML)
+		}
+	}
+
+	digest.Achievements = nil
+	msg, err = Email(digest, "anna@example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(msg.Body, "Achievements") || strings.Contains(msg.HTML, "Achievements") {
+		t.Error("Expected no achievements section without achievements")
+	}
+}
+
+func TestRunDueOnlyOnMondays(t *testing.T) {
+	mailer := &mail.FakeSender{}
+	job := &Job{Builder: &Builder{}, Mailer: mailer}
+
+	// Without a database nothing can be sent; the job must not panic.
+	job.RunDue(context.Background(), time.Date(2025, 6, 9, 6, 0, 0, 0, time.UTC))
+	job.RunDue(context.Background(), time.Date(2025, 6, 10, 6, 0, 0, 0, time.UTC))
+	if len(mailer.Messages()) != 0 {
+		t.Errorf("Expected no emails, got %d", len(mailer.Messages()))
+	}
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,99 @@
+package digest
+
+import (
+	htmltemplate "html/template"
+	"math"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/mail"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+)
+
+var funcs = map[string]any{
+	"number": number,
+	"change": change,
+	"date":   func(d *model.WeeklyDigest) string { return weekRange(d) },
+}
+
+var textEmail = texttemplate.Must(texttemplate.New("text").Funcs(funcs).Parse(`Hi {{.FirstName}},
+
+Here is your week from {{date .}}.
+{{range .Metrics}}
+{{.Label}}: {{number .Current}}{{if .Unit}} {{.Unit}}{{end}} ({{change .}})
+{{- end}}
+{{if .Achievements}}
+Achievements earned:
+{{- range .Achievements}}
+- {{.Name}}: {{.Details}}
+{{- end}}
+{{end}}
+Have a great week!
+`))
+
+var htmlEmail = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family:Arial,sans-serif;color:#333;max-width:600px;margin:0 auto">
+<p>Hi {{.FirstName}},</p>
+<p>Here is your week from {{date .}}.</p>
+{{if .Metrics}}<table cellpadding="6" style="border-collapse:collapse;width:100%">
+<tr style="text-align:left;border-bottom:1px solid #ddd"><th></th><th>This week</th><th>Last week</th><th>Change</th></tr>
+{{range .Metrics}}<tr style="border-bottom:1px solid #eee">
+<td>{{.Label}}</td>
+<td><b>{{number .Current}}</b>{{if .Unit}} {{.Unit}}{{end}}</td>
+<td>{{number .Previous}}{{if .Unit}} {{.Unit}}{{end}}</td>
+<td style="color:{{if lt .Change 0.0}}#c0392b{{else}}#27ae60{{end}}">{{change .}}</td>
+</tr>
+{{end}}</table>{{end}}
+{{if .Achievements}}<h3>Achievements earned</h3>
+<ul>
+{{range .Achievements}}<li><b>{{.Name}}</b>: {{.Details}}</li>
+{{end}}</ul>{{end}}
+<p>Have a great week!</p>
+</body>
+</html>
+`))
+
+// Email renders the digest as a plain-text email with an HTML alternative.
+func Email(d *model.WeeklyDigest, to string) (mail.Message, error) {
+	var text, html strings.Builder
+	if err := textEmail.Execute(&text, d); err != nil {
+		return mail.Message{}, err
+	}
+	if err := htmlEmail.Execute(&html, d); err != nil {
+		return mail.Message{}, err
+	}
+	return mail.Message{
+		To:      to,
+		Subject: "Your Healthy Summer week: " + weekRange(d),
+		Body:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// weekRange formats the week as e.g. "Jun 2 to Jun 8".
+func weekRange(d *model.WeeklyDigest) string {
+	return d.WeekStart.Format("Jan 2") + " to " + d.WeekEnd.AddDate(0, 0, -1).Format("Jan 2")
+}
+
+// number shows whole numbers without decimals and others with one.
+func number(v float64) string {
+	if v == math.Trunc(v) {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 1, 64)
+}
+
+func change(m model.DigestMetric) string {
+	switch {
+	case m.Change == 0:
+		return "same as last week"
+	case m.ChangePercent == nil:
+		return "up from 0"
+	case *m.ChangePercent > 0:
+		return "+" + number(*m.ChangePercent) + "%"
+	default:
+		return number(*m.ChangePercent) + "%"
+	}
+}
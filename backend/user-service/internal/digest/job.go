@@ -0,0 +1,88 @@
+package digest
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/mail"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// batchSize is how many recipients are loaded at a time.
+const batchSize = 100
+
+// Job emails last week's digest to every user on Mondays (UTC).
+type Job struct {
+	Builder *Builder
+	Mailer  mail.Sender
+}
+
+// RunDue sends the digest of the week before now to users who have not got
+// it yet. Outside of Mondays it does nothing, so a digest that could not be
+// sent is retried for the rest of the day but not later in the week.
+func (j *Job) RunDue(ctx context.Context, now time.Time) {
+	if now.UTC().Weekday() != time.Monday {
+		return
+	}
+	weekStart := LastWeek(now)
+	after := uuid.Nil
+	for {
+		users, err := db.GetDigestRecipients(weekStart, after, batchSize)
+		if err != nil {
+			log.Printf("Failed to get digest recipients: %v", err)
+			return
+		}
+		for i := range users {
+			if ctx.Err() != nil {
+				return
+			}
+			claimed, err := db.ClaimDigestDelivery(users[i].ID, weekStart, now)
+			if err != nil {
+				log.Printf("Failed to claim digest for user %s: %v", users[i].ID, err)
+				continue
+			}
+			if !claimed {
+				continue
+			}
+			if err := j.send(ctx, &users[i], weekStart); err != nil {
+				log.Printf("Failed to send digest to user %s: %v", users[i].ID, err)
+				if err := db.ReleaseDigestDelivery(users[i].ID, weekStart); err != nil {
+					log.Printf("Failed to release digest for user %s: %v", users[i].ID, err)
+				}
+			}
+		}
+		if len(users) < batchSize {
+			return
+		}
+		after = users[len(users)-1].ID
+	}
+}
+
+func (j *Job) send(ctx context.Context, user *model.User, weekStart time.Time) error {
+	digest, err := j.Builder.Build(ctx, user, weekStart)
+	if err != nil {
+		return err
+	}
+	msg, err := Email(digest, user.Email)
+	if err != nil {
+		return err
+	}
+	return j.Mailer.Send(ctx, msg)
+}
+
+// Start sends due digests every interval until ctx is cancelled.
+func (j *Job) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		j.RunDue(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
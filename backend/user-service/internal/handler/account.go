@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/deletion"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// @Summary Delete Current User
+// @Description Schedule deletion of the current account. After the grace period (ACCOUNT_DELETION_GRACE_DAYS, 30 days by default) the user's data is purged from every service and the account is anonymised. Repeating the request returns the deletion already scheduled.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param deleteAccountRequest body model.DeleteAccountRequest true "Current password"
+// @Success 202 {object} model.AccountDeletion
+// @Security BearerAuth
+// @Router /api/users/me [delete]
+func DeleteCurrentUserHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	var req model.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	user, err := db.GetUserByID(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found", "details": err.Error()})
+		return
+	}
+	if rejectWrongPassword(c, user, req.Password, "Invalid password") {
+		return
+	}
+
+	scheduledFor := time.Now().Add(deletion.GracePeriod())
+	accountDeletion, err := db.CreateAccountDeletion(user.ID, scheduledFor, deletion.StepNames())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule account deletion", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, accountDeletion)
+}
+
+// @Summary Get Account Deletion
+// @Description Get the status of the current user's latest account deletion request, including the outcome of each service's purge
+// @Tags user
+// @Produce json
+// @Success 200 {object} model.AccountDeletion
+// @Security BearerAuth
+// @Router /api/users/me/deletion [get]
+func GetAccountDeletionHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	accountDeletion, err := db.GetLatestAccountDeletion(uuid.MustParse(userID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No account deletion requested"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get account deletion", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, accountDeletion)
+}
+
+// @Summary Cancel Account Deletion
+// @Description Cancel a scheduled account deletion while its grace period is still running
+// @Tags user
+// @Produce json
+// @Success 200 {object} model.AccountDeletion
+// @Security BearerAuth
+// @Router /api/users/me/deletion/cancel [post]
+func CancelAccountDeletionHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	accountDeletion, err := db.CancelAccountDeletion(uuid.MustParse(userID))
+	if errors.Is(err, db.ErrNoScheduledDeletion) {
+		c.JSON(http.StatusConflict, gin.H{"error": "No scheduled account deletion to cancel"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel account deletion", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, accountDeletion)
+}
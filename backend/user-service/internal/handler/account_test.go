@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestAccountDeletionHandlers(t *testing.T) {
+	router := setupRouter()
+	router.DELETE("/api/users/me", DeleteCurrentUserHandler)
+	router.GET("/api/users/me/deletion", GetAccountDeletionHandler)
+	router.POST("/api/users/me/deletion/cancel", CancelAccountDeletionHandler)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": uuid.New().String()}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		authHeader     string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Delete without authorization",
+			method:         "DELETE",
+			path:           "/api/users/me",
+			body:           `{"password":"secret"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Delete without password",
+			method:         "DELETE",
+			path:           "/api/users/me",
+			authHeader:     "Bearer " + token,
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Delete without database",
+			method:         "DELETE",
+			path:           "/api/users/me",
+			authHeader:     "Bearer " + token,
+			body:           `{"password":"secret"}`,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "User not found",
+		},
+		{
+			name:           "Status without authorization",
+			method:         "GET",
+			path:           "/api/users/me/deletion",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Status without database",
+			method:         "GET",
+			path:           "/api/users/me/deletion",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get account deletion",
+		},
+		{
+			name:           "Cancel without database",
+			method:         "POST",
+			path:           "/api/users/me/deletion/cancel",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to cancel account deletion",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
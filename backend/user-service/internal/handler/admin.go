@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAdminPageSize = 50
+	maxAdminPageSize     = 200
+)
+
+// rejectSuspended answers 403 and returns true if user is suspended
+func rejectSuspended(c *gin.Context, user *model.User) bool {
+	if user.SuspendedAt == nil {
+		return false
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Account suspended", "details": user.SuspensionReason})
+	return true
+}
+
+// adminTarget parses the :id of the user an admin acts on. Admins cannot
+// act on themselves, so that nobody locks themselves out by mistake
+func adminTarget(c *gin.Context) (uuid.UUID, bool) {
+	adminID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return uuid.Nil, false
+	}
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	if targetID.String() == adminID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Admins cannot change their own account here"})
+		return uuid.Nil, false
+	}
+	return targetID, true
+}
+
+func respondAdminUpdate(c *gin.Context, user *model.User, err error, action string) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action, "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary List Users
+// @Description List users for administration, newest first
+// @Tags admin
+// @Produce json
+// @Param q query string false "Search email or name"
+// @Param role query string false "Filter by role"
+// @Param suspended query bool false "Filter by suspension"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Number of users to skip"
+// @Success 200 {object} model.AdminUserListResponse
+// @Security BearerAuth
+// @Router /api/admin/users [get]
+func AdminListUsersHandler(c *gin.Context) {
+	filter := db.UserFilter{
+		Query: c.Query("q"),
+		Role:  c.Query("role"),
+		Limit: defaultAdminPageSize,
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		filter.Limit = min(limit, maxAdminPageSize)
+	}
+	if raw := c.Query("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+			return
+		}
+		filter.Offset = offset
+	}
+	if raw := c.Query("suspended"); raw != "" {
+		suspended, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "suspended must be true or false"})
+			return
+		}
+		filter.Suspended = &suspended
+	}
+
+	users, total, err := db.ListUsers(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list users", "details": err.Error()})
+		return
+	}
+	if users == nil {
+		users = []model.User{}
+	}
+	c.JSON(http.StatusOK, model.AdminUserListResponse{
+		Users:  users,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	})
+}
+
+// @Summary Suspend User
+// @Description Block a user from logging in and sign out all their sessions
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param suspendUserRequest body model.SuspendUserRequest true "Reason shown to the user"
+// @Success 200 {object} model.User
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/suspend [post]
+func AdminSuspendUserHandler(c *gin.Context) {
+	targetID, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+	var req model.SuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+	user, err := db.SuspendUser(targetID, req.Reason)
+	respondAdminUpdate(c, user, err, "suspend user")
+}
+
+// @Summary Unsuspend User
+// @Description Lift a user's suspension
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} model.User
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/unsuspend [post]
+func AdminUnsuspendUserHandler(c *gin.Context) {
+	targetID, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+	user, err := db.UnsuspendUser(targetID)
+	respondAdminUpdate(c, user, err, "unsuspend user")
+}
+
+// @Summary Set User Role
+// @Description Change a user's role. The user is signed out so their next token carries the new role.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param setRoleRequest body model.SetRoleRequest true "New role"
+// @Success 200 {object} model.User
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/role [put]
+func AdminSetUserRoleHandler(c *gin.Context) {
+	targetID, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+	var req model.SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+	user, err := db.SetUserRole(targetID, req.Role)
+	respondAdminUpdate(c, user, err, "set role")
+}
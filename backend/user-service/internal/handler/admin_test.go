@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestAdminHandlers(t *testing.T) {
+	router := setupRouter()
+	router.GET("/api/admin/users", AdminListUsersHandler)
+	router.POST("/api/admin/users/:id/suspend", AdminSuspendUserHandler)
+	router.POST("/api/admin/users/:id/unsuspend", AdminUnsuspendUserHandler)
+	router.PUT("/api/admin/users/:id/role", AdminSetUserRoleHandler)
+
+	adminID := uuid.New().String()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": adminID, "role": "admin"}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	otherID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		authHeader     string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "List with invalid limit",
+			method:         "GET",
+			path:           "/api/admin/users?limit=0",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "limit must be a positive integer",
+		},
+		{
+			name:           "List with invalid suspended filter",
+			method:         "GET",
+			path:           "/api/admin/users?suspended=maybe",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "suspended must be true or false",
+		},
+		{
+			name:           "List without database",
+			method:         "GET",
+			path:           "/api/admin/users?q=jane",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to list users",
+		},
+		{
+			name:           "Suspend without authorization",
+			method:         "POST",
+			path:           "/api/admin/users/" + otherID + "/suspend",
+			body:           `{"reason":"spam"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Suspend with invalid ID",
+			method:         "POST",
+			path:           "/api/admin/users/not-a-uuid/suspend",
+			authHeader:     "Bearer " + token,
+			body:           `{"reason":"spam"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid user ID",
+		},
+		{
+			name:           "Suspend self",
+			method:         "POST",
+			path:           "/api/admin/users/" + adminID + "/suspend",
+			authHeader:     "Bearer " + token,
+			body:           `{"reason":"spam"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "cannot change their own account",
+		},
+		{
+			name:           "Suspend without reason",
+			method:         "POST",
+			path:           "/api/admin/users/" + otherID + "/suspend",
+			authHeader:     "Bearer " + token,
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Suspend without database",
+			method:         "POST",
+			path:           "/api/admin/users/" + otherID + "/suspend",
+			authHeader:     "Bearer " + token,
+			body:           `{"reason":"spam"}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to suspend user",
+		},
+		{
+			name:           "Unsuspend without database",
+			method:         "POST",
+			path:           "/api/admin/users/" + otherID + "/unsuspend",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to unsuspend user",
+		},
+		{
+			name:           "Set unknown role",
+			method:         "PUT",
+			path:           "/api/admin/users/" + otherID + "/role",
+			authHeader:     "Bearer " + token,
+			body:           `{"role":"superuser"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Set own role",
+			method:         "PUT",
+			path:           "/api/admin/users/" + adminID + "/role",
+			authHeader:     "Bearer " + token,
+			body:           `{"role":"user"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "cannot change their own account",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
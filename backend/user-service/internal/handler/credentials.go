@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/mail"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// EmailChangeTTL is how long the confirmation link for a new email stays valid
+const EmailChangeTTL = 24 * time.Hour
+
+// rejectWrongPassword answers and returns true unless password matches the
+// user's. Accounts created through an identity provider have no password, so
+// they get 403 pointing at the forgot password flow instead of 401
+func rejectWrongPassword(c *gin.Context, user *model.User, password, message string) bool {
+	if user.Password == "" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Password not set",
+			"details": "this account signs in with an identity provider; set a password through /api/users/password/forgot first",
+		})
+		return true
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": message})
+		return true
+	}
+	return false
+}
+
+func sendEmailChangeEmails(ctx context.Context, user *model.User, newEmail string) error {
+	token, hash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	if err := db.CreateUserToken(&model.UserToken{
+		UserID:    user.ID,
+		Purpose:   model.TokenPurposeEmailChange,
+		TokenHash: hash,
+		Email:     newEmail,
+		ExpiresAt: time.Now().Add(EmailChangeTTL),
+	}); err != nil {
+		return err
+	}
+	if err := Mailer.Send(ctx, mail.Message{
+		To:      newEmail,
+		Subject: "Confirm your new Healthy Summer email",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm that you want to use this address for your account by opening the link below:\n\n%s\n\nThe link expires in %d hours.\n",
+			user.FirstName, appLink("/confirm-email", token), int(EmailChangeTTL.Hours())),
+	}); err != nil {
+		return err
+	}
+
+	// Let the current address know, in case the change was not requested by
+	// its owner. The change itself does not depend on this email.
+	if err := Mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Your Healthy Summer email is being changed",
+		Body: fmt.Sprintf("Hi %s,\n\nA change of your account email to %s was requested. If this was not you, reset your password.\n",
+			user.FirstName, newEmail),
+	}); err != nil {
+		log.Printf("Failed to notify user %s about email change: %v", user.ID, err)
+	}
+	return nil
+}
+
+// @Summary Change Password
+// @Description Change the current user's password. All other sessions are signed out; the response carries a new token for this one. Accounts created through an identity provider have no password and get 403; they set one through /api/users/password/forgot.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param changePasswordRequest body model.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} model.LoginResponse
+// @Security BearerAuth
+// @Router /api/users/password [put]
+func ChangePasswordHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	var req model.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	user, err := db.GetUserByID(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found", "details": err.Error()})
+		return
+	}
+	if rejectWrongPassword(c, user, req.CurrentPassword, "Invalid current password") {
+		return
+	}
+
+	hashedPassword, err := HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password", "details": err.Error()})
+		return
+	}
+	user, err = db.ChangePassword(user.ID, hashedPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password", "details": err.Error()})
+		return
+	}
+
+	token, err := auth.GenerateJWT(user.ID, user.TokenVersion, user.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, model.LoginResponse{
+		User:      *user,
+		Token:     token,
+		TokenType: "Bearer",
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+	})
+}
+
+// @Summary Change Email
+// @Description Request a change of the current user's email. A confirmation link is sent to the new address and the email only changes once it is opened.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param changeEmailRequest body model.ChangeEmailRequest true "New email and current password"
+// @Success 202 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/users/email [post]
+func ChangeEmailHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	var req model.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+	newEmail := strings.TrimSpace(req.NewEmail)
+
+	user, err := db.GetUserByID(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found", "details": err.Error()})
+		return
+	}
+	if rejectWrongPassword(c, user, req.Password, "Invalid password") {
+		return
+	}
+	if strings.EqualFold(newEmail, user.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New email matches the current email"})
+		return
+	}
+
+	taken, err := db.EmailInUse(newEmail, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check email", "details": err.Error()})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email is already in use"})
+		return
+	}
+
+	if err := sendEmailChangeEmails(c.Request.Context(), user, newEmail); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send confirmation email", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "Confirmation email sent to the new address"})
+}
+
+// @Summary Confirm Email Change
+// @Description Switch the account to the new email with the token from the confirmation email
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param verifyEmailRequest body model.VerifyEmailRequest true "Confirmation token"
+// @Success 200 {object} model.User
+// @Router /api/users/email/confirm [post]
+func ConfirmEmailChangeHandler(c *gin.Context) {
+	var req model.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	user, err := db.ConfirmEmailChangeWithToken(auth.HashOpaqueToken(req.Token))
+	switch {
+	case errors.Is(err, db.ErrInvalidToken):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+		return
+	case errors.Is(err, db.ErrEmailTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "Email is already in use"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change email", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
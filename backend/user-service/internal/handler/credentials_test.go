@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestCredentialHandlers(t *testing.T) {
+	router := setupRouter()
+	router.PUT("/api/users/password", ChangePasswordHandler)
+	router.POST("/api/users/email", ChangeEmailHandler)
+	router.POST("/api/users/email/confirm", ConfirmEmailChangeHandler)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": uuid.New().String()}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		authHeader     string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Change password without authorization",
+			method:         "PUT",
+			path:           "/api/users/password",
+			body:           `{"current_password":"old","new_password":"new password"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Change password with short new password",
+			method:         "PUT",
+			path:           "/api/users/password",
+			authHeader:     "Bearer " + token,
+			body:           `{"current_password":"old","new_password":"short"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Change password for unknown user",
+			method:         "PUT",
+			path:           "/api/users/password",
+			authHeader:     "Bearer " + token,
+			body:           `{"current_password":"old","new_password":"new password"}`,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "User not found",
+		},
+		{
+			name:           "Change email with invalid address",
+			method:         "POST",
+			path:           "/api/users/email",
+			authHeader:     "Bearer " + token,
+			body:           `{"new_email":"nope","password":"secret"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Change email for unknown user",
+			method:         "POST",
+			path:           "/api/users/email",
+			authHeader:     "Bearer " + token,
+			body:           `{"new_email":"new@example.com","password":"secret"}`,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "User not found",
+		},
+		{
+			name:           "Confirm without token",
+			method:         "POST",
+			path:           "/api/users/email/confirm",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Confirm without database",
+			method:         "POST",
+			path:           "/api/users/email/confirm",
+			body:           `{"token":"abc"}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to change email",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRejectWrongPassword(t *testing.T) {
+	hashed, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		user           model.User
+		password       string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Matching password",
+			user:           model.User{Password: hashed},
+			password:       "correct horse",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Wrong password",
+			user:           model.User{Password: hashed},
+			password:       "battery staple",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Invalid password",
+		},
+		{
+			// Accounts created through an identity provider
+			name:           "Account without a password",
+			user:           model.User{},
+			password:       "anything",
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "/api/users/password/forgot",
+		},
+		{
+			name:           "Account without a password and an empty attempt",
+			user:           model.User{},
+			password:       "",
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "Password not set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupRouter()
+			router.POST("/check", func(c *gin.Context) {
+				if rejectWrongPassword(c, &tt.user, tt.password, "Invalid password") {
+					return
+				}
+				c.Status(http.StatusOK)
+			})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("POST", "/check", nil))
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/ffabious/healthy-summer/user-service/internal/transfer"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MaxImportFileSize limits the size of a bulk import upload
+const MaxImportFileSize = 20 << 20
+
+var friendColumns = []string{"friend_id", "first_name", "last_name", "email", "created_at"}
+
+var achievementColumns = []string{"id", "name", "details", "created_at"}
+
+// importID returns the ID an imported row is stored under. Rows exported by
+// us keep their ID; other rows get an ID derived from the user and the row
+// content, so uploading the same file twice does not create duplicates.
+func importID(userID uuid.UUID, dataset string, r transfer.Record) uuid.UUID {
+	if id, err := uuid.Parse(r.Fields["id"]); err == nil {
+		return id
+	}
+	keys := make([]string, 0, len(r.Fields))
+	for k := range r.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteString(dataset)
+	for _, k := range keys {
+		sb.WriteString("\x00" + k + "=" + r.Fields[k])
+	}
+	return uuid.NewSHA1(userID, []byte(sb.String()))
+}
+
+func achievementFromRecord(userID uuid.UUID, r transfer.Record) (model.Achievement, error) {
+	achievement := model.Achievement{ID: importID(userID, "achievements", r), UserID: userID}
+	var err error
+	if achievement.Name, err = r.Required("name"); err != nil {
+		return achievement, err
+	}
+	if achievement.Details, err = r.Required("details"); err != nil {
+		return achievement, err
+	}
+	if len(achievement.Name) > 100 || len(achievement.Details) > 255 {
+		return achievement, fmt.Errorf("name or details is too long")
+	}
+	achievement.CreatedAt = time.Now()
+	if r.Fields["created_at"] != "" {
+		if achievement.CreatedAt, err = r.Time("created_at"); err != nil {
+			return achievement, err
+		}
+	}
+	achievement.UpdatedAt = achievement.CreatedAt
+	return achievement, nil
+}
+
+func userDatasets(friends []model.FriendWithDetails, achievements []model.Achievement) []transfer.Dataset {
+	if friends == nil {
+		friends = []model.FriendWithDetails{}
+	}
+	if achievements == nil {
+		achievements = []model.Achievement{}
+	}
+
+	friendRows := make([][]string, len(friends))
+	for i, f := range friends {
+		friendRows[i] = []string{f.FriendID.String(), f.FirstName, f.LastName, f.Email, f.CreatedAt.UTC().Format(time.RFC3339)}
+	}
+	achievementRows := make([][]string, len(achievements))
+	for i, a := range achievements {
+		achievementRows[i] = []string{a.ID.String(), a.Name, a.Details, a.CreatedAt.UTC().Format(time.RFC3339)}
+	}
+
+	return []transfer.Dataset{
+		{Name: "friends", Columns: friendColumns, Rows: friendRows, Items: friends},
+		{Name: "achievements", Columns: achievementColumns, Rows: achievementRows, Items: achievements},
+	}
+}
+
+// @Summary Export User Data
+// @Description Download the friends and achievements of the currently authenticated user as a zip of CSV or JSON files
+// @Tags data
+// @Produce application/zip
+// @Param format query string false "csv (default) or json"
+// @Success 200 {file} file
+// @Security BearerAuth
+// @Router /api/users/data/export [get]
+func ExportUserDataHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+	format, err := transfer.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	friends, err := db.GetFriendsByUserID(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve friends", "details": err.Error()})
+		return
+	}
+	achievements, err := db.GetAchievementsByUserID(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve achievements", "details": err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := transfer.WriteZip(&buf, format, userDatasets(friends, achievements)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build export", "details": err.Error()})
+		return
+	}
+
+	filename := "social-" + time.Now().UTC().Format("20060102") + ".zip"
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// @Summary Import User Data
+// @Description Import achievements from a zip produced by the export endpoint or from a single achievements .csv or .json file. Friends are exported for reference only and cannot be imported. Invalid rows are reported and skipped; rows that were already imported are not duplicated.
+// @Tags data
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Zip, CSV or JSON file"
+// @Success 200 {object} transfer.Report
+// @Security BearerAuth
+// @Router /api/users/data/import [post]
+func ImportUserDataHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+	uid := uuid.MustParse(userID)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Import file is required", "details": err.Error()})
+		return
+	}
+	if fileHeader.Size > MaxImportFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Import file is too large"})
+		return
+	}
+	f, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read import file", "details": err.Error()})
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(f, MaxImportFileSize+1))
+	f.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read import file", "details": err.Error()})
+		return
+	}
+
+	files, err := transfer.ReadFiles(fileHeader.Filename, data)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid import file", "details": err.Error()})
+		return
+	}
+
+	report := transfer.Report{Datasets: []transfer.DatasetReport{}, Errors: []transfer.RowError{}}
+	seen := make(map[uuid.UUID]bool)
+	for _, file := range files {
+		switch file.Name {
+		case "achievements":
+		case "friends":
+			// Friendships need the other user's consent, so they are not recreated from a file.
+			continue
+		default:
+			report.Errors = append(report.Errors, transfer.RowError{File: file.Path, Error: "unknown dataset, expected achievements"})
+			continue
+		}
+
+		summary := transfer.DatasetReport{Dataset: file.Name, Rows: len(file.Records)}
+		var achievements []model.Achievement
+		for _, r := range file.Records {
+			achievement, err := achievementFromRecord(uid, r)
+			if err != nil {
+				summary.Failed++
+				report.Errors = append(report.Errors, transfer.RowError{File: file.Path, Row: r.Row, Error: err.Error()})
+				continue
+			}
+			if seen[achievement.ID] {
+				summary.Skipped++
+				continue
+			}
+			seen[achievement.ID] = true
+			achievements = append(achievements, achievement)
+		}
+
+		inserted, err := db.InsertAchievements(achievements)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import data", "details": err.Error(), "report": report})
+			return
+		}
+		summary.Imported = int(inserted)
+		summary.Skipped += len(achievements) - int(inserted)
+		report.Datasets = append(report.Datasets, summary)
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/ffabious/healthy-summer/user-service/internal/transfer"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestAchievementFromRecord(t *testing.T) {
+	userID := uuid.New()
+
+	achievement, err := achievementFromRecord(userID, transfer.Record{Fields: map[string]string{
+		"name": "First 10k", "details": "Ran 10 km", "created_at": "2025-06-01T07:00:00Z",
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !achievement.CreatedAt.Equal(time.Date(2025, 6, 1, 7, 0, 0, 0, time.UTC)) || achievement.UserID != userID {
+		t.Errorf("Unexpected achievement %+v", achievement)
+	}
+
+	if _, err := achievementFromRecord(userID, transfer.Record{Fields: map[string]string{"name": "First 10k"}}); err == nil {
+		t.Error("Expected error for missing details")
+	}
+	if _, err := achievementFromRecord(userID, transfer.Record{Fields: map[string]string{
+		"name": "First 10k", "details": "Ran 10 km", "created_at": "last week",
+	}}); err == nil {
+		t.Error("Expected error for invalid created_at")
+	}
+}
+
+func TestUserDatasets(t *testing.T) {
+	friends := []model.FriendWithDetails{{FriendID: uuid.New(), FirstName: "Ann", LastName: "Lee", Email: "ann@example.com"}}
+	datasets := userDatasets(friends, nil)
+	if len(datasets) != 2 || len(datasets[0].Rows) != 1 || len(datasets[1].Rows) != 0 {
+		t.Fatalf("Unexpected datasets %+v", datasets)
+	}
+	if datasets[0].Rows[0][3] != "ann@example.com" {
+		t.Errorf("Unexpected friend row %v", datasets[0].Rows[0])
+	}
+}
+
+func TestImportUserDataHandler(t *testing.T) {
+	router := setupRouter()
+	router.POST("/api/users/data/import", ImportUserDataHandler)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": uuid.New().String()}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		filename       string
+		content        string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Missing authorization",
+			filename:       "achievements.csv",
+			content:        "name,details\na,b\n",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Missing file",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Import file is required",
+		},
+		{
+			name:           "Friends are not imported",
+			authHeader:     "Bearer " + token,
+			filename:       "friends.csv",
+			content:        "friend_id,email\n" + uuid.New().String() + ",a@b.c\n",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"datasets":[]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			if tt.filename != "" {
+				part, _ := writer.CreateFormFile("file", tt.filename)
+				part.Write([]byte(tt.content))
+			}
+			writer.Close()
+
+			req := httptest.NewRequest("POST", "/api/users/data/import", &body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain '%s', got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
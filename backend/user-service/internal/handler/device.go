@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/ffabious/healthy-summer/user-service/internal/push"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PushDispatcher delivers notifications to devices. Pushes are not sent
+// when it is nil
+var PushDispatcher *push.Dispatcher
+
+// pushTimeout bounds the delivery of one notification, retries included
+const pushTimeout = 2 * time.Minute
+
+// pushNotification sends a notification to the user's devices in the
+// background
+func pushNotification(notification model.Notification) {
+	if PushDispatcher == nil {
+		return
+	}
+	msg := push.Message{
+		Title: "Healthy Summer",
+		Body:  notification.Message,
+		Data: map[string]string{
+			"notification_id": notification.ID.String(),
+			"type":            notification.Type,
+		},
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+		defer cancel()
+		result, err := PushDispatcher.Send(ctx, notification.UserID, msg)
+		if err != nil {
+			log.Printf("Failed to push notification %s: %v", notification.ID, err)
+			return
+		}
+		if result.Pruned > 0 {
+			log.Printf("Pruned %d invalid push tokens of user %s", result.Pruned, notification.UserID)
+		}
+	}()
+}
+
+// @Summary List Devices
+// @Description List the devices registered for push notifications by the current user
+// @Tags push
+// @Produce json
+// @Success 200 {array} model.DeviceToken
+// @Security BearerAuth
+// @Router /api/users/me/devices [get]
+func GetDevicesHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	devices, err := db.GetDeviceTokens(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve devices", "details": err.Error()})
+		return
+	}
+	if devices == nil {
+		devices = []model.DeviceToken{}
+	}
+	c.JSON(http.StatusOK, devices)
+}
+
+// @Summary Register Device
+// @Description Register a device for push notifications. The token is the FCM registration token on Android, the APNs device token on iOS and the JSON push subscription in browsers. Registering a known token moves it to the current user.
+// @Tags push
+// @Accept json
+// @Produce json
+// @Param deviceTokenRequest body model.DeviceTokenRequest true "Device token"
+// @Success 201 {object} model.DeviceToken
+// @Security BearerAuth
+// @Router /api/users/me/devices [post]
+func RegisterDeviceHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	var req model.DeviceTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	device, err := db.RegisterDeviceToken(uuid.MustParse(userID), req.Platform, req.Token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register device", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, device)
+}
+
+// @Summary Unregister Device
+// @Description Stop sending push notifications to a device, e.g. when signing out
+// @Tags push
+// @Accept json
+// @Param deviceTokenRequest body model.DeviceTokenRequest true "Device token"
+// @Success 204
+// @Security BearerAuth
+// @Router /api/users/me/devices [delete]
+func UnregisterDeviceHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	var req model.DeviceTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	err = db.UnregisterDeviceToken(uuid.MustParse(userID), req.Platform, req.Token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Device not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unregister device", "details": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get Web Push Configuration
+// @Description Get the VAPID public key browsers subscribe to push notifications with
+// @Tags push
+// @Produce json
+// @Success 200 {object} model.WebPushConfigResponse
+// @Router /api/users/push/web-config [get]
+func GetWebPushConfigHandler(c *gin.Context) {
+	var key string
+	if PushDispatcher != nil {
+		key = PushDispatcher.VAPIDPublicKey()
+	}
+	if key == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Web push is not configured"})
+		return
+	}
+	c.JSON(http.StatusOK, model.WebPushConfigResponse{VAPIDPublicKey: key})
+}
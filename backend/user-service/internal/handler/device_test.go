@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestDeviceHandlers(t *testing.T) {
+	router := setupRouter()
+	router.GET("/api/users/me/devices", GetDevicesHandler)
+	router.POST("/api/users/me/devices", RegisterDeviceHandler)
+	router.DELETE("/api/users/me/devices", UnregisterDeviceHandler)
+	router.GET("/api/users/push/web-config", GetWebPushConfigHandler)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": uuid.New().String()}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		authHeader     string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "List without authorization",
+			method:         "GET",
+			path:           "/api/users/me/devices",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "List without database",
+			method:         "GET",
+			path:           "/api/users/me/devices",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to retrieve devices",
+		},
+		{
+			name:           "Register without authorization",
+			method:         "POST",
+			path:           "/api/users/me/devices",
+			body:           `{"platform":"android","token":"abc"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Register unknown platform",
+			method:         "POST",
+			path:           "/api/users/me/devices",
+			authHeader:     "Bearer " + token,
+			body:           `{"platform":"windows","token":"abc"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Register without token",
+			method:         "POST",
+			path:           "/api/users/me/devices",
+			authHeader:     "Bearer " + token,
+			body:           `{"platform":"ios"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Register without database",
+			method:         "POST",
+			path:           "/api/users/me/devices",
+			authHeader:     "Bearer " + token,
+			body:           `{"platform":"android","token":"abc"}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to register device",
+		},
+		{
+			name:           "Unregister without body",
+			method:         "DELETE",
+			path:           "/api/users/me/devices",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Unregister without database",
+			method:         "DELETE",
+			path:           "/api/users/me/devices",
+			authHeader:     "Bearer " + token,
+			body:           `{"platform":"web","token":"{}"}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to unregister device",
+		},
+		{
+			name:           "Web push not configured",
+			method:         "GET",
+			path:           "/api/users/push/web-config",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Web push is not configured",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/digest"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// DigestBuilder gathers weekly digests. Without activity and nutrition
+// sources digests only list achievements.
+var DigestBuilder = &digest.Builder{Achievements: db.GetAchievementsBetween}
+
+// @Summary Get Weekly Digest
+// @Description Recap of the user's activity, nutrition and achievements in a week (Monday to Monday, UTC) compared with the week before. The same digest is emailed on Mondays.
+// @Tags user
+// @Produce json
+// @Param week query string false "Any day of the week as YYYY-MM-DD, defaults to last week"
+// @Success 200 {object} model.WeeklyDigest
+// @Security BearerAuth
+// @Router /api/users/me/digest [get]
+func GetWeeklyDigestHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	weekStart := digest.LastWeek(time.Now())
+	if raw := c.Query("week"); raw != "" {
+		day, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid week", "details": "week must be a YYYY-MM-DD date"})
+			return
+		}
+		weekStart = digest.WeekStart(day)
+	}
+
+	user, err := db.GetUserByID(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found", "details": err.Error()})
+		return
+	}
+
+	var weekly *model.WeeklyDigest
+	weekly, err = DigestBuilder.Build(c.Request.Context(), user, weekStart)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to build digest", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, weekly)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestGetWeeklyDigestHandler(t *testing.T) {
+	router := setupRouter()
+	router.GET("/api/users/me/digest", GetWeeklyDigestHandler)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": uuid.New().String()}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		authHeader     string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Without authorization",
+			path:           "/api/users/me/digest",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Invalid week",
+			path:           "/api/users/me/digest?week=last",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid week",
+		},
+		{
+			name:           "Without database",
+			path:           "/api/users/me/digest?week=2025-06-04",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "User not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultSuggestionCount = 10
+	maxSuggestionCount     = 50
+)
+
+// pathUserIDs returns the caller's ID and the UUID in the :id path
+// parameter, writing the error response if either is missing or invalid
+func pathUserIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return uuid.Nil, uuid.Nil, false
+	}
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return uuid.MustParse(userID), targetID, true
+}
+
+// @Summary Get Sent Friend Requests
+// @Description Get all friend requests sent by the authenticated user
+// @Tags friends
+// @Produce json
+// @Success 200 {array} model.FriendRequest
+// @Security BearerAuth
+// @Router /api/users/friends/requests/sent [get]
+func GetSentFriendRequestsHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	requests, err := db.GetSentFriendRequests(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve friend requests", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+// @Summary Cancel Friend Request
+// @Description Withdraw a pending friend request sent by the authenticated user
+// @Tags friends
+// @Produce json
+// @Param id path string true "Friend request ID"
+// @Success 200 {object} model.FriendRequest
+// @Security BearerAuth
+// @Router /api/users/friends/requests/{id} [delete]
+func CancelFriendRequestHandler(c *gin.Context) {
+	userID, requestID, ok := pathUserIDs(c)
+	if !ok {
+		return
+	}
+
+	request, err := db.CancelFriendRequest(requestID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Friend request not found"})
+		return
+	}
+	if errors.Is(err, db.ErrFriendRequestNotPending) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Friend request is not pending"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel friend request", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+// @Summary Remove Friend
+// @Description Remove a user from the authenticated user's friends, for both of them
+// @Tags friends
+// @Param id path string true "Friend's user ID"
+// @Success 204
+// @Security BearerAuth
+// @Router /api/users/friends/{id} [delete]
+func RemoveFriendHandler(c *gin.Context) {
+	userID, friendID, ok := pathUserIDs(c)
+	if !ok {
+		return
+	}
+
+	err := db.RemoveFriend(userID, friendID)
+	if errors.Is(err, db.ErrNotFriends) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Friend not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove friend", "details": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get Blocked Users
+// @Description Get the users the authenticated user has blocked
+// @Tags friends
+// @Produce json
+// @Success 200 {array} model.BlockedUserResponse
+// @Security BearerAuth
+// @Router /api/users/blocks [get]
+func GetBlockedUsersHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	blocked, err := db.GetBlockedUsers(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve blocked users", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, blocked)
+}
+
+// @Summary Block User
+// @Description Block a user. This ends any friendship, cancels pending friend requests between the two users and stops the blocked user from sending new requests or messages
+// @Tags friends
+// @Accept json
+// @Produce json
+// @Param blockUserRequest body model.BlockUserRequest true "User to block"
+// @Success 201 {object} model.Block
+// @Security BearerAuth
+// @Router /api/users/blocks [post]
+func BlockUserHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	var req model.BlockUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+	if req.UserID.String() == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot block yourself"})
+		return
+	}
+
+	block, err := db.BlockUser(uuid.MustParse(userID), req.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to block user", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, block)
+}
+
+// @Summary Unblock User
+// @Description Unblock a user. Friendships ended by the block are not restored
+// @Tags friends
+// @Param id path string true "Blocked user's ID"
+// @Success 204
+// @Security BearerAuth
+// @Router /api/users/blocks/{id} [delete]
+func UnblockUserHandler(c *gin.Context) {
+	userID, blockedID, ok := pathUserIDs(c)
+	if !ok {
+		return
+	}
+
+	err := db.UnblockUser(userID, blockedID)
+	if errors.Is(err, db.ErrNotBlocked) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not blocked"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unblock user", "details": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get Friend Suggestions
+// @Description Suggest friends of the authenticated user's friends, ranked by mutual friend count
+// @Tags friends
+// @Produce json
+// @Param limit query int false "Number of suggestions (default 10, max 50)"
+// @Success 200 {array} model.FriendSuggestion
+// @Security BearerAuth
+// @Router /api/users/friends/suggestions [get]
+func GetFriendSuggestionsHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	limit := defaultSuggestionCount
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = min(limit, maxSuggestionCount)
+	}
+
+	suggestions, err := db.GetFriendSuggestions(uuid.MustParse(userID), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve friend suggestions", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
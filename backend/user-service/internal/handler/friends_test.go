@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestFriendManagementHandlers(t *testing.T) {
+	router := setupRouter()
+	router.GET("/api/users/friends/requests/sent", GetSentFriendRequestsHandler)
+	router.DELETE("/api/users/friends/requests/:id", CancelFriendRequestHandler)
+	router.DELETE("/api/users/friends/:id", RemoveFriendHandler)
+	router.GET("/api/users/friends/suggestions", GetFriendSuggestionsHandler)
+	router.GET("/api/users/blocks", GetBlockedUsersHandler)
+	router.POST("/api/users/blocks", BlockUserHandler)
+	router.DELETE("/api/users/blocks/:id", UnblockUserHandler)
+
+	userID := uuid.New().String()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": userID}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	otherID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		authHeader     string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Sent requests without authorization",
+			method:         "GET",
+			path:           "/api/users/friends/requests/sent",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Sent requests without database",
+			method:         "GET",
+			path:           "/api/users/friends/requests/sent",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to retrieve friend requests",
+		},
+		{
+			name:           "Cancel request with invalid ID",
+			method:         "DELETE",
+			path:           "/api/users/friends/requests/not-a-uuid",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid ID",
+		},
+		{
+			name:           "Cancel request without database",
+			method:         "DELETE",
+			path:           "/api/users/friends/requests/" + otherID,
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to cancel friend request",
+		},
+		{
+			name:           "Remove friend without authorization",
+			method:         "DELETE",
+			path:           "/api/users/friends/" + otherID,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Remove friend without database",
+			method:         "DELETE",
+			path:           "/api/users/friends/" + otherID,
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to remove friend",
+		},
+		{
+			name:           "Suggestions without authorization",
+			method:         "GET",
+			path:           "/api/users/friends/suggestions",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Suggestions with invalid limit",
+			method:         "GET",
+			path:           "/api/users/friends/suggestions?limit=0",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "limit must be a positive integer",
+		},
+		{
+			name:           "Suggestions without database",
+			method:         "GET",
+			path:           "/api/users/friends/suggestions?limit=500",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to retrieve friend suggestions",
+		},
+		{
+			name:           "Block without user ID",
+			method:         "POST",
+			path:           "/api/users/blocks",
+			authHeader:     "Bearer " + token,
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Block yourself",
+			method:         "POST",
+			path:           "/api/users/blocks",
+			authHeader:     "Bearer " + token,
+			body:           `{"user_id":"` + userID + `"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "You cannot block yourself",
+		},
+		{
+			name:           "Block without database",
+			method:         "POST",
+			path:           "/api/users/blocks",
+			authHeader:     "Bearer " + token,
+			body:           `{"user_id":"` + otherID + `"}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to block user",
+		},
+		{
+			name:           "List blocks without database",
+			method:         "GET",
+			path:           "/api/users/blocks",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to retrieve blocked users",
+		},
+		{
+			name:           "Unblock with invalid ID",
+			method:         "DELETE",
+			path:           "/api/users/blocks/nope",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid ID",
+		},
+		{
+			name:           "Unblock without database",
+			method:         "DELETE",
+			path:           "/api/users/blocks/" + otherID,
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to unblock user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
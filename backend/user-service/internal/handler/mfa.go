@@ -0,0 +1,333 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/ffabious/healthy-summer/user-service/internal/totp"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TOTPIssuer is the name authenticator apps show next to the account
+const TOTPIssuer = "Healthy Summer"
+
+// verifyTOTP checks a code against the user's secret and records its time
+// step, so the same code cannot be replayed within its validity window
+func verifyTOTP(user *model.User, code string) (bool, error) {
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return db.UseTOTPStep(user.ID, step)
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code
+func verifySecondFactor(user *model.User, factor model.SecondFactor) (bool, error) {
+	switch {
+	case factor.Code != "":
+		return verifyTOTP(user, factor.Code)
+	case factor.RecoveryCode != "":
+		return db.UseRecoveryCode(user.ID, auth.HashRecoveryCode(factor.RecoveryCode))
+	default:
+		return false, nil
+	}
+}
+
+// @Summary Get MFA Status
+// @Description Get whether two-factor authentication is enabled and how many recovery codes are left
+// @Tags mfa
+// @Produce json
+// @Success 200 {object} model.MFAStatusResponse
+// @Security BearerAuth
+// @Router /api/users/mfa [get]
+func GetMFAStatusHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	user, err := db.GetUserByID(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found", "details": err.Error()})
+		return
+	}
+	resp := model.MFAStatusResponse{TOTPEnabled: user.TOTPEnabled}
+	if user.TOTPEnabled {
+		resp.RecoveryCodesRemaining, err = db.CountRecoveryCodes(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count recovery codes", "details": err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary Set Up TOTP
+// @Description Generate a new TOTP secret. It only takes effect once confirmed with a code from the authenticator app.
+// @Tags mfa
+// @Produce json
+// @Success 200 {object} model.TOTPSetupResponse
+// @Security BearerAuth
+// @Router /api/users/mfa/totp/setup [post]
+func SetupTOTPHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	user, err := db.GetUserByID(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found", "details": err.Error()})
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret", "details": err.Error()})
+		return
+	}
+	if err := db.SetPendingTOTPSecret(user.ID, secret); err != nil {
+		if errors.Is(err, db.ErrTOTPAlreadyEnabled) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save secret", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, model.TOTPSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(TOTPIssuer, user.Email, secret),
+	})
+}
+
+// @Summary Confirm TOTP
+// @Description Enable two-factor authentication with a code from the authenticator app. The response contains recovery codes, which are only shown once.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param totpConfirmRequest body model.TOTPConfirmRequest true "Code from the authenticator app"
+// @Success 200 {object} model.RecoveryCodesResponse
+// @Security BearerAuth
+// @Router /api/users/mfa/totp/confirm [post]
+func ConfirmTOTPHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	var req model.TOTPConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	user, err := db.GetUserByID(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found", "details": err.Error()})
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor setup has not been started"})
+		return
+	}
+	step, ok := totp.Validate(user.TOTPSecret, req.Code, time.Now())
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	codes, hashes, err := auth.GenerateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes", "details": err.Error()})
+		return
+	}
+	if err := db.EnableTOTP(user.ID, step, hashes); err != nil {
+		if errors.Is(err, db.ErrTOTPAlreadyEnabled) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, model.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// @Summary Disable TOTP
+// @Description Disable two-factor authentication. Requires the password and either a TOTP code or a recovery code.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param disableTOTPRequest body model.DisableTOTPRequest true "Password and second factor"
+// @Success 200 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/users/mfa/totp/disable [post]
+func DisableTOTPHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	var req model.DisableTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	user, err := db.GetUserByID(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found", "details": err.Error()})
+		return
+	}
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+	if rejectWrongPassword(c, user, req.Password, "Invalid password") {
+		return
+	}
+	ok, err := verifySecondFactor(user, req.SecondFactor)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code", "details": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	if err := db.DisableTOTP(user.ID); err != nil && !errors.Is(err, db.ErrTOTPNotEnabled) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// @Summary Regenerate Recovery Codes
+// @Description Replace all recovery codes with a new set. Requires a current TOTP code.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param totpConfirmRequest body model.TOTPConfirmRequest true "Code from the authenticator app"
+// @Success 200 {object} model.RecoveryCodesResponse
+// @Security BearerAuth
+// @Router /api/users/mfa/recovery-codes [post]
+func RegenerateRecoveryCodesHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	var req model.TOTPConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	user, err := db.GetUserByID(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found", "details": err.Error()})
+		return
+	}
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+	ok, err := verifyTOTP(user, req.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code", "details": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	codes, hashes, err := auth.GenerateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes", "details": err.Error()})
+		return
+	}
+	if err := db.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save recovery codes", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, model.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// @Summary Complete MFA Login
+// @Description Exchange the challenge token from login and a TOTP or recovery code for an access token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param mfaLoginRequest body model.MFALoginRequest true "Challenge token and second factor"
+// @Success 200 {object} model.LoginResponse
+// @Router /api/users/login/mfa [post]
+func MFALoginHandler(c *gin.Context) {
+	var req model.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	userID, version, err := auth.ParseMFAChallenge(req.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge", "details": err.Error()})
+		return
+	}
+	user, err := db.GetUserByID(userID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge", "details": err.Error()})
+		return
+	}
+	// A password change since the challenge was issued invalidates it.
+	if user.TokenVersion != version || !user.TOTPEnabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+		return
+	}
+	if rejectSuspended(c, user) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	ip := c.ClientIP()
+	if wait, err := LoginLimiter.Check(ctx, user.Email, ip); err != nil {
+		log.Printf("Failed to check login attempts: %v", err)
+	} else if wait > 0 {
+		tooManyLoginAttempts(c, wait)
+		return
+	}
+
+	ok, err := verifySecondFactor(user, req.SecondFactor)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code", "details": err.Error()})
+		return
+	}
+	if !ok {
+		recordLoginFailure(c, user.Email, ip)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+	if err := LoginLimiter.Succeed(ctx, user.Email); err != nil {
+		log.Printf("Failed to reset login attempts: %v", err)
+	}
+	sendLoginResponse(c, user)
+}
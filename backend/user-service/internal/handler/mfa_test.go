@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestMFAHandlers(t *testing.T) {
+	router := setupRouter()
+	router.GET("/api/users/mfa", GetMFAStatusHandler)
+	router.POST("/api/users/mfa/totp/setup", SetupTOTPHandler)
+	router.POST("/api/users/mfa/totp/confirm", ConfirmTOTPHandler)
+	router.POST("/api/users/mfa/totp/disable", DisableTOTPHandler)
+	router.POST("/api/users/mfa/recovery-codes", RegenerateRecoveryCodesHandler)
+	router.POST("/api/users/login/mfa", MFALoginHandler)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": uuid.New().String()}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		authHeader     string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Status without authorization",
+			method:         "GET",
+			path:           "/api/users/mfa",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Status for unknown user",
+			method:         "GET",
+			path:           "/api/users/mfa",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "User not found",
+		},
+		{
+			name:           "Setup for unknown user",
+			method:         "POST",
+			path:           "/api/users/mfa/totp/setup",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "User not found",
+		},
+		{
+			name:           "Confirm without code",
+			method:         "POST",
+			path:           "/api/users/mfa/totp/confirm",
+			authHeader:     "Bearer " + token,
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Disable without password",
+			method:         "POST",
+			path:           "/api/users/mfa/totp/disable",
+			authHeader:     "Bearer " + token,
+			body:           `{"code":"123456"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Regenerate recovery codes for unknown user",
+			method:         "POST",
+			path:           "/api/users/mfa/recovery-codes",
+			authHeader:     "Bearer " + token,
+			body:           `{"code":"123456"}`,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "User not found",
+		},
+		{
+			name:           "MFA login without challenge",
+			method:         "POST",
+			path:           "/api/users/login/mfa",
+			body:           `{"code":"123456"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "MFA login with access token as challenge",
+			method:         "POST",
+			path:           "/api/users/login/mfa",
+			body:           `{"mfa_token":"` + token + `","code":"123456"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Invalid or expired challenge",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultNotificationPageSize = 20
+	maxNotificationPageSize     = 100
+)
+
+// notify tells userID that actorID did something concerning entityID, in
+// the app and on their devices. Failures are logged rather than returned so that they never fail the
+// action that caused the notification
+func notify(userID, actorID uuid.UUID, notificationType string, entityID uuid.UUID) {
+	actor, err := db.GetUserByID(actorID)
+	if err != nil {
+		log.Printf("Failed to create %s notification for user %s: %v", notificationType, userID, err)
+		return
+	}
+	notification := model.Notification{
+		UserID:   userID,
+		Type:     notificationType,
+		ActorID:  &actorID,
+		EntityID: &entityID,
+		Message:  model.NotificationText(notificationType, actor.FirstName+" "+actor.LastName),
+	}
+	if err := db.CreateNotification(&notification); err != nil {
+		log.Printf("Failed to create %s notification for user %s: %v", notificationType, userID, err)
+		return
+	}
+	pushNotification(notification)
+}
+
+// @Summary Create Notification
+// @Description Internal endpoint used by other services to notify a user, in the app and on their devices
+// @Tags internal
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param X-Internal-Token header string true "Internal API token"
+// @Param createNotificationRequest body model.CreateNotificationRequest true "Notification"
+// @Success 201 {object} model.Notification
+// @Router /internal/users/{user_id}/notifications [post]
+func CreateNotificationHandler(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID", "details": err.Error()})
+		return
+	}
+
+	var req model.CreateNotificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	notification := model.Notification{UserID: userID, Type: req.Type, Message: req.Message}
+	if req.Type == model.NotificationReminder {
+		if req.Message == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": "message is required for reminders"})
+			return
+		}
+	} else {
+		if req.ActorID == nil || req.EntityID == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": "actor_id and entity_id are required"})
+			return
+		}
+		actor, err := db.GetUserByID(*req.ActorID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Actor not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create notification", "details": err.Error()})
+			return
+		}
+		notification.ActorID = req.ActorID
+		notification.EntityID = req.EntityID
+		notification.Message = model.NotificationText(req.Type, actor.FirstName+" "+actor.LastName)
+	}
+	if err := db.CreateNotification(&notification); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create notification", "details": err.Error()})
+		return
+	}
+	pushNotification(notification)
+	c.JSON(http.StatusCreated, notification)
+}
+
+// @Summary List Notifications
+// @Description List the current user's notifications, newest first, with the number still unread
+// @Tags notifications
+// @Produce json
+// @Param unread query bool false "Only return unread notifications"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of notifications to skip"
+// @Success 200 {object} model.NotificationListResponse
+// @Security BearerAuth
+// @Router /api/notifications [get]
+func GetNotificationsHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	limit, offset := defaultNotificationPageSize, 0
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = min(limit, maxNotificationPageSize)
+	}
+	if raw := c.Query("offset"); raw != "" {
+		offset, err = strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+			return
+		}
+	}
+	var unreadOnly bool
+	if raw := c.Query("unread"); raw != "" {
+		unreadOnly, err = strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unread must be true or false"})
+			return
+		}
+	}
+
+	notifications, total, err := db.GetNotifications(uuid.MustParse(userID), unreadOnly, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notifications", "details": err.Error()})
+		return
+	}
+	unread, err := db.CountUnreadNotifications(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notifications", "details": err.Error()})
+		return
+	}
+	if notifications == nil {
+		notifications = []model.Notification{}
+	}
+	c.JSON(http.StatusOK, model.NotificationListResponse{
+		Notifications: notifications,
+		Total:         total,
+		UnreadCount:   unread,
+		Limit:         limit,
+		Offset:        offset,
+	})
+}
+
+// @Summary Get Unread Notification Count
+// @Description Get the number of notifications the current user has not read
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} model.UnreadCountResponse
+// @Security BearerAuth
+// @Router /api/notifications/unread-count [get]
+func GetUnreadNotificationCountHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	count, err := db.CountUnreadNotifications(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count notifications", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, model.UnreadCountResponse{UnreadCount: count})
+}
+
+// @Summary Mark Notification Read
+// @Description Mark one of the current user's notifications as read
+// @Tags notifications
+// @Produce json
+// @Param id path string true "Notification ID"
+// @Success 200 {object} model.Notification
+// @Security BearerAuth
+// @Router /api/notifications/{id}/read [post]
+func MarkNotificationReadHandler(c *gin.Context) {
+	userID, notificationID, ok := pathUserIDs(c)
+	if !ok {
+		return
+	}
+
+	notification, err := db.MarkNotificationRead(notificationID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notification as read", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, notification)
+}
+
+// @Summary Mark All Notifications Read
+// @Description Mark every unread notification of the current user as read
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} model.MarkAllReadResponse
+// @Security BearerAuth
+// @Router /api/notifications/read-all [post]
+func MarkAllNotificationsReadHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	updated, err := db.MarkAllNotificationsRead(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications as read", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, model.MarkAllReadResponse{Updated: updated})
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestNotificationHandlers(t *testing.T) {
+	router := setupRouter()
+	router.GET("/api/notifications", GetNotificationsHandler)
+	router.GET("/api/notifications/unread-count", GetUnreadNotificationCountHandler)
+	router.POST("/api/notifications/read-all", MarkAllNotificationsReadHandler)
+	router.POST("/api/notifications/:id/read", MarkNotificationReadHandler)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": uuid.New().String()}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		authHeader     string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "List without authorization",
+			method:         "GET",
+			path:           "/api/notifications",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "List with invalid limit",
+			method:         "GET",
+			path:           "/api/notifications?limit=0",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "limit must be a positive integer",
+		},
+		{
+			name:           "List with invalid offset",
+			method:         "GET",
+			path:           "/api/notifications?offset=-1",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "offset must be a non-negative integer",
+		},
+		{
+			name:           "List with invalid unread filter",
+			method:         "GET",
+			path:           "/api/notifications?unread=maybe",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "unread must be true or false",
+		},
+		{
+			name:           "List without database",
+			method:         "GET",
+			path:           "/api/notifications?unread=true",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to retrieve notifications",
+		},
+		{
+			name:           "Unread count without authorization",
+			method:         "GET",
+			path:           "/api/notifications/unread-count",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Unread count without database",
+			method:         "GET",
+			path:           "/api/notifications/unread-count",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to count notifications",
+		},
+		{
+			name:           "Mark read with invalid ID",
+			method:         "POST",
+			path:           "/api/notifications/not-a-uuid/read",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid ID",
+		},
+		{
+			name:           "Mark read without database",
+			method:         "POST",
+			path:           "/api/notifications/" + uuid.New().String() + "/read",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to mark notification as read",
+		},
+		{
+			name:           "Mark all read without authorization",
+			method:         "POST",
+			path:           "/api/notifications/read-all",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Mark all read without database",
+			method:         "POST",
+			path:           "/api/notifications/read-all",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to mark notifications as read",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateNotificationHandler(t *testing.T) {
+	router := setupRouter()
+	router.POST("/internal/users/:user_id/notifications", CreateNotificationHandler)
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Invalid user ID",
+			path:           "/internal/users/not-a-uuid/notifications",
+			body:           `{"type":"reminder","message":"Drink water"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid user ID",
+		},
+		{
+			name:           "Unsupported type",
+			path:           "/internal/users/" + uuid.New().String() + "/notifications",
+			body:           `{"type":"friend_request","message":"Hi"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Missing message",
+			path:           "/internal/users/" + uuid.New().String() + "/notifications",
+			body:           `{"type":"reminder"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Message notification without actor",
+			path:           "/internal/users/" + uuid.New().String() + "/notifications",
+			body:           `{"type":"message","entity_id":"` + uuid.New().String() + `"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "actor_id and entity_id are required",
+		},
+		{
+			name:           "Message notification without database",
+			path:           "/internal/users/" + uuid.New().String() + "/notifications",
+			body:           `{"type":"message","actor_id":"` + uuid.New().String() + `","entity_id":"` + uuid.New().String() + `"}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to create notification",
+		},
+		{
+			name:           "Without database",
+			path:           "/internal/users/" + uuid.New().String() + "/notifications",
+			body:           `{"type":"reminder","message":"Drink water"}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to create notification",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
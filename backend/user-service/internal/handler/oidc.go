@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/ffabious/healthy-summer/user-service/internal/oidc"
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCProviders are the identity providers users can log in with, by name
+var OIDCProviders = map[string]oidc.Provider{}
+
+// OIDCStateTTL is how long the user has to complete a login at the provider
+const OIDCStateTTL = 10 * time.Minute
+
+func lookupProvider(c *gin.Context) (oidc.Provider, bool) {
+	provider, ok := OIDCProviders[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown identity provider"})
+	}
+	return provider, ok
+}
+
+// @Summary List Identity Providers
+// @Description List the identity providers available for login
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string][]string
+// @Router /api/users/oidc/providers [get]
+func ListOIDCProvidersHandler(c *gin.Context) {
+	names := make([]string, 0, len(OIDCProviders))
+	for name := range OIDCProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	c.JSON(http.StatusOK, gin.H{"providers": names})
+}
+
+// @Summary Start Identity Provider Login
+// @Description Start an authorization code login with PKCE. Send the user to the returned URL; the provider redirects back to the app with a code and the state.
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Success 200 {object} model.OIDCAuthorizeResponse
+// @Router /api/users/oidc/{provider}/authorize [post]
+func OIDCAuthorizeHandler(c *gin.Context) {
+	provider, ok := lookupProvider(c)
+	if !ok {
+		return
+	}
+
+	state, stateHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate state", "details": err.Error()})
+		return
+	}
+	nonce, _, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate nonce", "details": err.Error()})
+		return
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate code verifier", "details": err.Error()})
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to reach identity provider", "details": err.Error()})
+		return
+	}
+	if err := db.CreateOIDCLoginState(&model.OIDCLoginState{
+		StateHash:    stateHash,
+		Provider:     provider.Name(),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(OIDCStateTTL),
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, model.OIDCAuthorizeResponse{AuthorizationURL: authURL, State: state})
+}
+
+// @Summary Complete Identity Provider Login
+// @Description Exchange the code the provider redirected back with for a session. The identity is linked to the account with the same verified email, or a new account is created.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param oidcCallbackRequest body model.OIDCCallbackRequest true "Code and state from the redirect"
+// @Success 200 {object} model.LoginResponse
+// @Success 202 {object} model.MFAChallengeResponse "Second factor required"
+// @Router /api/users/oidc/{provider}/callback [post]
+func OIDCCallbackHandler(c *gin.Context) {
+	provider, ok := lookupProvider(c)
+	if !ok {
+		return
+	}
+
+	var req model.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	state, err := db.ConsumeOIDCLoginState(auth.HashOpaqueToken(req.State), provider.Name())
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired state"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load login state", "details": err.Error()})
+		return
+	}
+
+	identity, err := provider.Exchange(c.Request.Context(), req.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		if errors.Is(err, oidc.ErrInvalidIDToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid identity token", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to exchange code", "details": err.Error()})
+		return
+	}
+
+	user, err := db.LoginWithIdentity(model.UserIdentity{
+		Provider: provider.Name(),
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}, identity.EmailVerified, identity.GivenName, identity.FamilyName)
+	if err != nil {
+		if errors.Is(err, db.ErrUnverifiedEmail) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Identity provider did not return a verified email"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in", "details": err.Error()})
+		return
+	}
+
+	if rejectSuspended(c, user) {
+		return
+	}
+	if user.TOTPEnabled {
+		sendMFAChallenge(c, user)
+		return
+	}
+	sendLoginResponse(c, user)
+}
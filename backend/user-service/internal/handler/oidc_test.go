@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/oidc"
+	"github.com/ffabious/healthy-summer/user-service/internal/oidc/oidctest"
+)
+
+func TestOIDCHandlers(t *testing.T) {
+	iss, err := oidctest.NewIssuer("client", "secret")
+	if err != nil {
+		t.Fatalf("Failed to start issuer: %v", err)
+	}
+	defer iss.Close()
+
+	originalProviders := OIDCProviders
+	defer func() { OIDCProviders = originalProviders }()
+	OIDCProviders = map[string]oidc.Provider{
+		"mock": &oidc.Client{ProviderName: "mock", Issuer: iss.URL(), ClientID: "client", ClientSecret: "secret", RedirectURL: "http://app.example.com/callback"},
+	}
+
+	router := setupRouter()
+	router.GET("/api/users/oidc/providers", ListOIDCProvidersHandler)
+	router.POST("/api/users/oidc/:provider/authorize", OIDCAuthorizeHandler)
+	router.POST("/api/users/oidc/:provider/callback", OIDCCallbackHandler)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "List providers",
+			method:         "GET",
+			path:           "/api/users/oidc/providers",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"providers":["mock"]}`,
+		},
+		{
+			name:           "Authorize with unknown provider",
+			method:         "POST",
+			path:           "/api/users/oidc/unknown/authorize",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Unknown identity provider",
+		},
+		{
+			name:           "Authorize without database",
+			method:         "POST",
+			path:           "/api/users/oidc/mock/authorize",
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to start login",
+		},
+		{
+			name:           "Callback with unknown provider",
+			method:         "POST",
+			path:           "/api/users/oidc/unknown/callback",
+			body:           `{"code":"abc","state":"xyz"}`,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Unknown identity provider",
+		},
+		{
+			name:           "Callback without state",
+			method:         "POST",
+			path:           "/api/users/oidc/mock/callback",
+			body:           `{"code":"abc"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Callback without database",
+			method:         "POST",
+			path:           "/api/users/oidc/mock/callback",
+			body:           `{"code":"abc","state":"xyz"}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to load login state",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
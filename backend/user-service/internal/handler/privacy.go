@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Get Privacy Settings
+// @Description Get what the current user shares with friends and other users
+// @Tags user
+// @Produce json
+// @Success 200 {object} model.PrivacySettings
+// @Security BearerAuth
+// @Router /api/users/privacy [get]
+func GetPrivacySettingsHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	settings, err := db.GetPrivacySettings(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get privacy settings", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// @Summary Update Privacy Settings
+// @Description Change some or all of the current user's privacy settings
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param updatePrivacySettingsRequest body model.UpdatePrivacySettingsRequest true "Settings to change"
+// @Success 200 {object} model.PrivacySettings
+// @Security BearerAuth
+// @Router /api/users/privacy [put]
+func UpdatePrivacySettingsHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	var req model.UpdatePrivacySettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	settings, err := db.UpdatePrivacySettings(uuid.MustParse(userID), req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update privacy settings", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/activity"
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ActivityClient fetches the recent activity shown on profiles. Profiles are
+// served without it when nil
+var ActivityClient *activity.Client
+
+// @Summary Get User Profile
+// @Description Get another user's profile card. Private profiles, and users who blocked or were blocked by the caller, are not found; friends-only profiles are only visible to friends. Recent activity is included for friends the user shares activities with.
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} model.PublicProfile
+// @Security BearerAuth
+// @Router /api/users/{id} [get]
+func GetUserProfileHandler(c *gin.Context) {
+	viewerID, targetID, ok := pathUserIDs(c)
+	if !ok {
+		return
+	}
+	self := viewerID == targetID
+
+	if !self {
+		blocked, err := db.IsBlocked(viewerID, targetID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user profile", "details": err.Error()})
+			return
+		}
+		if blocked {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+	}
+
+	settings, err := db.GetPrivacySettings(targetID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user profile", "details": err.Error()})
+		return
+	}
+	if !self && settings.ProfileVisibility == model.ProfileVisibilityPrivate {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	profile, err := db.GetPublicProfile(targetID, viewerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user profile", "details": err.Error()})
+		return
+	}
+	if !self && settings.ProfileVisibility == model.ProfileVisibilityFriends && !profile.IsFriend {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This profile is only visible to friends"})
+		return
+	}
+
+	if ActivityClient != nil && (self || (profile.IsFriend && settings.ShareActivities)) {
+		summary, err := ActivityClient.Summary(c.Request.Context(), targetID)
+		if err != nil {
+			log.Printf("Failed to get activity summary for user %s: %v", targetID, err)
+		} else {
+			profile.RecentActivity = summary
+		}
+	}
+	c.JSON(http.StatusOK, profile)
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/ffabious/healthy-summer/user-service/internal/upload"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UploadStorage keeps uploaded images
+var UploadStorage upload.Storage = &upload.LocalStorage{Dir: "uploads", BaseURL: upload.DefaultLocalBaseURL}
+
+// MaxUploadSize is the largest image accepted, in bytes
+var MaxUploadSize int64 = 10 << 20
+
+// @Summary Upload Image
+// @Description Upload a JPEG, PNG or GIF image of up to 10 MB as the multipart field "file". The type is detected from the content and a thumbnail is generated. The returned ID can be set as the avatar or attached to meals and activities. Uploaded files are public to anyone with their URL.
+// @Tags uploads
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Image"
+// @Success 201 {object} model.Upload
+// @Security BearerAuth
+// @Router /api/uploads [post]
+func UploadImageHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	// Leave room for the multipart headers around the file.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxUploadSize+64<<10)
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required", "details": err.Error()})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, MaxUploadSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file", "details": err.Error()})
+		return
+	}
+	if int64(len(data)) > MaxUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+		return
+	}
+
+	img, err := upload.ProcessImage(data)
+	if errors.Is(err, upload.ErrUnsupportedType) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Only JPEG, PNG and GIF images are supported"})
+		return
+	}
+	if errors.Is(err, upload.ErrImageTooLarge) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Image dimensions are too large"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process image", "details": err.Error()})
+		return
+	}
+
+	id := uuid.New()
+	record := model.Upload{
+		ID:           id,
+		UserID:       uuid.MustParse(userID),
+		Key:          fmt.Sprintf("users/%s/%s%s", userID, id, img.Ext),
+		ThumbnailKey: fmt.Sprintf("users/%s/%s_thumb.jpg", userID, id),
+		ContentType:  img.ContentType,
+		Size:         int64(len(data)),
+		Width:        img.Width,
+		Height:       img.Height,
+		CreatedAt:    time.Now(),
+	}
+	record.URL = UploadStorage.URL(record.Key)
+	record.ThumbnailURL = UploadStorage.URL(record.ThumbnailKey)
+
+	ctx := c.Request.Context()
+	if err := UploadStorage.Put(ctx, record.Key, data, img.ContentType); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store image", "details": err.Error()})
+		return
+	}
+	if err := UploadStorage.Put(ctx, record.ThumbnailKey, img.Thumbnail, "image/jpeg"); err != nil {
+		removeStoredFiles(c, record.Key)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store image", "details": err.Error()})
+		return
+	}
+	if err := db.CreateUpload(&record); err != nil {
+		removeStoredFiles(c, record.Key, record.ThumbnailKey)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save upload", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, record)
+}
+
+func removeStoredFiles(c *gin.Context, keys ...string) {
+	for _, key := range keys {
+		if err := UploadStorage.Delete(c.Request.Context(), key); err != nil {
+			log.Printf("Failed to remove uploaded file %s: %v", key, err)
+		}
+	}
+}
+
+// @Summary Serve Uploaded File
+// @Description Serve an uploaded image or thumbnail kept in local storage
+// @Tags uploads
+// @Produce image/jpeg,image/png,image/gif
+// @Param key path string true "File key"
+// @Success 200 {file} binary
+// @Router /api/uploads/files/{key} [get]
+func ServeUploadHandler(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	file, err := UploadStorage.Open(c.Request.Context(), key)
+	if errors.Is(err, upload.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file", "details": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	// Keys are never reused, so files can be cached forever.
+	c.DataFromReader(http.StatusOK, -1, contentType, file, map[string]string{
+		"Cache-Control":          "public, max-age=31536000, immutable",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// @Summary Set Avatar
+// @Description Use one of the current user's uploads as their avatar
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param setAvatarRequest body model.SetAvatarRequest true "Uploaded image"
+// @Success 200 {object} model.User
+// @Security BearerAuth
+// @Router /api/users/me/avatar [put]
+func SetAvatarHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	var req model.SetAvatarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	image, err := db.GetUpload(req.ImageID, uuid.MustParse(userID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set avatar", "details": err.Error()})
+		return
+	}
+
+	user, err := db.SetUserAvatar(uuid.MustParse(userID), image.ThumbnailURL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set avatar", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary Remove Avatar
+// @Description Remove the current user's avatar
+// @Tags users
+// @Produce json
+// @Success 200 {object} model.User
+// @Security BearerAuth
+// @Router /api/users/me/avatar [delete]
+func DeleteAvatarHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	user, err := db.SetUserAvatar(uuid.MustParse(userID), "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove avatar", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/upload"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func multipartFile(t *testing.T, data []byte) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", "photo.png")
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(data)
+	w.Close()
+	return &body, w.FormDataContentType()
+}
+
+func TestUploadImageHandler(t *testing.T) {
+	originalStorage, originalMax := UploadStorage, MaxUploadSize
+	defer func() { UploadStorage, MaxUploadSize = originalStorage, originalMax }()
+	dir := t.TempDir()
+	UploadStorage = &upload.LocalStorage{Dir: dir, BaseURL: upload.DefaultLocalBaseURL}
+
+	router := setupRouter()
+	router.POST("/api/uploads", UploadImageHandler)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": uuid.New().String()}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	var pngData bytes.Buffer
+	if err := png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		file           []byte
+		maxSize        int64
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Without authorization",
+			file:           pngData.Bytes(),
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Without file",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "File is required",
+		},
+		{
+			name:           "Not an image",
+			authHeader:     "Bearer " + token,
+			file:           []byte("<html><script>alert(1)</script></html>"),
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedBody:   "Only JPEG, PNG and GIF images are supported",
+		},
+		{
+			name:           "Too large",
+			authHeader:     "Bearer " + token,
+			file:           pngData.Bytes(),
+			maxSize:        16,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   "File is too large",
+		},
+		{
+			name:           "Image without database",
+			authHeader:     "Bearer " + token,
+			file:           pngData.Bytes(),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to save upload",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			MaxUploadSize = originalMax
+			if tt.maxSize > 0 {
+				MaxUploadSize = tt.maxSize
+			}
+			var req *http.Request
+			if tt.file != nil {
+				body, contentType := multipartFile(t, tt.file)
+				req = httptest.NewRequest("POST", "/api/uploads", body)
+				req.Header.Set("Content-Type", contentType)
+			} else {
+				req = httptest.NewRequest("POST", "/api/uploads", strings.NewReader(""))
+			}
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+
+	// The files of the upload that could not be saved are removed again.
+	var files []string
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if len(files) != 0 {
+		t.Errorf("Expected stored files to be cleaned up, found %v", files)
+	}
+}
+
+func TestServeUploadHandler(t *testing.T) {
+	originalStorage := UploadStorage
+	defer func() { UploadStorage = originalStorage }()
+	storage := &upload.LocalStorage{Dir: t.TempDir(), BaseURL: upload.DefaultLocalBaseURL}
+	UploadStorage = storage
+	if err := storage.Put(context.Background(), "users/1/a.png", []byte("png data"), "image/png"); err != nil {
+		t.Fatalf("Failed to store file: %v", err)
+	}
+
+	router := setupRouter()
+	router.GET("/api/uploads/files/*key", ServeUploadHandler)
+
+	req := httptest.NewRequest("GET", "/api/uploads/files/users/1/a.png", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "png data" {
+		t.Errorf("Expected stored file, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Expected image/png, got %s", ct)
+	}
+
+	for _, path := range []string{"/api/uploads/files/users/1/missing.png", "/api/uploads/files/../../etc/passwd"} {
+		req = httptest.NewRequest("GET", path, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound && w.Code != http.StatusMovedPermanently {
+			t.Errorf("Expected %s to be unavailable, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestAvatarHandlers(t *testing.T) {
+	router := setupRouter()
+	router.PUT("/api/users/me/avatar", SetAvatarHandler)
+	router.DELETE("/api/users/me/avatar", DeleteAvatarHandler)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": uuid.New().String()}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		authHeader     string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Set without authorization",
+			method:         "PUT",
+			body:           `{"image_id":"` + uuid.New().String() + `"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Set without image",
+			method:         "PUT",
+			authHeader:     "Bearer " + token,
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Set without database",
+			method:         "PUT",
+			authHeader:     "Bearer " + token,
+			body:           `{"image_id":"` + uuid.New().String() + `"}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to set avatar",
+		},
+		{
+			name:           "Remove without database",
+			method:         "DELETE",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to remove avatar",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/users/me/avatar", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
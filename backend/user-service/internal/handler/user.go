@@ -1,29 +1,81 @@
 package handler
 
 import (
+	"errors"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/ffabious/healthy-summer/user-service/internal/auth"
 	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/lockout"
 	"github.com/ffabious/healthy-summer/user-service/internal/model"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+// LoginLimiter throttles failed logins per account and per client IP
+var LoginLimiter = lockout.NewLimiter(lockout.NewMemoryStore())
+
+func tooManyLoginAttempts(c *gin.Context, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts", "retry_after": seconds})
+}
+
+func recordLoginFailure(c *gin.Context, email, ip string) {
+	if _, err := LoginLimiter.Fail(c.Request.Context(), email, ip); err != nil {
+		log.Printf("Failed to record login attempt: %v", err)
+	}
+}
+
+// sendLoginResponse issues an access token for user
+func sendLoginResponse(c *gin.Context, user *model.User) {
+	token, err := auth.GenerateJWT(user.ID, user.TokenVersion, user.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, model.LoginResponse{
+		User:      *user,
+		Token:     token,
+		TokenType: "Bearer",
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+	})
+}
+
+// sendMFAChallenge answers a login whose first factor was accepted with a
+// challenge token to redeem at /api/users/login/mfa
+func sendMFAChallenge(c *gin.Context, user *model.User) {
+	challenge, err := auth.GenerateMFAChallenge(user.ID, user.TokenVersion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, model.MFAChallengeResponse{
+		MFARequired: true,
+		MFAToken:    challenge,
+		ExpiresAt:   time.Now().Add(auth.MFAChallengeTTL),
+	})
+}
+
 func HashPassword(password string) (string, error) {
 	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(hashed), err
 }
 
 // @Summary User Login
-// @Description Login a user and return a JWT token
+// @Description Login a user and return a JWT token. Users with two-factor authentication get a challenge token to complete at /api/users/login/mfa instead.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param loginRequest body model.LoginRequest true "Login Request"
 // @Success 200 {object} model.LoginResponse
+// @Success 202 {object} model.MFAChallengeResponse "Password accepted, second factor required"
 // @Router /api/users/login [post]
 func LoginHandler(c *gin.Context) {
 	var req model.LoginRequest
@@ -32,27 +84,41 @@ func LoginHandler(c *gin.Context) {
 		return
 	}
 
+	ctx := c.Request.Context()
+	ip := c.ClientIP()
+	if wait, err := LoginLimiter.Check(ctx, req.Email, ip); err != nil {
+		log.Printf("Failed to check login attempts: %v", err)
+	} else if wait > 0 {
+		tooManyLoginAttempts(c, wait)
+		return
+	}
+
 	user, err := db.GetUserByEmail(req.Email)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			recordLoginFailure(c, req.Email, ip)
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password", "details": err.Error()})
 		return
 	}
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		recordLoginFailure(c, req.Email, ip)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password", "details": err.Error()})
 		return
 	}
-	token, err := auth.GenerateJWT(user.ID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token", "details": err.Error()})
+	if rejectSuspended(c, user) {
 		return
 	}
-	resp := model.LoginResponse{
-		User:      *user,
-		Token:     token,
-		TokenType: "Bearer",
-		ExpiresAt: time.Now().Add(24 * time.Hour),
+	if user.TOTPEnabled {
+		// The account's failure counter is only reset once the second factor
+		// is accepted too.
+		sendMFAChallenge(c, user)
+		return
 	}
-	c.JSON(http.StatusOK, resp)
+	if err := LoginLimiter.Succeed(ctx, req.Email); err != nil {
+		log.Printf("Failed to reset login attempts: %v", err)
+	}
+	sendLoginResponse(c, user)
 }
 
 // @Summary User Registration
@@ -85,7 +151,10 @@ func RegisterHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user", "details": err.Error()})
 		return
 	}
-	token, err := auth.GenerateJWT(user.ID)
+	if err := sendVerificationEmail(c.Request.Context(), user); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+	}
+	token, err := auth.GenerateJWT(user.ID, user.TokenVersion, user.Role)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token", "details": err.Error()})
 		return
@@ -218,10 +287,15 @@ func SendFriendRequestHandler(c *gin.Context) {
 	}
 
 	friendRequest, err := db.SendFriendRequest(uuid.MustParse(userID), req.ReceiverID)
+	if errors.Is(err, db.ErrBlocked) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot send a friend request to this user"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send friend request", "details": err.Error()})
 		return
 	}
+	notify(friendRequest.ReceiverID, friendRequest.SenderID, model.NotificationFriendRequest, friendRequest.ID)
 	c.JSON(http.StatusCreated, friendRequest)
 }
 
@@ -322,11 +396,14 @@ func RespondToFriendRequestHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to respond to friend request", "details": err.Error()})
 		return
 	}
+	if request.Status == model.FriendRequestAccepted {
+		notify(request.SenderID, request.ReceiverID, model.NotificationFriendRequestAccepted, request.ID)
+	}
 	c.JSON(http.StatusOK, request)
 }
 
 // @Summary Search Users
-// @Description Search for users by email or name
+// @Description Search for users by email or name, honouring their privacy settings
 // @Tags users
 // @Produce json
 // @Param q query string true "Search query"
@@ -346,22 +423,11 @@ func SearchUsersHandler(c *gin.Context) {
 		return
 	}
 
-	users, err := db.SearchUsers(query, uuid.MustParse(userID))
+	response, err := db.SearchUsers(query, uuid.MustParse(userID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search users", "details": err.Error()})
 		return
 	}
 
-	// Convert to response format
-	var response []model.SearchUsersResponse
-	for _, user := range users {
-		response = append(response, model.SearchUsersResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-		})
-	}
-
 	c.JSON(http.StatusOK, response)
 }
@@ -2,11 +2,14 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/ffabious/healthy-summer/user-service/internal/lockout"
 	"github.com/ffabious/healthy-summer/user-service/internal/model"
 	"github.com/gin-gonic/gin"
 )
@@ -490,3 +493,30 @@ func TestHashPassword(t *testing.T) {
 		})
 	}
 }
+
+func TestLoginHandlerLockout(t *testing.T) {
+	router := setupRouter()
+	router.POST("/login", LoginHandler)
+
+	originalLimiter := LoginLimiter
+	defer func() { LoginLimiter = originalLimiter }()
+	LoginLimiter = lockout.NewLimiter(lockout.NewMemoryStore())
+	LoginLimiter.Account = lockout.Policy{FreeAttempts: 1, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+
+	ctx := context.Background()
+	LoginLimiter.Fail(ctx, "locked@example.com", "192.0.2.1")
+	LoginLimiter.Fail(ctx, "locked@example.com", "192.0.2.1")
+
+	body, _ := json.Marshal(model.LoginRequest{Email: "locked@example.com", Password: "password123"})
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusTooManyRequests, w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Expected Retry-After 60, got %q", got)
+	}
+}
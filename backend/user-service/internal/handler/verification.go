@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/db"
+	"github.com/ffabious/healthy-summer/user-service/internal/mail"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// EmailVerificationTTL is how long an email verification link stays valid
+	EmailVerificationTTL = 48 * time.Hour
+	// PasswordResetTTL is how long a password reset link stays valid
+	PasswordResetTTL = time.Hour
+)
+
+// Mailer delivers verification and password reset emails
+var Mailer mail.Sender = mail.LogSender{}
+
+// appLink builds a link into the app for an emailed token. APP_BASE_URL is
+// the public URL of the frontend.
+func appLink(path, token string) string {
+	return strings.TrimRight(os.Getenv("APP_BASE_URL"), "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+func sendVerificationEmail(ctx context.Context, user *model.User) error {
+	token, hash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	if err := db.CreateUserToken(&model.UserToken{
+		UserID:    user.ID,
+		Purpose:   model.TokenPurposeEmailVerification,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(EmailVerificationTTL),
+	}); err != nil {
+		return err
+	}
+	return Mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your Healthy Summer email",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm your email address by opening the link below:\n\n%s\n\nThe link expires in %d hours.\n",
+			user.FirstName, appLink("/verify-email", token), int(EmailVerificationTTL.Hours())),
+	})
+}
+
+func sendPasswordResetEmail(ctx context.Context, user *model.User) error {
+	token, hash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	if err := db.CreateUserToken(&model.UserToken{
+		UserID:    user.ID,
+		Purpose:   model.TokenPurposePasswordReset,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(PasswordResetTTL),
+	}); err != nil {
+		return err
+	}
+	return Mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your Healthy Summer password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your account. Choose a new password here:\n\n%s\n\nThe link expires in %d minutes. If you did not ask for this, you can ignore this email.\n",
+			user.FirstName, appLink("/reset-password", token), int(PasswordResetTTL.Minutes())),
+	})
+}
+
+// @Summary Verify Email
+// @Description Confirm the user's email address with the token from the verification email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param verifyEmailRequest body model.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} model.User
+// @Router /api/users/verify-email [post]
+func VerifyEmailHandler(c *gin.Context) {
+	var req model.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	user, err := db.VerifyEmailWithToken(auth.HashOpaqueToken(req.Token))
+	if errors.Is(err, db.ErrInvalidToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary Resend Verification Email
+// @Description Send a new verification email to the current user. Earlier links stop working.
+// @Tags auth
+// @Produce json
+// @Success 202 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/users/verify-email/resend [post]
+func ResendVerificationHandler(c *gin.Context) {
+	userID, err := auth.ExtractUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "details": err.Error()})
+		return
+	}
+
+	user, err := db.GetUserByID(uuid.MustParse(userID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found", "details": err.Error()})
+		return
+	}
+	if user.EmailVerified {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email is already verified"})
+		return
+	}
+	if err := sendVerificationEmail(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "Verification email sent"})
+}
+
+// @Summary Forgot Password
+// @Description Email a password reset link. The response is the same whether or not the email is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param forgotPasswordRequest body model.ForgotPasswordRequest true "Account email"
+// @Success 202 {object} map[string]string
+// @Router /api/users/password/forgot [post]
+func ForgotPasswordHandler(c *gin.Context) {
+	var req model.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	// Failures are only logged so the response does not reveal which
+	// emails have accounts.
+	if user, err := db.GetUserByEmail(req.Email); err == nil {
+		if err := sendPasswordResetEmail(c.Request.Context(), user); err != nil {
+			log.Printf("Failed to send password reset email to user %s: %v", user.ID, err)
+		}
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the email is registered, a reset link has been sent"})
+}
+
+// @Summary Reset Password
+// @Description Set a new password with the token from the password reset email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param resetPasswordRequest body model.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} map[string]string
+// @Router /api/users/password/reset [post]
+func ResetPasswordHandler(c *gin.Context) {
+	var req model.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	hashedPassword, err := HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password", "details": err.Error()})
+		return
+	}
+	if _, err := db.ResetPasswordWithToken(auth.HashOpaqueToken(req.Token), hashedPassword); err != nil {
+		if errors.Is(err, db.ErrInvalidToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/mail"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestVerificationHandlers(t *testing.T) {
+	router := setupRouter()
+	router.POST("/api/users/verify-email", VerifyEmailHandler)
+	router.POST("/api/users/verify-email/resend", ResendVerificationHandler)
+	router.POST("/api/users/password/forgot", ForgotPasswordHandler)
+	router.POST("/api/users/password/reset", ResetPasswordHandler)
+
+	fake := &mail.FakeSender{}
+	originalMailer := Mailer
+	Mailer = fake
+	defer func() { Mailer = originalMailer }()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": uuid.New().String()}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		authHeader     string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Verify without token",
+			path:           "/api/users/verify-email",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Verify without database",
+			path:           "/api/users/verify-email",
+			body:           `{"token":"abc"}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to verify email",
+		},
+		{
+			name:           "Resend without authorization",
+			path:           "/api/users/verify-email/resend",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized",
+		},
+		{
+			name:           "Resend for unknown user",
+			path:           "/api/users/verify-email/resend",
+			authHeader:     "Bearer " + token,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "User not found",
+		},
+		{
+			name:           "Forgot password with invalid email",
+			path:           "/api/users/password/forgot",
+			body:           `{"email":"not-an-email"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Forgot password for unknown email",
+			path:           "/api/users/password/forgot",
+			body:           `{"email":"nobody@example.com"}`,
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   "If the email is registered",
+		},
+		{
+			name:           "Reset with short password",
+			path:           "/api/users/password/reset",
+			body:           `{"token":"abc","new_password":"short"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request data",
+		},
+		{
+			name:           "Reset without database",
+			path:           "/api/users/password/reset",
+			body:           `{"token":"abc","new_password":"long enough"}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to reset password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got: %s", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+
+	if got := len(fake.Messages()); got != 0 {
+		t.Errorf("Expected no emails to be sent, got %d", got)
+	}
+}
+
+func TestAppLink(t *testing.T) {
+	t.Setenv("APP_BASE_URL", "https://app.example.com/")
+	if got := appLink("/reset-password", "a+b/c"); got != "https://app.example.com/reset-password?token=a%2Bb%2Fc" {
+		t.Errorf("Unexpected link %q", got)
+	}
+}
@@ -0,0 +1,144 @@
+// Package lockout throttles login attempts. Failed attempts are counted per
+// account and per client IP; once a key runs out of free attempts every
+// further failure locks it for an exponentially growing period.
+package lockout
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+)
+
+// Policy controls how failures translate into lockouts.
+type Policy struct {
+	// FreeAttempts is the number of failures allowed before locking.
+	FreeAttempts int
+	// BaseDelay is the lock applied on the first failure past FreeAttempts;
+	// it doubles with every further failure.
+	BaseDelay time.Duration
+	// MaxDelay caps the lock duration.
+	MaxDelay time.Duration
+	// Window is how long failures are remembered after the last one.
+	Window time.Duration
+}
+
+var (
+	// DefaultAccountPolicy allows five wrong passwords per account before
+	// locking it for 30 seconds, doubling up to 15 minutes.
+	DefaultAccountPolicy = Policy{FreeAttempts: 5, BaseDelay: 30 * time.Second, MaxDelay: 15 * time.Minute, Window: time.Hour}
+	// DefaultIPPolicy is looser so that users behind a shared address are
+	// not locked out by each other's typos.
+	DefaultIPPolicy = Policy{FreeAttempts: 20, BaseDelay: 10 * time.Second, MaxDelay: 15 * time.Minute, Window: time.Hour}
+)
+
+// LockDuration returns how long a key is locked after its nth failure.
+func (p Policy) LockDuration(failures int) time.Duration {
+	over := failures - p.FreeAttempts
+	if over <= 0 {
+		return 0
+	}
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(over-1))
+	if delay > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+// State is the failure history of one key.
+type State struct {
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time
+}
+
+// RetryAfter returns how long the key stays locked at now.
+func (s State) RetryAfter(now time.Time) time.Duration {
+	if now.Before(s.LockedUntil) {
+		return s.LockedUntil.Sub(now)
+	}
+	return 0
+}
+
+// next returns the state after a failure at now. Failures older than the
+// policy window are forgotten first.
+func next(s State, now time.Time, p Policy) State {
+	if !s.LastFailure.IsZero() && now.Sub(s.LastFailure) > p.Window {
+		s = State{}
+	}
+	s.Failures++
+	s.LastFailure = now
+	if d := p.LockDuration(s.Failures); d > 0 {
+		s.LockedUntil = now.Add(d)
+	}
+	return s
+}
+
+// Store keeps failure state. Implementations must apply RecordFailure
+// atomically so that concurrent attempts are all counted.
+type Store interface {
+	Get(ctx context.Context, key string) (State, error)
+	RecordFailure(ctx context.Context, key string, now time.Time, p Policy) (State, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// Limiter applies the account and IP policies to login attempts.
+type Limiter struct {
+	Store   Store
+	Account Policy
+	IP      Policy
+	Now     func() time.Time
+}
+
+// NewLimiter returns a Limiter with the default policies.
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{
+		Store:   store,
+		Account: DefaultAccountPolicy,
+		IP:      DefaultIPPolicy,
+		Now:     time.Now,
+	}
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check returns how long the caller must wait before trying again, or zero
+// if the attempt may proceed.
+func (l *Limiter) Check(ctx context.Context, email, ip string) (time.Duration, error) {
+	now := l.Now()
+	var wait time.Duration
+	for _, key := range []string{accountKey(email), ipKey(ip)} {
+		state, err := l.Store.Get(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		wait = max(wait, state.RetryAfter(now))
+	}
+	return wait, nil
+}
+
+// Fail records a failed attempt and returns the resulting wait.
+func (l *Limiter) Fail(ctx context.Context, email, ip string) (time.Duration, error) {
+	now := l.Now()
+	account, err := l.Store.RecordFailure(ctx, accountKey(email), now, l.Account)
+	if err != nil {
+		return 0, err
+	}
+	address, err := l.Store.RecordFailure(ctx, ipKey(ip), now, l.IP)
+	if err != nil {
+		return 0, err
+	}
+	return max(account.RetryAfter(now), address.RetryAfter(now)), nil
+}
+
+// Succeed clears the account's failures. The IP counter is left alone so a
+// client cannot reset it by logging in to an account it controls.
+func (l *Limiter) Succeed(ctx context.Context, email string) error {
+	return l.Store.Reset(ctx, accountKey(email))
+}
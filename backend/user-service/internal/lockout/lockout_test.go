@@ -0,0 +1,101 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLockDuration(t *testing.T) {
+	p := Policy{FreeAttempts: 3, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{failures: 0, expected: 0},
+		{failures: 3, expected: 0},
+		{failures: 4, expected: time.Second},
+		{failures: 5, expected: 2 * time.Second},
+		{failures: 7, expected: 8 * time.Second},
+		{failures: 8, expected: 10 * time.Second},
+		{failures: 100, expected: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := p.LockDuration(tt.failures); got != tt.expected {
+			t.Errorf("LockDuration(%d): expected %v, got %v", tt.failures, tt.expected, got)
+		}
+	}
+}
+
+func TestNextForgetsOldFailures(t *testing.T) {
+	p := Policy{FreeAttempts: 1, BaseDelay: time.Second, MaxDelay: time.Minute, Window: time.Hour}
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	s := next(State{}, start, p)
+	s = next(s, start.Add(time.Minute), p)
+	if s.Failures != 2 || s.RetryAfter(start.Add(time.Minute)) != time.Second {
+		t.Fatalf("Expected second failure to lock for 1s, got %+v", s)
+	}
+
+	s = next(s, start.Add(2*time.Hour), p)
+	if s.Failures != 1 || s.RetryAfter(start.Add(2*time.Hour)) != 0 {
+		t.Errorf("Expected failures outside the window to be forgotten, got %+v", s)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	l := &Limiter{
+		Store:   NewMemoryStore(),
+		Account: Policy{FreeAttempts: 2, BaseDelay: 30 * time.Second, MaxDelay: time.Hour, Window: time.Hour},
+		IP:      Policy{FreeAttempts: 4, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour},
+		Now:     func() time.Time { return now },
+	}
+
+	for i := 0; i < 2; i++ {
+		if wait, _ := l.Fail(ctx, "User@Example.com", "10.0.0.1"); wait != 0 {
+			t.Fatalf("Expected free attempt %d, got wait %v", i+1, wait)
+		}
+	}
+	wait, _ := l.Fail(ctx, "user@example.com ", "10.0.0.1")
+	if wait != 30*time.Second {
+		t.Fatalf("Expected 30s account lock, got %v", wait)
+	}
+	if wait, _ := l.Check(ctx, "USER@example.com", "10.0.0.2"); wait != 30*time.Second {
+		t.Errorf("Expected account lock to apply from any IP, got %v", wait)
+	}
+	if wait, _ := l.Check(ctx, "other@example.com", "10.0.0.1"); wait != 0 {
+		t.Errorf("Expected other account on the same IP to be allowed, got %v", wait)
+	}
+
+	now = now.Add(31 * time.Second)
+	if wait, _ := l.Check(ctx, "user@example.com", "10.0.0.1"); wait != 0 {
+		t.Errorf("Expected lock to expire, got %v", wait)
+	}
+
+	// The fourth and fifth failures from the address exhaust the IP policy
+	// even though they target a fresh account.
+	l.Fail(ctx, "other@example.com", "10.0.0.1")
+	wait, _ = l.Fail(ctx, "other@example.com", "10.0.0.1")
+	if wait != time.Minute {
+		t.Errorf("Expected 1m IP lock, got %v", wait)
+	}
+	if wait, _ := l.Check(ctx, "third@example.com", "10.0.0.1"); wait != time.Minute {
+		t.Errorf("Expected IP lock to apply to every account, got %v", wait)
+	}
+
+	if err := l.Succeed(ctx, "user@example.com"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	state, _ := l.Store.Get(ctx, accountKey("user@example.com"))
+	if state.Failures != 0 {
+		t.Errorf("Expected success to clear account failures, got %d", state.Failures)
+	}
+	state, _ = l.Store.Get(ctx, ipKey("10.0.0.1"))
+	if state.Failures == 0 {
+		t.Error("Expected success to keep IP failures")
+	}
+}
@@ -0,0 +1,52 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps state in process. It is suitable for a single replica.
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]State
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: make(map[string]State)}
+}
+
+func (m *MemoryStore) Get(_ context.Context, key string) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.states[key], nil
+}
+
+func (m *MemoryStore) RecordFailure(_ context.Context, key string, now time.Time, p Policy) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state := next(m.states[key], now, p)
+	m.states[key] = state
+	m.prune(now, p.Window)
+	return state, nil
+}
+
+func (m *MemoryStore) Reset(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.states, key)
+	return nil
+}
+
+// prune drops keys that are neither locked nor failed within window, so
+// attempts from many addresses do not grow the map without bound.
+func (m *MemoryStore) prune(now time.Time, window time.Duration) {
+	if len(m.states) < 10000 {
+		return
+	}
+	for key, s := range m.states {
+		if now.After(s.LockedUntil) && now.Sub(s.LastFailure) > window {
+			delete(m.states, key)
+		}
+	}
+}
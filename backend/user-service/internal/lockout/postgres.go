@@ -0,0 +1,91 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresStore keeps state in the login_attempts table so that every
+// replica sees the same counters.
+type PostgresStore struct {
+	DB *gorm.DB
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{DB: db}
+}
+
+func toState(a model.LoginAttempt) State {
+	return State{Failures: a.Failures, LastFailure: a.LastFailureAt, LockedUntil: a.LockedUntil}
+}
+
+func (s *PostgresStore) Get(ctx context.Context, key string) (State, error) {
+	var attempt model.LoginAttempt
+	err := s.DB.WithContext(ctx).First(&attempt, "key = ?", key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+	return toState(attempt), nil
+}
+
+// RecordFailure locks the key's row for the read-modify-write, creating it
+// first if needed, so concurrent failures on different replicas are all
+// counted.
+func (s *PostgresStore) RecordFailure(ctx context.Context, key string, now time.Time, p Policy) (State, error) {
+	var state State
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.LoginAttempt{Key: key}).Error; err != nil {
+			return err
+		}
+		var attempt model.LoginAttempt
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&attempt, "key = ?", key).Error; err != nil {
+			return err
+		}
+		state = next(toState(attempt), now, p)
+		return tx.Model(&attempt).Updates(map[string]any{
+			"failures":        state.Failures,
+			"last_failure_at": state.LastFailure,
+			"locked_until":    state.LockedUntil,
+		}).Error
+	})
+	return state, err
+}
+
+func (s *PostgresStore) Reset(ctx context.Context, key string) error {
+	return s.DB.WithContext(ctx).Where("key = ?", key).Delete(&model.LoginAttempt{}).Error
+}
+
+// Prune deletes rows that are unlocked and whose last failure is older than
+// window.
+func (s *PostgresStore) Prune(ctx context.Context, now time.Time, window time.Duration) error {
+	return s.DB.WithContext(ctx).
+		Where("locked_until < ? AND last_failure_at < ?", now, now.Add(-window)).
+		Delete(&model.LoginAttempt{}).Error
+}
+
+// PruneEvery runs Prune every interval until ctx is cancelled.
+func (s *PostgresStore) PruneEvery(ctx context.Context, interval, window time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.Prune(ctx, now, window); err != nil {
+				log.Printf("Failed to prune login attempts: %v", err)
+			}
+		}
+	}
+}
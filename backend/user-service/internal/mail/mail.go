@@ -0,0 +1,192 @@
+// Package mail sends transactional email such as verification and password
+// reset links.
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message is an email with a plain-text body and, optionally, an HTML
+// alternative.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	// HTML, when set, is sent alongside Body as multipart/alternative so
+	// clients that can render it show it instead.
+	HTML string
+}
+
+// Sender delivers messages.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewSenderFromEnv returns an SMTPSender when SMTP_HOST is set. Otherwise
+// messages are only written to the log, which is enough for local
+// development.
+func NewSenderFromEnv() Sender {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		log.Println("SMTP_HOST not set, emails will be logged instead of sent")
+		return LogSender{}
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	return &SMTPSender{
+		Addr:     net.JoinHostPort(host, port),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("MAIL_FROM"),
+	}
+}
+
+// SMTPSender sends messages through an SMTP relay. The connection is
+// upgraded with STARTTLS when the server supports it.
+type SMTPSender struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	data, err := s.compose(msg, time.Now())
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, _ := net.SplitHostPort(s.Addr)
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.Addr, auth, s.From, []string{msg.To}, data)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send mail to %s: %w", msg.To, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// compose renders the RFC 5322 message. Header values containing line
+// breaks are rejected to prevent header injection.
+func (s *SMTPSender) compose(msg Message, date time.Time) ([]byte, error) {
+	for _, v := range []string{s.From, msg.To, msg.Subject} {
+		if strings.ContainsAny(v, "\r\n") {
+			return nil, fmt.Errorf("invalid header value %q", v)
+		}
+	}
+	if msg.To == "" {
+		return nil, fmt.Errorf("recipient cannot be empty")
+	}
+
+	var b strings.Builder
+	b.WriteString("From: " + s.From + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	if msg.HTML == "" {
+		b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		b.WriteString("\r\n")
+		b.WriteString(crlf(msg.Body))
+		return []byte(b.String()), nil
+	}
+
+	parts := multipart.NewWriter(&b)
+	b.WriteString("Content-Type: multipart/alternative; boundary=" + parts.Boundary() + "\r\n")
+	b.WriteString("\r\n")
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Body},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(crlf(part.body))); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return []byte(b.String()), nil
+}
+
+// crlf converts line endings to the CRLF required by SMTP.
+func crlf(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+}
+
+// LogSender writes messages to the standard logger instead of sending them.
+type LogSender struct{}
+
+func (LogSender) Send(_ context.Context, msg Message) error {
+	log.Printf("mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FakeSender records messages in memory. It is meant for tests.
+type FakeSender struct {
+	mu       sync.Mutex
+	messages []Message
+	// Err, when set, is returned from Send and the message is not recorded.
+	Err error
+}
+
+func (f *FakeSender) Send(_ context.Context, msg Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	f.messages = append(f.messages, msg)
+	return nil
+}
+
+// Messages returns a copy of the messages sent so far.
+func (f *FakeSender) Messages() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Message(nil), f.messages...)
+}
+
+// Last returns the most recent message, if any.
+func (f *FakeSender) Last() (Message, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.messages) == 0 {
+		return Message{}, false
+	}
+	return f.messages[len(f.messages)-1], true
+}
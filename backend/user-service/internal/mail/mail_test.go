@@ -0,0 +1,138 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	netmail "net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompose(t *testing.T) {
+	s := &SMTPSender{From: "Healthy Summer <no-reply@example.com>"}
+	date := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	data, err := s.compose(Message{To: "user@example.com", Subject: "Verify your email", Body: "line 1\nline 2"}, date)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	msg := string(data)
+	for _, expected := range []string{
+		"From: Healthy Summer <no-reply@example.com>\r\n",
+		"To: user@example.com\r\n",
+		"Subject: Verify your email\r\n",
+		"Date: Sun, 01 Jun 2025 12:00:00 +0000\r\n",
+		"Content-Type: text/plain; charset=utf-8\r\n",
+		"\r\n\r\nline 1\r\nline 2",
+	} {
+		if !strings.Contains(msg, expected) {
+			t.Errorf("Expected message to contain %q, got:\n%s", expected, msg)
+		}
+	}
+
+	tests := []struct {
+		name string
+		msg  Message
+	}{
+		{name: "Header injection in subject", msg: Message{To: "user@example.com", Subject: "Hi\r\nBcc: evil@example.com"}},
+		{name: "Header injection in recipient", msg: Message{To: "user@example.com\nBcc: evil@example.com"}},
+		{name: "Missing recipient", msg: Message{Subject: "Hi"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.compose(tt.msg, date); err == nil {
+				t.Error("Expected error, got none")
+			}
+		})
+	}
+}
+
+func TestComposeWithHTML(t *testing.T) {
+	s := &SMTPSender{From: "no-reply@example.com"}
+	data, err := s.compose(Message{
+		To:      "user@example.com",
+		Subject: "Your week",
+		Body:    "You walked 42000 steps.\nKeep going!",
+		HTML:    `<p style="color:#333">You walked <b>42000</b> steps.</p>`,
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	msg, err := netmail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to parse message: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Expected multipart/alternative, got %q (%v)", msg.Header.Get("Content-Type"), err)
+	}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	expected := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", "You walked 42000 steps.\r\nKeep going!"},
+		{"text/html; charset=utf-8", `<p style="color:#333">You walked <b>42000</b> steps.</p>`},
+	}
+	for _, e := range expected {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("Failed to read part: %v", err)
+		}
+		if ct := part.Header.Get("Content-Type"); ct != e.contentType {
+			t.Errorf("Expected content type %q, got %q", e.contentType, ct)
+		}
+		// NextPart decodes quoted-printable bodies transparently.
+		body, _ := io.ReadAll(part)
+		if string(body) != e.body {
+			t.Errorf("Expected body %q, got %q", e.body, body)
+		}
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("Expected exactly two parts, got %v", err)
+	}
+}
+
+func TestFakeSender(t *testing.T) {
+	f := &FakeSender{}
+	if _, ok := f.Last(); ok {
+		t.Error("Expected no messages initially")
+	}
+
+	f.Send(context.Background(), Message{To: "a@example.com", Subject: "first"})
+	f.Send(context.Background(), Message{To: "b@example.com", Subject: "second"})
+	if got := len(f.Messages()); got != 2 {
+		t.Errorf("Expected 2 messages, got %d", got)
+	}
+	if last, _ := f.Last(); last.Subject != "second" {
+		t.Errorf("Expected last message 'second', got %q", last.Subject)
+	}
+
+	f.Err = errors.New("unavailable")
+	if err := f.Send(context.Background(), Message{To: "c@example.com"}); err == nil {
+		t.Error("Expected configured error, got none")
+	}
+	if got := len(f.Messages()); got != 2 {
+		t.Errorf("Expected failed send not to be recorded, got %d messages", got)
+	}
+}
+
+func TestNewSenderFromEnv(t *testing.T) {
+	t.Setenv("SMTP_HOST", "")
+	if _, ok := NewSenderFromEnv().(LogSender); !ok {
+		t.Error("Expected LogSender without SMTP_HOST")
+	}
+
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("SMTP_PORT", "")
+	s, ok := NewSenderFromEnv().(*SMTPSender)
+	if !ok {
+		t.Fatal("Expected SMTPSender with SMTP_HOST")
+	}
+	if s.Addr != "smtp.example.com:587" {
+		t.Errorf("Expected default port 587, got %s", s.Addr)
+	}
+}
@@ -0,0 +1,16 @@
+package model
+
+type AdminUserListResponse struct {
+	Users  []User `json:"users"`
+	Total  int64  `json:"total"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+type SuspendUserRequest struct {
+	Reason string `json:"reason" binding:"required,max=255" example:"Spam"`
+}
+
+type SetRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user moderator admin" example:"moderator"`
+}
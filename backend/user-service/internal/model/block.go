@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Friend request statuses. Cancelled requests were withdrawn by their sender
+// or closed because one of the users blocked the other.
+const (
+	FriendRequestPending   = "pending"
+	FriendRequestAccepted  = "accepted"
+	FriendRequestRejected  = "rejected"
+	FriendRequestCancelled = "cancelled"
+)
+
+// Block stops BlockedID from sending friend requests or messages to
+// BlockerID and hides each user from the other's search results. The social
+// service reads this table directly.
+type Block struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	BlockerID uuid.UUID `json:"blocker_id" gorm:"type:uuid;not null;uniqueIndex:idx_blocks_pair"`
+	BlockedID uuid.UUID `json:"blocked_id" gorm:"type:uuid;not null;uniqueIndex:idx_blocks_pair;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+}
+
+type BlockUserRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+// BlockedUserResponse is a user the caller has blocked.
+type BlockedUserResponse struct {
+	UserID    uuid.UUID `json:"user_id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	BlockedAt time.Time `json:"blocked_at"`
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DeletionStatusScheduled  = "scheduled"
+	DeletionStatusInProgress = "in_progress"
+	DeletionStatusCompleted  = "completed"
+	DeletionStatusCancelled  = "cancelled"
+)
+
+const (
+	DeletionStepPending   = "pending"
+	DeletionStepCompleted = "completed"
+	DeletionStepFailed    = "failed"
+)
+
+// AccountDeletion is a user's request to erase their account. Nothing is
+// removed until ScheduledFor, and until then the request can be cancelled.
+// Each service holding user data is tracked as a separate step so the
+// progress of the erasure can be audited.
+type AccountDeletion struct {
+	ID           uuid.UUID      `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID       uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	Status       string         `json:"status" gorm:"type:varchar(20);not null;index" example:"scheduled"`
+	RequestedAt  time.Time      `json:"requested_at" gorm:"not null"`
+	ScheduledFor time.Time      `json:"scheduled_for" gorm:"not null"`
+	CancelledAt  *time.Time     `json:"cancelled_at,omitempty"`
+	CompletedAt  *time.Time     `json:"completed_at,omitempty"`
+	Steps        []DeletionStep `json:"steps" gorm:"foreignKey:DeletionID"`
+}
+
+// DeletionStep records the outcome of purging one service's data.
+type DeletionStep struct {
+	ID          uuid.UUID `json:"-" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	DeletionID  uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+	Position    int       `json:"-" gorm:"not null"`
+	Service     string    `json:"service" gorm:"type:varchar(50);not null" example:"activity-service"`
+	Status      string    `json:"status" gorm:"type:varchar(20);not null" example:"pending"`
+	RowsDeleted int64     `json:"rows_deleted" gorm:"not null;default:0"`
+	Attempts    int       `json:"attempts" gorm:"not null;default:0"`
+	LastError   string    `json:"last_error,omitempty" gorm:"type:varchar(500)"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"not null"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
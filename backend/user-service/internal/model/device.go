@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Push platforms. Android devices use FCM, iOS devices use APNs and
+// browsers use Web Push, whose token is the JSON push subscription.
+const (
+	PlatformAndroid = "android"
+	PlatformIOS     = "ios"
+	PlatformWeb     = "web"
+)
+
+// DeviceToken is where pushes for one of a user's devices are delivered. A
+// token belongs to the last user who registered it, so signing in as
+// someone else on a device moves the token over.
+type DeviceToken struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Platform  string    `json:"platform" gorm:"type:varchar(10);not null;uniqueIndex:idx_device_tokens_platform_token" example:"android"`
+	Token     string    `json:"token" gorm:"type:text;not null;uniqueIndex:idx_device_tokens_platform_token"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+type DeviceTokenRequest struct {
+	Platform string `json:"platform" binding:"required,oneof=android ios web" example:"android"`
+	Token    string `json:"token" binding:"required,max=4096"`
+}
+
+// WebPushConfigResponse holds what browsers need to subscribe to pushes.
+type WebPushConfigResponse struct {
+	VAPIDPublicKey string `json:"vapid_public_key"`
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NutritionPeriod mirrors nutrition-service's totals for a period.
+type NutritionPeriod struct {
+	MealCount     int     `json:"meal_count"`
+	TotalCalories int     `json:"total_calories"`
+	TotalProtein  float64 `json:"total_protein"`
+	TotalCarbs    float64 `json:"total_carbohydrates"`
+	TotalFats     float64 `json:"total_fats"`
+	TotalWaterMl  float64 `json:"total_water_ml"`
+}
+
+// WeeklyDigest recaps a user's week from Monday to Monday (UTC) next to the
+// week before it.
+type WeeklyDigest struct {
+	UserID    uuid.UUID `json:"user_id"`
+	FirstName string    `json:"first_name"`
+	WeekStart time.Time `json:"week_start"`
+	WeekEnd   time.Time `json:"week_end"`
+	// Activity and Nutrition are left out when the service holding the
+	// data is not configured.
+	Activity  *ActivityComparison  `json:"activity,omitempty"`
+	Nutrition *NutritionComparison `json:"nutrition,omitempty"`
+	// Metrics are the headline numbers of both comparisons, in the order
+	// they are shown in the email.
+	Metrics      []DigestMetric `json:"metrics"`
+	Achievements []Achievement  `json:"achievements"`
+}
+
+type ActivityComparison struct {
+	ThisWeek     ActivityPeriod `json:"this_week"`
+	PreviousWeek ActivityPeriod `json:"previous_week"`
+}
+
+type NutritionComparison struct {
+	ThisWeek     NutritionPeriod `json:"this_week"`
+	PreviousWeek NutritionPeriod `json:"previous_week"`
+}
+
+// DigestMetric compares one number between the two weeks. ChangePercent is
+// omitted when the previous week was zero.
+type DigestMetric struct {
+	Key           string   `json:"key" example:"steps"`
+	Label         string   `json:"label" example:"Steps"`
+	Unit          string   `json:"unit,omitempty"`
+	Current       float64  `json:"current"`
+	Previous      float64  `json:"previous"`
+	Change        float64  `json:"change"`
+	ChangePercent *float64 `json:"change_percent,omitempty"`
+}
+
+// DigestDelivery records that a user was emailed the digest of a week, so
+// that replicas and restarts do not send it twice.
+type DigestDelivery struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	WeekStart time.Time `gorm:"primaryKey"`
+	SentAt    time.Time `gorm:"not null"`
+}
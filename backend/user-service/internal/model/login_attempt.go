@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// LoginAttempt tracks failed logins for one account or client IP, keyed as
+// "account:<email>" or "ip:<address>".
+type LoginAttempt struct {
+	Key           string    `gorm:"type:varchar(255);primaryKey"`
+	Failures      int       `gorm:"not null;default:0"`
+	LastFailureAt time.Time `gorm:"not null;default:'epoch'"`
+	LockedUntil   time.Time `gorm:"not null;default:'epoch';index"`
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecoveryCode is a one-time code that replaces a TOTP code when the user
+// has lost their authenticator. Only the SHA-256 of the code is stored.
+type RecoveryCode struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	CodeHash  string     `json:"-" gorm:"type:varchar(64);not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"not null"`
+}
+
+// SecondFactor carries either a TOTP code or a recovery code.
+type SecondFactor struct {
+	Code         string `json:"code" example:"123456"`
+	RecoveryCode string `json:"recovery_code" example:"abcde-fghij"`
+}
+
+type MFAChallengeResponse struct {
+	MFARequired bool      `json:"mfa_required" example:"true"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	SecondFactor
+}
+
+type TOTPSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type TOTPConfirmRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type DisableTOTPRequest struct {
+	Password string `json:"password" binding:"required"`
+	SecondFactor
+}
+
+type MFAStatusResponse struct {
+	TOTPEnabled            bool  `json:"totp_enabled"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
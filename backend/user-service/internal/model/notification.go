@@ -0,0 +1,75 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification types. Message and comment notifications come from
+// social-service and reminders from nutrition-service, both through the
+// internal API.
+const (
+	NotificationFriendRequest         = "friend_request"
+	NotificationFriendRequestAccepted = "friend_request_accepted"
+	NotificationMessage               = "message"
+	NotificationComment               = "comment"
+	NotificationReminder              = "reminder"
+)
+
+// Notification tells a user that someone else did something involving them.
+// EntityID refers to the friend request, message or comment concerned.
+type Notification struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index:idx_notifications_user_created"`
+	Type      string     `json:"type" gorm:"type:varchar(50);not null" example:"friend_request"`
+	ActorID   *uuid.UUID `json:"actor_id,omitempty" gorm:"type:uuid;index"`
+	EntityID  *uuid.UUID `json:"entity_id,omitempty" gorm:"type:uuid"`
+	Message   string     `json:"message" gorm:"type:text;not null" example:"Jane Doe sent you a friend request"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"not null;index:idx_notifications_user_created"`
+}
+
+// NotificationListResponse is a page of notifications, newest first.
+type NotificationListResponse struct {
+	Notifications []Notification `json:"notifications"`
+	Total         int64          `json:"total"`
+	UnreadCount   int64          `json:"unread_count"`
+	Limit         int            `json:"limit"`
+	Offset        int            `json:"offset"`
+}
+
+// CreateNotificationRequest is sent by other services. Reminders have no
+// actor and their message is shown as is. Message and comment
+// notifications name the actor and the message or comment concerned; their
+// text is written from the actor's name.
+type CreateNotificationRequest struct {
+	Type     string     `json:"type" binding:"required,oneof=reminder message comment" example:"reminder"`
+	Message  string     `json:"message" binding:"max=500" example:"Time for a glass of water"`
+	ActorID  *uuid.UUID `json:"actor_id,omitempty"`
+	EntityID *uuid.UUID `json:"entity_id,omitempty"`
+}
+
+type UnreadCountResponse struct {
+	UnreadCount int64 `json:"unread_count"`
+}
+
+type MarkAllReadResponse struct {
+	Updated int64 `json:"updated"`
+}
+
+// NotificationText describes a notification of the given type caused by
+// actorName, e.g. "Jane Doe sent you a friend request".
+func NotificationText(notificationType, actorName string) string {
+	switch notificationType {
+	case NotificationFriendRequest:
+		return actorName + " sent you a friend request"
+	case NotificationFriendRequestAccepted:
+		return actorName + " accepted your friend request"
+	case NotificationMessage:
+		return actorName + " sent you a message"
+	case NotificationComment:
+		return actorName + " commented on your post"
+	}
+	return actorName + " interacted with you"
+}
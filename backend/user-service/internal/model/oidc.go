@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity links a user to an account at an external identity provider.
+type UserIdentity struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Provider  string    `json:"provider" gorm:"type:varchar(50);not null;uniqueIndex:idx_identity_provider_subject"`
+	Subject   string    `json:"-" gorm:"type:varchar(255);not null;uniqueIndex:idx_identity_provider_subject"`
+	Email     string    `json:"email" gorm:"type:varchar(100)"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+}
+
+// OIDCLoginState is a login that was started but not yet completed. It
+// holds the PKCE verifier and nonce, which must not leave the server.
+type OIDCLoginState struct {
+	StateHash    string    `gorm:"type:varchar(64);primaryKey"`
+	Provider     string    `gorm:"type:varchar(50);not null"`
+	Nonce        string    `gorm:"type:varchar(64);not null"`
+	CodeVerifier string    `gorm:"type:varchar(128);not null"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time `gorm:"not null"`
+}
+
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Profile visibility levels. Private profiles are only visible to their
+// owner; friends-only profiles also to accepted friends.
+const (
+	ProfileVisibilityPublic  = "public"
+	ProfileVisibilityFriends = "friends"
+	ProfileVisibilityPrivate = "private"
+)
+
+// PrivacySettings controls what other users can see. Users without a row
+// get DefaultPrivacySettings. The activity and social services read this
+// table directly and apply the same defaults with COALESCE, so changing a
+// default here means changing it there too.
+type PrivacySettings struct {
+	UserID            uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	ProfileVisibility string    `json:"profile_visibility" gorm:"type:varchar(10);not null" example:"friends"`
+	ShareActivities   bool      `json:"share_activities" gorm:"not null"`
+	ShareNutrition    bool      `json:"share_nutrition" gorm:"not null"`
+	ShareWeight       bool      `json:"share_weight" gorm:"not null"`
+	SearchableByEmail bool      `json:"searchable_by_email" gorm:"not null"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// DefaultPrivacySettings shares activity and nutrition with friends, keeps
+// weight private and lets others find the user by email.
+func DefaultPrivacySettings(userID uuid.UUID) PrivacySettings {
+	return PrivacySettings{
+		UserID:            userID,
+		ProfileVisibility: ProfileVisibilityFriends,
+		ShareActivities:   true,
+		ShareNutrition:    true,
+		ShareWeight:       false,
+		SearchableByEmail: true,
+	}
+}
+
+// UpdatePrivacySettingsRequest changes only the fields that are present.
+type UpdatePrivacySettingsRequest struct {
+	ProfileVisibility *string `json:"profile_visibility" binding:"omitempty,oneof=public friends private" example:"friends"`
+	ShareActivities   *bool   `json:"share_activities"`
+	ShareNutrition    *bool   `json:"share_nutrition"`
+	ShareWeight       *bool   `json:"share_weight"`
+	SearchableByEmail *bool   `json:"searchable_by_email"`
+}
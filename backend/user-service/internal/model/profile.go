@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PublicProfile is the profile card shown to other users.
+type PublicProfile struct {
+	ID                uuid.UUID `json:"id"`
+	FirstName         string    `json:"first_name"`
+	LastName          string    `json:"last_name"`
+	AvatarURL         string    `json:"avatar_url,omitempty"`
+	JoinedAt          time.Time `json:"joined_at"`
+	AchievementCount  int64     `json:"achievement_count"`
+	FriendCount       int64     `json:"friend_count"`
+	MutualFriendCount int64     `json:"mutual_friend_count"`
+	IsFriend          bool      `json:"is_friend"`
+	// RecentActivity is only included for the user themselves and for
+	// friends the user shares activities with.
+	RecentActivity *ActivitySummary `json:"recent_activity,omitempty"`
+}
+
+// ActivitySummary mirrors activity-service's summary of a user's recent
+// activity.
+type ActivitySummary struct {
+	Week             ActivityPeriod `json:"week"`
+	Month            ActivityPeriod `json:"month"`
+	LastActivityType string         `json:"last_activity_type,omitempty" example:"running"`
+	LastActivityAt   *time.Time     `json:"last_activity_at,omitempty"`
+}
+
+type ActivityPeriod struct {
+	ActivityCount int `json:"activity_count"`
+	DurationMin   int `json:"duration_min"`
+	Calories      int `json:"calories"`
+	Steps         int `json:"steps"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// FriendSuggestion is a user the caller may know, with the reason they were
+// suggested.
+type FriendSuggestion struct {
+	UserID        uuid.UUID `json:"user_id"`
+	FirstName     string    `json:"first_name"`
+	LastName      string    `json:"last_name"`
+	MutualFriends int       `json:"mutual_friends"`
+	Explanation   string    `json:"explanation" example:"3 mutual friends"`
+}
+
+// MutualFriendsExplanation describes a mutual friend count, e.g.
+// "1 mutual friend" or "3 mutual friends".
+func MutualFriendsExplanation(count int) string {
+	if count == 1 {
+		return "1 mutual friend"
+	}
+	return fmt.Sprintf("%d mutual friends", count)
+}
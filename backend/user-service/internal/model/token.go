@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailChange       = "email_change"
+)
+
+// UserToken is a single-use, expiring token emailed to a user. Only the
+// SHA-256 of the token is stored.
+type UserToken struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Purpose   string    `json:"purpose" gorm:"type:varchar(30);not null"`
+	TokenHash string    `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	// Email is the new address for email_change tokens.
+	Email     string     `json:"-" gorm:"type:varchar(100)"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"not null"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email" example:"string@mail.com"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Upload is an image a user uploaded, together with its thumbnail. The
+// nutrition and activity services read this table to attach uploads to
+// meals and activities.
+type Upload struct {
+	ID           uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID       uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Key          string    `json:"-" gorm:"type:varchar(255);not null"`
+	ThumbnailKey string    `json:"-" gorm:"type:varchar(255);not null"`
+	ContentType  string    `json:"content_type" gorm:"type:varchar(50);not null" example:"image/jpeg"`
+	Size         int64     `json:"size" gorm:"not null"`
+	Width        int       `json:"width" gorm:"not null"`
+	Height       int       `json:"height" gorm:"not null"`
+	URL          string    `json:"url" gorm:"type:varchar(512);not null"`
+	ThumbnailURL string    `json:"thumbnail_url" gorm:"type:varchar(512);not null"`
+	CreatedAt    time.Time `json:"created_at" gorm:"not null"`
+}
+
+type SetAvatarRequest struct {
+	ImageID uuid.UUID `json:"image_id" binding:"required"`
+}
@@ -12,8 +12,24 @@ type User struct {
 	Password  string    `json:"-" gorm:"type:varchar(100);not null"`
 	FirstName string    `json:"first_name" gorm:"type:varchar(50);not null"`
 	LastName  string    `json:"last_name" gorm:"type:varchar(50);not null"`
+	AvatarURL string    `json:"avatar_url,omitempty" gorm:"type:varchar(255)"`
 	CreatedAt time.Time `json:"created_at" gorm:"not null"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+
+	EmailVerified   bool       `json:"email_verified" gorm:"not null;default:false"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	// TokenVersion is embedded in issued JWTs and bumped to revoke them.
+	TokenVersion int `json:"-" gorm:"not null;default:0"`
+
+	TOTPEnabled bool   `json:"totp_enabled" gorm:"column:totp_enabled;not null;default:false"`
+	TOTPSecret  string `json:"-" gorm:"column:totp_secret;type:varchar(64)"`
+	// TOTPLastStep is the time step of the last accepted code, so a code
+	// cannot be used twice.
+	TOTPLastStep int64 `json:"-" gorm:"column:totp_last_step;not null;default:0"`
+
+	Role             string     `json:"role" gorm:"type:varchar(20);not null;default:user" example:"user"`
+	SuspendedAt      *time.Time `json:"suspended_at,omitempty"`
+	SuspensionReason string     `json:"suspension_reason,omitempty" gorm:"type:varchar(255)"`
 }
 
 type LoginRequest struct {
@@ -42,6 +58,16 @@ type RegisterResponse struct {
 	ExpiresAt time.Time `json:"expires_at"`
 }
 
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email" example:"string@mail.com"`
+	Password string `json:"password" binding:"required"`
+}
+
 type UpdateProfileRequest struct {
 	FirstName string `json:"first_name" binding:"required"`
 	LastName  string `json:"last_name" binding:"required"`
@@ -100,7 +126,7 @@ type AcceptRejectRequestBody struct {
 
 type SearchUsersResponse struct {
 	ID        uuid.UUID `json:"id"`
-	Email     string    `json:"email"`
+	Email     string    `json:"email,omitempty"`
 	FirstName string    `json:"first_name"`
 	LastName  string    `json:"last_name"`
 }
@@ -0,0 +1,68 @@
+// Package nutrition fetches the nutrition totals shown in a user's weekly
+// digest from nutrition-service's internal API.
+package nutrition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// Client calls nutrition-service's internal endpoints.
+type Client struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+// NewClientFromEnv configures a Client from NUTRITION_SERVICE_URL and
+// INTERNAL_API_TOKEN. It returns nil when the URL is not set.
+func NewClientFromEnv() *Client {
+	url := os.Getenv("NUTRITION_SERVICE_URL")
+	if url == "" {
+		return nil
+	}
+	return &Client{
+		URL:    url,
+		Token:  os.Getenv("INTERNAL_API_TOKEN"),
+		Client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Period totals the user's meals and water between from (inclusive) and to
+// (exclusive).
+func (c *Client) Period(ctx context.Context, userID uuid.UUID, from, to time.Time) (*model.NutritionPeriod, error) {
+	query := url.Values{"from": {from.Format(time.RFC3339)}, "to": {to.Format(time.RFC3339)}}
+	endpoint := strings.TrimRight(c.URL, "/") + "/internal/users/" + userID.String() + "/nutrition-period?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(auth.InternalTokenHeader, c.Token)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var period model.NutritionPeriod
+	if err := json.NewDecoder(resp.Body).Decode(&period); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return &period, nil
+}
@@ -0,0 +1,59 @@
+package nutrition
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/auth"
+	"github.com/google/uuid"
+)
+
+func TestPeriod(t *testing.T) {
+	userID := uuid.New()
+	from := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/internal/users/"+userID.String()+"/nutrition-period" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("from") != "2025-06-02T00:00:00Z" || r.URL.Query().Get("to") != "2025-06-09T00:00:00Z" {
+			t.Errorf("Unexpected query %s", r.URL.RawQuery)
+		}
+		if r.Header.Get(auth.InternalTokenHeader) != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"meal_count":18,"total_calories":14000,"total_protein":560.5,"total_water_ml":12500}`))
+	}))
+	defer server.Close()
+
+	client := &Client{URL: server.URL + "/", Token: "secret", Client: server.Client()}
+	period, err := client.Period(context.Background(), userID, from, from.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if period.MealCount != 18 || period.TotalCalories != 14000 || period.TotalProtein != 560.5 || period.TotalWaterMl != 12500 {
+		t.Errorf("Unexpected period: %+v", period)
+	}
+
+	client.Token = "wrong"
+	if _, err := client.Period(context.Background(), userID, from, from.AddDate(0, 0, 7)); err == nil {
+		t.Error("Expected error for rejected token, got none")
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv("NUTRITION_SERVICE_URL", "")
+	if NewClientFromEnv() != nil {
+		t.Error("Expected no client without NUTRITION_SERVICE_URL")
+	}
+
+	t.Setenv("NUTRITION_SERVICE_URL", "http://nutrition-service:8082")
+	t.Setenv("INTERNAL_API_TOKEN", "secret")
+	client := NewClientFromEnv()
+	if client == nil || client.URL != "http://nutrition-service:8082" || client.Token != "secret" {
+		t.Errorf("Unexpected client: %+v", client)
+	}
+}
@@ -0,0 +1,37 @@
+package oidc
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+// ProvidersFromEnv builds a Client for each name listed in OIDC_PROVIDERS,
+// for example "google,apple". Each provider is configured by
+// OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID, OIDC_<NAME>_CLIENT_SECRET,
+// OIDC_<NAME>_REDIRECT_URL and optionally OIDC_<NAME>_SCOPES. Providers
+// with missing settings are skipped.
+func ProvidersFromEnv() map[string]Provider {
+	providers := make(map[string]Provider)
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		client := &Client{
+			ProviderName: name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+		}
+		if client.Issuer == "" || client.ClientID == "" || client.RedirectURL == "" {
+			log.Printf("Skipping OIDC provider %s: issuer, client ID and redirect URL are required", name)
+			continue
+		}
+		providers[name] = client
+	}
+	return providers
+}
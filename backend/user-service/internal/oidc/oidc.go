@@ -0,0 +1,286 @@
+// Package oidc implements the client side of OpenID Connect login with the
+// authorization code flow and PKCE. Providers are configured by issuer URL
+// and discovered on first use, so Google, Apple and any standard issuer are
+// handled by the same Client.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Identity is what a provider asserts about the user in the ID token.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+}
+
+// Provider is an identity provider users can log in with.
+type Provider interface {
+	Name() string
+	// AuthCodeURL returns the URL to send the user to. codeChallenge is the
+	// S256 PKCE challenge of the verifier later passed to Exchange.
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	// Exchange redeems an authorization code and returns the verified
+	// identity from the ID token.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error)
+}
+
+// ErrInvalidIDToken is returned when the ID token fails verification.
+var ErrInvalidIDToken = errors.New("invalid id token")
+
+// NewCodeVerifier returns a random PKCE code verifier.
+func NewCodeVerifier() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CodeChallenge returns the S256 challenge for a code verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Client is a Provider for a standard OpenID Connect issuer.
+type Client struct {
+	ProviderName string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	HTTPClient   *http.Client
+
+	mu   sync.Mutex
+	meta *metadata
+	keys map[string]*rsa.PublicKey
+}
+
+func (c *Client) Name() string {
+	return c.ProviderName
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+func (c *Client) getJSON(ctx context.Context, endpoint string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// discover fetches and caches the issuer's metadata.
+func (c *Client) discover(ctx context.Context) (*metadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.meta != nil {
+		return c.meta, nil
+	}
+	var meta metadata
+	if err := c.getJSON(ctx, strings.TrimSuffix(c.Issuer, "/")+"/.well-known/openid-configuration", &meta); err != nil {
+		return nil, fmt.Errorf("failed to discover %s: %w", c.Issuer, err)
+	}
+	if meta.Issuer != c.Issuer {
+		return nil, fmt.Errorf("discovery returned issuer %q, expected %q", meta.Issuer, c.Issuer)
+	}
+	c.meta = &meta
+	return c.meta, nil
+}
+
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	meta, err := c.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	scopes := c.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", c.ClientID)
+	q.Set("redirect_uri", c.RedirectURL)
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+func (c *Client) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	meta, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.RedirectURL)
+	form.Set("client_id", c.ClientID)
+	form.Set("client_secret", c.ClientSecret)
+	form.Set("code_verifier", codeVerifier)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned %d: %s %s", resp.StatusCode, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return nil, fmt.Errorf("token response has no id_token")
+	}
+	return c.verify(ctx, meta, body.IDToken, nonce)
+}
+
+// idClaims are the ID token claims we use. Some providers send
+// email_verified as the string "true", hence the loose type.
+type idClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+}
+
+func (c *Client) verify(ctx context.Context, meta *metadata, raw, nonce string) (*Identity, error) {
+	var claims idClaims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return c.key(ctx, meta, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(c.Issuer),
+		jwt.WithAudience(c.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+	verified := claims.EmailVerified == true || claims.EmailVerified == "true"
+	return &Identity{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: verified,
+		GivenName:     claims.GivenName,
+		FamilyName:    claims.FamilyName,
+	}, nil
+}
+
+// key returns the signing key with the given ID, refetching the key set
+// once when the ID is unknown so that provider key rotation is picked up.
+func (c *Client) key(ctx context.Context, meta *metadata, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key, ok := c.keys[kid]; ok {
+		return key, nil
+	}
+	keys, err := c.fetchKeys(ctx, meta.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+	c.keys = keys
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (c *Client) fetchKeys(ctx context.Context, jwksURI string) (map[string]*rsa.PublicKey, error) {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := c.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
@@ -0,0 +1,146 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/oidc"
+	"github.com/ffabious/healthy-summer/user-service/internal/oidc/oidctest"
+)
+
+func newTestClient(t *testing.T) (*oidc.Client, *oidctest.Issuer) {
+	t.Helper()
+	iss, err := oidctest.NewIssuer("client", "secret")
+	if err != nil {
+		t.Fatalf("Failed to start issuer: %v", err)
+	}
+	t.Cleanup(iss.Close)
+	return &oidc.Client{
+		ProviderName: "mock",
+		Issuer:       iss.URL(),
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://app.example.com/oidc/callback",
+	}, iss
+}
+
+func startLogin(t *testing.T, client *oidc.Client, iss *oidctest.Issuer, verifier, nonce string) string {
+	t.Helper()
+	authURL, err := client.AuthCodeURL(context.Background(), "state-1", nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	code, state, err := iss.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Failed to authorize: %v", err)
+	}
+	if state != "state-1" {
+		t.Errorf("Expected state to round trip, got %q", state)
+	}
+	return code
+}
+
+func TestLoginFlow(t *testing.T) {
+	client, iss := newTestClient(t)
+	iss.SetUser(oidctest.User{Subject: "abc", Email: "jane@example.com", EmailVerified: true, GivenName: "Jane", FamilyName: "Doe"})
+
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	code := startLogin(t, client, iss, verifier, "nonce-1")
+
+	identity, err := client.Exchange(context.Background(), code, verifier, "nonce-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := oidc.Identity{Subject: "abc", Email: "jane@example.com", EmailVerified: true, GivenName: "Jane", FamilyName: "Doe"}
+	if *identity != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *identity)
+	}
+
+	if _, err := client.Exchange(context.Background(), code, verifier, "nonce-1"); err == nil {
+		t.Error("Expected reused code to be rejected")
+	}
+}
+
+func TestAuthCodeURLParameters(t *testing.T) {
+	client, _ := newTestClient(t)
+	authURL, err := client.AuthCodeURL(context.Background(), "s", "n", "challenge")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("Invalid URL: %v", err)
+	}
+	q := u.Query()
+	for key, want := range map[string]string{
+		"response_type":         "code",
+		"client_id":             "client",
+		"scope":                 "openid email profile",
+		"code_challenge":        "challenge",
+		"code_challenge_method": "S256",
+		"state":                 "s",
+		"nonce":                 "n",
+	} {
+		if got := q.Get(key); got != want {
+			t.Errorf("Expected %s=%q, got %q", key, want, got)
+		}
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	client, iss := newTestClient(t)
+	verifier, _ := oidc.NewCodeVerifier()
+	code := startLogin(t, client, iss, verifier, "nonce-1")
+
+	other, _ := oidc.NewCodeVerifier()
+	if _, err := client.Exchange(context.Background(), code, other, "nonce-1"); err == nil {
+		t.Error("Expected exchange with wrong code verifier to fail")
+	}
+}
+
+func TestExchangeRejectsWrongNonce(t *testing.T) {
+	client, iss := newTestClient(t)
+	verifier, _ := oidc.NewCodeVerifier()
+	code := startLogin(t, client, iss, verifier, "nonce-1")
+
+	_, err := client.Exchange(context.Background(), code, verifier, "nonce-2")
+	if !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Errorf("Expected ErrInvalidIDToken, got %v", err)
+	}
+}
+
+func TestExchangeRejectsOtherAudience(t *testing.T) {
+	client, iss := newTestClient(t)
+	verifier, _ := oidc.NewCodeVerifier()
+	code := startLogin(t, client, iss, verifier, "nonce-1")
+
+	// The issuer issues tokens for "client"; a client expecting a different
+	// audience must not accept them.
+	other := &oidc.Client{ProviderName: "mock", Issuer: client.Issuer, ClientID: "other", ClientSecret: "secret", RedirectURL: client.RedirectURL}
+	iss.ClientID = "other"
+	_, err := other.Exchange(context.Background(), code, verifier, "nonce-1")
+	if err == nil {
+		t.Error("Expected token for another audience to be rejected")
+	}
+}
+
+func TestDiscoveryRejectsIssuerMismatch(t *testing.T) {
+	client, _ := newTestClient(t)
+	client.Issuer += "/"
+	if _, err := client.AuthCodeURL(context.Background(), "s", "n", "c"); err == nil {
+		t.Error("Expected discovery with mismatched issuer to fail")
+	}
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// base64url(sha256(verifier)) without padding.
+	got := oidc.CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r7wW1gXk9lQ5eU")
+	if got != "Eg9XzU-GDpUZceSiXvwxvnLuTeBrecWXCt6z8IxGWJk" {
+		t.Errorf("Unexpected challenge %s", got)
+	}
+}
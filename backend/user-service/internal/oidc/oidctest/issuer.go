@@ -0,0 +1,251 @@
+// Package oidctest runs a minimal OpenID Connect issuer for tests and local
+// development. It implements discovery, a key set, an authorization
+// endpoint that immediately approves the configured user, and a token
+// endpoint that enforces PKCE.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "oidctest"
+
+// User is the identity the issuer signs in.
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+}
+
+type grant struct {
+	clientID    string
+	redirectURI string
+	challenge   string
+	nonce       string
+	user        User
+}
+
+// Issuer is a mock issuer. Use SetUser before starting a login to choose
+// who is signed in.
+type Issuer struct {
+	// Server is set when the issuer was started by NewIssuer.
+	Server       *httptest.Server
+	ClientID     string
+	ClientSecret string
+
+	url     string
+	handler http.Handler
+
+	mu     sync.Mutex
+	user   User
+	key    *rsa.PrivateKey
+	grants map[string]grant
+}
+
+// New returns an issuer identified by issuerURL without starting it. Serve
+// its Handler at that URL.
+func New(issuerURL, clientID, clientSecret string) (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	iss := &Issuer{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		url:          issuerURL,
+		key:          key,
+		grants:       make(map[string]grant),
+		user:         User{Subject: "user-1", Email: "user@example.com", EmailVerified: true, GivenName: "Test", FamilyName: "User"},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", iss.discovery)
+	mux.HandleFunc("/jwks", iss.jwks)
+	mux.HandleFunc("/authorize", iss.authorize)
+	mux.HandleFunc("/token", iss.token)
+	iss.handler = mux
+	return iss, nil
+}
+
+// NewIssuer starts an issuer on a local test server. Close it when done.
+func NewIssuer(clientID, clientSecret string) (*Issuer, error) {
+	iss, err := New("", clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	iss.Server = httptest.NewServer(iss.handler)
+	iss.url = iss.Server.URL
+	return iss, nil
+}
+
+// Handler serves the issuer's endpoints.
+func (i *Issuer) Handler() http.Handler {
+	return i.handler
+}
+
+// URL is the issuer identifier.
+func (i *Issuer) URL() string {
+	return i.url
+}
+
+// SetUser changes the identity signed in by later authorizations.
+func (i *Issuer) SetUser(u User) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.user = u
+}
+
+func (i *Issuer) Close() {
+	if i.Server != nil {
+		i.Server.Close()
+	}
+}
+
+// Authorize follows an authorization URL the way a browser would and
+// returns the code and state from the redirect back to the client.
+func (i *Issuer) Authorize(authURL string) (code, state string, err error) {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	location, err := resp.Location()
+	if err != nil {
+		return "", "", err
+	}
+	q := location.Query()
+	return q.Get("code"), q.Get("state"), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (i *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                i.URL(),
+		"authorization_endpoint":                i.URL() + "/authorize",
+		"token_endpoint":                        i.URL() + "/token",
+		"jwks_uri":                              i.URL() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (i *Issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := i.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (i *Issuer) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != i.ClientID ||
+		q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	code := base64.RawURLEncoding.EncodeToString(buf)
+	i.mu.Lock()
+	i.grants[code] = grant{
+		clientID:    q.Get("client_id"),
+		redirectURI: q.Get("redirect_uri"),
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+		user:        i.user,
+	}
+	i.mu.Unlock()
+
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (i *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+	if r.PostForm.Get("client_id") != i.ClientID || r.PostForm.Get("client_secret") != i.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	// Codes are single use, whether or not the exchange succeeds.
+	code := r.PostForm.Get("code")
+	i.mu.Lock()
+	g, ok := i.grants[code]
+	delete(i.grants, code)
+	i.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || g.redirectURI != r.PostForm.Get("redirect_uri") ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            i.URL(),
+		"aud":            g.clientID,
+		"sub":            g.user.Subject,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"nonce":          g.nonce,
+		"email":          g.user.Email,
+		"email_verified": g.user.EmailVerified,
+		"given_name":     g.user.GivenName,
+		"family_name":    g.user.FamilyName,
+	})
+	token.Header["kid"] = keyID
+	idToken, err := token.SignedString(i.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "mock-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
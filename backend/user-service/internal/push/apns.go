@@ -0,0 +1,153 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	APNsProduction = "https://api.push.apple.com"
+	APNsSandbox    = "https://api.sandbox.push.apple.com"
+
+	// Apple rejects provider tokens older than an hour and throttles
+	// clients that renew them more than every 20 minutes.
+	apnsTokenLifetime = 40 * time.Minute
+)
+
+// APNsProvider sends to iOS devices through the APNs HTTP/2 API,
+// authenticating with a token signed by a .p8 key.
+type APNsProvider struct {
+	// Endpoint is APNsProduction or APNsSandbox.
+	Endpoint string
+	// Topic is the app's bundle ID.
+	Topic  string
+	KeyID  string
+	TeamID string
+	Key    *ecdsa.PrivateKey
+	Client *http.Client
+	// Now defaults to time.Now.
+	Now func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	tokenTime time.Time
+}
+
+// ParseAPNsKey reads a .p8 signing key downloaded from the Apple developer
+// portal.
+func ParseAPNsKey(data []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid APNs key: no PEM data")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid APNs key: %w", err)
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid APNs key: not an ECDSA key")
+	}
+	return ecKey, nil
+}
+
+// providerToken returns the cached signed token, renewing it when old.
+func (p *APNsProvider) providerToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+	if p.token != "" && now().Sub(p.tokenTime) < apnsTokenLifetime {
+		return p.token, nil
+	}
+	issued := now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.TeamID,
+		"iat": issued.Unix(),
+	})
+	token.Header["kid"] = p.KeyID
+	signed, err := token.SignedString(p.Key)
+	if err != nil {
+		return "", err
+	}
+	p.token, p.tokenTime = signed, issued
+	return signed, nil
+}
+
+func (p *APNsProvider) Send(ctx context.Context, token string, msg Message) error {
+	providerToken, err := p.providerToken()
+	if err != nil {
+		return err
+	}
+	payload := map[string]any{
+		"aps": map[string]any{
+			"alert": map[string]string{"title": msg.Title, "body": msg.Body},
+			"sound": "default",
+		},
+	}
+	for key, value := range msg.Data {
+		if key != "aps" {
+			payload[key] = value
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = APNsProduction
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimRight(endpoint, "/")+"/3/device/"+url.PathEscape(token), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+providerToken)
+	req.Header.Set("apns-topic", p.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient(p.Client).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var failure struct {
+		Reason string `json:"reason"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&failure)
+	switch {
+	case resp.StatusCode == http.StatusGone,
+		failure.Reason == "BadDeviceToken",
+		failure.Reason == "DeviceTokenNotForTopic":
+		return fmt.Errorf("apns: %s: %w", failure.Reason, ErrInvalidToken)
+	case failure.Reason == "ExpiredProviderToken":
+		// Sign a fresh token for the retry.
+		p.mu.Lock()
+		p.token = ""
+		p.mu.Unlock()
+		return &StatusError{Provider: "apns", StatusCode: http.StatusServiceUnavailable, Reason: failure.Reason}
+	}
+	return &StatusError{Provider: "apns", StatusCode: resp.StatusCode, Reason: failure.Reason}
+}
@@ -0,0 +1,99 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestAPNsProvider(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	parsed, err := ParseAPNsKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: mustPKCS8(t, key)}))
+	if err != nil {
+		t.Fatalf("ParseAPNsKey failed: %v", err)
+	}
+
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "bearer ")
+		parsedToken, err := jwt.Parse(token, func(*jwt.Token) (any, error) {
+			return &key.PublicKey, nil
+		}, jwt.WithValidMethods([]string{"ES256"}))
+		if err != nil || parsedToken.Header["kid"] != "KEY123" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"reason":"InvalidProviderToken"}`))
+			return
+		}
+		if claims, _ := parsedToken.Claims.(jwt.MapClaims); claims["iss"] != "TEAM123" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"reason":"InvalidProviderToken"}`))
+			return
+		}
+		if r.Header.Get("apns-topic") != "com.example.healthysummer" || r.Header.Get("apns-push-type") != "alert" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"reason":"MissingTopic"}`))
+			return
+		}
+		switch strings.TrimPrefix(r.URL.Path, "/3/device/") {
+		case "unregistered":
+			w.WriteHeader(http.StatusGone)
+			w.Write([]byte(`{"reason":"Unregistered"}`))
+		case "malformed":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"reason":"BadDeviceToken"}`))
+		case "abcd":
+			json.NewDecoder(r.Body).Decode(&payload)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	provider := &APNsProvider{
+		Endpoint: server.URL,
+		Topic:    "com.example.healthysummer",
+		KeyID:    "KEY123",
+		TeamID:   "TEAM123",
+		Key:      parsed,
+	}
+	ctx := context.Background()
+	msg := Message{Title: "Healthy Summer", Body: "Hello", Data: map[string]string{"type": "message", "aps": "ignored"}}
+
+	if err := provider.Send(ctx, "abcd", msg); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	aps, _ := payload["aps"].(map[string]any)
+	alert, _ := aps["alert"].(map[string]any)
+	if alert["title"] != "Healthy Summer" || alert["body"] != "Hello" || payload["type"] != "message" {
+		t.Errorf("Unexpected payload: %v", payload)
+	}
+
+	for _, token := range []string{"unregistered", "malformed"} {
+		if err := provider.Send(ctx, token, msg); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken for %s, got %v", token, err)
+		}
+	}
+	var status *StatusError
+	if err := provider.Send(ctx, "other", msg); !errors.As(err, &status) || !status.Temporary() {
+		t.Errorf("Expected a temporary StatusError, got %v", err)
+	}
+}
+
+func TestParseAPNsKeyRejectsInvalidKeys(t *testing.T) {
+	if _, err := ParseAPNsKey([]byte("not a key")); err == nil {
+		t.Error("Expected error for non-PEM data")
+	}
+}
@@ -0,0 +1,82 @@
+package push
+
+import (
+	"log"
+	"os"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+)
+
+// NewDispatcherFromEnv sets up a provider for each platform that is
+// configured:
+//
+//   - Android: FCM_PROJECT_ID and FCM_CREDENTIALS_FILE, the path of a
+//     service account JSON key
+//   - iOS: APNS_KEY_FILE (a .p8 key), APNS_KEY_ID, APNS_TEAM_ID and
+//     APNS_TOPIC, with APNS_SANDBOX=true for development builds
+//   - Web: VAPID_PRIVATE_KEY and VAPID_SUBJECT
+//
+// Pushes to unconfigured platforms are skipped.
+func NewDispatcherFromEnv(store Store) *Dispatcher {
+	providers := make(map[string]Provider)
+
+	if project, file := os.Getenv("FCM_PROJECT_ID"), os.Getenv("FCM_CREDENTIALS_FILE"); project != "" && file != "" {
+		data, err := os.ReadFile(file)
+		if err == nil {
+			var account *ServiceAccount
+			if account, err = ParseServiceAccount(data); err == nil {
+				providers[model.PlatformAndroid] = &FCMProvider{ProjectID: project, Tokens: account}
+			}
+		}
+		if err != nil {
+			log.Printf("FCM push disabled: %v", err)
+		}
+	} else {
+		log.Println("FCM_PROJECT_ID or FCM_CREDENTIALS_FILE not set, Android push disabled")
+	}
+
+	if file := os.Getenv("APNS_KEY_FILE"); file != "" {
+		data, err := os.ReadFile(file)
+		if err == nil {
+			provider := &APNsProvider{
+				Endpoint: APNsProduction,
+				Topic:    os.Getenv("APNS_TOPIC"),
+				KeyID:    os.Getenv("APNS_KEY_ID"),
+				TeamID:   os.Getenv("APNS_TEAM_ID"),
+			}
+			if os.Getenv("APNS_SANDBOX") == "true" {
+				provider.Endpoint = APNsSandbox
+			}
+			if provider.Key, err = ParseAPNsKey(data); err == nil {
+				providers[model.PlatformIOS] = provider
+			}
+		}
+		if err != nil {
+			log.Printf("APNs push disabled: %v", err)
+		}
+	} else {
+		log.Println("APNS_KEY_FILE not set, iOS push disabled")
+	}
+
+	if encoded := os.Getenv("VAPID_PRIVATE_KEY"); encoded != "" {
+		key, err := ParseVAPIDKey(encoded)
+		if err != nil {
+			log.Printf("Web push disabled: %v", err)
+		} else {
+			providers[model.PlatformWeb] = &WebPushProvider{PrivateKey: key, Subject: os.Getenv("VAPID_SUBJECT")}
+		}
+	} else {
+		log.Println("VAPID_PRIVATE_KEY not set, web push disabled")
+	}
+
+	return NewDispatcher(store, providers)
+}
+
+// VAPIDPublicKey returns the key browsers subscribe with, or an empty string
+// when web push is not configured.
+func (d *Dispatcher) VAPIDPublicKey() string {
+	if web, ok := d.Providers[model.PlatformWeb].(*WebPushProvider); ok {
+		return web.PublicKey()
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package push
+
+import (
+	"context"
+	"sync"
+)
+
+// Delivery is a message accepted by a FakeProvider.
+type Delivery struct {
+	Token   string
+	Message Message
+}
+
+// FakeProvider records messages in memory. It is meant for tests.
+type FakeProvider struct {
+	mu sync.Mutex
+	// Invalid tokens are rejected with ErrInvalidToken.
+	Invalid map[string]bool
+	// Failures makes the next sends to a token fail with a temporary error,
+	// counting down to zero.
+	Failures  map[string]int
+	Attempts  int
+	delivered []Delivery
+}
+
+func (f *FakeProvider) Send(_ context.Context, token string, msg Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Attempts++
+	if f.Invalid[token] {
+		return ErrInvalidToken
+	}
+	if f.Failures[token] > 0 {
+		f.Failures[token]--
+		return &StatusError{Provider: "fake", StatusCode: 503, Reason: "unavailable"}
+	}
+	f.delivered = append(f.delivered, Delivery{Token: token, Message: msg})
+	return nil
+}
+
+// Delivered returns a copy of the accepted messages.
+func (f *FakeProvider) Delivered() []Delivery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Delivery(nil), f.delivered...)
+}
@@ -0,0 +1,195 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultFCMEndpoint = "https://fcm.googleapis.com"
+	fcmScope           = "https://www.googleapis.com/auth/firebase.messaging"
+)
+
+// FCMProvider sends to Android devices through the FCM HTTP v1 API.
+type FCMProvider struct {
+	ProjectID string
+	// Endpoint defaults to https://fcm.googleapis.com.
+	Endpoint string
+	Tokens   AccessTokenSource
+	Client   *http.Client
+}
+
+// AccessTokenSource returns an OAuth 2.0 access token.
+type AccessTokenSource interface {
+	AccessToken(ctx context.Context) (string, error)
+}
+
+func (p *FCMProvider) Send(ctx context.Context, token string, msg Message) error {
+	accessToken, err := p.Tokens.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]any{
+		"message": map[string]any{
+			"token":        token,
+			"notification": map[string]string{"title": msg.Title, "body": msg.Body},
+			"data":         msg.Data,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = defaultFCMEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimRight(endpoint, "/")+"/v1/projects/"+url.PathEscape(p.ProjectID)+"/messages:send",
+		bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := httpClient(p.Client).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var failure struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&failure)
+	reason := failure.Error.Status
+	for _, detail := range failure.Error.Details {
+		if detail.ErrorCode != "" {
+			reason = detail.ErrorCode
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound || reason == "UNREGISTERED" {
+		return fmt.Errorf("fcm: %s: %w", reason, ErrInvalidToken)
+	}
+	return &StatusError{Provider: "fcm", StatusCode: resp.StatusCode, Reason: strings.TrimSpace(reason + " " + failure.Error.Message)}
+}
+
+// ServiceAccount exchanges a signed assertion for access tokens using the
+// key of a Google service account, caching each token until shortly before
+// it expires.
+type ServiceAccount struct {
+	ClientEmail string
+	PrivateKey  *rsa.PrivateKey
+	TokenURI    string
+	Client      *http.Client
+	// Now defaults to time.Now.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// ParseServiceAccount reads the JSON key file downloaded from the Google
+// Cloud console.
+func ParseServiceAccount(data []byte) (*ServiceAccount, error) {
+	var file struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid service account file: %w", err)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(file.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid service account key: %w", err)
+	}
+	if file.ClientEmail == "" || file.TokenURI == "" {
+		return nil, fmt.Errorf("service account file is missing client_email or token_uri")
+	}
+	return &ServiceAccount{ClientEmail: file.ClientEmail, PrivateKey: key, TokenURI: file.TokenURI}, nil
+}
+
+func (s *ServiceAccount) AccessToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	if s.token != "" && now().Before(s.expires) {
+		return s.token, nil
+	}
+
+	issued := now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.ClientEmail,
+		"scope": fcmScope,
+		"aud":   s.TokenURI,
+		"iat":   issued.Unix(),
+		"exp":   issued.Add(time.Hour).Unix(),
+	}).SignedString(s.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := httpClient(s.Client).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", &StatusError{Provider: "google oauth", StatusCode: resp.StatusCode, Reason: strings.TrimSpace(string(msg))}
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("invalid token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("token response has no access token")
+	}
+	s.token = token.AccessToken
+	// Renew a minute early so a token never expires in flight.
+	s.expires = issued.Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return s.token, nil
+}
+
+func httpClient(client *http.Client) *http.Client {
+	if client == nil {
+		return http.DefaultClient
+	}
+	return client
+}
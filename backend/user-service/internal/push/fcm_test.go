@@ -0,0 +1,122 @@
+package push
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestFCMProvider(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	var tokenRequests int
+	var sent map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+			http.Error(w, "bad grant", http.StatusBadRequest)
+			return
+		}
+		claims := jwt.MapClaims{}
+		if _, err := jwt.ParseWithClaims(r.FormValue("assertion"), claims, func(*jwt.Token) (any, error) {
+			return &key.PublicKey, nil
+		}, jwt.WithValidMethods([]string{"RS256"})); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if claims["iss"] != "push@example.iam.gserviceaccount.com" || claims["scope"] != fcmScope {
+			http.Error(w, "bad claims", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "access-1", "expires_in": 3600})
+	})
+	mux.HandleFunc("POST /v1/projects/healthy-summer/messages:send", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body struct {
+			Message map[string]any `json:"message"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		switch body.Message["token"] {
+		case "unregistered":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":404,"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`))
+		case "busy":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":{"code":503,"status":"UNAVAILABLE","message":"try later"}}`))
+		default:
+			sent = body.Message
+			w.Write([]byte(`{"name":"projects/healthy-summer/messages/1"}`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: mustPKCS8(t, key)})
+	file, _ := json.Marshal(map[string]string{
+		"client_email": "push@example.iam.gserviceaccount.com",
+		"private_key":  string(pemKey),
+		"token_uri":    server.URL + "/token",
+	})
+	account, err := ParseServiceAccount(file)
+	if err != nil {
+		t.Fatalf("ParseServiceAccount failed: %v", err)
+	}
+	provider := &FCMProvider{ProjectID: "healthy-summer", Endpoint: server.URL, Tokens: account}
+	ctx := context.Background()
+
+	msg := Message{Title: "Healthy Summer", Body: "Hello", Data: map[string]string{"type": "message"}}
+	if err := provider.Send(ctx, "device-1", msg); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	notification, _ := sent["notification"].(map[string]any)
+	if sent["token"] != "device-1" || notification["title"] != "Healthy Summer" || notification["body"] != "Hello" {
+		t.Errorf("Unexpected message sent: %v", sent)
+	}
+	if data, _ := sent["data"].(map[string]any); data["type"] != "message" {
+		t.Errorf("Expected data to be sent, got %v", sent["data"])
+	}
+
+	if err := provider.Send(ctx, "unregistered", msg); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken, got %v", err)
+	}
+	var status *StatusError
+	if err := provider.Send(ctx, "busy", msg); !errors.As(err, &status) || !status.Temporary() {
+		t.Errorf("Expected a temporary StatusError, got %v", err)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("Expected the access token to be cached, got %d token requests", tokenRequests)
+	}
+
+	account.Now = func() time.Time { return time.Now().Add(time.Hour) }
+	if err := provider.Send(ctx, "device-1", msg); err != nil {
+		t.Fatalf("Send after expiry failed: %v", err)
+	}
+	if tokenRequests != 2 {
+		t.Errorf("Expected the access token to be renewed, got %d token requests", tokenRequests)
+	}
+}
+
+func mustPKCS8(t *testing.T, key any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	return der
+}
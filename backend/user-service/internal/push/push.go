@@ -0,0 +1,175 @@
+// Package push delivers notifications to users' devices through Firebase
+// Cloud Messaging, the Apple Push Notification service and Web Push.
+// Temporary failures are retried with exponential backoff and tokens that a
+// provider reports as no longer valid are removed.
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// Message is a notification shown on a device. Data is passed to the app
+// alongside it.
+type Message struct {
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// Provider delivers messages to the tokens of one platform.
+type Provider interface {
+	Send(ctx context.Context, token string, msg Message) error
+}
+
+// ErrInvalidToken is returned, possibly wrapped, by providers when a token
+// was unregistered or never valid. Such tokens are pruned.
+var ErrInvalidToken = errors.New("invalid push token")
+
+// StatusError is an unsuccessful response from a provider.
+type StatusError struct {
+	Provider   string
+	StatusCode int
+	Reason     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: unexpected status %d: %s", e.Provider, e.StatusCode, e.Reason)
+}
+
+// Temporary reports whether the request may succeed if retried.
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode == 429 || e.StatusCode >= 500
+}
+
+// retryable reports whether a failed delivery is worth another attempt.
+// Network errors are retried; rejected requests are not.
+func retryable(err error) bool {
+	if errors.Is(err, ErrInvalidToken) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.Temporary()
+	}
+	return true
+}
+
+// Store keeps device tokens.
+type Store interface {
+	Tokens(ctx context.Context, userID uuid.UUID) ([]model.DeviceToken, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// Result counts what happened to the tokens of a user.
+type Result struct {
+	Sent    int
+	Failed  int
+	Pruned  int
+	Skipped int
+}
+
+// Dispatcher sends messages to every device of a user.
+type Dispatcher struct {
+	Store Store
+	// Providers maps a platform such as model.PlatformAndroid to its
+	// provider. Tokens of platforms without a provider are skipped.
+	Providers map[string]Provider
+	// MaxAttempts is the number of tries per token.
+	MaxAttempts int
+	// BaseDelay is the wait after the first failure; it doubles with every
+	// further failure up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Sleep waits between attempts. Defaults to a timer that stops early
+	// when ctx is done.
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+func NewDispatcher(store Store, providers map[string]Provider) *Dispatcher {
+	return &Dispatcher{
+		Store:       store,
+		Providers:   providers,
+		MaxAttempts: 4,
+		BaseDelay:   time.Second,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// backoff returns the wait before attempt n+1 after n failures.
+func (d *Dispatcher) backoff(failures int) time.Duration {
+	delay := d.BaseDelay
+	for i := 1; i < failures && delay < d.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, d.MaxDelay)
+}
+
+func (d *Dispatcher) sleep(ctx context.Context, wait time.Duration) error {
+	if d.Sleep != nil {
+		return d.Sleep(ctx, wait)
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Send delivers msg to every device of userID. Tokens are tried one after
+// another; an error is only returned when the tokens cannot be loaded.
+func (d *Dispatcher) Send(ctx context.Context, userID uuid.UUID, msg Message) (Result, error) {
+	var result Result
+	devices, err := d.Store.Tokens(ctx, userID)
+	if err != nil {
+		return result, fmt.Errorf("failed to load push tokens for user %s: %w", userID, err)
+	}
+	for _, device := range devices {
+		provider, ok := d.Providers[device.Platform]
+		if !ok {
+			result.Skipped++
+			continue
+		}
+		err := d.deliver(ctx, provider, device.Token, msg)
+		switch {
+		case err == nil:
+			result.Sent++
+		case errors.Is(err, ErrInvalidToken):
+			if err := d.Store.Delete(ctx, device.ID); err != nil {
+				log.Printf("Failed to prune push token %s: %v", device.ID, err)
+				result.Failed++
+				continue
+			}
+			result.Pruned++
+		default:
+			log.Printf("Failed to push to %s device %s of user %s: %v", device.Platform, device.ID, userID, err)
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// deliver sends to one token, retrying temporary failures.
+func (d *Dispatcher) deliver(ctx context.Context, provider Provider, token string, msg Message) error {
+	attempts := max(d.MaxAttempts, 1)
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = provider.Send(ctx, token, msg)
+		if err == nil || !retryable(err) || attempt == attempts {
+			break
+		}
+		if sleepErr := d.sleep(ctx, d.backoff(attempt)); sleepErr != nil {
+			return sleepErr
+		}
+	}
+	return err
+}
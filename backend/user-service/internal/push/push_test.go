@@ -0,0 +1,173 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
+type memoryStore struct {
+	mu      sync.Mutex
+	devices []model.DeviceToken
+}
+
+func (s *memoryStore) Tokens(_ context.Context, userID uuid.UUID) ([]model.DeviceToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var devices []model.DeviceToken
+	for _, d := range s.devices {
+		if d.UserID == userID {
+			devices = append(devices, d)
+		}
+	}
+	return devices, nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, d := range s.devices {
+		if d.ID == id {
+			s.devices = append(s.devices[:i], s.devices[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestDispatcherSend(t *testing.T) {
+	userID := uuid.New()
+	device := func(platform, token string) model.DeviceToken {
+		return model.DeviceToken{ID: uuid.New(), UserID: userID, Platform: platform, Token: token}
+	}
+	store := &memoryStore{devices: []model.DeviceToken{
+		device(model.PlatformAndroid, "ok"),
+		device(model.PlatformAndroid, "flaky"),
+		device(model.PlatformAndroid, "gone"),
+		device(model.PlatformIOS, "no-provider"),
+		{ID: uuid.New(), UserID: uuid.New(), Platform: model.PlatformAndroid, Token: "someone-else"},
+	}}
+	fake := &FakeProvider{
+		Invalid:  map[string]bool{"gone": true},
+		Failures: map[string]int{"flaky": 2},
+	}
+	var waits []time.Duration
+	d := NewDispatcher(store, map[string]Provider{model.PlatformAndroid: fake})
+	d.Sleep = func(_ context.Context, wait time.Duration) error {
+		waits = append(waits, wait)
+		return nil
+	}
+
+	msg := Message{Title: "Healthy Summer", Body: "Hello", Data: map[string]string{"type": "friend_request"}}
+	result, err := d.Send(context.Background(), userID, msg)
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if want := (Result{Sent: 2, Pruned: 1, Skipped: 1}); result != want {
+		t.Errorf("Expected result %+v, got %+v", want, result)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; !reflect.DeepEqual(waits, want) {
+		t.Errorf("Expected backoff %v, got %v", want, waits)
+	}
+
+	var tokens []string
+	for _, delivery := range fake.Delivered() {
+		tokens = append(tokens, delivery.Token)
+		if !reflect.DeepEqual(delivery.Message, msg) {
+			t.Errorf("Unexpected message %+v", delivery.Message)
+		}
+	}
+	if want := []string{"ok", "flaky"}; !reflect.DeepEqual(tokens, want) {
+		t.Errorf("Expected deliveries to %v, got %v", want, tokens)
+	}
+
+	remaining, _ := store.Tokens(context.Background(), userID)
+	for _, d := range remaining {
+		if d.Token == "gone" {
+			t.Error("Expected invalid token to be pruned")
+		}
+	}
+	if len(remaining) != 3 {
+		t.Errorf("Expected 3 remaining tokens, got %d", len(remaining))
+	}
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	userID := uuid.New()
+	store := &memoryStore{devices: []model.DeviceToken{
+		{ID: uuid.New(), UserID: userID, Platform: model.PlatformWeb, Token: "down"},
+	}}
+	fake := &FakeProvider{Failures: map[string]int{"down": 10}}
+	d := NewDispatcher(store, map[string]Provider{model.PlatformWeb: fake})
+	d.Sleep = func(context.Context, time.Duration) error { return nil }
+
+	result, err := d.Send(context.Background(), userID, Message{Body: "Hello"})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if result.Failed != 1 || fake.Attempts != d.MaxAttempts {
+		t.Errorf("Expected 1 failure after %d attempts, got %+v after %d", d.MaxAttempts, result, fake.Attempts)
+	}
+	if len(store.devices) != 1 {
+		t.Error("Expected a failing token to be kept")
+	}
+}
+
+type rejectingProvider struct{ attempts int }
+
+func (p *rejectingProvider) Send(context.Context, string, Message) error {
+	p.attempts++
+	return &StatusError{Provider: "test", StatusCode: 400, Reason: "bad request"}
+}
+
+func TestDispatcherDoesNotRetryRejectedRequests(t *testing.T) {
+	userID := uuid.New()
+	store := &memoryStore{devices: []model.DeviceToken{
+		{ID: uuid.New(), UserID: userID, Platform: model.PlatformAndroid, Token: "token"},
+	}}
+	provider := &rejectingProvider{}
+	d := NewDispatcher(store, map[string]Provider{model.PlatformAndroid: provider})
+	d.Sleep = func(context.Context, time.Duration) error {
+		t.Error("Expected no backoff for a rejected request")
+		return nil
+	}
+
+	result, _ := d.Send(context.Background(), userID, Message{})
+	if result.Failed != 1 || provider.attempts != 1 {
+		t.Errorf("Expected a single failed attempt, got %+v after %d", result, provider.attempts)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := &Dispatcher{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	for failures, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 35: 5 * time.Second, 80: 5 * time.Second} {
+		if got := d.backoff(failures); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", failures, got, want)
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("connection reset"), true},
+		{&StatusError{StatusCode: 503}, true},
+		{&StatusError{StatusCode: 429}, true},
+		{&StatusError{StatusCode: 400}, false},
+		{ErrInvalidToken, false},
+		{context.Canceled, false},
+	}
+	for _, tt := range tests {
+		if got := retryable(tt.err); got != tt.want {
+			t.Errorf("retryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
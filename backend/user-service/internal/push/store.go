@@ -0,0 +1,30 @@
+package push
+
+import (
+	"context"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PostgresStore reads tokens from the device_tokens table.
+type PostgresStore struct {
+	DB *gorm.DB
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{DB: db}
+}
+
+func (s *PostgresStore) Tokens(ctx context.Context, userID uuid.UUID) ([]model.DeviceToken, error) {
+	var devices []model.DeviceToken
+	if err := s.DB.WithContext(ctx).Where("user_id = ?", userID).Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+func (s *PostgresStore) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.DB.WithContext(ctx).Delete(&model.DeviceToken{}, "id = ?", id).Error
+}
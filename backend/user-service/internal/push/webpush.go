@@ -0,0 +1,252 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// WebPushProvider sends to browsers with the Web Push protocol. Payloads are
+// encrypted for the subscription (RFC 8291) and requests identify the
+// server with VAPID (RFC 8292).
+type WebPushProvider struct {
+	// PrivateKey is the VAPID key pair browsers subscribe with.
+	PrivateKey *ecdsa.PrivateKey
+	// Subject is a mailto: or https: URL push services can use to reach the
+	// operator.
+	Subject string
+	// TTL is how long push services keep undelivered messages.
+	TTL    time.Duration
+	Client *http.Client
+	// Now defaults to time.Now.
+	Now func() time.Time
+}
+
+// Subscription is the JSON a browser's PushManager.subscribe returns. It is
+// stored as the device token.
+type Subscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// ParseVAPIDKey reads a VAPID private key in the unpadded base64url form
+// that web push libraries generate.
+func ParseVAPIDKey(encoded string) (*ecdsa.PrivateKey, error) {
+	raw, err := decodeBase64URL(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID key: %w", err)
+	}
+	priv, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID key: %w", err)
+	}
+	// The uncompressed public key is 0x04 || X || Y.
+	pub := priv.PublicKey().Bytes()
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(pub[1:33]),
+			Y:     new(big.Int).SetBytes(pub[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}, nil
+}
+
+// PublicKey returns the application server key browsers subscribe with,
+// unpadded base64url encoded.
+func (p *WebPushProvider) PublicKey() string {
+	pub, err := p.PrivateKey.PublicKey.ECDH()
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(pub.Bytes())
+}
+
+func (p *WebPushProvider) Send(ctx context.Context, token string, msg Message) error {
+	var sub Subscription
+	if err := json.Unmarshal([]byte(token), &sub); err != nil {
+		return fmt.Errorf("webpush: malformed subscription: %w", ErrInvalidToken)
+	}
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || endpoint.Scheme != "https" && endpoint.Scheme != "http" || endpoint.Host == "" {
+		return fmt.Errorf("webpush: invalid endpoint: %w", ErrInvalidToken)
+	}
+
+	payload, err := json.Marshal(map[string]any{"title": msg.Title, "body": msg.Body, "data": msg.Data})
+	if err != nil {
+		return err
+	}
+	body, err := encryptPayload(sub, payload)
+	if err != nil {
+		return fmt.Errorf("webpush: %v: %w", err, ErrInvalidToken)
+	}
+	authorization, err := p.vapidAuthorization(endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	ttl := p.TTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	req.Header.Set("Urgency", "normal")
+
+	resp, err := httpClient(p.Client).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	reason, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return fmt.Errorf("webpush: subscription expired: %w", ErrInvalidToken)
+	}
+	return &StatusError{Provider: "webpush", StatusCode: resp.StatusCode, Reason: strings.TrimSpace(string(reason))}
+}
+
+// vapidAuthorization signs a token for the push service at endpoint.
+func (p *WebPushProvider) vapidAuthorization(endpoint *url.URL) (string, error) {
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": endpoint.Scheme + "://" + endpoint.Host,
+		"exp": now().Add(12 * time.Hour).Unix(),
+		"sub": p.Subject,
+	}).SignedString(p.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	return "vapid t=" + signed + ", k=" + p.PublicKey(), nil
+}
+
+// recordSize is the aes128gcm record size. Payloads are small enough to fit
+// in a single record.
+const recordSize = 4096
+
+// encryptPayload encrypts plaintext for a subscription as a single
+// aes128gcm record, as described in RFC 8291 section 3.4.
+func encryptPayload(sub Subscription, plaintext []byte) ([]byte, error) {
+	uaPublicRaw, err := decodeBase64URL(sub.Keys.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %v", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicRaw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %v", err)
+	}
+	authSecret, err := decodeBase64URL(sub.Keys.Auth)
+	if err != nil || len(authSecret) != 16 {
+		return nil, fmt.Errorf("invalid auth secret")
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return encryptRecord(asPrivate, uaPublic, authSecret, salt, plaintext)
+}
+
+func encryptRecord(asPrivate *ecdh.PrivateKey, uaPublic *ecdh.PublicKey, authSecret, salt, plaintext []byte) ([]byte, error) {
+	if len(plaintext)+17 > recordSize {
+		return nil, fmt.Errorf("payload too large")
+	}
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+	cek, nonce, err := contentKeys(sharedSecret, authSecret, salt, uaPublic.Bytes(), asPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 0x02 marks the last (and only) record; no further padding.
+	record := append(append([]byte(nil), plaintext...), 0x02)
+
+	header := make([]byte, 0, 21+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+	return gcm.Seal(header, nonce, record, nil), nil
+}
+
+// contentKeys derives the content encryption key and nonce from the ECDH
+// secret, the subscription's auth secret and the record salt.
+func contentKeys(sharedSecret, authSecret, salt, uaPublic, asPublic []byte) (cek, nonce []byte, err error) {
+	prkKey, err := hkdf.Extract(sha256.New, sharedSecret, authSecret)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyInfo := "WebPush: info\x00" + string(uaPublic) + string(asPublic)
+	ikm, err := hkdf.Expand(sha256.New, prkKey, keyInfo, 32)
+	if err != nil {
+		return nil, nil, err
+	}
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	cek, err = hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce, err = hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cek, nonce, nil
+}
+
+// decodeBase64URL accepts base64url with or without padding, as browsers
+// and libraries differ.
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,156 @@
+package push
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func mustDecode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := decodeBase64URL(s)
+	if err != nil {
+		t.Fatalf("Failed to decode %q: %v", s, err)
+	}
+	return b
+}
+
+// TestEncryptRecordVector checks the example from RFC 8291 Appendix A.
+func TestEncryptRecordVector(t *testing.T) {
+	asPrivate, err := ecdh.P256().NewPrivateKey(mustDecode(t, "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	if err != nil {
+		t.Fatalf("Invalid sender key: %v", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(mustDecode(t, "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"))
+	if err != nil {
+		t.Fatalf("Invalid receiver key: %v", err)
+	}
+	body, err := encryptRecord(asPrivate, uaPublic,
+		mustDecode(t, "BTBZMqHH6r4Tts7J_aSIgg"),
+		mustDecode(t, "DGv6ra1nlYgDCS1FRnbzlw"),
+		[]byte("When I grow up, I want to be a watermelon"))
+	if err != nil {
+		t.Fatalf("encryptRecord failed: %v", err)
+	}
+	want := "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+	if got := base64.RawURLEncoding.EncodeToString(body); got != want {
+		t.Errorf("Unexpected body:\n got %s\nwant %s", got, want)
+	}
+}
+
+// decryptRecord reverses encryptRecord the way a browser would.
+func decryptRecord(t *testing.T, uaPrivate *ecdh.PrivateKey, authSecret, body []byte) []byte {
+	t.Helper()
+	salt, idLen := body[:16], int(body[20])
+	if rs := binary.BigEndian.Uint32(body[16:20]); rs != recordSize {
+		t.Fatalf("Unexpected record size %d", rs)
+	}
+	asPublicRaw := body[21 : 21+idLen]
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicRaw)
+	if err != nil {
+		t.Fatalf("Invalid sender key in header: %v", err)
+	}
+	sharedSecret, err := uaPrivate.ECDH(asPublic)
+	if err != nil {
+		t.Fatalf("ECDH failed: %v", err)
+	}
+	cek, nonce, err := contentKeys(sharedSecret, authSecret, salt, uaPrivate.PublicKey().Bytes(), asPublicRaw)
+	if err != nil {
+		t.Fatalf("contentKeys failed: %v", err)
+	}
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	plaintext, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	if plaintext[len(plaintext)-1] != 0x02 {
+		t.Fatalf("Missing last record delimiter")
+	}
+	return plaintext[:len(plaintext)-1]
+}
+
+func TestWebPushProvider(t *testing.T) {
+	vapidRaw := make([]byte, 32)
+	rand.Read(vapidRaw)
+	vapidRaw[0] &= 0x7f
+	vapidKey, err := ParseVAPIDKey(base64.RawURLEncoding.EncodeToString(vapidRaw))
+	if err != nil {
+		t.Fatalf("ParseVAPIDKey failed: %v", err)
+	}
+	provider := &WebPushProvider{PrivateKey: vapidKey, Subject: "mailto:push@example.com"}
+
+	uaPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	authSecret := make([]byte, 16)
+	rand.Read(authSecret)
+
+	var received map[string]any
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/expired" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "vapid "), ", ")
+		if len(parts) != 2 || parts[1] != "k="+provider.PublicKey() {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		claims := jwt.MapClaims{}
+		if _, err := jwt.ParseWithClaims(strings.TrimPrefix(parts[0], "t="), claims, func(*jwt.Token) (any, error) {
+			return &vapidKey.PublicKey, nil
+		}, jwt.WithValidMethods([]string{"ES256"})); err != nil || claims["aud"] != server.URL || claims["sub"] != "mailto:push@example.com" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("Content-Encoding") != "aes128gcm" || r.Header.Get("TTL") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(decryptRecord(t, uaPrivate, authSecret, body), &received)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	subscription := func(endpoint string) string {
+		var sub Subscription
+		sub.Endpoint = endpoint
+		sub.Keys.P256dh = base64.RawURLEncoding.EncodeToString(uaPrivate.PublicKey().Bytes())
+		sub.Keys.Auth = base64.RawURLEncoding.EncodeToString(authSecret)
+		data, _ := json.Marshal(sub)
+		return string(data)
+	}
+	ctx := context.Background()
+	msg := Message{Title: "Healthy Summer", Body: "Hello", Data: map[string]string{"type": "message"}}
+
+	if err := provider.Send(ctx, subscription(server.URL+"/push/abc"), msg); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if received["title"] != "Healthy Summer" || received["body"] != "Hello" {
+		t.Errorf("Unexpected payload: %v", received)
+	}
+
+	if err := provider.Send(ctx, subscription(server.URL+"/expired"), msg); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for an expired subscription, got %v", err)
+	}
+	if err := provider.Send(ctx, "not json", msg); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for a malformed subscription, got %v", err)
+	}
+}
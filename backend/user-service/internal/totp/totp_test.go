@@ -0,0 +1,84 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 seed from RFC 6238 appendix B ("12345678901234567890").
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// The RFC lists 8-digit codes; a 6-digit code is the last six digits.
+	tests := []struct {
+		unix     int64
+		expected string
+	}{
+		{unix: 59, expected: "287082"},
+		{unix: 1111111109, expected: "081804"},
+		{unix: 1111111111, expected: "050471"},
+		{unix: 1234567890, expected: "005924"},
+		{unix: 2000000000, expected: "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got != tt.expected {
+			t.Errorf("At %d: expected %s, got %s", tt.unix, tt.expected, got)
+		}
+	}
+
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("Expected error for invalid secret, got none")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, _ := Code(rfcSecret, Step(now))
+	previous, _ := Code(rfcSecret, Step(now)-1)
+	stale, _ := Code(rfcSecret, Step(now)-2)
+
+	if step, ok := Validate(rfcSecret, code, now); !ok || step != Step(now) {
+		t.Errorf("Expected current code to match step %d, got %d %v", Step(now), step, ok)
+	}
+	if step, ok := Validate(rfcSecret, previous, now); !ok || step != Step(now)-1 {
+		t.Errorf("Expected previous code to be accepted within skew, got %d %v", step, ok)
+	}
+	if _, ok := Validate(rfcSecret, stale, now); ok {
+		t.Error("Expected code two periods old to be rejected")
+	}
+	if _, ok := Validate(rfcSecret, code[:3]+" "+code[3:], now); !ok {
+		t.Error("Expected spaces in the code to be ignored")
+	}
+	if _, ok := Validate(rfcSecret, "12345", now); ok {
+		t.Error("Expected short code to be rejected")
+	}
+}
+
+func TestGenerateSecretAndURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("Expected 32 character secret, got %d", len(secret))
+	}
+	if _, err := Code(secret, 1); err != nil {
+		t.Errorf("Expected generated secret to be usable, got %v", err)
+	}
+
+	uri := URI("Healthy Summer", "user@example.com", "ABC")
+	if !strings.HasPrefix(uri, "otpauth://totp/Healthy%20Summer:user@example.com?") {
+		t.Errorf("Unexpected URI label: %s", uri)
+	}
+	for _, param := range []string{"secret=ABC", "issuer=Healthy+Summer", "digits=6", "period=30"} {
+		if !strings.Contains(uri, param) {
+			t.Errorf("Expected URI to contain %q, got %s", param, uri)
+		}
+	}
+}
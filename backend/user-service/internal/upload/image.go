@@ -0,0 +1,135 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+const (
+	// MaxPixels bounds the decoded size of an image so that a small file
+	// cannot expand into gigabytes of memory.
+	MaxPixels = 40_000_000
+	// ThumbnailSize is the longest side of a generated thumbnail.
+	ThumbnailSize = 256
+)
+
+var (
+	// ErrUnsupportedType is returned for files that are not JPEG, PNG or
+	// GIF images, whatever their name or declared type.
+	ErrUnsupportedType = errors.New("unsupported image type")
+	// ErrImageTooLarge is returned for images with more than MaxPixels.
+	ErrImageTooLarge = errors.New("image dimensions are too large")
+)
+
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Sniff detects the type of an image from its content and returns its MIME
+// type and file extension.
+func Sniff(data []byte) (contentType, ext string, err error) {
+	contentType = http.DetectContentType(data)
+	ext, ok := extensions[contentType]
+	if !ok {
+		return "", "", ErrUnsupportedType
+	}
+	return contentType, ext, nil
+}
+
+// Image is a checked upload and its thumbnail.
+type Image struct {
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+	// Thumbnail is a JPEG no larger than ThumbnailSize on either side.
+	Thumbnail []byte
+}
+
+// ProcessImage checks that data is a supported image of reasonable
+// dimensions and renders its thumbnail.
+func ProcessImage(data []byte) (*Image, error) {
+	contentType, ext, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrImageTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+
+	var thumb bytes.Buffer
+	if err := jpeg.Encode(&thumb, Thumbnail(src, ThumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return &Image{
+		ContentType: contentType,
+		Ext:         ext,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+		Thumbnail:   thumb.Bytes(),
+	}, nil
+}
+
+// Thumbnail scales src so that its longest side is at most size, averaging
+// the source pixels behind each thumbnail pixel. Transparent areas are
+// flattened onto white. Smaller images keep their size.
+func Thumbnail(src image.Image, size int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	tw, th := w, h
+	if w > size || h > size {
+		if w >= h {
+			tw, th = size, max(1, h*size/w)
+		} else {
+			tw, th = max(1, w*size/h), size
+		}
+	}
+
+	// Flatten onto white first so that averaging works on opaque pixels.
+	flat := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, b.Min, draw.Over)
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := y*h/th, max((y+1)*h/th, y*h/th+1)
+		for x := 0; x < tw; x++ {
+			x0, x1 := x*w/tw, max((x+1)*w/tw, x*w/tw+1)
+			var r, g, bl, n uint32
+			for sy := y0; sy < y1; sy++ {
+				row := flat.Pix[sy*flat.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4:]
+					r += uint32(p[0])
+					g += uint32(p[1])
+					bl += uint32(p[2])
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(bl / n)
+			dst.Pix[i+3] = 0xff
+		}
+	}
+	return dst
+}
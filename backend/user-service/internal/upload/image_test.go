@@ -0,0 +1,79 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestSniff(t *testing.T) {
+	contentType, ext, err := Sniff(encodePNG(t, 2, 2))
+	if err != nil || contentType != "image/png" || ext != ".png" {
+		t.Errorf("Expected PNG, got %s %s (%v)", contentType, ext, err)
+	}
+	if _, _, err := Sniff([]byte("<html><body>not an image</body></html>")); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType for HTML, got %v", err)
+	}
+}
+
+func TestProcessImage(t *testing.T) {
+	img, err := ProcessImage(encodePNG(t, 600, 300))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if img.ContentType != "image/png" || img.Width != 600 || img.Height != 300 {
+		t.Errorf("Unexpected image: %+v", img)
+	}
+	thumb, err := jpeg.Decode(bytes.NewReader(img.Thumbnail))
+	if err != nil {
+		t.Fatalf("Thumbnail is not a JPEG: %v", err)
+	}
+	if b := thumb.Bounds(); b.Dx() != ThumbnailSize || b.Dy() != ThumbnailSize/2 {
+		t.Errorf("Expected %dx%d thumbnail, got %dx%d", ThumbnailSize, ThumbnailSize/2, b.Dx(), b.Dy())
+	}
+	r, g, _, _ := thumb.At(10, 10).RGBA()
+	if r>>8 < 200 || g>>8 > 60 {
+		t.Errorf("Expected thumbnail to stay red, got r=%d g=%d", r>>8, g>>8)
+	}
+}
+
+func TestProcessImageRejectsHugeDimensions(t *testing.T) {
+	data := encodePNG(t, 1, 1)
+	// Rewrite the IHDR chunk to claim 100000x100000 pixels.
+	ihdr := data[8+8 : 8+8+13]
+	binary.BigEndian.PutUint32(ihdr[0:4], 100000)
+	binary.BigEndian.PutUint32(ihdr[4:8], 100000)
+	binary.BigEndian.PutUint32(data[8+8+13:], crc32.ChecksumIEEE(data[8+4:8+8+13]))
+
+	if _, err := ProcessImage(data); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("Expected ErrImageTooLarge, got %v", err)
+	}
+}
+
+func TestThumbnailKeepsSmallImages(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	if b := Thumbnail(src, ThumbnailSize).Bounds(); b.Dx() != 40 || b.Dy() != 20 {
+		t.Errorf("Expected 40x20, got %dx%d", b.Dx(), b.Dy())
+	}
+}
@@ -0,0 +1,179 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// S3Storage keeps files in a bucket of an S3-compatible service such as AWS
+// S3 or MinIO. Requests use path-style addressing and are signed with AWS
+// Signature Version 4.
+type S3Storage struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PublicURL is the base URL clients load objects from, for example a
+	// CDN in front of the bucket. Defaults to the bucket URL.
+	PublicURL string
+	Client    *http.Client
+	// Now returns the signing time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+func (s *S3Storage) objectURL(key string) string {
+	return strings.TrimRight(s.Endpoint, "/") + "/" + s.Bucket + "/" + escapePath(key)
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if !validKey(key) {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := s.do(req, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !validKey(key) {
+		return nil, ErrNotFound
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete removes an object. S3 reports success for missing keys too.
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Storage) URL(key string) string {
+	if s.PublicURL != "" {
+		return strings.TrimRight(s.PublicURL, "/") + "/" + escapePath(key)
+	}
+	return s.objectURL(key)
+}
+
+// do signs and sends req, turning 404 into ErrNotFound and other non-2xx
+// responses into errors.
+func (s *S3Storage) do(req *http.Request, body []byte) (*http.Response, error) {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	s.sign(req, body, now().UTC())
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("s3 %s %s: unexpected status %d: %s", req.Method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+// sign adds the x-amz-date, x-amz-content-sha256 and Authorization headers.
+func (s *S3Storage) sign(req *http.Request, body []byte, t time.Time) {
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	signature := hex.EncodeToString(hmacSHA256(signingKey(s.SecretAccessKey, date, s.Region, "s3"), stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// signingKey derives the Signature Version 4 key for a day, region and
+// service.
+func signingKey(secret, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// escapePath escapes each segment of a key the way S3 expects.
+func escapePath(key string) string {
+	parts := strings.Split(key, "/")
+	for i, part := range parts {
+		parts[i] = strings.ReplaceAll(url.PathEscape(part), "+", "%2B")
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,76 @@
+package upload
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/ffabious/healthy-summer/user-service/internal/upload/s3test"
+)
+
+func TestSigningKey(t *testing.T) {
+	// Example from the AWS Signature Version 4 documentation.
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	expected := "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d"
+	if got := hex.EncodeToString(key); got != expected {
+		t.Errorf("Expected signing key %s, got %s", expected, got)
+	}
+}
+
+func TestS3Storage(t *testing.T) {
+	ctx := context.Background()
+	server := s3test.NewServer("access", "secret")
+	defer server.Close()
+
+	s := &S3Storage{
+		Endpoint:        server.URL,
+		Region:          "eu-central-1",
+		Bucket:          "uploads",
+		AccessKeyID:     "access",
+		SecretAccessKey: "secret",
+		Client:          server.Client(),
+	}
+
+	if err := s.Put(ctx, "users/1/photo.jpg", []byte("jpeg"), "image/jpeg"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	obj, ok := server.Object("uploads", "users/1/photo.jpg")
+	if !ok || string(obj.Data) != "jpeg" || obj.ContentType != "image/jpeg" {
+		t.Errorf("Unexpected stored object: %+v (%v)", obj, ok)
+	}
+
+	r, err := s.Open(ctx, "users/1/photo.jpg")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "jpeg" {
+		t.Errorf("Expected stored data, got %q", data)
+	}
+
+	if err := s.Delete(ctx, "users/1/photo.jpg"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := s.Open(ctx, "users/1/photo.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+
+	s.SecretAccessKey = "wrong"
+	if err := s.Put(ctx, "users/1/other.jpg", []byte("jpeg"), "image/jpeg"); err == nil {
+		t.Error("Expected a request with a bad signature to fail, got none")
+	}
+}
+
+func TestS3StorageURL(t *testing.T) {
+	s := &S3Storage{Endpoint: "http://minio:9000/", Bucket: "uploads"}
+	if url := s.URL("users/1/a b.jpg"); url != "http://minio:9000/uploads/users/1/a%20b.jpg" {
+		t.Errorf("Unexpected URL %s", url)
+	}
+	s.PublicURL = "https://cdn.example.com/"
+	if url := s.URL("users/1/a.jpg"); url != "https://cdn.example.com/users/1/a.jpg" {
+		t.Errorf("Unexpected public URL %s", url)
+	}
+}
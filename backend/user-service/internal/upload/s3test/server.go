@@ -0,0 +1,131 @@
+// Package s3test runs an in-memory stand-in for an S3-compatible object
+// store. It understands path-style PUT, GET and DELETE object requests and
+// rejects any request whose Signature Version 4 signature does not match
+// its credentials.
+package s3test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Object is a stored object.
+type Object struct {
+	Data        []byte
+	ContentType string
+}
+
+// Server is a running stand-in. Its buckets are created on first use.
+type Server struct {
+	*httptest.Server
+	AccessKeyID     string
+	SecretAccessKey string
+
+	mu      sync.Mutex
+	objects map[string]Object
+}
+
+// NewServer starts a stand-in that accepts requests signed with the given
+// credentials. Close it when done.
+func NewServer(accessKeyID, secretAccessKey string) *Server {
+	s := &Server{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		objects:         make(map[string]Object),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Object returns the object stored under bucket and key.
+func (s *Server) Object(bucket, key string) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[bucket+"/"+key]
+	return obj, ok
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.verify(r, body) {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	if !strings.Contains(name, "/") {
+		http.Error(w, "InvalidRequest", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		s.objects[name] = Object{Data: body, ContentType: r.Header.Get("Content-Type")}
+	case http.MethodGet:
+		obj, ok := s.objects[name]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", obj.ContentType)
+		w.Write(obj.Data)
+	case http.MethodDelete:
+		delete(s.objects, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
+
+var authorization = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=([^/]+)/(\d{8})/([^/]+)/s3/aws4_request, SignedHeaders=([a-z0-9;-]+), Signature=([0-9a-f]{64})$`)
+
+// verify recomputes the request signature from the signed headers.
+func (s *Server) verify(r *http.Request, body []byte) bool {
+	m := authorization.FindStringSubmatch(r.Header.Get("Authorization"))
+	if m == nil || m[1] != s.AccessKeyID {
+		return false
+	}
+	date, region, signedHeaders, signature := m[2], m[3], m[4], m[5]
+	amzDate := r.Header.Get("X-Amz-Date")
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	sum := sha256.Sum256(body)
+	if !strings.HasPrefix(amzDate, date) || payloadHash != hex.EncodeToString(sum[:]) {
+		return false
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	canonicalRequest := strings.Join([]string{
+		r.Method, r.URL.EscapedPath(), r.URL.RawQuery,
+		canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" +
+		date + "/" + region + "/s3/aws4_request\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + s.SecretAccessKey)
+	for _, part := range []string{date, region, "s3", "aws4_request", stringToSign} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+	return hmac.Equal([]byte(hex.EncodeToString(key)), []byte(signature))
+}
@@ -0,0 +1,76 @@
+// Package upload stores user images such as avatars and meal or activity
+// photos. Files go to a Storage backend, either a local directory or an
+// S3-compatible bucket; this package also checks their type and size and
+// generates thumbnails.
+package upload
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// ErrNotFound is returned when an object does not exist.
+var ErrNotFound = errors.New("object not found")
+
+// Storage keeps uploaded files by key.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// URL returns the address clients load the object from.
+	URL(key string) string
+}
+
+// DefaultLocalBaseURL is where user-service serves files kept in local
+// storage.
+const DefaultLocalBaseURL = "/api/uploads/files"
+
+// StorageFromEnv returns an S3Storage when UPLOAD_STORAGE=s3, configured by
+// S3_ENDPOINT, S3_REGION, S3_BUCKET, S3_ACCESS_KEY_ID, S3_SECRET_ACCESS_KEY
+// and optionally S3_PUBLIC_URL. Otherwise files are kept under UPLOAD_DIR
+// (default "uploads") and served from UPLOAD_BASE_URL.
+func StorageFromEnv() Storage {
+	if strings.EqualFold(os.Getenv("UPLOAD_STORAGE"), "s3") {
+		region := os.Getenv("S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		return &S3Storage{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          region,
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PublicURL:       os.Getenv("S3_PUBLIC_URL"),
+		}
+	}
+
+	dir := os.Getenv("UPLOAD_DIR")
+	if dir == "" {
+		dir = "uploads"
+	}
+	baseURL := os.Getenv("UPLOAD_BASE_URL")
+	if baseURL == "" {
+		baseURL = DefaultLocalBaseURL
+	}
+	log.Printf("Storing uploads in %s", dir)
+	return &LocalStorage{Dir: dir, BaseURL: baseURL}
+}
+
+// validKey reports whether key is a relative slash-separated path without
+// empty, "." or ".." segments.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		valid bool
+	}{
+		{"avatars/abc.jpg", true},
+		{"abc.jpg", true},
+		{"", false},
+		{"/etc/passwd", false},
+		{"../secret", false},
+		{"a/../../b", false},
+		{"a//b", false},
+		{"a\\b", false},
+	}
+	for _, tt := range tests {
+		if got := validKey(tt.key); got != tt.valid {
+			t.Errorf("validKey(%q) = %v, want %v", tt.key, got, tt.valid)
+		}
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	s := &LocalStorage{Dir: t.TempDir(), BaseURL: "/api/uploads/files/"}
+
+	if err := s.Put(ctx, "users/1/photo.png", []byte("data"), "image/png"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	r, err := s.Open(ctx, "users/1/photo.png")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "data" {
+		t.Errorf("Expected stored data, got %q", data)
+	}
+	if url := s.URL("users/1/photo.png"); url != "/api/uploads/files/users/1/photo.png" {
+		t.Errorf("Unexpected URL %s", url)
+	}
+
+	if err := s.Delete(ctx, "users/1/photo.png"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := s.Delete(ctx, "users/1/photo.png"); err != nil {
+		t.Errorf("Expected deleting a missing file to succeed, got %v", err)
+	}
+	if _, err := s.Open(ctx, "users/1/photo.png"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if _, err := s.Open(ctx, "../outside"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for invalid key, got %v", err)
+	}
+	if err := s.Put(ctx, "../outside", []byte("x"), "image/png"); err == nil {
+		t.Error("Expected error for key outside the directory, got none")
+	}
+}
+
+func TestStorageFromEnv(t *testing.T) {
+	t.Setenv("UPLOAD_STORAGE", "")
+	t.Setenv("UPLOAD_DIR", "/tmp/uploads")
+	t.Setenv("UPLOAD_BASE_URL", "")
+	local, ok := StorageFromEnv().(*LocalStorage)
+	if !ok || local.Dir != "/tmp/uploads" || local.BaseURL != DefaultLocalBaseURL {
+		t.Errorf("Unexpected local storage: %+v", local)
+	}
+
+	t.Setenv("UPLOAD_STORAGE", "s3")
+	t.Setenv("S3_ENDPOINT", "http://minio:9000")
+	t.Setenv("S3_BUCKET", "uploads")
+	t.Setenv("S3_REGION", "")
+	s3, ok := StorageFromEnv().(*S3Storage)
+	if !ok || s3.Endpoint != "http://minio:9000" || s3.Bucket != "uploads" || s3.Region != "us-east-1" {
+		t.Errorf("Unexpected S3 storage: %+v", s3)
+	}
+}
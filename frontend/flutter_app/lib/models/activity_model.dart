@@ -197,9 +197,21 @@ class GetActivitiesResponseModel {
 
   const GetActivitiesResponseModel({required this.activities});
 
-  factory GetActivitiesResponseModel.fromJson(List<dynamic> json) {
+  factory GetActivitiesResponseModel.fromJson(dynamic json) {
+    List<dynamic> activitiesJson;
+
+    if (json is List) {
+      activitiesJson = json;
+    } else if (json is Map && json.containsKey('activities')) {
+      activitiesJson = json['activities'] as List;
+    } else {
+      throw Exception('Invalid JSON format for activities');
+    }
+
     return GetActivitiesResponseModel(
-      activities: json.map((item) => ActivityModel.fromJson(item)).toList(),
+      activities: activitiesJson
+          .map((item) => ActivityModel.fromJson(item))
+          .toList(),
     );
   }
 